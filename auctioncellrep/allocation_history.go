@@ -0,0 +1,119 @@
+package auctioncellrep
+
+import (
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/lager/v3"
+	"code.cloudfoundry.org/rep"
+)
+
+const (
+	AllocationKindLRP  = "lrp"
+	AllocationKindTask = "task"
+
+	AllocationResultAccepted = "accepted"
+	AllocationResultRejected = "rejected"
+)
+
+// AllocationDecision records whether the cell accepted a single LRP instance
+// or task offered to it in a Perform. ContainerGone is set on accepted
+// decisions restored from a snapshot whose container did not survive the
+// restart.
+type AllocationDecision struct {
+	Kind          string
+	Guid          string
+	RootFS        string
+	MemoryMB      int32
+	DiskMB        int32
+	Tags          []string
+	Result        string
+	Timestamp     time.Time
+	ContainerGone bool
+}
+
+// AllocationHistory keeps the most recent allocation decisions in memory. It
+// is a DecisionNotifier so it can be handed to the perform handler.
+type AllocationHistory struct {
+	clock clock.Clock
+	size  int
+
+	lock      sync.Mutex
+	decisions []AllocationDecision
+}
+
+// NewAllocationHistory returns an AllocationHistory holding at most size
+// decisions. Older ones are dropped first.
+func NewAllocationHistory(clock clock.Clock, size int) *AllocationHistory {
+	return &AllocationHistory{clock: clock, size: size}
+}
+
+func (h *AllocationHistory) NotifyPerform(logger lager.Logger, traceID string, work, failedWork rep.Work) {
+	if h.size <= 0 {
+		return
+	}
+
+	now := h.clock.Now()
+	decisions := make([]AllocationDecision, 0, len(work.LRPs)+len(work.Tasks))
+
+	rejectedLRPs := map[string]struct{}{}
+	for _, lrp := range failedWork.LRPs {
+		rejectedLRPs[lrp.InstanceGUID] = struct{}{}
+	}
+	for _, lrp := range work.LRPs {
+		result := AllocationResultAccepted
+		if _, rejected := rejectedLRPs[lrp.InstanceGUID]; rejected {
+			result = AllocationResultRejected
+		}
+		decisions = append(decisions, AllocationDecision{
+			Kind:      AllocationKindLRP,
+			Guid:      lrp.InstanceGUID,
+			RootFS:    lrp.RootFs,
+			MemoryMB:  lrp.MemoryMB,
+			DiskMB:    lrp.DiskMB,
+			Tags:      lrp.PlacementTags,
+			Result:    result,
+			Timestamp: now,
+		})
+	}
+
+	rejectedTasks := map[string]struct{}{}
+	for _, task := range failedWork.Tasks {
+		rejectedTasks[task.TaskGuid] = struct{}{}
+	}
+	for _, task := range work.Tasks {
+		result := AllocationResultAccepted
+		if _, rejected := rejectedTasks[task.TaskGuid]; rejected {
+			result = AllocationResultRejected
+		}
+		decisions = append(decisions, AllocationDecision{
+			Kind:      AllocationKindTask,
+			Guid:      task.TaskGuid,
+			RootFS:    task.RootFs,
+			MemoryMB:  task.MemoryMB,
+			DiskMB:    task.DiskMB,
+			Tags:      task.PlacementTags,
+			Result:    result,
+			Timestamp: now,
+		})
+	}
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.decisions = append(h.decisions, decisions...)
+	if len(h.decisions) > h.size {
+		h.decisions = h.decisions[len(h.decisions)-h.size:]
+	}
+}
+
+// Decisions returns the recorded decisions, oldest first.
+func (h *AllocationHistory) Decisions() []AllocationDecision {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	decisions := make([]AllocationDecision, len(h.decisions))
+	copy(decisions, h.decisions)
+	return decisions
+}
@@ -0,0 +1,74 @@
+package auctioncellrep_test
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/lager/v3/lagertest"
+	"code.cloudfoundry.org/rep"
+	"code.cloudfoundry.org/rep/auctioncellrep"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AllocationHistory", func() {
+	var (
+		logger    *lagertest.TestLogger
+		fakeClock *fakeclock.FakeClock
+		lrp       rep.LRP
+		task      rep.Task
+	)
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("test")
+		fakeClock = fakeclock.NewFakeClock(time.Unix(1700000000, 0).UTC())
+
+		resource := rep.NewResource(128, 256, 256)
+		lrp = rep.NewLRP("ig-1", models.NewActualLRPKey("pg-1", 0, "domain"), resource, rep.NewPlacementConstraint("some-rootfs", []string{"tag"}, nil))
+		task = rep.NewTask("tg-1", "domain", resource, rep.NewPlacementConstraint("other-rootfs", nil, nil))
+	})
+
+	It("records whether each LRP and task was accepted", func() {
+		history := auctioncellrep.NewAllocationHistory(fakeClock, 10)
+		history.NotifyPerform(logger, "trace-id", rep.Work{LRPs: []rep.LRP{lrp}, Tasks: []rep.Task{task}}, rep.Work{LRPs: []rep.LRP{lrp}})
+
+		Expect(history.Decisions()).To(Equal([]auctioncellrep.AllocationDecision{
+			{
+				Kind:      auctioncellrep.AllocationKindLRP,
+				Guid:      "ig-1",
+				RootFS:    "some-rootfs",
+				MemoryMB:  128,
+				DiskMB:    256,
+				Tags:      []string{"tag"},
+				Result:    auctioncellrep.AllocationResultRejected,
+				Timestamp: fakeClock.Now(),
+			},
+			{
+				Kind:      auctioncellrep.AllocationKindTask,
+				Guid:      "tg-1",
+				RootFS:    "other-rootfs",
+				MemoryMB:  128,
+				DiskMB:    256,
+				Result:    auctioncellrep.AllocationResultAccepted,
+				Timestamp: fakeClock.Now(),
+			},
+		}))
+	})
+
+	It("keeps only the most recent decisions", func() {
+		history := auctioncellrep.NewAllocationHistory(fakeClock, 1)
+		history.NotifyPerform(logger, "trace-id", rep.Work{LRPs: []rep.LRP{lrp}, Tasks: []rep.Task{task}}, rep.Work{})
+
+		decisions := history.Decisions()
+		Expect(decisions).To(HaveLen(1))
+		Expect(decisions[0].Guid).To(Equal("tg-1"))
+	})
+
+	It("records nothing when its size is zero", func() {
+		history := auctioncellrep.NewAllocationHistory(fakeClock, 0)
+		history.NotifyPerform(logger, "trace-id", rep.Work{LRPs: []rep.LRP{lrp}}, rep.Work{})
+
+		Expect(history.Decisions()).To(BeEmpty())
+	})
+})
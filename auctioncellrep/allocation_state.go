@@ -0,0 +1,156 @@
+package auctioncellrep
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+
+	"code.cloudfoundry.org/executor"
+	"code.cloudfoundry.org/lager/v3"
+	"code.cloudfoundry.org/rep"
+)
+
+// allocationState is the on-disk form of an AllocationHistory.
+type allocationState struct {
+	Decisions []AllocationDecision `json:"decisions"`
+}
+
+// Snapshot writes the recorded decisions to path. The file is written and
+// synced next to path before being renamed into place, so a crash leaves
+// either the previous snapshot or the new one, never a partial file.
+func (h *AllocationHistory) Snapshot(path string) error {
+	payload, err := json.Marshal(allocationState{Decisions: h.Decisions()})
+	if err != nil {
+		return err
+	}
+
+	tmpPath := path + ".tmp"
+	err = writeSynced(tmpPath, payload)
+	if err != nil {
+		return err
+	}
+
+	err = os.Rename(tmpPath, path)
+	if err != nil {
+		return err
+	}
+
+	dir, err := os.Open(filepath.Dir(path))
+	if err != nil {
+		return err
+	}
+	defer dir.Close()
+	return dir.Sync()
+}
+
+func writeSynced(path string, payload []byte) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+
+	_, err = file.Write(payload)
+	if err == nil {
+		err = file.Sync()
+	}
+	closeErr := file.Close()
+	if err != nil {
+		return err
+	}
+	return closeErr
+}
+
+// Restore loads decisions written by Snapshot. The history is an audit log,
+// so every decision is kept: accepted decisions whose container is no longer
+// known to the executor are marked ContainerGone rather than dropped. A
+// missing file is not an error.
+func (h *AllocationHistory) Restore(logger lager.Logger, path string, containers []executor.Container) error {
+	logger = logger.Session("restore-allocation-state", lager.Data{"path": path})
+
+	payload, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		logger.Info("no-state-file")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var state allocationState
+	err = json.Unmarshal(payload, &state)
+	if err != nil {
+		return err
+	}
+
+	live := map[string]struct{}{}
+	for _, container := range containers {
+		live[container.Guid] = struct{}{}
+		if instanceGuid, ok := container.Tags[rep.InstanceGuidTag]; ok {
+			live[instanceGuid] = struct{}{}
+		}
+	}
+
+	gone := 0
+	for i, decision := range state.Decisions {
+		if decision.Result != AllocationResultAccepted {
+			continue
+		}
+		if _, ok := live[decision.Guid]; !ok {
+			state.Decisions[i].ContainerGone = true
+			gone++
+		}
+	}
+
+	h.lock.Lock()
+	h.decisions = append(state.Decisions, h.decisions...)
+	if len(h.decisions) > h.size {
+		h.decisions = h.decisions[len(h.decisions)-h.size:]
+	}
+	h.lock.Unlock()
+
+	logger.Info("restored", lager.Data{
+		"restored":       len(state.Decisions),
+		"container-gone": gone,
+	})
+	return nil
+}
+
+// AllocationStateKeeper restores an AllocationHistory from disk when it
+// starts and snapshots it back when signalled.
+type AllocationStateKeeper struct {
+	logger         lager.Logger
+	history        *AllocationHistory
+	executorClient executor.Client
+	path           string
+}
+
+func NewAllocationStateKeeper(logger lager.Logger, history *AllocationHistory, executorClient executor.Client, path string) *AllocationStateKeeper {
+	return &AllocationStateKeeper{
+		logger:         logger.Session("allocation-state-keeper"),
+		history:        history,
+		executorClient: executorClient,
+		path:           path,
+	}
+}
+
+func (k *AllocationStateKeeper) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	containers, err := k.executorClient.ListContainers(k.logger)
+	if err != nil {
+		k.logger.Error("failed-to-list-containers", err)
+	} else {
+		err = k.history.Restore(k.logger, k.path, containers)
+		if err != nil {
+			k.logger.Error("failed-to-restore", err)
+		}
+	}
+
+	close(ready)
+	<-signals
+
+	err = k.history.Snapshot(k.path)
+	if err != nil {
+		k.logger.Error("failed-to-snapshot", err)
+	}
+	return nil
+}
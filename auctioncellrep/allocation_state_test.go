@@ -0,0 +1,150 @@
+package auctioncellrep_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/executor"
+	fake_client "code.cloudfoundry.org/executor/fakes"
+	"code.cloudfoundry.org/lager/v3/lagertest"
+	"code.cloudfoundry.org/rep"
+	"code.cloudfoundry.org/rep/auctioncellrep"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/tedsuo/ifrit"
+)
+
+var _ = Describe("AllocationState", func() {
+	var (
+		logger     *lagertest.TestLogger
+		fakeClock  *fakeclock.FakeClock
+		statePath  string
+		history    *auctioncellrep.AllocationHistory
+		containers []executor.Container
+	)
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("test")
+		fakeClock = fakeclock.NewFakeClock(time.Unix(1700000000, 0).UTC())
+		statePath = filepath.Join(GinkgoT().TempDir(), "allocation-state.json")
+
+		resource := rep.NewResource(128, 256, 256)
+		lrp := rep.NewLRP("ig-1", models.NewActualLRPKey("pg-1", 0, "domain"), resource, rep.NewPlacementConstraint("some-rootfs", []string{"tag"}, nil))
+		rejectedLRP := rep.NewLRP("ig-2", models.NewActualLRPKey("pg-2", 0, "domain"), resource, rep.NewPlacementConstraint("some-rootfs", nil, nil))
+		task := rep.NewTask("tg-1", "domain", resource, rep.NewPlacementConstraint("other-rootfs", nil, nil))
+
+		history = auctioncellrep.NewAllocationHistory(fakeClock, 10)
+		history.NotifyPerform(logger, "trace-id", rep.Work{LRPs: []rep.LRP{lrp, rejectedLRP}, Tasks: []rep.Task{task}}, rep.Work{LRPs: []rep.LRP{rejectedLRP}})
+
+		containers = []executor.Container{
+			{Guid: rep.LRPContainerGuid("pg-1", "ig-1"), Tags: executor.Tags{rep.InstanceGuidTag: "ig-1"}},
+			{Guid: "tg-1"},
+		}
+	})
+
+	It("round-trips the decisions through the state file", func() {
+		Expect(history.Snapshot(statePath)).To(Succeed())
+
+		restored := auctioncellrep.NewAllocationHistory(fakeClock, 10)
+		Expect(restored.Restore(logger, statePath, containers)).To(Succeed())
+		Expect(restored.Decisions()).To(Equal(history.Decisions()))
+	})
+
+	It("marks accepted decisions whose containers are gone", func() {
+		Expect(history.Snapshot(statePath)).To(Succeed())
+
+		restored := auctioncellrep.NewAllocationHistory(fakeClock, 10)
+		Expect(restored.Restore(logger, statePath, containers[1:])).To(Succeed())
+
+		decisions := restored.Decisions()
+		Expect(decisions).To(HaveLen(3))
+		Expect(decisions[0].Guid).To(Equal("ig-1"))
+		Expect(decisions[0].ContainerGone).To(BeTrue())
+		Expect(decisions[1].Guid).To(Equal("ig-2"))
+		Expect(decisions[1].Result).To(Equal(auctioncellrep.AllocationResultRejected))
+		Expect(decisions[1].ContainerGone).To(BeFalse())
+		Expect(decisions[2].Guid).To(Equal("tg-1"))
+		Expect(decisions[2].ContainerGone).To(BeFalse())
+		Expect(logger).To(gbytes.Say(`"container-gone":1`))
+	})
+
+	It("does not leave the temporary file behind", func() {
+		Expect(history.Snapshot(statePath)).To(Succeed())
+		Expect(statePath + ".tmp").NotTo(BeAnExistingFile())
+	})
+
+	It("keeps only the most recent decisions", func() {
+		Expect(history.Snapshot(statePath)).To(Succeed())
+
+		restored := auctioncellrep.NewAllocationHistory(fakeClock, 1)
+		Expect(restored.Restore(logger, statePath, containers)).To(Succeed())
+		Expect(restored.Decisions()).To(HaveLen(1))
+		Expect(restored.Decisions()[0].Guid).To(Equal("tg-1"))
+	})
+
+	It("does nothing when there is no state file", func() {
+		restored := auctioncellrep.NewAllocationHistory(fakeClock, 10)
+		Expect(restored.Restore(logger, statePath, containers)).To(Succeed())
+		Expect(restored.Decisions()).To(BeEmpty())
+	})
+
+	It("fails when the state file is corrupt", func() {
+		Expect(os.WriteFile(statePath, []byte("{"), 0600)).To(Succeed())
+
+		restored := auctioncellrep.NewAllocationHistory(fakeClock, 10)
+		Expect(restored.Restore(logger, statePath, containers)).NotTo(Succeed())
+	})
+
+	Describe("AllocationStateKeeper", func() {
+		var (
+			executorClient *fake_client.FakeClient
+			restored       *auctioncellrep.AllocationHistory
+			process        ifrit.Process
+		)
+
+		BeforeEach(func() {
+			Expect(history.Snapshot(statePath)).To(Succeed())
+
+			executorClient = new(fake_client.FakeClient)
+			executorClient.ListContainersReturns(containers, nil)
+			restored = auctioncellrep.NewAllocationHistory(fakeClock, 10)
+		})
+
+		JustBeforeEach(func() {
+			process = ifrit.Invoke(auctioncellrep.NewAllocationStateKeeper(logger, restored, executorClient, statePath))
+		})
+
+		AfterEach(func() {
+			process.Signal(os.Interrupt)
+			Eventually(process.Wait()).Should(Receive())
+		})
+
+		It("restores the state before becoming ready", func() {
+			Expect(restored.Decisions()).To(Equal(history.Decisions()))
+		})
+
+		It("snapshots the state when signalled", func() {
+			Expect(os.Remove(statePath)).To(Succeed())
+
+			process.Signal(os.Interrupt)
+			Eventually(process.Wait()).Should(Receive(BeNil()))
+			Expect(statePath).To(BeAnExistingFile())
+		})
+
+		Context("when listing containers fails", func() {
+			BeforeEach(func() {
+				executorClient.ListContainersReturns(nil, errors.New("boom"))
+			})
+
+			It("starts without restoring", func() {
+				Expect(restored.Decisions()).To(BeEmpty())
+				Expect(logger).To(gbytes.Say("failed-to-list-containers"))
+			})
+		})
+	})
+})
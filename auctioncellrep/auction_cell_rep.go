@@ -1,14 +1,21 @@
 package auctioncellrep
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
 	"net/url"
 	"sort"
+	"sync"
+	"time"
 
 	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/clock"
+	loggingclient "code.cloudfoundry.org/diego-logging-client"
 	"code.cloudfoundry.org/executor"
+	"code.cloudfoundry.org/executor/containermetrics"
 	"code.cloudfoundry.org/lager/v3"
 	"code.cloudfoundry.org/rep"
 	"code.cloudfoundry.org/rep/evacuation/evacuation_context"
@@ -18,20 +25,36 @@ import (
 
 type AuctionCellClient interface {
 	State(logger lager.Logger) (rep.CellState, bool, error)
-	Perform(logger lager.Logger, traceID string, work rep.Work) (rep.Work, error)
+	Perform(ctx context.Context, logger lager.Logger, traceID string, work rep.Work) (rep.Work, error)
 	Reset() error
+	ResourceAccounting(logger lager.Logger) (rep.ResourceAccounting, error)
+	ValidatePlacement(ctx context.Context, logger lager.Logger, work rep.Work) (rep.PlacementValidation, error)
 }
 
 var ErrCellUnhealthy = errors.New("internal cell healthcheck failed")
 var ErrCellIdMismatch = errors.New("workload cell ID does not match this cell")
 var ErrNotEnoughMemory = errors.New("not enough memory for container and additional memory allocation")
 
+const auctionWinRatioMetric = "AuctionWinRatio"
+
+// performDeadlineMargin is the least time a Perform must have left before its
+// deadline for the rep to attempt allocating containers.
+const performDeadlineMargin = 100 * time.Millisecond
+
+// ClockSkewReporter reports whether the cell's clock has drifted too far from
+// the rest of the deployment to be trusted with new work.
+type ClockSkewReporter interface {
+	Skewed() bool
+}
+
 type AuctionCellRep struct {
 	cellID                   string
 	cellIndex                int
 	repURL                   string
 	stackPathMap             rep.StackPathMap
 	rootFSProviders          rep.RootFSProviders
+	arbitraryRootFSes        []string
+	rootFSQuarantine         *RootFSQuarantine
 	containerMetricsProvider rep.ContainerMetricsProvider
 	zone                     string
 	client                   executor.Client
@@ -40,7 +63,50 @@ type AuctionCellRep struct {
 	optionalPlacementTags    []string
 	enableContainerProxy     bool
 	proxyMemoryAllocation    int
+	proxyMemoryByRootFS      ProxyMemoryByRootFS
 	allocator                BatchContainerAllocator
+	clock                    clock.Clock
+	metricsWarmupEndsAt      time.Time
+	metronClient             loggingclient.IngressClient
+	clockSkewReporter        ClockSkewReporter
+	capacityFactor           *CapacityFactor
+	segmentLimits            map[string]rep.Resources
+	batchAllocationPolicy    BatchAllocationPolicy
+
+	auctionStatsLock sync.Mutex
+	offeredWork      uint64
+	acceptedWork     uint64
+
+	remainingDriftLock sync.Mutex
+	remainingDrifted   bool
+
+	stateCacheTTL        time.Duration
+	stateCacheLock       sync.Mutex
+	stateCache           *cachedState
+	stateCacheGeneration uint64
+}
+
+type cachedState struct {
+	state      rep.CellState
+	healthy    bool
+	computedAt time.Time
+}
+
+// Options holds the optional behaviour of an AuctionCellRep. The zero value
+// leaves all of it off.
+type Options struct {
+	ProxyMemoryByRootFS ProxyMemoryByRootFS
+	MetricsWarmupPeriod time.Duration
+	RootFSQuarantine    *RootFSQuarantine
+	StateCacheTTL       time.Duration
+	ClockSkewReporter   ClockSkewReporter
+	CapacityFactor      *CapacityFactor
+	SegmentLimits       map[string]rep.Resources
+	// BatchAllocationPolicy decides whether Perform keeps the LRPs it could
+	// allocate when others offered with them are rejected. The allocator
+	// must be built with the same policy so it can roll back its partial
+	// allocations.
+	BatchAllocationPolicy BatchAllocationPolicy
 }
 
 func New(
@@ -58,13 +124,23 @@ func New(
 	proxyMemoryAllocation int,
 	enableContainerProxy bool,
 	allocator BatchContainerAllocator,
+	clock clock.Clock,
+	metronClient loggingclient.IngressClient,
+	options Options,
 ) *AuctionCellRep {
+	rootFSQuarantine := options.RootFSQuarantine
+	if rootFSQuarantine == nil {
+		rootFSQuarantine = NewRootFSQuarantine(0, 0, nil, clock, metronClient)
+	}
+
 	return &AuctionCellRep{
 		cellID:                   cellID,
 		cellIndex:                cellIndex,
 		repURL:                   repURL,
 		stackPathMap:             preloadedStackPathMap,
 		rootFSProviders:          rootFSProviders(preloadedStackPathMap, arbitraryRootFSes),
+		arbitraryRootFSes:        arbitraryRootFSes,
+		rootFSQuarantine:         rootFSQuarantine,
 		containerMetricsProvider: containerMetricsProvider,
 		zone:                     zone,
 		client:                   client,
@@ -73,7 +149,16 @@ func New(
 		optionalPlacementTags:    optionalPlacementTags,
 		enableContainerProxy:     enableContainerProxy,
 		proxyMemoryAllocation:    proxyMemoryAllocation,
+		proxyMemoryByRootFS:      options.ProxyMemoryByRootFS,
 		allocator:                allocator,
+		clock:                    clock,
+		metricsWarmupEndsAt:      clock.Now().Add(options.MetricsWarmupPeriod),
+		metronClient:             metronClient,
+		stateCacheTTL:            options.StateCacheTTL,
+		clockSkewReporter:        options.ClockSkewReporter,
+		capacityFactor:           options.CapacityFactor,
+		segmentLimits:            options.SegmentLimits,
+		batchAllocationPolicy:    options.BatchAllocationPolicy,
 	}
 }
 
@@ -93,6 +178,21 @@ func rootFSProviders(preloaded rep.StackPathMap, arbitrary []string) rep.RootFSP
 	return rootFSProviders
 }
 
+// advertisedRootFSProviders leaves the quarantined rootfses out of the
+// providers, so that the auctioneer stops placing work using them here.
+func (a *AuctionCellRep) advertisedRootFSProviders() rep.RootFSProviders {
+	quarantined := a.rootFSQuarantine.QuarantinedStacks()
+	if len(quarantined) == 0 {
+		return a.rootFSProviders
+	}
+
+	stacks := maps.Clone(a.stackPathMap)
+	for _, stack := range quarantined {
+		delete(stacks, stack)
+	}
+	return rootFSProviders(stacks, a.arbitraryRootFSes)
+}
+
 func rootFSURLFromPath(rootfsPath string, stackPathMap rep.StackPathMap) string {
 	url, err := url.Parse(rootfsPath)
 	if err != nil {
@@ -109,10 +209,55 @@ func rootFSURLFromPath(rootfsPath string, stackPathMap rep.StackPathMap) string
 	return rootfsPath
 }
 
+// State reports the cell's inventory. With a positive state cache TTL, calls
+// within the TTL of a successful computation reuse its result unless the
+// cache was invalidated in the meantime.
 func (a *AuctionCellRep) State(logger lager.Logger) (rep.CellState, bool, error) {
 	logger = logger.Session("auction-state")
+
+	if a.stateCacheTTL <= 0 {
+		logger.Info("providing")
+		return a.computeState(logger)
+	}
+
+	a.stateCacheLock.Lock()
+	cached := a.stateCache
+	generation := a.stateCacheGeneration
+	a.stateCacheLock.Unlock()
+
+	if cached != nil && a.clock.Since(cached.computedAt) < a.stateCacheTTL {
+		logger.Debug("provided-from-cache", lager.Data{"computed-at": cached.computedAt})
+		return cached.state, cached.healthy, nil
+	}
+
 	logger.Info("providing")
+	computedAt := a.clock.Now()
+	state, healthy, err := a.computeState(logger)
+	if err != nil {
+		return state, healthy, err
+	}
+
+	a.stateCacheLock.Lock()
+	// a computation that raced with an invalidation may be missing the
+	// change, so it is not kept
+	if a.stateCacheGeneration == generation {
+		a.stateCache = &cachedState{state: state, healthy: healthy, computedAt: computedAt}
+	}
+	a.stateCacheLock.Unlock()
+
+	return state, healthy, nil
+}
+
+// InvalidateState discards the cached state, so the next State call
+// recomputes it.
+func (a *AuctionCellRep) InvalidateState() {
+	a.stateCacheLock.Lock()
+	a.stateCache = nil
+	a.stateCacheGeneration++
+	a.stateCacheLock.Unlock()
+}
 
+func (a *AuctionCellRep) computeState(logger lager.Logger) (rep.CellState, bool, error) {
 	containers, err := a.client.ListContainers(logger)
 	if err != nil {
 		logger.Error("failed-to-fetch-containers", err)
@@ -125,7 +270,7 @@ func (a *AuctionCellRep) State(logger lager.Logger) (rep.CellState, bool, error)
 		return rep.CellState{}, false, err
 	}
 
-	availableResources, err := a.client.RemainingResources(logger)
+	availableResources, err := a.remainingResources(logger)
 	if err != nil {
 		logger.Error("failed-to-get-remaining-resource", err)
 		return rep.CellState{}, false, err
@@ -137,6 +282,11 @@ func (a *AuctionCellRep) State(logger lager.Logger) (rep.CellState, bool, error)
 		return rep.CellState{}, false, err
 	}
 
+	if a.capacityFactor.Reduced() {
+		availableResources = a.capacityFactor.ScaleRemaining(availableResources, totalResources)
+		totalResources = a.capacityFactor.ScaleTotal(totalResources)
+	}
+
 	lrps := []rep.LRP{}
 	tasks := []rep.Task{}
 	startingContainerCount := 0
@@ -223,7 +373,7 @@ func (a *AuctionCellRep) State(logger lager.Logger) (rep.CellState, bool, error)
 		a.cellID,
 		a.cellIndex,
 		a.repURL,
-		a.rootFSProviders,
+		a.advertisedRootFSProviders(),
 		a.convertResources(availableResources),
 		a.convertResources(totalResources),
 		lrps,
@@ -261,6 +411,17 @@ func (a *AuctionCellRep) Metrics(logger lager.Logger) (*rep.ContainerMetricsColl
 	logger.Info("starting")
 	defer logger.Info("complete")
 
+	// utilization is noisy while containers are still being recovered after
+	// a restart, so report none until the warmup period is over
+	if a.clock.Now().Before(a.metricsWarmupEndsAt) {
+		logger.Info("suppressed-during-warmup", lager.Data{"warmup-ends-at": a.metricsWarmupEndsAt})
+		return &rep.ContainerMetricsCollection{
+			CellID: a.cellID,
+			LRPs:   lrpMetrics,
+			Tasks:  taskMetrics,
+		}, nil
+	}
+
 	containers, err := a.client.ListContainers(logger)
 	if err != nil {
 		logger.Error("failed-to-fetch-containers", err)
@@ -278,8 +439,10 @@ func (a *AuctionCellRep) Metrics(logger lager.Logger) (*rep.ContainerMetricsColl
 		guid := container.Guid
 		containerMetrics, ok := metrics[guid]
 		if !ok {
+			// still report the reservation, with no usage, so containers the
+			// executor has not measured yet do not go missing
 			logger.Info("failed-to-get-metrics-for-container", lager.Data{"guid": guid})
-			continue
+			containerMetrics = &containermetrics.CachedContainerMetrics{}
 		}
 
 		switch container.Tags[rep.LifecycleTag] {
@@ -299,13 +462,15 @@ func (a *AuctionCellRep) Metrics(logger lager.Logger) (*rep.ContainerMetricsColl
 				ProcessGUID:            key.ProcessGuid,
 				Index:                  key.Index,
 				InstanceGUID:           instanceKey.InstanceGuid,
-				CachedContainerMetrics: *containerMetrics,
+				Reservation:            containerReservation(container),
+				CachedContainerMetrics: withNetworkDefaults(*containerMetrics),
 			}
 			lrpMetrics = append(lrpMetrics, lrpMetric)
 		case rep.TaskLifecycle:
 			taskMetric := rep.TaskMetric{
 				TaskGUID:               container.Guid,
-				CachedContainerMetrics: *containerMetrics,
+				Reservation:            containerReservation(container),
+				CachedContainerMetrics: withNetworkDefaults(*containerMetrics),
 			}
 			taskMetrics = append(taskMetrics, taskMetric)
 		}
@@ -318,15 +483,40 @@ func (a *AuctionCellRep) Metrics(logger lager.Logger) (*rep.ContainerMetricsColl
 	}, nil
 }
 
+// withNetworkDefaults reports zero network throughput for containers the
+// executor has no network counters for, so consumers always get rx_bytes and
+// tx_bytes.
+func withNetworkDefaults(metrics containermetrics.CachedContainerMetrics) containermetrics.CachedContainerMetrics {
+	var zero uint64
+	if metrics.RxBytes == nil {
+		metrics.RxBytes = &zero
+	}
+	if metrics.TxBytes == nil {
+		metrics.TxBytes = &zero
+	}
+	return metrics
+}
+
+func containerReservation(container executor.Container) rep.ContainerReservation {
+	return rep.ContainerReservation{
+		MemoryMB: container.MemoryMB,
+		DiskMB:   container.DiskMB,
+		MaxPids:  container.MaxPids,
+	}
+}
+
 func containerIsStarting(container *executor.Container) bool {
 	return container.State == executor.StateReserved ||
 		container.State == executor.StateInitializing ||
 		container.State == executor.StateCreated
 }
 
-func (a *AuctionCellRep) Perform(logger lager.Logger, traceID string, work rep.Work) (rep.Work, error) {
-	var failedWork = rep.Work{}
-
+// Perform allocates containers for the work it can, returning the rest as
+// failed. When ctx has a deadline and too little time remains before it,
+// the work is returned as failed without being allocated. Under the
+// all-or-nothing batch allocation policy, rejecting any of the LRPs fails
+// all of them.
+func (a *AuctionCellRep) Perform(ctx context.Context, logger lager.Logger, traceID string, work rep.Work) (rep.Work, error) {
 	logger = logger.Session("auction-work", lager.Data{
 		"lrp-starts": len(work.LRPs),
 		"tasks":      len(work.Tasks),
@@ -338,41 +528,337 @@ func (a *AuctionCellRep) Perform(logger lager.Logger, traceID string, work rep.W
 		return work, ErrCellIdMismatch
 	}
 
-	remainingResources, err := a.client.RemainingResources(logger)
+	if reason := a.workRefusal(ctx); reason != "" {
+		logger.Info("refusing-work", lager.Data{"reason": reason})
+		a.recordAuctionOutcome(logger, work, work)
+		return work, nil
+	}
+
+	remainingResources, totalResources, cordoned, err := a.availableResources(logger)
 	if err != nil {
-		logger.Error("failed-gathering-remaining-reosurces", err)
 		return work, err
 	}
 
-	var lrpRequests []rep.LRP
-	remainingMemory := int32(remainingResources.MemoryMB)
+	fit := a.fitWork(logger, work, remainingResources, totalResources, cordoned)
+
+	// the cell may have started evacuating, or the deadline come closer,
+	// while gathering its resources
+	if reason := a.workRefusal(ctx); reason != "" {
+		logger.Info("refusing-work", lager.Data{"reason": reason})
+		a.recordAuctionOutcome(logger, work, work)
+		return work, nil
+	}
+
+	failedWork := fit.failed()
+	lrpRequests, taskRequests := fit.fitting()
+
+	unallocatedLRPs := a.allocator.BatchLRPAllocationRequest(ctx, logger, traceID, a.enableContainerProxy, a.proxyMemoryAllocation, lrpRequests)
+	if a.batchAllocationPolicy == BatchAllocationPolicyAllOrNothing && len(unallocatedLRPs) > 0 {
+		failedWork.LRPs = fit.lrps
+	} else {
+		failedWork.LRPs = append(failedWork.LRPs, unallocatedLRPs...)
+	}
+	failedWork.Tasks = append(failedWork.Tasks, a.allocator.BatchTaskAllocationRequest(ctx, logger, traceID, taskRequests)...)
+	a.InvalidateState()
+
+	a.recordAuctionOutcome(logger, work, failedWork)
+	return failedWork, nil
+}
+
+// workRefusal returns why the cell refuses all of the work offered to it
+// right now, or an empty string when it considers the work.
+func (a *AuctionCellRep) workRefusal(ctx context.Context) string {
+	switch {
+	case a.clockSkewReporter != nil && a.clockSkewReporter.Skewed():
+		return PlacementReasonClockSkewed
+	case a.evacuationReporter.Evacuating():
+		return PlacementReasonEvacuating
+	case a.deadlineNear(ctx):
+		return PlacementReasonDeadlineNear
+	}
+	return ""
+}
 
-	sort.SliceStable(work.LRPs, func(i, j int) bool {
-		return work.LRPs[i].MemoryMB > work.LRPs[j].MemoryMB
+// availableResources returns the resources left for new work, scaled down
+// when the capacity is reduced, the cell's total resources, and whether the
+// capacity is reduced.
+func (a *AuctionCellRep) availableResources(logger lager.Logger) (executor.ExecutorResources, executor.ExecutorResources, bool, error) {
+	remainingResources, err := a.remainingResources(logger)
+	if err != nil {
+		logger.Error("failed-gathering-remaining-reosurces", err)
+		return executor.ExecutorResources{}, executor.ExecutorResources{}, false, err
+	}
+
+	totalResources, err := a.client.TotalResources(logger)
+	if err != nil {
+		logger.Error("failed-gathering-total-resources", err)
+		return executor.ExecutorResources{}, executor.ExecutorResources{}, false, err
+	}
+
+	if !a.capacityFactor.Reduced() {
+		return remainingResources, totalResources, false, nil
+	}
+
+	remainingResources = a.capacityFactor.ScaleRemaining(remainingResources, totalResources)
+	logger.Info("capacity-reduced", lager.Data{"capacity-factor": a.capacityFactor.Factor(), "remaining-resources": remainingResources})
+	return remainingResources, totalResources, true, nil
+}
+
+// workFit is the fit of offered work on the cell before it is allocated. The
+// LRPs and tasks with a reason are failed without being allocated.
+type workFit struct {
+	lrps        []rep.LRP
+	lrpReasons  []string
+	tasks       []rep.Task
+	taskReasons []string
+}
+
+// fitWork checks work against the remaining memory. Work that could never
+// fit on the cell, even with nothing else running on it, is failed first
+// without being weighed against what remains. LRPs are considered largest
+// first, with the memory of their proxy. Tasks are only held to the
+// remaining memory when the capacity is reduced, otherwise the executor is
+// left to reject what does not fit. Under the all-or-nothing batch
+// allocation policy, one LRP not fitting fails all of them.
+func (a *AuctionCellRep) fitWork(logger lager.Logger, work rep.Work, remainingResources, totalResources executor.ExecutorResources, cordoned bool) workFit {
+	fit := workFit{
+		lrps:        make([]rep.LRP, len(work.LRPs)),
+		lrpReasons:  make([]string, len(work.LRPs)),
+		tasks:       work.Tasks,
+		taskReasons: make([]string, len(work.Tasks)),
+	}
+	copy(fit.lrps, work.LRPs)
+	sort.SliceStable(fit.lrps, func(i, j int) bool {
+		return fit.lrps[i].MemoryMB > fit.lrps[j].MemoryMB
 	})
 
-	for _, lrp := range work.LRPs {
-		requiredMemory := lrp.MemoryMB
-		if a.enableContainerProxy {
-			requiredMemory += int32(a.proxyMemoryAllocation)
+	for i, lrp := range fit.lrps {
+		if exceedsCellCapacity(totalResources, a.lrpMemoryMB(lrp), lrp.DiskMB) {
+			logger.Info("exceeds-cell-capacity", lager.Data{
+				"process-guid":    lrp.ProcessGuid,
+				"index":           lrp.Index,
+				"memory-mb":       a.lrpMemoryMB(lrp),
+				"disk-mb":         lrp.DiskMB,
+				"total-memory-mb": totalResources.MemoryMB,
+				"total-disk-mb":   totalResources.DiskMB,
+			})
+			fit.lrpReasons[i] = PlacementReasonExceedsCellCapacity
 		}
-		if requiredMemory <= remainingMemory {
-			remainingMemory -= requiredMemory
-			lrpRequests = append(lrpRequests, lrp)
-		} else {
-			failedWork.LRPs = append(failedWork.LRPs, lrp)
+	}
+	for i, task := range fit.tasks {
+		if exceedsCellCapacity(totalResources, task.MemoryMB, task.DiskMB) {
+			logger.Info("exceeds-cell-capacity", lager.Data{
+				"task-guid":       task.TaskGuid,
+				"memory-mb":       task.MemoryMB,
+				"disk-mb":         task.DiskMB,
+				"total-memory-mb": totalResources.MemoryMB,
+				"total-disk-mb":   totalResources.DiskMB,
+			})
+			fit.taskReasons[i] = PlacementReasonExceedsCellCapacity
 		}
 	}
 
-	if a.evacuationReporter.Evacuating() {
-		return work, nil
+	remainingMemory := int32(remainingResources.MemoryMB)
+	for i, lrp := range fit.lrps {
+		if fit.lrpReasons[i] != "" {
+			continue
+		}
+		requiredMemory := a.lrpMemoryMB(lrp)
+		if requiredMemory > remainingMemory {
+			fit.lrpReasons[i] = PlacementReasonInsufficientMemory
+			continue
+		}
+		remainingMemory -= requiredMemory
 	}
 
-	unallocatedLRPs := a.allocator.BatchLRPAllocationRequest(logger, traceID, a.enableContainerProxy, a.proxyMemoryAllocation, lrpRequests)
-	failedWork.LRPs = append(failedWork.LRPs, unallocatedLRPs...)
-	failedWork.Tasks = a.allocator.BatchTaskAllocationRequest(logger, traceID, work.Tasks)
+	if a.batchAllocationPolicy == BatchAllocationPolicyAllOrNothing && fit.rejectsLRPs() {
+		logger.Info("rejecting-batch-after-partial-failure", lager.Data{"num-lrps": len(fit.lrps)})
+		fit.rejectLRPBatch()
+		remainingMemory = int32(remainingResources.MemoryMB)
+	}
 
-	return failedWork, nil
+	if cordoned {
+		for i, task := range fit.tasks {
+			if fit.taskReasons[i] != "" {
+				continue
+			}
+			if task.MemoryMB > remainingMemory {
+				fit.taskReasons[i] = PlacementReasonInsufficientMemory
+				continue
+			}
+			remainingMemory -= task.MemoryMB
+		}
+	}
+
+	return fit
+}
+
+// exceedsCellCapacity reports whether a single container request could never
+// fit on this cell, regardless of what else is currently running on it.
+func exceedsCellCapacity(total executor.ExecutorResources, memoryMB, diskMB int32) bool {
+	return int(memoryMB) > total.MemoryMB || int(diskMB) > total.DiskMB
+}
+
+func (a *AuctionCellRep) lrpMemoryMB(lrp rep.LRP) int32 {
+	if !a.enableContainerProxy {
+		return lrp.MemoryMB
+	}
+	return lrp.MemoryMB + int32(a.proxyMemoryByRootFS.ForRootFS(lrp.RootFs, a.proxyMemoryAllocation))
+}
+
+func (f workFit) rejectsLRPs() bool {
+	for _, reason := range f.lrpReasons {
+		if reason != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// rejectLRPBatch fails the LRPs that would otherwise fit, along with the
+// rest of their batch.
+func (f workFit) rejectLRPBatch() {
+	for i, reason := range f.lrpReasons {
+		if reason == "" {
+			f.lrpReasons[i] = PlacementReasonBatchRejected
+		}
+	}
+}
+
+// fitting returns the LRPs and tasks to hand to the allocator.
+func (f workFit) fitting() ([]rep.LRP, []rep.Task) {
+	var lrps []rep.LRP
+	for i, lrp := range f.lrps {
+		if f.lrpReasons[i] == "" {
+			lrps = append(lrps, lrp)
+		}
+	}
+
+	var tasks []rep.Task
+	for i, task := range f.tasks {
+		if f.taskReasons[i] == "" {
+			tasks = append(tasks, task)
+		}
+	}
+	return lrps, tasks
+}
+
+// failed returns the work failed before it reaches the allocator.
+func (f workFit) failed() rep.Work {
+	var failed rep.Work
+	for i, lrp := range f.lrps {
+		if f.lrpReasons[i] != "" {
+			failed.LRPs = append(failed.LRPs, lrp)
+		}
+	}
+	for i, task := range f.tasks {
+		if f.taskReasons[i] != "" {
+			failed.Tasks = append(failed.Tasks, task)
+		}
+	}
+	return failed
+}
+
+// deadlineNear reports whether ctx is done or its deadline is closer than
+// performDeadlineMargin on the cell's clock, leaving too little time to
+// allocate containers.
+func (a *AuctionCellRep) deadlineNear(ctx context.Context) bool {
+	if ctx.Err() != nil {
+		return true
+	}
+	deadline, ok := ctx.Deadline()
+	return ok && deadline.Sub(a.clock.Now()) < performDeadlineMargin
+}
+
+// recordAuctionOutcome keeps a running count of the work this cell was
+// offered and accepted, and emits the ratio between the two. A low ratio on
+// a cell with free capacity points at scoring or placement tag issues.
+func (a *AuctionCellRep) recordAuctionOutcome(logger lager.Logger, offered, failed rep.Work) {
+	offeredCount := uint64(len(offered.LRPs) + len(offered.Tasks))
+	if offeredCount == 0 {
+		return
+	}
+	acceptedCount := offeredCount - uint64(len(failed.LRPs)+len(failed.Tasks))
+
+	a.auctionStatsLock.Lock()
+	a.offeredWork += offeredCount
+	a.acceptedWork += acceptedCount
+	ratio := float64(a.acceptedWork) / float64(a.offeredWork)
+	a.auctionStatsLock.Unlock()
+
+	err := a.metronClient.SendComponentMetric(auctionWinRatioMetric, ratio, "ratio")
+	if err != nil {
+		logger.Error("failed-to-send-auction-win-ratio-metric", err)
+	}
+}
+
+// ResourceAccounting explains how the cell's total capacity is spent.
+// Allocated excludes the memory added to LRP containers for their proxy,
+// which is reported as ProxyReserved instead, and SystemReserved is whatever
+// the executor withholds beyond the containers' own allocations. It is
+// computed from the remaining resources as the executor reports them, so a
+// negative remaining amount shows up as Drift rather than in SystemReserved.
+func (a *AuctionCellRep) ResourceAccounting(logger lager.Logger) (rep.ResourceAccounting, error) {
+	logger = logger.Session("resource-accounting")
+
+	containers, err := a.client.ListContainers(logger)
+	if err != nil {
+		logger.Error("failed-to-fetch-containers", err)
+		return rep.ResourceAccounting{}, err
+	}
+
+	totalResources, err := a.client.TotalResources(logger)
+	if err != nil {
+		logger.Error("failed-to-get-total-resources", err)
+		return rep.ResourceAccounting{}, err
+	}
+
+	reportedRemaining, err := a.client.RemainingResources(logger)
+	if err != nil {
+		logger.Error("failed-to-get-remaining-resource", err)
+		return rep.ResourceAccounting{}, err
+	}
+	remainingResources := a.clampRemainingResources(logger, reportedRemaining)
+
+	var allocated, proxyReserved executor.ExecutorResources
+	for _, container := range containers {
+		memoryMB := container.MemoryMB
+		if a.enableContainerProxy && memoryMB > 0 && container.Tags[rep.LifecycleTag] == rep.LRPLifecycle {
+			rootFS := rootFSURLFromPath(container.RootFSPath, a.stackPathMap)
+			proxyMemoryMB := a.proxyMemoryByRootFS.ForRootFS(rootFS, a.proxyMemoryAllocation)
+			if proxyMemoryMB > memoryMB {
+				proxyMemoryMB = memoryMB
+			}
+			proxyReserved.MemoryMB += proxyMemoryMB
+			memoryMB -= proxyMemoryMB
+		}
+
+		allocated.MemoryMB += memoryMB
+		allocated.DiskMB += container.DiskMB
+		allocated.Containers++
+	}
+
+	systemReserved := executor.ExecutorResources{
+		MemoryMB:   totalResources.MemoryMB - proxyReserved.MemoryMB - allocated.MemoryMB - reportedRemaining.MemoryMB,
+		DiskMB:     totalResources.DiskMB - proxyReserved.DiskMB - allocated.DiskMB - reportedRemaining.DiskMB,
+		Containers: totalResources.Containers - proxyReserved.Containers - allocated.Containers - reportedRemaining.Containers,
+	}
+
+	drift := executor.ExecutorResources{
+		MemoryMB:   remainingResources.MemoryMB - reportedRemaining.MemoryMB,
+		DiskMB:     remainingResources.DiskMB - reportedRemaining.DiskMB,
+		Containers: remainingResources.Containers - reportedRemaining.Containers,
+	}
+
+	return rep.ResourceAccounting{
+		Total:          a.convertResources(totalResources),
+		SystemReserved: a.convertResources(systemReserved),
+		ProxyReserved:  a.convertResources(proxyReserved),
+		Allocated:      a.convertResources(allocated),
+		Remaining:      a.convertResources(remainingResources),
+		Drift:          a.convertResources(drift),
+		Segments:       SegmentCapacities(containers, a.segmentLimits),
+	}, nil
 }
 
 func (a *AuctionCellRep) convertResources(resources executor.ExecutorResources) rep.Resources {
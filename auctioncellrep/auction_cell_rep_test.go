@@ -1,12 +1,17 @@
 package auctioncellrep_test
 
 import (
+	"context"
 	"errors"
+	"time"
 
 	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/clock/fakeclock"
+	mfakes "code.cloudfoundry.org/diego-logging-client/testhelpers"
 	"code.cloudfoundry.org/executor"
 	"code.cloudfoundry.org/executor/containermetrics"
 	fake_client "code.cloudfoundry.org/executor/fakes"
+	"code.cloudfoundry.org/lager/v3"
 	"code.cloudfoundry.org/lager/v3/lagertest"
 	"code.cloudfoundry.org/rep"
 	"code.cloudfoundry.org/rep/auctioncellrep"
@@ -14,6 +19,7 @@ import (
 	"code.cloudfoundry.org/rep/evacuation/evacuation_context/fake_evacuation_context"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
 )
 
 const (
@@ -38,8 +44,18 @@ var _ = Describe("AuctionCellRep", func() {
 		placementTags, optionalPlacementTags []string
 		enableContainerProxy                 bool
 		proxyMemoryAllocation                int
+		proxyMemoryByRootFS                  auctioncellrep.ProxyMemoryByRootFS
 
 		fakeContainerAllocator *fakes.FakeBatchContainerAllocator
+		fakeClock              *fakeclock.FakeClock
+		metricsWarmupPeriod    time.Duration
+		fakeMetronClient       *mfakes.FakeIngressClient
+		rootFSQuarantine       *auctioncellrep.RootFSQuarantine
+		stateCacheTTL          time.Duration
+		clockSkewReporter      auctioncellrep.ClockSkewReporter
+		capacityFactor         *auctioncellrep.CapacityFactor
+		segmentLimits          map[string]rep.Resources
+		batchAllocationPolicy  auctioncellrep.BatchAllocationPolicy
 	)
 
 	BeforeEach(func() {
@@ -54,6 +70,16 @@ var _ = Describe("AuctionCellRep", func() {
 		commonErr = errors.New("Failed to fetch")
 		enableContainerProxy = false
 		proxyMemoryAllocation = 12
+		proxyMemoryByRootFS = nil
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		metricsWarmupPeriod = 0
+		fakeMetronClient = new(mfakes.FakeIngressClient)
+		rootFSQuarantine = auctioncellrep.NewRootFSQuarantine(1, 0, rep.StackPathMap{linuxStack: linuxPath}, fakeClock, fakeMetronClient)
+		stateCacheTTL = 0
+		clockSkewReporter = nil
+		capacityFactor = auctioncellrep.NewCapacityFactor()
+		segmentLimits = nil
+		batchAllocationPolicy = auctioncellrep.BatchAllocationPolicyBestEffort
 		client.HealthyReturns(true)
 	})
 
@@ -73,6 +99,18 @@ var _ = Describe("AuctionCellRep", func() {
 			proxyMemoryAllocation,
 			enableContainerProxy,
 			fakeContainerAllocator,
+			fakeClock,
+			fakeMetronClient,
+			auctioncellrep.Options{
+				ProxyMemoryByRootFS:   proxyMemoryByRootFS,
+				MetricsWarmupPeriod:   metricsWarmupPeriod,
+				RootFSQuarantine:      rootFSQuarantine,
+				StateCacheTTL:         stateCacheTTL,
+				ClockSkewReporter:     clockSkewReporter,
+				CapacityFactor:        capacityFactor,
+				SegmentLimits:         segmentLimits,
+				BatchAllocationPolicy: batchAllocationPolicy,
+			},
 		)
 	})
 
@@ -97,6 +135,33 @@ var _ = Describe("AuctionCellRep", func() {
 			})
 		})
 
+		Context("during the metrics warmup period", func() {
+			BeforeEach(func() {
+				metricsWarmupPeriod = time.Minute
+				client.ListContainersReturns([]executor.Container{createContainer(executor.StateRunning, rep.LRPLifecycle)}, nil)
+				fakeContainerMetricsProvider.MetricsReturns(map[string]*containermetrics.CachedContainerMetrics{
+					"some-container-guid": {MetricGUID: "some-metric-guid"},
+				})
+			})
+
+			It("suppresses the container metrics", func() {
+				Expect(metrics.CellID).To(Equal(cellID))
+				Expect(metrics.LRPs).To(BeEmpty())
+				Expect(metrics.Tasks).To(BeEmpty())
+				Expect(logger).To(gbytes.Say("suppressed-during-warmup"))
+			})
+
+			Context("once the warmup period has elapsed", func() {
+				It("reports the container metrics", func() {
+					fakeClock.Increment(time.Minute)
+
+					metrics, err := cellRep.Metrics(logger)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(metrics.LRPs).To(HaveLen(1))
+				})
+			})
+		})
+
 		Context("when the rep has an lrp container", func() {
 			var metricValues containermetrics.CachedContainerMetrics
 			BeforeEach(func() {
@@ -123,7 +188,38 @@ var _ = Describe("AuctionCellRep", func() {
 				Expect(lrpMetrics.ProcessGUID).To(Equal("some-process-guid"))
 				Expect(lrpMetrics.InstanceGUID).To(Equal("some-instance-guid"))
 				Expect(lrpMetrics.Index).To(Equal(int32(1)))
-				Expect(lrpMetrics.CachedContainerMetrics).To(Equal(metricValues))
+
+				zero := uint64(0)
+				expected := metricValues
+				expected.RxBytes = &zero
+				expected.TxBytes = &zero
+				Expect(lrpMetrics.CachedContainerMetrics).To(Equal(expected))
+			})
+
+			It("does not modify the metrics cached by the provider", func() {
+				Expect(metricValues.RxBytes).To(BeNil())
+				Expect(metricValues.TxBytes).To(BeNil())
+			})
+
+			Context("when the executor reports network throughput", func() {
+				BeforeEach(func() {
+					rx, tx := uint64(1024), uint64(2048)
+					metricValues.RxBytes = &rx
+					metricValues.TxBytes = &tx
+				})
+
+				It("includes it in the metrics", func() {
+					Expect(*metrics.LRPs[0].RxBytes).To(Equal(uint64(1024)))
+					Expect(*metrics.LRPs[0].TxBytes).To(Equal(uint64(2048)))
+				})
+			})
+
+			It("should return the resources reserved for the container", func() {
+				Expect(metrics.LRPs[0].Reservation).To(Equal(rep.ContainerReservation{
+					MemoryMB: 20,
+					DiskMB:   10,
+					MaxPids:  100,
+				}))
 			})
 		})
 
@@ -151,7 +247,36 @@ var _ = Describe("AuctionCellRep", func() {
 
 				taskMetrics := metrics.Tasks[0]
 				Expect(taskMetrics.TaskGUID).To(Equal("some-container-guid"))
-				Expect(taskMetrics.CachedContainerMetrics).To(Equal(metricValues))
+
+				zero := uint64(0)
+				expected := metricValues
+				expected.RxBytes = &zero
+				expected.TxBytes = &zero
+				Expect(taskMetrics.CachedContainerMetrics).To(Equal(expected))
+			})
+
+			It("should return the resources reserved for the container", func() {
+				Expect(metrics.Tasks[0].Reservation).To(Equal(rep.ContainerReservation{
+					MemoryMB: 20,
+					DiskMB:   10,
+					MaxPids:  100,
+				}))
+			})
+		})
+
+		Context("when the executor has no metrics for a container yet", func() {
+			BeforeEach(func() {
+				container := createContainer(executor.StateRunning, rep.TaskLifecycle)
+				client.ListContainersReturns([]executor.Container{container}, nil)
+				fakeContainerMetricsProvider.MetricsReturns(map[string]*containermetrics.CachedContainerMetrics{})
+			})
+
+			It("reports its reservation with zero usage", func() {
+				Expect(metrics.Tasks).To(HaveLen(1))
+				Expect(metrics.Tasks[0].Reservation.DiskMB).To(Equal(10))
+				Expect(metrics.Tasks[0].DiskUsageBytes).To(BeZero())
+				Expect(metrics.Tasks[0].MemoryUsageBytes).To(BeZero())
+				Expect(logger).To(gbytes.Say("failed-to-get-metrics-for-container"))
 			})
 		})
 	})
@@ -452,6 +577,49 @@ var _ = Describe("AuctionCellRep", func() {
 			Expect(state.ProxyMemoryAllocationMB).To(Equal(0))
 		})
 
+		Context("when the capacity is reduced", func() {
+			BeforeEach(func() {
+				Expect(capacityFactor.Set(0.25)).To(Succeed())
+				client.TotalResourcesReturns(executor.ExecutorResources{MemoryMB: 1024, DiskMB: 2048, Containers: 8}, nil)
+				client.RemainingResourcesReturns(executor.ExecutorResources{MemoryMB: 512, DiskMB: 1024, Containers: 4}, nil)
+			})
+
+			It("advertises the reduced capacity", func() {
+				state, _, err := cellRep.State(logger)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(state.TotalResources).To(Equal(rep.Resources{MemoryMB: 256, DiskMB: 512, Containers: 2}))
+				Expect(state.AvailableResources).To(Equal(rep.Resources{MemoryMB: 0, DiskMB: 0, Containers: 0}))
+			})
+
+			It("advertises the full capacity again once restored", func() {
+				Expect(capacityFactor.Set(1)).To(Succeed())
+
+				state, _, err := cellRep.State(logger)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(state.TotalResources).To(Equal(rep.Resources{MemoryMB: 1024, DiskMB: 2048, Containers: 8}))
+				Expect(state.AvailableResources).To(Equal(rep.Resources{MemoryMB: 512, DiskMB: 1024, Containers: 4}))
+			})
+		})
+
+		Context("when a rootfs is quarantined", func() {
+			JustBeforeEach(func() {
+				rootFSQuarantine.RecordMountFailure(logger, linuxPath)
+			})
+
+			It("stops advertising it", func() {
+				state, _, err := cellRep.State(logger)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(state.RootFSProviders).To(Equal(rep.RootFSProviders{
+					models.PreloadedRootFSScheme:    rep.NewFixedSetRootFSProvider(),
+					models.PreloadedOCIRootFSScheme: rep.NewFixedSetRootFSProvider(),
+					"docker":                        rep.ArbitraryRootFSProvider{},
+				}))
+			})
+		})
+
 		Context("when enableContainerProxy is true", func() {
 			BeforeEach(func() {
 				enableContainerProxy = true
@@ -499,6 +667,54 @@ var _ = Describe("AuctionCellRep", func() {
 			})
 		})
 
+		Context("when the executor reports negative remaining resources", func() {
+			BeforeEach(func() {
+				client.TotalResourcesReturns(executor.ExecutorResources{MemoryMB: 1024, DiskMB: 2048, Containers: 8}, nil)
+				client.RemainingResourcesReturns(executor.ExecutorResources{MemoryMB: -128, DiskMB: 512, Containers: -1}, nil)
+			})
+
+			It("advertises zero instead", func() {
+				state, _, err := cellRep.State(logger)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(state.AvailableResources).To(Equal(rep.Resources{MemoryMB: 0, DiskMB: 512, Containers: 0}))
+			})
+
+			It("logs a warning and counts the drift", func() {
+				_, _, err := cellRep.State(logger)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(logger).To(gbytes.Say("clamped-negative-remaining-resources.*reported-memory-mb\":-128"))
+
+				Expect(fakeMetronClient.IncrementCounterCallCount()).To(Equal(1))
+				Expect(fakeMetronClient.IncrementCounterArgsForCall(0)).To(Equal("NegativeRemainingResources"))
+			})
+
+			It("counts the drift once until it is over", func() {
+				_, _, err := cellRep.State(logger)
+				Expect(err).NotTo(HaveOccurred())
+				_, _, err = cellRep.State(logger)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(fakeMetronClient.IncrementCounterCallCount()).To(Equal(1))
+
+				client.RemainingResourcesReturns(executor.ExecutorResources{MemoryMB: 128, DiskMB: 512, Containers: 1}, nil)
+				_, _, err = cellRep.State(logger)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(logger).To(gbytes.Say("remaining-resources-no-longer-negative"))
+
+				client.RemainingResourcesReturns(executor.ExecutorResources{MemoryMB: -128, DiskMB: 512, Containers: -1}, nil)
+				_, _, err = cellRep.State(logger)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(fakeMetronClient.IncrementCounterCallCount()).To(Equal(2))
+			})
+		})
+
+		Context("when the executor reports no negative remaining resources", func() {
+			It("does not count any drift", func() {
+				_, _, err := cellRep.State(logger)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(fakeMetronClient.IncrementCounterCallCount()).To(BeZero())
+			})
+		})
+
 		Context("when the client fails to list containers", func() {
 			BeforeEach(func() {
 				client.ListContainersReturns(nil, commonErr)
@@ -535,6 +751,85 @@ var _ = Describe("AuctionCellRep", func() {
 				Expect(state.OptionalPlacementTags).To(ConsistOf(optionalPlacementTags))
 			})
 		})
+
+		Context("when the state cache is disabled", func() {
+			It("recomputes the state on every call", func() {
+				_, _, err := cellRep.State(logger)
+				Expect(err).NotTo(HaveOccurred())
+				_, _, err = cellRep.State(logger)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(client.ListContainersCallCount()).To(Equal(2))
+			})
+		})
+
+		Context("when a state cache TTL is configured", func() {
+			BeforeEach(func() {
+				stateCacheTTL = 5 * time.Second
+				client.ListContainersReturns([]executor.Container{createContainer(executor.StateRunning, rep.TaskLifecycle)}, nil)
+			})
+
+			It("reuses the state computed within the TTL", func() {
+				first, _, err := cellRep.State(logger)
+				Expect(err).NotTo(HaveOccurred())
+
+				fakeClock.Increment(4 * time.Second)
+				second, healthy, err := cellRep.State(logger)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(healthy).To(BeTrue())
+
+				Expect(second).To(Equal(first))
+				Expect(client.ListContainersCallCount()).To(Equal(1))
+			})
+
+			It("recomputes the state once the TTL has passed", func() {
+				_, _, err := cellRep.State(logger)
+				Expect(err).NotTo(HaveOccurred())
+
+				fakeClock.Increment(5 * time.Second)
+				_, _, err = cellRep.State(logger)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(client.ListContainersCallCount()).To(Equal(2))
+			})
+
+			It("recomputes the state after it is invalidated", func() {
+				_, _, err := cellRep.State(logger)
+				Expect(err).NotTo(HaveOccurred())
+
+				cellRep.InvalidateState()
+				client.ListContainersReturns(nil, nil)
+				state, _, err := cellRep.State(logger)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(client.ListContainersCallCount()).To(Equal(2))
+				Expect(state.Tasks).To(BeEmpty())
+			})
+
+			It("recomputes the state after work is performed", func() {
+				_, _, err := cellRep.State(logger)
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = cellRep.Perform(context.Background(), logger, "some-trace-id", rep.Work{})
+				Expect(err).NotTo(HaveOccurred())
+				_, _, err = cellRep.State(logger)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(client.ListContainersCallCount()).To(Equal(2))
+			})
+
+			It("does not cache failures", func() {
+				client.ListContainersReturns(nil, commonErr)
+				_, _, err := cellRep.State(logger)
+				Expect(err).To(MatchError(commonErr))
+
+				client.ListContainersReturns(nil, nil)
+				_, _, err = cellRep.State(logger)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(client.ListContainersCallCount()).To(Equal(2))
+			})
+		})
 	})
 
 	Describe("Perform", func() {
@@ -551,6 +846,7 @@ var _ = Describe("AuctionCellRep", func() {
 
 		BeforeEach(func() {
 			remainingCellMemory = 8192
+			client.TotalResourcesReturns(executor.ExecutorResources{MemoryMB: 16384, DiskMB: 16384, Containers: 256}, nil)
 
 			successfulLRP = rep.NewLRP(
 				"ig-1",
@@ -598,18 +894,18 @@ var _ = Describe("AuctionCellRep", func() {
 			fakeContainerAllocator.BatchLRPAllocationRequestReturns([]rep.LRP{unsuccessfulLRP})
 			fakeContainerAllocator.BatchTaskAllocationRequestReturns([]rep.Task{unsuccessfulTask})
 
-			cellRep.Perform(logger, "some-trace-id", rep.Work{
+			cellRep.Perform(context.Background(), logger, "some-trace-id", rep.Work{
 				LRPs:  []rep.LRP{successfulLRP, unsuccessfulLRP},
 				Tasks: []rep.Task{successfulTask, unsuccessfulTask},
 			})
 
 			Expect(fakeContainerAllocator.BatchLRPAllocationRequestCallCount()).To(Equal(1))
-			_, traceID, _, _, lrpRequests := fakeContainerAllocator.BatchLRPAllocationRequestArgsForCall(0)
+			_, _, traceID, _, _, lrpRequests := fakeContainerAllocator.BatchLRPAllocationRequestArgsForCall(0)
 			Expect(traceID).To(Equal("some-trace-id"))
 			Expect(lrpRequests).To(ConsistOf(successfulLRP, unsuccessfulLRP))
 
 			Expect(fakeContainerAllocator.BatchTaskAllocationRequestCallCount()).To(Equal(1))
-			_, traceID, taskRequests := fakeContainerAllocator.BatchTaskAllocationRequestArgsForCall(0)
+			_, _, traceID, taskRequests := fakeContainerAllocator.BatchTaskAllocationRequestArgsForCall(0)
 			Expect(traceID).To(Equal("some-trace-id"))
 			Expect(taskRequests).To(ConsistOf(successfulTask, unsuccessfulTask))
 		})
@@ -618,7 +914,7 @@ var _ = Describe("AuctionCellRep", func() {
 			fakeContainerAllocator.BatchLRPAllocationRequestReturns([]rep.LRP{unsuccessfulLRP})
 			fakeContainerAllocator.BatchTaskAllocationRequestReturns([]rep.Task{unsuccessfulTask})
 
-			failedWork, err := cellRep.Perform(logger, "some-trace-id", rep.Work{
+			failedWork, err := cellRep.Perform(context.Background(), logger, "some-trace-id", rep.Work{
 				LRPs:  []rep.LRP{successfulLRP, unsuccessfulLRP},
 				Tasks: []rep.Task{successfulTask, unsuccessfulTask},
 			})
@@ -627,6 +923,273 @@ var _ = Describe("AuctionCellRep", func() {
 			Expect(failedWork.Tasks).To(ConsistOf(unsuccessfulTask))
 		})
 
+		Context("when an LRP requests more than the cell's total capacity", func() {
+			var oversizedLRP, tooBigForNowLRP rep.LRP
+
+			BeforeEach(func() {
+				oversizedLRP = rep.NewLRP(
+					"ig-oversized",
+					models.NewActualLRPKey("process-guid", 2, "domain"),
+					rep.NewResource(16385, 0, 0),
+					rep.PlacementConstraint{},
+				)
+				tooBigForNowLRP = rep.NewLRP(
+					"ig-too-big-for-now",
+					models.NewActualLRPKey("process-guid", 3, "domain"),
+					rep.NewResource(int32(remainingCellMemory)+1, 0, 0),
+					rep.PlacementConstraint{},
+				)
+			})
+
+			It("fails it without allocating it or weighing it against the remaining capacity", func() {
+				failedWork, err := cellRep.Perform(context.Background(), logger, "some-trace-id", rep.Work{
+					LRPs: []rep.LRP{successfulLRP, oversizedLRP},
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(failedWork.LRPs).To(ConsistOf(oversizedLRP))
+
+				_, _, _, _, _, lrpRequests := fakeContainerAllocator.BatchLRPAllocationRequestArgsForCall(0)
+				Expect(lrpRequests).To(ConsistOf(successfulLRP))
+				Expect(logger).To(gbytes.Say("exceeds-cell-capacity.*ig-oversized"))
+			})
+
+			It("gives a different reason than for an LRP only exceeding the remaining capacity", func() {
+				validation, err := cellRep.ValidatePlacement(context.Background(), logger, rep.Work{
+					LRPs: []rep.LRP{oversizedLRP, tooBigForNowLRP},
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(validation.LRPs).To(Equal([]rep.PlacementFit{
+					{Guid: "ig-oversized", Fits: false, Reason: auctioncellrep.PlacementReasonExceedsCellCapacity},
+					{Guid: "ig-too-big-for-now", Fits: false, Reason: auctioncellrep.PlacementReasonInsufficientMemory},
+				}))
+			})
+
+			It("does not log an LRP only exceeding the remaining capacity as exceeding the cell capacity", func() {
+				failedWork, err := cellRep.Perform(context.Background(), logger, "some-trace-id", rep.Work{
+					LRPs: []rep.LRP{tooBigForNowLRP},
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(failedWork.LRPs).To(ConsistOf(tooBigForNowLRP))
+				Expect(logger).NotTo(gbytes.Say("exceeds-cell-capacity"))
+			})
+
+			Context("because of the memory of its proxy", func() {
+				BeforeEach(func() {
+					enableContainerProxy = true
+					oversizedLRP.MemoryMB = 16384
+				})
+
+				It("fails it", func() {
+					failedWork, err := cellRep.Perform(context.Background(), logger, "some-trace-id", rep.Work{
+						LRPs: []rep.LRP{oversizedLRP},
+					})
+					Expect(err).NotTo(HaveOccurred())
+					Expect(failedWork.LRPs).To(ConsistOf(oversizedLRP))
+					Expect(logger).To(gbytes.Say("exceeds-cell-capacity"))
+				})
+			})
+		})
+
+		Context("when a task requests more disk than the cell's total capacity", func() {
+			It("fails it without allocating it", func() {
+				oversizedTask := rep.NewTask("tg-oversized", "domain", rep.NewResource(0, 16385, 0), rep.PlacementConstraint{})
+
+				failedWork, err := cellRep.Perform(context.Background(), logger, "some-trace-id", rep.Work{
+					Tasks: []rep.Task{successfulTask, oversizedTask},
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(failedWork.Tasks).To(ConsistOf(oversizedTask))
+
+				_, _, _, taskRequests := fakeContainerAllocator.BatchTaskAllocationRequestArgsForCall(0)
+				Expect(taskRequests).To(ConsistOf(successfulTask))
+			})
+		})
+
+		Context("when fetching the total resources fails", func() {
+			BeforeEach(func() {
+				client.TotalResourcesReturns(executor.ExecutorResources{}, commonErr)
+			})
+
+			It("returns the error without allocating the work", func() {
+				_, err := cellRep.Perform(context.Background(), logger, "some-trace-id", rep.Work{LRPs: []rep.LRP{successfulLRP}})
+				Expect(err).To(MatchError(commonErr))
+				Expect(fakeContainerAllocator.BatchLRPAllocationRequestCallCount()).To(BeZero())
+			})
+		})
+
+		Context("when the batch allocation policy is all-or-nothing", func() {
+			var bigLRP rep.LRP
+
+			BeforeEach(func() {
+				batchAllocationPolicy = auctioncellrep.BatchAllocationPolicyAllOrNothing
+				bigLRP = rep.NewLRP(
+					"ig-3",
+					models.NewActualLRPKey("process-guid", 2, "domain"),
+					rep.NewResource(int32(remainingCellMemory)+1, 0, 0),
+					rep.PlacementConstraint{},
+				)
+			})
+
+			It("fails every LRP without allocating them when one does not fit", func() {
+				failedWork, err := cellRep.Perform(context.Background(), logger, "some-trace-id", rep.Work{
+					LRPs:  []rep.LRP{successfulLRP, bigLRP},
+					Tasks: []rep.Task{successfulTask},
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(failedWork.LRPs).To(ConsistOf(successfulLRP, bigLRP))
+				Expect(failedWork.Tasks).To(BeEmpty())
+
+				Expect(fakeContainerAllocator.BatchLRPAllocationRequestCallCount()).To(Equal(1))
+				_, _, _, _, _, lrpRequests := fakeContainerAllocator.BatchLRPAllocationRequestArgsForCall(0)
+				Expect(lrpRequests).To(BeEmpty())
+				Expect(logger).To(gbytes.Say("rejecting-batch-after-partial-failure"))
+			})
+
+			It("fails every LRP when the allocator rejects one", func() {
+				fakeContainerAllocator.BatchLRPAllocationRequestReturns([]rep.LRP{unsuccessfulLRP})
+
+				failedWork, err := cellRep.Perform(context.Background(), logger, "some-trace-id", rep.Work{
+					LRPs: []rep.LRP{successfulLRP, unsuccessfulLRP},
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(failedWork.LRPs).To(ConsistOf(successfulLRP, unsuccessfulLRP))
+			})
+
+			It("allocates the LRPs when all of them fit", func() {
+				failedWork, err := cellRep.Perform(context.Background(), logger, "some-trace-id", rep.Work{
+					LRPs: []rep.LRP{successfulLRP, unsuccessfulLRP},
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(failedWork.LRPs).To(BeEmpty())
+				_, _, _, _, _, lrpRequests := fakeContainerAllocator.BatchLRPAllocationRequestArgsForCall(0)
+				Expect(lrpRequests).To(ConsistOf(successfulLRP, unsuccessfulLRP))
+			})
+		})
+
+		Context("when the Perform has a deadline", func() {
+			var work rep.Work
+
+			BeforeEach(func() {
+				work = rep.Work{
+					LRPs:  []rep.LRP{successfulLRP},
+					Tasks: []rep.Task{successfulTask},
+				}
+			})
+
+			It("allocates the work when the deadline is far enough away", func() {
+				ctx, cancel := context.WithDeadline(context.Background(), fakeClock.Now().Add(time.Minute))
+				defer cancel()
+
+				failedWork, err := cellRep.Perform(ctx, logger, "some-trace-id", work)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(failedWork.LRPs).To(BeEmpty())
+				Expect(failedWork.Tasks).To(BeEmpty())
+				Expect(fakeContainerAllocator.BatchLRPAllocationRequestCallCount()).To(Equal(1))
+				Expect(fakeContainerAllocator.BatchTaskAllocationRequestCallCount()).To(Equal(1))
+			})
+
+			It("passes the context on to the allocator", func() {
+				ctx, cancel := context.WithDeadline(context.Background(), fakeClock.Now().Add(time.Minute))
+				defer cancel()
+
+				_, err := cellRep.Perform(ctx, logger, "some-trace-id", work)
+				Expect(err).NotTo(HaveOccurred())
+
+				lrpCtx, _, _, _, _, _ := fakeContainerAllocator.BatchLRPAllocationRequestArgsForCall(0)
+				Expect(lrpCtx).To(Equal(ctx))
+				taskCtx, _, _, _ := fakeContainerAllocator.BatchTaskAllocationRequestArgsForCall(0)
+				Expect(taskCtx).To(Equal(ctx))
+			})
+
+			It("measures the time left on the cell's clock", func() {
+				ctx, cancel := context.WithDeadline(context.Background(), fakeClock.Now().Add(time.Minute))
+				defer cancel()
+				fakeClock.Increment(time.Minute - 50*time.Millisecond)
+
+				failedWork, err := cellRep.Perform(ctx, logger, "some-trace-id", work)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(failedWork).To(Equal(work))
+				Expect(fakeContainerAllocator.BatchLRPAllocationRequestCallCount()).To(BeZero())
+			})
+
+			It("fails the work without allocating it when the deadline is near", func() {
+				ctx, cancel := context.WithDeadline(context.Background(), fakeClock.Now().Add(time.Millisecond))
+				defer cancel()
+
+				failedWork, err := cellRep.Perform(ctx, logger, "some-trace-id", work)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(failedWork).To(Equal(work))
+				Expect(fakeContainerAllocator.BatchLRPAllocationRequestCallCount()).To(BeZero())
+				Expect(fakeContainerAllocator.BatchTaskAllocationRequestCallCount()).To(BeZero())
+				Expect(logger).To(gbytes.Say("refusing-work.*deadline near"))
+			})
+
+			It("fails the work when the deadline passes while gathering resources", func() {
+				ctx, cancel := context.WithCancel(context.Background())
+				client.RemainingResourcesStub = func(lager.Logger) (executor.ExecutorResources, error) {
+					cancel()
+					return executor.ExecutorResources{MemoryMB: 1024, DiskMB: 1024, Containers: 10}, nil
+				}
+
+				failedWork, err := cellRep.Perform(ctx, logger, "some-trace-id", work)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(failedWork).To(Equal(work))
+				Expect(fakeContainerAllocator.BatchLRPAllocationRequestCallCount()).To(BeZero())
+			})
+		})
+
+		Describe("the auction win ratio", func() {
+			lastRatio := func() float64 {
+				count := fakeMetronClient.SendComponentMetricCallCount()
+				ExpectWithOffset(1, count).To(BeNumerically(">", 0))
+				name, value, _ := fakeMetronClient.SendComponentMetricArgsForCall(count - 1)
+				ExpectWithOffset(1, name).To(Equal("AuctionWinRatio"))
+				return value
+			}
+
+			It("emits the ratio of accepted to offered work", func() {
+				fakeContainerAllocator.BatchLRPAllocationRequestReturns([]rep.LRP{unsuccessfulLRP})
+				fakeContainerAllocator.BatchTaskAllocationRequestReturns([]rep.Task{unsuccessfulTask})
+
+				_, err := cellRep.Perform(context.Background(), logger, "some-trace-id", rep.Work{
+					LRPs:  []rep.LRP{successfulLRP, unsuccessfulLRP},
+					Tasks: []rep.Task{successfulTask, unsuccessfulTask},
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(lastRatio()).To(Equal(0.5))
+			})
+
+			It("accumulates the ratio across auctions", func() {
+				fakeContainerAllocator.BatchLRPAllocationRequestReturnsOnCall(0, nil)
+				fakeContainerAllocator.BatchTaskAllocationRequestReturnsOnCall(0, nil)
+				fakeContainerAllocator.BatchLRPAllocationRequestReturnsOnCall(1, []rep.LRP{successfulLRP, unsuccessfulLRP})
+				fakeContainerAllocator.BatchTaskAllocationRequestReturnsOnCall(1, nil)
+
+				_, err := cellRep.Perform(context.Background(), logger, "some-trace-id", rep.Work{Tasks: []rep.Task{successfulTask, unsuccessfulTask}})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(lastRatio()).To(Equal(1.0))
+
+				_, err = cellRep.Perform(context.Background(), logger, "some-trace-id", rep.Work{LRPs: []rep.LRP{successfulLRP, unsuccessfulLRP}})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(lastRatio()).To(Equal(0.5))
+			})
+
+			It("does not emit anything when no work is offered", func() {
+				_, err := cellRep.Perform(context.Background(), logger, "some-trace-id", rep.Work{})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(fakeMetronClient.SendComponentMetricCallCount()).To(BeZero())
+			})
+
+			It("does not count work addressed to another cell", func() {
+				_, err := cellRep.Perform(context.Background(), logger, "some-trace-id", rep.Work{
+					CellID: "another-cell",
+					Tasks:  []rep.Task{successfulTask},
+				})
+				Expect(err).To(HaveOccurred())
+				Expect(fakeMetronClient.SendComponentMetricCallCount()).To(BeZero())
+			})
+		})
+
 		Context("when evacuating", func() {
 			BeforeEach(func() {
 				evacuationReporter.EvacuatingReturns(true)
@@ -652,7 +1215,68 @@ var _ = Describe("AuctionCellRep", func() {
 			})
 
 			It("returns all work it was given", func() {
-				Expect(cellRep.Perform(logger, "some-trace-id", work)).To(Equal(work))
+				Expect(cellRep.Perform(context.Background(), logger, "some-trace-id", work)).To(Equal(work))
+			})
+		})
+
+		Context("when the cell clock is skewed", func() {
+			BeforeEach(func() {
+				clockSkewReporter = &stubClockSkewReporter{skewed: true}
+				work = rep.Work{
+					LRPs:  []rep.LRP{successfulLRP},
+					Tasks: []rep.Task{successfulTask},
+				}
+			})
+
+			It("returns all work it was given without allocating any of it", func() {
+				Expect(cellRep.Perform(context.Background(), logger, "some-trace-id", work)).To(Equal(work))
+				Expect(fakeContainerAllocator.BatchLRPAllocationRequestCallCount()).To(BeZero())
+				Expect(fakeContainerAllocator.BatchTaskAllocationRequestCallCount()).To(BeZero())
+				Expect(logger).To(gbytes.Say("refusing-work.*clock skewed"))
+			})
+		})
+
+		Context("when the cell clock is not skewed", func() {
+			BeforeEach(func() {
+				clockSkewReporter = &stubClockSkewReporter{skewed: false}
+			})
+
+			It("allocates the work", func() {
+				_, err := cellRep.Perform(context.Background(), logger, "some-trace-id", rep.Work{Tasks: []rep.Task{successfulTask}})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(fakeContainerAllocator.BatchTaskAllocationRequestCallCount()).To(Equal(1))
+			})
+		})
+
+		Context("when the capacity is reduced", func() {
+			var smallLRP, largeLRP rep.LRP
+			var smallTask, largeTask rep.Task
+
+			BeforeEach(func() {
+				Expect(capacityFactor.Set(0.5)).To(Succeed())
+				client.TotalResourcesReturns(executor.ExecutorResources{MemoryMB: 8192}, nil)
+				remainingCellMemory = 6144
+
+				largeLRP = rep.LRP{Resource: rep.Resource{MemoryMB: 1024}}
+				smallLRP = rep.LRP{Resource: rep.Resource{MemoryMB: 512}}
+				largeTask = rep.NewTask("large-task", "domain", rep.Resource{MemoryMB: 1024}, rep.PlacementConstraint{})
+				smallTask = rep.NewTask("small-task", "domain", rep.Resource{MemoryMB: 256}, rep.PlacementConstraint{})
+			})
+
+			It("only accepts the work fitting in the reduced capacity", func() {
+				failedWork, err := cellRep.Perform(context.Background(), logger, "some-trace-id", rep.Work{
+					LRPs:  []rep.LRP{smallLRP, largeLRP},
+					Tasks: []rep.Task{largeTask, smallTask},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(failedWork.LRPs).To(BeEmpty())
+				Expect(failedWork.Tasks).To(ConsistOf(largeTask))
+
+				_, _, _, _, _, lrpRequests := fakeContainerAllocator.BatchLRPAllocationRequestArgsForCall(0)
+				Expect(lrpRequests).To(ConsistOf(smallLRP, largeLRP))
+				_, _, _, taskRequests := fakeContainerAllocator.BatchTaskAllocationRequestArgsForCall(0)
+				Expect(taskRequests).To(ConsistOf(smallTask))
 			})
 		})
 
@@ -667,7 +1291,7 @@ var _ = Describe("AuctionCellRep", func() {
 			})
 
 			It("allocates containers for the largest workloads it can run", func() {
-				failedWork, err := cellRep.Perform(logger, "some-trace-id", rep.Work{
+				failedWork, err := cellRep.Perform(context.Background(), logger, "some-trace-id", rep.Work{
 					LRPs:  []rep.LRP{smallestLRP, middleLRP, largestLRP},
 					Tasks: []rep.Task{},
 				})
@@ -677,7 +1301,7 @@ var _ = Describe("AuctionCellRep", func() {
 
 				Expect(fakeContainerAllocator.BatchLRPAllocationRequestCallCount()).To(Equal(1))
 
-				_, traceID, proxyEnabledArg, proxyMemFootprintArg, lrpRequests := fakeContainerAllocator.BatchLRPAllocationRequestArgsForCall(0)
+				_, _, traceID, proxyEnabledArg, proxyMemFootprintArg, lrpRequests := fakeContainerAllocator.BatchLRPAllocationRequestArgsForCall(0)
 				Expect(traceID).To(Equal("some-trace-id"))
 				Expect(proxyEnabledArg).To(BeFalse())
 				Expect(proxyMemFootprintArg).To(Equal(12))
@@ -691,7 +1315,7 @@ var _ = Describe("AuctionCellRep", func() {
 				})
 
 				It("accounts for the proxy overhead when determining which workloads to run and which to reject", func() {
-					failedWork, err := cellRep.Perform(logger, "some-trace-id", rep.Work{
+					failedWork, err := cellRep.Perform(context.Background(), logger, "some-trace-id", rep.Work{
 						LRPs:  []rep.LRP{smallestLRP, middleLRP, largestLRP},
 						Tasks: []rep.Task{},
 					})
@@ -701,18 +1325,57 @@ var _ = Describe("AuctionCellRep", func() {
 
 					Expect(fakeContainerAllocator.BatchLRPAllocationRequestCallCount()).To(Equal(1))
 
-					_, traceID, proxyEnabledArg, proxyMemFootprintArg, lrpRequests := fakeContainerAllocator.BatchLRPAllocationRequestArgsForCall(0)
+					_, _, traceID, proxyEnabledArg, proxyMemFootprintArg, lrpRequests := fakeContainerAllocator.BatchLRPAllocationRequestArgsForCall(0)
 					Expect(traceID).To(Equal("some-trace-id"))
 					Expect(proxyEnabledArg).To(BeTrue())
 					Expect(proxyMemFootprintArg).To(Equal(proxyMemoryAllocation))
 					Expect(lrpRequests).To(ConsistOf(largestLRP))
 				})
+
+				Context("when the proxy memory is overridden for the LRPs' rootfs", func() {
+					BeforeEach(func() {
+						proxyMemoryByRootFS = auctioncellrep.ProxyMemoryByRootFS{linuxStack: 1}
+						smallestLRP.RootFs = linuxRootFSURL
+						middleLRP.RootFs = linuxRootFSURL
+						largestLRP.RootFs = linuxRootFSURL
+						middleLRP.MemoryMB = int32(remainingCellMemory) - largestLRP.MemoryMB - 4
+					})
+
+					It("accounts for the overridden proxy memory instead of the global one", func() {
+						failedWork, err := cellRep.Perform(context.Background(), logger, "some-trace-id", rep.Work{
+							LRPs:  []rep.LRP{smallestLRP, middleLRP, largestLRP},
+							Tasks: []rep.Task{},
+						})
+
+						Expect(err).NotTo(HaveOccurred())
+						Expect(failedWork.LRPs).To(BeEmpty())
+
+						_, _, _, _, _, lrpRequests := fakeContainerAllocator.BatchLRPAllocationRequestArgsForCall(0)
+						Expect(lrpRequests).To(ConsistOf(smallestLRP, middleLRP, largestLRP))
+					})
+				})
+
+				Context("when the proxy memory is overridden for a different rootfs", func() {
+					BeforeEach(func() {
+						proxyMemoryByRootFS = auctioncellrep.ProxyMemoryByRootFS{"other-stack": 1}
+					})
+
+					It("falls back to the global proxy memory allocation", func() {
+						failedWork, err := cellRep.Perform(context.Background(), logger, "some-trace-id", rep.Work{
+							LRPs:  []rep.LRP{smallestLRP, middleLRP, largestLRP},
+							Tasks: []rep.Task{},
+						})
+
+						Expect(err).NotTo(HaveOccurred())
+						Expect(failedWork.LRPs).To(ConsistOf(smallestLRP, middleLRP))
+					})
+				})
 			})
 		})
 
 		Context("when the workload's cell ID does not match the cell's ID", func() {
 			It("rejects the workload", func() {
-				_, err := cellRep.Perform(logger, "some-trace-id", rep.Work{
+				_, err := cellRep.Perform(context.Background(), logger, "some-trace-id", rep.Work{
 					LRPs:   lrpAuctions,
 					CellID: "do-not-want-your-work",
 				})
@@ -720,6 +1383,204 @@ var _ = Describe("AuctionCellRep", func() {
 			})
 		})
 	})
+
+	Describe("ValidatePlacement", func() {
+		var work rep.Work
+
+		BeforeEach(func() {
+			client.RemainingResourcesReturns(executor.ExecutorResources{MemoryMB: 1024, DiskMB: 2048, Containers: 3}, nil)
+			client.TotalResourcesReturns(executor.ExecutorResources{MemoryMB: 4096, DiskMB: 4096, Containers: 8}, nil)
+
+			work = rep.Work{
+				LRPs: []rep.LRP{
+					rep.NewLRP("ig-small", models.NewActualLRPKey("pg", 0, "domain"), rep.NewResource(256, 256, 10), rep.NewPlacementConstraint(linuxRootFSURL, nil, nil)),
+					rep.NewLRP("ig-big", models.NewActualLRPKey("pg", 1, "domain"), rep.NewResource(768, 256, 10), rep.NewPlacementConstraint(linuxRootFSURL, nil, nil)),
+					rep.NewLRP("ig-too-big", models.NewActualLRPKey("pg", 2, "domain"), rep.NewResource(512, 256, 10), rep.NewPlacementConstraint(linuxRootFSURL, nil, nil)),
+				},
+				Tasks: []rep.Task{
+					rep.NewTask("tg-quarantined", "domain", rep.NewResource(0, 0, 10), rep.NewPlacementConstraint(linuxRootFSURL, nil, nil)),
+					rep.NewTask("tg-docker", "domain", rep.NewResource(0, 512, 10), rep.NewPlacementConstraint("docker:///busybox", nil, nil)),
+				},
+			}
+
+			fakeContainerAllocator.CheckTasksReturns([]error{auctioncellrep.ErrRootFSQuarantined, nil})
+		})
+
+		It("computes the fit of each item as Perform would, largest LRPs first, and the remaining capacity", func() {
+			validation, err := cellRep.ValidatePlacement(context.Background(), logger, work)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(validation.LRPs).To(Equal([]rep.PlacementFit{
+				{Guid: "ig-big", Fits: true},
+				{Guid: "ig-too-big", Fits: false, Reason: auctioncellrep.PlacementReasonInsufficientMemory},
+				{Guid: "ig-small", Fits: true},
+			}))
+			Expect(validation.Tasks).To(Equal([]rep.PlacementFit{
+				{Guid: "tg-quarantined", Fits: false, Reason: auctioncellrep.ErrRootFSQuarantined.Error()},
+				{Guid: "tg-docker", Fits: true},
+			}))
+			Expect(validation.Remaining).To(Equal(rep.Resources{MemoryMB: 0, DiskMB: 1024, Containers: 0}))
+		})
+
+		It("runs the allocator's checks on the work that fits", func() {
+			_, err := cellRep.ValidatePlacement(context.Background(), logger, work)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeContainerAllocator.CheckLRPsCallCount()).To(Equal(1))
+			_, _, _, lrps := fakeContainerAllocator.CheckLRPsArgsForCall(0)
+			Expect(lrps).To(Equal([]rep.LRP{work.LRPs[1], work.LRPs[0]}))
+
+			Expect(fakeContainerAllocator.CheckTasksCallCount()).To(Equal(1))
+			_, tasks := fakeContainerAllocator.CheckTasksArgsForCall(0)
+			Expect(tasks).To(Equal(work.Tasks))
+		})
+
+		It("reports the LRPs the allocator would reject", func() {
+			fakeContainerAllocator.CheckLRPsReturns([]error{nil, auctioncellrep.ErrDomainNotAllowed})
+
+			validation, err := cellRep.ValidatePlacement(context.Background(), logger, work)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(validation.LRPs).To(Equal([]rep.PlacementFit{
+				{Guid: "ig-big", Fits: true},
+				{Guid: "ig-too-big", Fits: false, Reason: auctioncellrep.PlacementReasonInsufficientMemory},
+				{Guid: "ig-small", Fits: false, Reason: auctioncellrep.ErrDomainNotAllowed.Error()},
+			}))
+		})
+
+		Context("when the batch allocation policy is all-or-nothing", func() {
+			BeforeEach(func() {
+				batchAllocationPolicy = auctioncellrep.BatchAllocationPolicyAllOrNothing
+			})
+
+			It("fails every LRP when one does not fit", func() {
+				validation, err := cellRep.ValidatePlacement(context.Background(), logger, work)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(validation.LRPs).To(Equal([]rep.PlacementFit{
+					{Guid: "ig-big", Fits: false, Reason: auctioncellrep.PlacementReasonBatchRejected},
+					{Guid: "ig-too-big", Fits: false, Reason: auctioncellrep.PlacementReasonInsufficientMemory},
+					{Guid: "ig-small", Fits: false, Reason: auctioncellrep.PlacementReasonBatchRejected},
+				}))
+			})
+
+			It("fails every LRP when the allocator would reject one", func() {
+				fakeContainerAllocator.CheckLRPsReturns([]error{auctioncellrep.ErrExceedsMaxInstancesPerLRP, nil})
+
+				validation, err := cellRep.ValidatePlacement(context.Background(), logger, rep.Work{LRPs: work.LRPs[:2]})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(validation.LRPs).To(Equal([]rep.PlacementFit{
+					{Guid: "ig-big", Fits: false, Reason: auctioncellrep.ErrExceedsMaxInstancesPerLRP.Error()},
+					{Guid: "ig-small", Fits: false, Reason: auctioncellrep.PlacementReasonBatchRejected},
+				}))
+			})
+		})
+
+		Context("when the cell is evacuating", func() {
+			BeforeEach(func() {
+				evacuationReporter.EvacuatingReturns(true)
+			})
+
+			It("reports that none of the work fits", func() {
+				validation, err := cellRep.ValidatePlacement(context.Background(), logger, work)
+				Expect(err).NotTo(HaveOccurred())
+				for _, fit := range append(validation.LRPs, validation.Tasks...) {
+					Expect(fit).To(Equal(rep.PlacementFit{Guid: fit.Guid, Fits: false, Reason: auctioncellrep.PlacementReasonEvacuating}))
+				}
+				Expect(fakeContainerAllocator.CheckLRPsCallCount()).To(BeZero())
+			})
+		})
+
+		Context("when the cell clock is skewed", func() {
+			BeforeEach(func() {
+				clockSkewReporter = &stubClockSkewReporter{skewed: true}
+			})
+
+			It("reports that none of the work fits", func() {
+				validation, err := cellRep.ValidatePlacement(context.Background(), logger, work)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(validation.LRPs[0].Reason).To(Equal(auctioncellrep.PlacementReasonClockSkewed))
+				Expect(validation.Tasks[0].Reason).To(Equal(auctioncellrep.PlacementReasonClockSkewed))
+			})
+		})
+
+		Context("when the deadline is near", func() {
+			It("reports that none of the work fits", func() {
+				ctx, cancel := context.WithDeadline(context.Background(), fakeClock.Now().Add(time.Millisecond))
+				defer cancel()
+
+				validation, err := cellRep.ValidatePlacement(ctx, logger, work)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(validation.LRPs[0].Reason).To(Equal(auctioncellrep.PlacementReasonDeadlineNear))
+				Expect(validation.Tasks[0].Reason).To(Equal(auctioncellrep.PlacementReasonDeadlineNear))
+			})
+		})
+
+		It("has no side effects", func() {
+			originalLRPs := append([]rep.LRP{}, work.LRPs...)
+
+			_, err := cellRep.ValidatePlacement(context.Background(), logger, work)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(work.LRPs).To(Equal(originalLRPs))
+			Expect(fakeContainerAllocator.BatchLRPAllocationRequestCallCount()).To(BeZero())
+			Expect(fakeContainerAllocator.BatchTaskAllocationRequestCallCount()).To(BeZero())
+			Expect(client.AllocateContainersCallCount()).To(BeZero())
+			Expect(fakeMetronClient.SendComponentMetricCallCount()).To(BeZero())
+		})
+
+		Context("when the state is cached", func() {
+			BeforeEach(func() {
+				stateCacheTTL = time.Minute
+			})
+
+			It("keeps the cached state", func() {
+				_, _, err := cellRep.State(logger)
+				Expect(err).NotTo(HaveOccurred())
+				listCalls := client.ListContainersCallCount()
+
+				_, err = cellRep.ValidatePlacement(context.Background(), logger, work)
+				Expect(err).NotTo(HaveOccurred())
+
+				_, _, err = cellRep.State(logger)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(client.ListContainersCallCount()).To(Equal(listCalls))
+			})
+		})
+
+		Context("when the capacity is reduced", func() {
+			BeforeEach(func() {
+				client.TotalResourcesReturns(executor.ExecutorResources{MemoryMB: 1024, DiskMB: 2048, Containers: 3}, nil)
+				Expect(capacityFactor.Set(0.5)).To(Succeed())
+			})
+
+			It("validates against the reduced capacity", func() {
+				validation, err := cellRep.ValidatePlacement(context.Background(), logger, rep.Work{LRPs: work.LRPs[:1]})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(validation.Remaining.MemoryMB).To(Equal(int32(256)))
+			})
+
+			It("holds the tasks to the reduced capacity, as Perform does", func() {
+				bigTask := rep.NewTask("tg-big", "domain", rep.NewResource(768, 0, 10), rep.NewPlacementConstraint("docker:///busybox", nil, nil))
+				fakeContainerAllocator.CheckTasksReturns(nil)
+
+				validation, err := cellRep.ValidatePlacement(context.Background(), logger, rep.Work{Tasks: []rep.Task{bigTask}})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(validation.Tasks).To(Equal([]rep.PlacementFit{
+					{Guid: "tg-big", Fits: false, Reason: auctioncellrep.PlacementReasonInsufficientMemory},
+				}))
+			})
+		})
+
+		Context("when fetching the remaining resources fails", func() {
+			BeforeEach(func() {
+				client.RemainingResourcesReturns(executor.ExecutorResources{}, commonErr)
+			})
+
+			It("returns the error", func() {
+				_, err := cellRep.ValidatePlacement(context.Background(), logger, work)
+				Expect(err).To(MatchError(commonErr))
+			})
+		})
+	})
 })
 
 func createContainer(state executor.State, lifecycle string) executor.Container {
@@ -738,3 +1599,11 @@ func createContainer(state executor.State, lifecycle string) executor.Container
 		State: state,
 	}
 }
+
+type stubClockSkewReporter struct {
+	skewed bool
+}
+
+func (r *stubClockSkewReporter) Skewed() bool {
+	return r.skewed
+}
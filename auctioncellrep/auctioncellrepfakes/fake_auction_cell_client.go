@@ -2,6 +2,7 @@
 package auctioncellrepfakes
 
 import (
+	"context"
 	"sync"
 
 	lager "code.cloudfoundry.org/lager/v3"
@@ -10,12 +11,13 @@ import (
 )
 
 type FakeAuctionCellClient struct {
-	PerformStub        func(lager.Logger, string, rep.Work) (rep.Work, error)
+	PerformStub        func(context.Context, lager.Logger, string, rep.Work) (rep.Work, error)
 	performMutex       sync.RWMutex
 	performArgsForCall []struct {
-		arg1 lager.Logger
-		arg2 string
-		arg3 rep.Work
+		arg1 context.Context
+		arg2 lager.Logger
+		arg3 string
+		arg4 rep.Work
 	}
 	performReturns struct {
 		result1 rep.Work
@@ -35,6 +37,19 @@ type FakeAuctionCellClient struct {
 	resetReturnsOnCall map[int]struct {
 		result1 error
 	}
+	ResourceAccountingStub        func(lager.Logger) (rep.ResourceAccounting, error)
+	resourceAccountingMutex       sync.RWMutex
+	resourceAccountingArgsForCall []struct {
+		arg1 lager.Logger
+	}
+	resourceAccountingReturns struct {
+		result1 rep.ResourceAccounting
+		result2 error
+	}
+	resourceAccountingReturnsOnCall map[int]struct {
+		result1 rep.ResourceAccounting
+		result2 error
+	}
 	StateStub        func(lager.Logger) (rep.CellState, bool, error)
 	stateMutex       sync.RWMutex
 	stateArgsForCall []struct {
@@ -50,24 +65,40 @@ type FakeAuctionCellClient struct {
 		result2 bool
 		result3 error
 	}
+	ValidatePlacementStub        func(context.Context, lager.Logger, rep.Work) (rep.PlacementValidation, error)
+	validatePlacementMutex       sync.RWMutex
+	validatePlacementArgsForCall []struct {
+		arg1 context.Context
+		arg2 lager.Logger
+		arg3 rep.Work
+	}
+	validatePlacementReturns struct {
+		result1 rep.PlacementValidation
+		result2 error
+	}
+	validatePlacementReturnsOnCall map[int]struct {
+		result1 rep.PlacementValidation
+		result2 error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
 
-func (fake *FakeAuctionCellClient) Perform(arg1 lager.Logger, arg2 string, arg3 rep.Work) (rep.Work, error) {
+func (fake *FakeAuctionCellClient) Perform(arg1 context.Context, arg2 lager.Logger, arg3 string, arg4 rep.Work) (rep.Work, error) {
 	fake.performMutex.Lock()
 	ret, specificReturn := fake.performReturnsOnCall[len(fake.performArgsForCall)]
 	fake.performArgsForCall = append(fake.performArgsForCall, struct {
-		arg1 lager.Logger
-		arg2 string
-		arg3 rep.Work
-	}{arg1, arg2, arg3})
+		arg1 context.Context
+		arg2 lager.Logger
+		arg3 string
+		arg4 rep.Work
+	}{arg1, arg2, arg3, arg4})
 	stub := fake.PerformStub
 	fakeReturns := fake.performReturns
-	fake.recordInvocation("Perform", []interface{}{arg1, arg2, arg3})
+	fake.recordInvocation("Perform", []interface{}{arg1, arg2, arg3, arg4})
 	fake.performMutex.Unlock()
 	if stub != nil {
-		return stub(arg1, arg2, arg3)
+		return stub(arg1, arg2, arg3, arg4)
 	}
 	if specificReturn {
 		return ret.result1, ret.result2
@@ -81,17 +112,17 @@ func (fake *FakeAuctionCellClient) PerformCallCount() int {
 	return len(fake.performArgsForCall)
 }
 
-func (fake *FakeAuctionCellClient) PerformCalls(stub func(lager.Logger, string, rep.Work) (rep.Work, error)) {
+func (fake *FakeAuctionCellClient) PerformCalls(stub func(context.Context, lager.Logger, string, rep.Work) (rep.Work, error)) {
 	fake.performMutex.Lock()
 	defer fake.performMutex.Unlock()
 	fake.PerformStub = stub
 }
 
-func (fake *FakeAuctionCellClient) PerformArgsForCall(i int) (lager.Logger, string, rep.Work) {
+func (fake *FakeAuctionCellClient) PerformArgsForCall(i int) (context.Context, lager.Logger, string, rep.Work) {
 	fake.performMutex.RLock()
 	defer fake.performMutex.RUnlock()
 	argsForCall := fake.performArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
 }
 
 func (fake *FakeAuctionCellClient) PerformReturns(result1 rep.Work, result2 error) {
@@ -173,6 +204,70 @@ func (fake *FakeAuctionCellClient) ResetReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FakeAuctionCellClient) ResourceAccounting(arg1 lager.Logger) (rep.ResourceAccounting, error) {
+	fake.resourceAccountingMutex.Lock()
+	ret, specificReturn := fake.resourceAccountingReturnsOnCall[len(fake.resourceAccountingArgsForCall)]
+	fake.resourceAccountingArgsForCall = append(fake.resourceAccountingArgsForCall, struct {
+		arg1 lager.Logger
+	}{arg1})
+	stub := fake.ResourceAccountingStub
+	fakeReturns := fake.resourceAccountingReturns
+	fake.recordInvocation("ResourceAccounting", []interface{}{arg1})
+	fake.resourceAccountingMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeAuctionCellClient) ResourceAccountingCallCount() int {
+	fake.resourceAccountingMutex.RLock()
+	defer fake.resourceAccountingMutex.RUnlock()
+	return len(fake.resourceAccountingArgsForCall)
+}
+
+func (fake *FakeAuctionCellClient) ResourceAccountingCalls(stub func(lager.Logger) (rep.ResourceAccounting, error)) {
+	fake.resourceAccountingMutex.Lock()
+	defer fake.resourceAccountingMutex.Unlock()
+	fake.ResourceAccountingStub = stub
+}
+
+func (fake *FakeAuctionCellClient) ResourceAccountingArgsForCall(i int) lager.Logger {
+	fake.resourceAccountingMutex.RLock()
+	defer fake.resourceAccountingMutex.RUnlock()
+	argsForCall := fake.resourceAccountingArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeAuctionCellClient) ResourceAccountingReturns(result1 rep.ResourceAccounting, result2 error) {
+	fake.resourceAccountingMutex.Lock()
+	defer fake.resourceAccountingMutex.Unlock()
+	fake.ResourceAccountingStub = nil
+	fake.resourceAccountingReturns = struct {
+		result1 rep.ResourceAccounting
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAuctionCellClient) ResourceAccountingReturnsOnCall(i int, result1 rep.ResourceAccounting, result2 error) {
+	fake.resourceAccountingMutex.Lock()
+	defer fake.resourceAccountingMutex.Unlock()
+	fake.ResourceAccountingStub = nil
+	if fake.resourceAccountingReturnsOnCall == nil {
+		fake.resourceAccountingReturnsOnCall = make(map[int]struct {
+			result1 rep.ResourceAccounting
+			result2 error
+		})
+	}
+	fake.resourceAccountingReturnsOnCall[i] = struct {
+		result1 rep.ResourceAccounting
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeAuctionCellClient) State(arg1 lager.Logger) (rep.CellState, bool, error) {
 	fake.stateMutex.Lock()
 	ret, specificReturn := fake.stateReturnsOnCall[len(fake.stateArgsForCall)]
@@ -240,6 +335,72 @@ func (fake *FakeAuctionCellClient) StateReturnsOnCall(i int, result1 rep.CellSta
 	}{result1, result2, result3}
 }
 
+func (fake *FakeAuctionCellClient) ValidatePlacement(arg1 context.Context, arg2 lager.Logger, arg3 rep.Work) (rep.PlacementValidation, error) {
+	fake.validatePlacementMutex.Lock()
+	ret, specificReturn := fake.validatePlacementReturnsOnCall[len(fake.validatePlacementArgsForCall)]
+	fake.validatePlacementArgsForCall = append(fake.validatePlacementArgsForCall, struct {
+		arg1 context.Context
+		arg2 lager.Logger
+		arg3 rep.Work
+	}{arg1, arg2, arg3})
+	stub := fake.ValidatePlacementStub
+	fakeReturns := fake.validatePlacementReturns
+	fake.recordInvocation("ValidatePlacement", []interface{}{arg1, arg2, arg3})
+	fake.validatePlacementMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeAuctionCellClient) ValidatePlacementCallCount() int {
+	fake.validatePlacementMutex.RLock()
+	defer fake.validatePlacementMutex.RUnlock()
+	return len(fake.validatePlacementArgsForCall)
+}
+
+func (fake *FakeAuctionCellClient) ValidatePlacementCalls(stub func(context.Context, lager.Logger, rep.Work) (rep.PlacementValidation, error)) {
+	fake.validatePlacementMutex.Lock()
+	defer fake.validatePlacementMutex.Unlock()
+	fake.ValidatePlacementStub = stub
+}
+
+func (fake *FakeAuctionCellClient) ValidatePlacementArgsForCall(i int) (context.Context, lager.Logger, rep.Work) {
+	fake.validatePlacementMutex.RLock()
+	defer fake.validatePlacementMutex.RUnlock()
+	argsForCall := fake.validatePlacementArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeAuctionCellClient) ValidatePlacementReturns(result1 rep.PlacementValidation, result2 error) {
+	fake.validatePlacementMutex.Lock()
+	defer fake.validatePlacementMutex.Unlock()
+	fake.ValidatePlacementStub = nil
+	fake.validatePlacementReturns = struct {
+		result1 rep.PlacementValidation
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeAuctionCellClient) ValidatePlacementReturnsOnCall(i int, result1 rep.PlacementValidation, result2 error) {
+	fake.validatePlacementMutex.Lock()
+	defer fake.validatePlacementMutex.Unlock()
+	fake.ValidatePlacementStub = nil
+	if fake.validatePlacementReturnsOnCall == nil {
+		fake.validatePlacementReturnsOnCall = make(map[int]struct {
+			result1 rep.PlacementValidation
+			result2 error
+		})
+	}
+	fake.validatePlacementReturnsOnCall[i] = struct {
+		result1 rep.PlacementValidation
+		result2 error
+	}{result1, result2}
+}
+
 func (fake *FakeAuctionCellClient) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
@@ -247,8 +408,12 @@ func (fake *FakeAuctionCellClient) Invocations() map[string][][]interface{} {
 	defer fake.performMutex.RUnlock()
 	fake.resetMutex.RLock()
 	defer fake.resetMutex.RUnlock()
+	fake.resourceAccountingMutex.RLock()
+	defer fake.resourceAccountingMutex.RUnlock()
 	fake.stateMutex.RLock()
 	defer fake.stateMutex.RUnlock()
+	fake.validatePlacementMutex.RLock()
+	defer fake.validatePlacementMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
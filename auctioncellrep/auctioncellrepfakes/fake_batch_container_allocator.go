@@ -2,6 +2,7 @@
 package auctioncellrepfakes
 
 import (
+	"context"
 	"sync"
 
 	lager "code.cloudfoundry.org/lager/v3"
@@ -10,14 +11,15 @@ import (
 )
 
 type FakeBatchContainerAllocator struct {
-	BatchLRPAllocationRequestStub        func(lager.Logger, string, bool, int, []rep.LRP) []rep.LRP
+	BatchLRPAllocationRequestStub        func(context.Context, lager.Logger, string, bool, int, []rep.LRP) []rep.LRP
 	batchLRPAllocationRequestMutex       sync.RWMutex
 	batchLRPAllocationRequestArgsForCall []struct {
-		arg1 lager.Logger
-		arg2 string
-		arg3 bool
-		arg4 int
-		arg5 []rep.LRP
+		arg1 context.Context
+		arg2 lager.Logger
+		arg3 string
+		arg4 bool
+		arg5 int
+		arg6 []rep.LRP
 	}
 	batchLRPAllocationRequestReturns struct {
 		result1 []rep.LRP
@@ -25,12 +27,13 @@ type FakeBatchContainerAllocator struct {
 	batchLRPAllocationRequestReturnsOnCall map[int]struct {
 		result1 []rep.LRP
 	}
-	BatchTaskAllocationRequestStub        func(lager.Logger, string, []rep.Task) []rep.Task
+	BatchTaskAllocationRequestStub        func(context.Context, lager.Logger, string, []rep.Task) []rep.Task
 	batchTaskAllocationRequestMutex       sync.RWMutex
 	batchTaskAllocationRequestArgsForCall []struct {
-		arg1 lager.Logger
-		arg2 string
-		arg3 []rep.Task
+		arg1 context.Context
+		arg2 lager.Logger
+		arg3 string
+		arg4 []rep.Task
 	}
 	batchTaskAllocationRequestReturns struct {
 		result1 []rep.Task
@@ -38,31 +41,58 @@ type FakeBatchContainerAllocator struct {
 	batchTaskAllocationRequestReturnsOnCall map[int]struct {
 		result1 []rep.Task
 	}
+	CheckLRPsStub        func(lager.Logger, bool, int, []rep.LRP) []error
+	checkLRPsMutex       sync.RWMutex
+	checkLRPsArgsForCall []struct {
+		arg1 lager.Logger
+		arg2 bool
+		arg3 int
+		arg4 []rep.LRP
+	}
+	checkLRPsReturns struct {
+		result1 []error
+	}
+	checkLRPsReturnsOnCall map[int]struct {
+		result1 []error
+	}
+	CheckTasksStub        func(lager.Logger, []rep.Task) []error
+	checkTasksMutex       sync.RWMutex
+	checkTasksArgsForCall []struct {
+		arg1 lager.Logger
+		arg2 []rep.Task
+	}
+	checkTasksReturns struct {
+		result1 []error
+	}
+	checkTasksReturnsOnCall map[int]struct {
+		result1 []error
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
 
-func (fake *FakeBatchContainerAllocator) BatchLRPAllocationRequest(arg1 lager.Logger, arg2 string, arg3 bool, arg4 int, arg5 []rep.LRP) []rep.LRP {
-	var arg5Copy []rep.LRP
-	if arg5 != nil {
-		arg5Copy = make([]rep.LRP, len(arg5))
-		copy(arg5Copy, arg5)
+func (fake *FakeBatchContainerAllocator) BatchLRPAllocationRequest(arg1 context.Context, arg2 lager.Logger, arg3 string, arg4 bool, arg5 int, arg6 []rep.LRP) []rep.LRP {
+	var arg6Copy []rep.LRP
+	if arg6 != nil {
+		arg6Copy = make([]rep.LRP, len(arg6))
+		copy(arg6Copy, arg6)
 	}
 	fake.batchLRPAllocationRequestMutex.Lock()
 	ret, specificReturn := fake.batchLRPAllocationRequestReturnsOnCall[len(fake.batchLRPAllocationRequestArgsForCall)]
 	fake.batchLRPAllocationRequestArgsForCall = append(fake.batchLRPAllocationRequestArgsForCall, struct {
-		arg1 lager.Logger
-		arg2 string
-		arg3 bool
-		arg4 int
-		arg5 []rep.LRP
-	}{arg1, arg2, arg3, arg4, arg5Copy})
+		arg1 context.Context
+		arg2 lager.Logger
+		arg3 string
+		arg4 bool
+		arg5 int
+		arg6 []rep.LRP
+	}{arg1, arg2, arg3, arg4, arg5, arg6Copy})
 	stub := fake.BatchLRPAllocationRequestStub
 	fakeReturns := fake.batchLRPAllocationRequestReturns
-	fake.recordInvocation("BatchLRPAllocationRequest", []interface{}{arg1, arg2, arg3, arg4, arg5Copy})
+	fake.recordInvocation("BatchLRPAllocationRequest", []interface{}{arg1, arg2, arg3, arg4, arg5, arg6Copy})
 	fake.batchLRPAllocationRequestMutex.Unlock()
 	if stub != nil {
-		return stub(arg1, arg2, arg3, arg4, arg5)
+		return stub(arg1, arg2, arg3, arg4, arg5, arg6)
 	}
 	if specificReturn {
 		return ret.result1
@@ -76,17 +106,17 @@ func (fake *FakeBatchContainerAllocator) BatchLRPAllocationRequestCallCount() in
 	return len(fake.batchLRPAllocationRequestArgsForCall)
 }
 
-func (fake *FakeBatchContainerAllocator) BatchLRPAllocationRequestCalls(stub func(lager.Logger, string, bool, int, []rep.LRP) []rep.LRP) {
+func (fake *FakeBatchContainerAllocator) BatchLRPAllocationRequestCalls(stub func(context.Context, lager.Logger, string, bool, int, []rep.LRP) []rep.LRP) {
 	fake.batchLRPAllocationRequestMutex.Lock()
 	defer fake.batchLRPAllocationRequestMutex.Unlock()
 	fake.BatchLRPAllocationRequestStub = stub
 }
 
-func (fake *FakeBatchContainerAllocator) BatchLRPAllocationRequestArgsForCall(i int) (lager.Logger, string, bool, int, []rep.LRP) {
+func (fake *FakeBatchContainerAllocator) BatchLRPAllocationRequestArgsForCall(i int) (context.Context, lager.Logger, string, bool, int, []rep.LRP) {
 	fake.batchLRPAllocationRequestMutex.RLock()
 	defer fake.batchLRPAllocationRequestMutex.RUnlock()
 	argsForCall := fake.batchLRPAllocationRequestArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4, argsForCall.arg5, argsForCall.arg6
 }
 
 func (fake *FakeBatchContainerAllocator) BatchLRPAllocationRequestReturns(result1 []rep.LRP) {
@@ -112,25 +142,26 @@ func (fake *FakeBatchContainerAllocator) BatchLRPAllocationRequestReturnsOnCall(
 	}{result1}
 }
 
-func (fake *FakeBatchContainerAllocator) BatchTaskAllocationRequest(arg1 lager.Logger, arg2 string, arg3 []rep.Task) []rep.Task {
-	var arg3Copy []rep.Task
-	if arg3 != nil {
-		arg3Copy = make([]rep.Task, len(arg3))
-		copy(arg3Copy, arg3)
+func (fake *FakeBatchContainerAllocator) BatchTaskAllocationRequest(arg1 context.Context, arg2 lager.Logger, arg3 string, arg4 []rep.Task) []rep.Task {
+	var arg4Copy []rep.Task
+	if arg4 != nil {
+		arg4Copy = make([]rep.Task, len(arg4))
+		copy(arg4Copy, arg4)
 	}
 	fake.batchTaskAllocationRequestMutex.Lock()
 	ret, specificReturn := fake.batchTaskAllocationRequestReturnsOnCall[len(fake.batchTaskAllocationRequestArgsForCall)]
 	fake.batchTaskAllocationRequestArgsForCall = append(fake.batchTaskAllocationRequestArgsForCall, struct {
-		arg1 lager.Logger
-		arg2 string
-		arg3 []rep.Task
-	}{arg1, arg2, arg3Copy})
+		arg1 context.Context
+		arg2 lager.Logger
+		arg3 string
+		arg4 []rep.Task
+	}{arg1, arg2, arg3, arg4Copy})
 	stub := fake.BatchTaskAllocationRequestStub
 	fakeReturns := fake.batchTaskAllocationRequestReturns
-	fake.recordInvocation("BatchTaskAllocationRequest", []interface{}{arg1, arg2, arg3Copy})
+	fake.recordInvocation("BatchTaskAllocationRequest", []interface{}{arg1, arg2, arg3, arg4Copy})
 	fake.batchTaskAllocationRequestMutex.Unlock()
 	if stub != nil {
-		return stub(arg1, arg2, arg3)
+		return stub(arg1, arg2, arg3, arg4)
 	}
 	if specificReturn {
 		return ret.result1
@@ -144,17 +175,17 @@ func (fake *FakeBatchContainerAllocator) BatchTaskAllocationRequestCallCount() i
 	return len(fake.batchTaskAllocationRequestArgsForCall)
 }
 
-func (fake *FakeBatchContainerAllocator) BatchTaskAllocationRequestCalls(stub func(lager.Logger, string, []rep.Task) []rep.Task) {
+func (fake *FakeBatchContainerAllocator) BatchTaskAllocationRequestCalls(stub func(context.Context, lager.Logger, string, []rep.Task) []rep.Task) {
 	fake.batchTaskAllocationRequestMutex.Lock()
 	defer fake.batchTaskAllocationRequestMutex.Unlock()
 	fake.BatchTaskAllocationRequestStub = stub
 }
 
-func (fake *FakeBatchContainerAllocator) BatchTaskAllocationRequestArgsForCall(i int) (lager.Logger, string, []rep.Task) {
+func (fake *FakeBatchContainerAllocator) BatchTaskAllocationRequestArgsForCall(i int) (context.Context, lager.Logger, string, []rep.Task) {
 	fake.batchTaskAllocationRequestMutex.RLock()
 	defer fake.batchTaskAllocationRequestMutex.RUnlock()
 	argsForCall := fake.batchTaskAllocationRequestArgsForCall[i]
-	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
 }
 
 func (fake *FakeBatchContainerAllocator) BatchTaskAllocationRequestReturns(result1 []rep.Task) {
@@ -180,6 +211,142 @@ func (fake *FakeBatchContainerAllocator) BatchTaskAllocationRequestReturnsOnCall
 	}{result1}
 }
 
+func (fake *FakeBatchContainerAllocator) CheckLRPs(arg1 lager.Logger, arg2 bool, arg3 int, arg4 []rep.LRP) []error {
+	var arg4Copy []rep.LRP
+	if arg4 != nil {
+		arg4Copy = make([]rep.LRP, len(arg4))
+		copy(arg4Copy, arg4)
+	}
+	fake.checkLRPsMutex.Lock()
+	ret, specificReturn := fake.checkLRPsReturnsOnCall[len(fake.checkLRPsArgsForCall)]
+	fake.checkLRPsArgsForCall = append(fake.checkLRPsArgsForCall, struct {
+		arg1 lager.Logger
+		arg2 bool
+		arg3 int
+		arg4 []rep.LRP
+	}{arg1, arg2, arg3, arg4Copy})
+	stub := fake.CheckLRPsStub
+	fakeReturns := fake.checkLRPsReturns
+	fake.recordInvocation("CheckLRPs", []interface{}{arg1, arg2, arg3, arg4Copy})
+	fake.checkLRPsMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3, arg4)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeBatchContainerAllocator) CheckLRPsCallCount() int {
+	fake.checkLRPsMutex.RLock()
+	defer fake.checkLRPsMutex.RUnlock()
+	return len(fake.checkLRPsArgsForCall)
+}
+
+func (fake *FakeBatchContainerAllocator) CheckLRPsCalls(stub func(lager.Logger, bool, int, []rep.LRP) []error) {
+	fake.checkLRPsMutex.Lock()
+	defer fake.checkLRPsMutex.Unlock()
+	fake.CheckLRPsStub = stub
+}
+
+func (fake *FakeBatchContainerAllocator) CheckLRPsArgsForCall(i int) (lager.Logger, bool, int, []rep.LRP) {
+	fake.checkLRPsMutex.RLock()
+	defer fake.checkLRPsMutex.RUnlock()
+	argsForCall := fake.checkLRPsArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3, argsForCall.arg4
+}
+
+func (fake *FakeBatchContainerAllocator) CheckLRPsReturns(result1 []error) {
+	fake.checkLRPsMutex.Lock()
+	defer fake.checkLRPsMutex.Unlock()
+	fake.CheckLRPsStub = nil
+	fake.checkLRPsReturns = struct {
+		result1 []error
+	}{result1}
+}
+
+func (fake *FakeBatchContainerAllocator) CheckLRPsReturnsOnCall(i int, result1 []error) {
+	fake.checkLRPsMutex.Lock()
+	defer fake.checkLRPsMutex.Unlock()
+	fake.CheckLRPsStub = nil
+	if fake.checkLRPsReturnsOnCall == nil {
+		fake.checkLRPsReturnsOnCall = make(map[int]struct {
+			result1 []error
+		})
+	}
+	fake.checkLRPsReturnsOnCall[i] = struct {
+		result1 []error
+	}{result1}
+}
+
+func (fake *FakeBatchContainerAllocator) CheckTasks(arg1 lager.Logger, arg2 []rep.Task) []error {
+	var arg2Copy []rep.Task
+	if arg2 != nil {
+		arg2Copy = make([]rep.Task, len(arg2))
+		copy(arg2Copy, arg2)
+	}
+	fake.checkTasksMutex.Lock()
+	ret, specificReturn := fake.checkTasksReturnsOnCall[len(fake.checkTasksArgsForCall)]
+	fake.checkTasksArgsForCall = append(fake.checkTasksArgsForCall, struct {
+		arg1 lager.Logger
+		arg2 []rep.Task
+	}{arg1, arg2Copy})
+	stub := fake.CheckTasksStub
+	fakeReturns := fake.checkTasksReturns
+	fake.recordInvocation("CheckTasks", []interface{}{arg1, arg2Copy})
+	fake.checkTasksMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeBatchContainerAllocator) CheckTasksCallCount() int {
+	fake.checkTasksMutex.RLock()
+	defer fake.checkTasksMutex.RUnlock()
+	return len(fake.checkTasksArgsForCall)
+}
+
+func (fake *FakeBatchContainerAllocator) CheckTasksCalls(stub func(lager.Logger, []rep.Task) []error) {
+	fake.checkTasksMutex.Lock()
+	defer fake.checkTasksMutex.Unlock()
+	fake.CheckTasksStub = stub
+}
+
+func (fake *FakeBatchContainerAllocator) CheckTasksArgsForCall(i int) (lager.Logger, []rep.Task) {
+	fake.checkTasksMutex.RLock()
+	defer fake.checkTasksMutex.RUnlock()
+	argsForCall := fake.checkTasksArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeBatchContainerAllocator) CheckTasksReturns(result1 []error) {
+	fake.checkTasksMutex.Lock()
+	defer fake.checkTasksMutex.Unlock()
+	fake.CheckTasksStub = nil
+	fake.checkTasksReturns = struct {
+		result1 []error
+	}{result1}
+}
+
+func (fake *FakeBatchContainerAllocator) CheckTasksReturnsOnCall(i int, result1 []error) {
+	fake.checkTasksMutex.Lock()
+	defer fake.checkTasksMutex.Unlock()
+	fake.CheckTasksStub = nil
+	if fake.checkTasksReturnsOnCall == nil {
+		fake.checkTasksReturnsOnCall = make(map[int]struct {
+			result1 []error
+		})
+	}
+	fake.checkTasksReturnsOnCall[i] = struct {
+		result1 []error
+	}{result1}
+}
+
 func (fake *FakeBatchContainerAllocator) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
@@ -187,6 +354,10 @@ func (fake *FakeBatchContainerAllocator) Invocations() map[string][][]interface{
 	defer fake.batchLRPAllocationRequestMutex.RUnlock()
 	fake.batchTaskAllocationRequestMutex.RLock()
 	defer fake.batchTaskAllocationRequestMutex.RUnlock()
+	fake.checkLRPsMutex.RLock()
+	defer fake.checkLRPsMutex.RUnlock()
+	fake.checkTasksMutex.RLock()
+	defer fake.checkTasksMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
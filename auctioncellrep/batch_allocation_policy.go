@@ -0,0 +1,30 @@
+package auctioncellrep
+
+import "fmt"
+
+// BatchAllocationPolicy decides what happens to the rest of a batch of LRPs
+// when some of them cannot be allocated.
+type BatchAllocationPolicy string
+
+const (
+	// BatchAllocationPolicyBestEffort keeps the LRPs that were allocated and
+	// only reports the others as failed.
+	BatchAllocationPolicyBestEffort BatchAllocationPolicy = "best-effort"
+	// BatchAllocationPolicyAllOrNothing deletes the containers allocated for
+	// the batch and reports every LRP in it as failed.
+	BatchAllocationPolicyAllOrNothing BatchAllocationPolicy = "all-or-nothing"
+)
+
+// ParseBatchAllocationPolicy validates a configured policy. An empty policy
+// is best-effort.
+func ParseBatchAllocationPolicy(policy string) (BatchAllocationPolicy, error) {
+	switch BatchAllocationPolicy(policy) {
+	case "":
+		return BatchAllocationPolicyBestEffort, nil
+	case BatchAllocationPolicyBestEffort, BatchAllocationPolicyAllOrNothing:
+		return BatchAllocationPolicy(policy), nil
+	default:
+		return "", fmt.Errorf("unknown batch allocation policy %q: must be one of %q or %q",
+			policy, BatchAllocationPolicyBestEffort, BatchAllocationPolicyAllOrNothing)
+	}
+}
@@ -0,0 +1,25 @@
+package auctioncellrep_test
+
+import (
+	"code.cloudfoundry.org/rep/auctioncellrep"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ParseBatchAllocationPolicy", func() {
+	DescribeTable("accepts the known policies",
+		func(configured string, expected auctioncellrep.BatchAllocationPolicy) {
+			policy, err := auctioncellrep.ParseBatchAllocationPolicy(configured)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(policy).To(Equal(expected))
+		},
+		Entry("unset", "", auctioncellrep.BatchAllocationPolicyBestEffort),
+		Entry("best-effort", "best-effort", auctioncellrep.BatchAllocationPolicyBestEffort),
+		Entry("all-or-nothing", "all-or-nothing", auctioncellrep.BatchAllocationPolicyAllOrNothing),
+	)
+
+	It("rejects unknown policies", func() {
+		_, err := auctioncellrep.ParseBatchAllocationPolicy("most")
+		Expect(err).To(MatchError(ContainSubstring(`unknown batch allocation policy "most"`)))
+	})
+})
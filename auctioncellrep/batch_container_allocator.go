@@ -1,9 +1,14 @@
 package auctioncellrep
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"strconv"
+	"time"
 
+	"code.cloudfoundry.org/clock"
+	loggingclient "code.cloudfoundry.org/diego-logging-client"
 	"code.cloudfoundry.org/executor"
 	"code.cloudfoundry.org/lager/v3"
 	"code.cloudfoundry.org/rep"
@@ -11,25 +16,124 @@ import (
 
 //go:generate counterfeiter . BatchContainerAllocator
 type BatchContainerAllocator interface {
-	BatchLRPAllocationRequest(lager.Logger, string, bool, int, []rep.LRP) []rep.LRP
-	BatchTaskAllocationRequest(lager.Logger, string, []rep.Task) []rep.Task
+	BatchLRPAllocationRequest(context.Context, lager.Logger, string, bool, int, []rep.LRP) []rep.LRP
+	BatchTaskAllocationRequest(context.Context, lager.Logger, string, []rep.Task) []rep.Task
+
+	// CheckLRPs and CheckTasks run the checks of a batch allocation request
+	// without allocating anything. They return why each item would be
+	// rejected, or nil for the items that would be requested from the
+	// executor.
+	CheckLRPs(lager.Logger, bool, int, []rep.LRP) []error
+	CheckTasks(lager.Logger, []rep.Task) []error
+}
+
+var ErrExceedsMaxPerTaskDisk = errors.New("task disk request exceeds the per-task disk limit")
+var ErrExceedsMaxInstancesPerLRP = errors.New("cell already hosts the maximum number of instances of this LRP")
+var ErrDomainNotAllowed = errors.New("work's domain is not in the cell's allowed domains")
+
+const taskRootFSUnavailableMetric = "TaskRootFSUnavailableRejections"
+
+// maxAllocationRetryWait caps the total time spent waiting to retry
+// allocations within a single request, whatever the configured retries.
+const maxAllocationRetryWait = 5 * time.Second
+
+// transientAllocationErrors are the allocation failures that may go away on
+// their own, such as when containers being deleted still hold resources.
+var transientAllocationErrors = []executor.Error{
+	executor.ErrInsufficientResourcesAvailable,
+	executor.ErrFailureToCheckSpace,
 }
 
 type containerAllocator struct {
 	generateInstanceGuid func() (string, error)
 	stackPathMap         rep.StackPathMap
 	executorClient       executor.Client
+	maxPerTaskDiskMB     int
+	proxyMemoryByRootFS  ProxyMemoryByRootFS
+	maxInstancesPerLRP   int
+	allowedDomains       map[string]struct{}
+	rootFSQuarantine     *RootFSQuarantine
+	metronClient         loggingclient.IngressClient
+	allocationRetries    int
+	retryInterval        time.Duration
+	batchPolicy          BatchAllocationPolicy
+	clock                clock.Clock
 }
 
-func NewContainerAllocator(instanceGuidGenerator func() (string, error), stackPathMap rep.StackPathMap, executorClient executor.Client) BatchContainerAllocator {
+// ContainerAllocatorOptions holds the optional behaviour of a
+// BatchContainerAllocator. The zero value leaves all of it off.
+type ContainerAllocatorOptions struct {
+	// MaxPerTaskDiskMB, when positive, rejects any task requesting more disk
+	// than that, even if the cell has room for it.
+	MaxPerTaskDiskMB int
+	// ProxyMemoryByRootFS overrides the proxy memory allocation for LRPs
+	// using specific rootfses.
+	ProxyMemoryByRootFS ProxyMemoryByRootFS
+	// MaxInstancesPerLRP, when positive, caps how many instances of the same
+	// LRP the cell hosts at once.
+	MaxInstancesPerLRP int
+	// AllowedDomains, when not empty, rejects any work from another domain.
+	AllowedDomains []string
+	// RootFSQuarantine refuses work using a quarantined rootfs.
+	RootFSQuarantine *RootFSQuarantine
+	// AllocationRetries is how many times allocations failing with a
+	// transient error are retried, waiting RetryInterval longer before each
+	// attempt. The retries stop short of the Perform deadline, and never wait
+	// more than 5 seconds in total.
+	AllocationRetries int
+	RetryInterval     time.Duration
+	// BatchPolicy, when all-or-nothing, deletes the containers allocated for
+	// a batch of LRPs when others in it fail. Perform fails the rest of the
+	// batch.
+	BatchPolicy BatchAllocationPolicy
+}
+
+// NewContainerAllocator returns a BatchContainerAllocator. Tasks requesting a
+// preloaded rootfs the cell does not have are counted on metronClient.
+func NewContainerAllocator(instanceGuidGenerator func() (string, error), stackPathMap rep.StackPathMap, executorClient executor.Client, metronClient loggingclient.IngressClient, clock clock.Clock, options ContainerAllocatorOptions) BatchContainerAllocator {
+	rootFSQuarantine := options.RootFSQuarantine
+	if rootFSQuarantine == nil {
+		rootFSQuarantine = NewRootFSQuarantine(0, 0, nil, clock, metronClient)
+	}
+
 	return containerAllocator{
 		generateInstanceGuid: instanceGuidGenerator,
 		stackPathMap:         stackPathMap,
 		executorClient:       executorClient,
+		maxPerTaskDiskMB:     options.MaxPerTaskDiskMB,
+		proxyMemoryByRootFS:  options.ProxyMemoryByRootFS,
+		maxInstancesPerLRP:   options.MaxInstancesPerLRP,
+		allowedDomains:       domainSet(options.AllowedDomains),
+		rootFSQuarantine:     rootFSQuarantine,
+		metronClient:         metronClient,
+		allocationRetries:    options.AllocationRetries,
+		retryInterval:        options.RetryInterval,
+		batchPolicy:          options.BatchPolicy,
+		clock:                clock,
 	}
 }
 
-func buildLRPTags(lrp rep.LRP, instanceGuid string) executor.Tags {
+func domainSet(domains []string) map[string]struct{} {
+	if len(domains) == 0 {
+		return nil
+	}
+
+	set := make(map[string]struct{}, len(domains))
+	for _, domain := range domains {
+		set[domain] = struct{}{}
+	}
+	return set
+}
+
+func (ca containerAllocator) domainAllowed(domain string) bool {
+	if ca.allowedDomains == nil {
+		return true
+	}
+	_, ok := ca.allowedDomains[domain]
+	return ok
+}
+
+func buildLRPTags(lrp rep.LRP, instanceGuid, auctionID string) executor.Tags {
 	tags := executor.Tags{}
 	tags[rep.DomainTag] = lrp.Domain
 	tags[rep.ProcessGuidTag] = lrp.ProcessGuid
@@ -41,11 +145,14 @@ func buildLRPTags(lrp rep.LRP, instanceGuid string) executor.Tags {
 	volumeDrivers, _ := json.Marshal(lrp.PlacementConstraint.VolumeDrivers)
 	tags[rep.PlacementTagsTag] = string(placementTags)
 	tags[rep.VolumeDriversTag] = string(volumeDrivers)
+	if auctionID != "" {
+		tags[rep.AuctionIDTag] = auctionID
+	}
 
 	return tags
 }
 
-func buildTaskTags(task rep.Task) executor.Tags {
+func buildTaskTags(task rep.Task, auctionID string) executor.Tags {
 	tags := executor.Tags{}
 	tags[rep.LifecycleTag] = rep.TaskLifecycle
 	tags[rep.DomainTag] = task.Domain
@@ -54,13 +161,109 @@ func buildTaskTags(task rep.Task) executor.Tags {
 	volumeDrivers, _ := json.Marshal(task.PlacementConstraint.VolumeDrivers)
 	tags[rep.PlacementTagsTag] = string(placementTags)
 	tags[rep.VolumeDriversTag] = string(volumeDrivers)
+	if auctionID != "" {
+		tags[rep.AuctionIDTag] = auctionID
+	}
 	return tags
 }
 
-func (ca containerAllocator) BatchLRPAllocationRequest(logger lager.Logger, traceID string, enableContainerProxy bool, proxyMemoryAllocation int, lrps []rep.LRP) (unallocatedLRPs []rep.LRP) {
+// instancesByProcessGuid counts the LRP containers already on the cell for
+// each process guid.
+func (ca containerAllocator) instancesByProcessGuid(logger lager.Logger) (map[string]int, bool) {
+	containers, err := ca.executorClient.ListContainers(logger)
+	if err != nil {
+		logger.Error("failed-to-list-containers", err)
+		return nil, false
+	}
+
+	instances := map[string]int{}
+	for _, container := range containers {
+		if container.Tags[rep.LifecycleTag] != rep.LRPLifecycle {
+			continue
+		}
+		instances[container.Tags[rep.ProcessGuidTag]]++
+	}
+	return instances, true
+}
+
+// lrpChecks is what checking a batch of LRPs needs from the executor,
+// fetched once for the whole batch.
+type lrpChecks struct {
+	instances      map[string]int
+	checkInstances bool
+}
+
+func (ca containerAllocator) newLRPChecks(logger lager.Logger, lrps []rep.LRP) *lrpChecks {
+	checks := &lrpChecks{}
+	if len(lrps) > 0 && ca.maxInstancesPerLRP > 0 {
+		checks.instances, checks.checkInstances = ca.instancesByProcessGuid(logger)
+	}
+	return checks
+}
+
+// checkLRP returns why lrp cannot be allocated, or the memory to allocate
+// for it when it can. An LRP that passes counts towards the maximum
+// instances for the rest of the batch.
+func (ca containerAllocator) checkLRP(logger lager.Logger, checks *lrpChecks, enableContainerProxy bool, proxyMemoryAllocation int, lrp rep.LRP) (int, error) {
+	if !ca.domainAllowed(lrp.Domain) {
+		logger.Error("domain-not-allowed", ErrDomainNotAllowed, lager.Data{
+			"process-guid": lrp.ProcessGuid,
+			"index":        lrp.Index,
+			"domain":       lrp.Domain,
+		})
+		return 0, ErrDomainNotAllowed
+	}
+
+	_, err := ca.stackPathMap.PathForRootFS(lrp.RootFs)
+	if err != nil {
+		return 0, err
+	}
+
+	if ca.rootFSQuarantine.Quarantined(lrp.RootFs) {
+		logger.Error("rootfs-quarantined", ErrRootFSQuarantined, lager.Data{
+			"process-guid": lrp.ProcessGuid,
+			"index":        lrp.Index,
+			"rootfs":       lrp.RootFs,
+		})
+		return 0, ErrRootFSQuarantined
+	}
+
+	memoryMB := int(lrp.MemoryMB)
+	if memoryMB > 0 && enableContainerProxy {
+		memoryMB += ca.proxyMemoryByRootFS.ForRootFS(lrp.RootFs, proxyMemoryAllocation)
+	}
+
+	if checks.checkInstances {
+		if checks.instances[lrp.ProcessGuid] >= ca.maxInstancesPerLRP {
+			logger.Error("exceeds-max-instances-per-lrp", ErrExceedsMaxInstancesPerLRP, lager.Data{
+				"process-guid":          lrp.ProcessGuid,
+				"index":                 lrp.Index,
+				"max-instances-per-lrp": ca.maxInstancesPerLRP,
+			})
+			return 0, ErrExceedsMaxInstancesPerLRP
+		}
+		checks.instances[lrp.ProcessGuid]++
+	}
+
+	return memoryMB, nil
+}
+
+func (ca containerAllocator) CheckLRPs(logger lager.Logger, enableContainerProxy bool, proxyMemoryAllocation int, lrps []rep.LRP) []error {
+	logger = logger.Session("lrp-check-instances")
+	checks := ca.newLRPChecks(logger, lrps)
+
+	errs := make([]error, len(lrps))
+	for i, lrp := range lrps {
+		_, errs[i] = ca.checkLRP(logger, checks, enableContainerProxy, proxyMemoryAllocation, lrp)
+	}
+	return errs
+}
+
+func (ca containerAllocator) BatchLRPAllocationRequest(ctx context.Context, logger lager.Logger, traceID string, enableContainerProxy bool, proxyMemoryAllocation int, lrps []rep.LRP) (unallocatedLRPs []rep.LRP) {
 	logger = logger.Session("lrp-allocate-instances")
 	requests := make([]executor.AllocationRequest, 0, len(lrps))
 	lrpGuidMap := make(map[string]rep.LRP, len(lrps))
+	checks := ca.newLRPChecks(logger, lrps)
 
 	for _, lrp := range lrps {
 		instanceGuid, err := ca.generateInstanceGuid()
@@ -69,32 +272,31 @@ func (ca containerAllocator) BatchLRPAllocationRequest(logger lager.Logger, trac
 			continue
 		}
 
-		_, err = ca.stackPathMap.PathForRootFS(lrp.RootFs)
+		memoryMB, err := ca.checkLRP(logger, checks, enableContainerProxy, proxyMemoryAllocation, lrp)
 		if err != nil {
 			unallocatedLRPs = append(unallocatedLRPs, lrp)
 			continue
 		}
 
-		memoryMB := int(lrp.MemoryMB)
-		if memoryMB > 0 && enableContainerProxy {
-			memoryMB += proxyMemoryAllocation
-		}
-
 		resource := executor.NewResource(memoryMB, int(lrp.DiskMB), int(lrp.MaxPids))
 		containerGuid := rep.LRPContainerGuid(lrp.ProcessGuid, instanceGuid)
 
 		lrpGuidMap[containerGuid] = lrp
-		requests = append(requests, executor.NewAllocationRequest(containerGuid, &resource, true, buildLRPTags(lrp, instanceGuid)))
+		requests = append(requests, executor.NewAllocationRequest(containerGuid, &resource, true, buildLRPTags(lrp, instanceGuid, traceID)))
 	}
 
 	if len(unallocatedLRPs) > 0 {
 		logger.Info("failed-to-translate-lrps-to-containers", lager.Data{"num-failed-to-translate": len(unallocatedLRPs)})
+		if ca.batchPolicy == BatchAllocationPolicyAllOrNothing {
+			logger.Info("rejecting-batch-after-partial-failure", lager.Data{"num-lrps": len(lrps)})
+			return lrps
+		}
 	}
 
 	logger.Info("requesting-container-allocation", lager.Data{"num-requesting-allocation": len(requests)})
 	var failures []executor.AllocationFailure
 	if len(requests) > 0 {
-		failures = ca.executorClient.AllocateContainers(logger, traceID, requests)
+		failures = ca.allocateContainers(ctx, logger, traceID, requests)
 	}
 
 	logger.Info("succeeded-requesting-container-allocation", lager.Data{"num-failed-to-allocate": len(failures)})
@@ -106,10 +308,88 @@ func (ca containerAllocator) BatchLRPAllocationRequest(logger lager.Logger, trac
 		}
 	}
 
+	if len(failures) > 0 && ca.batchPolicy == BatchAllocationPolicyAllOrNothing {
+		ca.rollBack(logger, traceID, requests, failures)
+		return lrps
+	}
+
 	return unallocatedLRPs
 }
 
-func (ca containerAllocator) BatchTaskAllocationRequest(logger lager.Logger, traceID string, tasks []rep.Task) (unallocatedTasks []rep.Task) {
+// rollBack deletes the containers allocated for the requests that did not
+// fail, so that none of the batch is left on the cell.
+func (ca containerAllocator) rollBack(logger lager.Logger, traceID string, requests []executor.AllocationRequest, failures []executor.AllocationFailure) {
+	logger.Info("rolling-back-batch-after-partial-failure", lager.Data{"num-failed-to-allocate": len(failures)})
+
+	failed := make(map[string]struct{}, len(failures))
+	for _, failure := range failures {
+		failed[failure.Guid] = struct{}{}
+	}
+
+	for _, request := range requests {
+		if _, ok := failed[request.Guid]; ok {
+			continue
+		}
+		err := ca.executorClient.DeleteContainer(logger, traceID, request.Guid)
+		if err != nil {
+			logger.Error("failed-to-roll-back-container", err, lager.Data{"container-guid": request.Guid})
+		}
+	}
+}
+
+// checkTask returns why task cannot be allocated, or nil when it can.
+func (ca containerAllocator) checkTask(logger lager.Logger, task rep.Task) error {
+	if !ca.domainAllowed(task.Domain) {
+		logger.Error("domain-not-allowed", ErrDomainNotAllowed, lager.Data{
+			"task-guid": task.TaskGuid,
+			"domain":    task.Domain,
+		})
+		return ErrDomainNotAllowed
+	}
+
+	_, err := ca.stackPathMap.PathForRootFS(task.RootFs)
+	if err == rep.ErrPreloadedRootFSNotFound {
+		logger.Error("rootfs-unavailable", err, lager.Data{
+			"task-guid": task.TaskGuid,
+			"rootfs":    task.RootFs,
+		})
+		return err
+	}
+	if err != nil {
+		return err
+	}
+
+	if ca.rootFSQuarantine.Quarantined(task.RootFs) {
+		logger.Error("rootfs-quarantined", ErrRootFSQuarantined, lager.Data{
+			"task-guid": task.TaskGuid,
+			"rootfs":    task.RootFs,
+		})
+		return ErrRootFSQuarantined
+	}
+
+	if ca.maxPerTaskDiskMB > 0 && int(task.DiskMB) > ca.maxPerTaskDiskMB {
+		logger.Error("exceeds-max-per-task-disk", ErrExceedsMaxPerTaskDisk, lager.Data{
+			"task-guid":            task.TaskGuid,
+			"disk-mb":              task.DiskMB,
+			"max-per-task-disk-mb": ca.maxPerTaskDiskMB,
+		})
+		return ErrExceedsMaxPerTaskDisk
+	}
+
+	return nil
+}
+
+func (ca containerAllocator) CheckTasks(logger lager.Logger, tasks []rep.Task) []error {
+	logger = logger.Session("task-check-instances")
+
+	errs := make([]error, len(tasks))
+	for i, task := range tasks {
+		errs[i] = ca.checkTask(logger, task)
+	}
+	return errs
+}
+
+func (ca containerAllocator) BatchTaskAllocationRequest(ctx context.Context, logger lager.Logger, traceID string, tasks []rep.Task) (unallocatedTasks []rep.Task) {
 	logger = logger.Session("task-allocate-instances")
 
 	failedTasks := make([]rep.Task, 0)
@@ -118,13 +398,19 @@ func (ca containerAllocator) BatchTaskAllocationRequest(logger lager.Logger, tra
 
 	for _, task := range tasks {
 		taskMap[task.TaskGuid] = task
-		_, err := ca.stackPathMap.PathForRootFS(task.RootFs)
+
+		err := ca.checkTask(logger, task)
+		if err == rep.ErrPreloadedRootFSNotFound {
+			if err := ca.metronClient.IncrementCounter(taskRootFSUnavailableMetric); err != nil {
+				logger.Error("failed-to-increment-rootfs-unavailable-counter", err)
+			}
+		}
 		if err != nil {
 			failedTasks = append(failedTasks, task)
 			continue
 		}
 
-		tags := buildTaskTags(task)
+		tags := buildTaskTags(task, traceID)
 		resource := executor.NewResource(int(task.MemoryMB), int(task.DiskMB), int(task.MaxPids))
 		requests = append(requests, executor.NewAllocationRequest(task.TaskGuid, &resource, false, tags))
 	}
@@ -137,7 +423,7 @@ func (ca containerAllocator) BatchTaskAllocationRequest(logger lager.Logger, tra
 	logger.Info("requesting-container-allocation", lager.Data{"num-requesting-allocation": len(requests)})
 	var failures []executor.AllocationFailure
 	if len(requests) > 0 {
-		failures = ca.executorClient.AllocateContainers(logger, traceID, requests)
+		failures = ca.allocateContainers(ctx, logger, traceID, requests)
 	}
 
 	for _, failure := range failures {
@@ -149,3 +435,61 @@ func (ca containerAllocator) BatchTaskAllocationRequest(logger lager.Logger, tra
 
 	return unallocatedTasks
 }
+
+// allocateContainers requests the allocations from the executor, retrying
+// the ones that failed with a transient error. It stops retrying when ctx is
+// done, and does not wait for a retry that would leave less than
+// performDeadlineMargin before the deadline of ctx or take the total wait
+// past maxAllocationRetryWait.
+func (ca containerAllocator) allocateContainers(ctx context.Context, logger lager.Logger, traceID string, requests []executor.AllocationRequest) []executor.AllocationFailure {
+	failures := ca.executorClient.AllocateContainers(logger, traceID, requests)
+	retryDeadline := ca.clock.Now().Add(maxAllocationRetryWait)
+	if deadline, ok := ctx.Deadline(); ok && deadline.Add(-performDeadlineMargin).Before(retryDeadline) {
+		retryDeadline = deadline.Add(-performDeadlineMargin)
+	}
+
+	for attempt := 1; attempt <= ca.allocationRetries; attempt++ {
+		var retries []executor.AllocationRequest
+		var permanentFailures []executor.AllocationFailure
+		for _, failure := range failures {
+			if isTransientAllocationFailure(failure) {
+				retries = append(retries, failure.AllocationRequest)
+			} else {
+				permanentFailures = append(permanentFailures, failure)
+			}
+		}
+		if len(retries) == 0 {
+			break
+		}
+
+		wait := time.Duration(attempt) * ca.retryInterval
+		if ca.clock.Now().Add(wait).After(retryDeadline) {
+			logger.Info("giving-up-retrying", lager.Data{"attempt": attempt, "reason": "retry wait exceeded"})
+			return failures
+		}
+
+		logger.Info("retrying-transient-allocation-failures", lager.Data{"attempt": attempt, "num-retrying": len(retries)})
+		timer := ca.clock.NewTimer(wait)
+		select {
+		case <-timer.C():
+		case <-ctx.Done():
+		}
+		timer.Stop()
+		if ctx.Err() != nil {
+			logger.Info("giving-up-retrying", lager.Data{"attempt": attempt, "reason": ctx.Err().Error()})
+			return failures
+		}
+		failures = append(permanentFailures, ca.executorClient.AllocateContainers(logger, traceID, retries)...)
+	}
+
+	return failures
+}
+
+func isTransientAllocationFailure(failure executor.AllocationFailure) bool {
+	for _, transientErr := range transientAllocationErrors {
+		if failure.ErrorMsg == transientErr.Error() {
+			return true
+		}
+	}
+	return false
+}
@@ -1,12 +1,16 @@
 package auctioncellrep_test
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"strconv"
+	"time"
 
 	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/clock"
+	mfakes "code.cloudfoundry.org/diego-logging-client/testhelpers"
 	"code.cloudfoundry.org/executor"
 	fake_client "code.cloudfoundry.org/executor/fakes"
 	"code.cloudfoundry.org/lager/v3/lagertest"
@@ -26,6 +30,15 @@ var _ = Describe("ContainerAllocator", func() {
 		fakeGenerateContainerGuid func() (string, error)
 		logger                    *lagertest.TestLogger
 		commonErr                 error
+		maxPerTaskDiskMB          int
+		proxyMemoryByRootFS       auctioncellrep.ProxyMemoryByRootFS
+		maxInstancesPerLRP        int
+		allowedDomains            []string
+		rootFSQuarantine          *auctioncellrep.RootFSQuarantine
+		fakeMetronClient          *mfakes.FakeIngressClient
+		allocationRetries         int
+		batchPolicy               auctioncellrep.BatchAllocationPolicy
+		retryInterval             time.Duration
 
 		allocator auctioncellrep.BatchContainerAllocator
 	)
@@ -37,6 +50,15 @@ var _ = Describe("ContainerAllocator", func() {
 		proxyMemoryAllocation = 12
 		executorClient = new(fake_client.FakeClient)
 		commonErr = errors.New("Failed to fetch")
+		maxPerTaskDiskMB = 0
+		proxyMemoryByRootFS = nil
+		maxInstancesPerLRP = 0
+		allowedDomains = nil
+		rootFSQuarantine = auctioncellrep.NewRootFSQuarantine(1, 0, rep.StackPathMap{linuxStack: linuxPath}, clock.NewClock(), new(mfakes.FakeIngressClient))
+		fakeMetronClient = new(mfakes.FakeIngressClient)
+		allocationRetries = 0
+		batchPolicy = auctioncellrep.BatchAllocationPolicyBestEffort
+		retryInterval = time.Millisecond
 
 		fakeGenerateContainerGuidCallCount := 0
 		fakeGenerateContainerGuid = func() (string, error) {
@@ -50,6 +72,18 @@ var _ = Describe("ContainerAllocator", func() {
 			fakeGenerateContainerGuid,
 			rep.StackPathMap{linuxStack: linuxPath},
 			executorClient,
+			fakeMetronClient,
+			clock.NewClock(),
+			auctioncellrep.ContainerAllocatorOptions{
+				MaxPerTaskDiskMB:    maxPerTaskDiskMB,
+				ProxyMemoryByRootFS: proxyMemoryByRootFS,
+				MaxInstancesPerLRP:  maxInstancesPerLRP,
+				AllowedDomains:      allowedDomains,
+				RootFSQuarantine:    rootFSQuarantine,
+				AllocationRetries:   allocationRetries,
+				RetryInterval:       retryInterval,
+				BatchPolicy:         batchPolicy,
+			},
 		)
 	})
 
@@ -79,7 +113,7 @@ var _ = Describe("ContainerAllocator", func() {
 		})
 
 		It("makes the correct allocation requests for all LRPs", func() {
-			allocator.BatchLRPAllocationRequest(logger, "some-trace-id", enableContainerProxy, proxyMemoryAllocation, []rep.LRP{lrp1, lrp2})
+			allocator.BatchLRPAllocationRequest(context.Background(), logger, "some-trace-id", enableContainerProxy, proxyMemoryAllocation, []rep.LRP{lrp1, lrp2})
 
 			Expect(executorClient.AllocateContainersCallCount()).To(Equal(1))
 			_, traceID, arg := executorClient.AllocateContainersArgsForCall(0)
@@ -90,8 +124,28 @@ var _ = Describe("ContainerAllocator", func() {
 			))
 		})
 
+		It("tags the containers with the auction that placed them", func() {
+			allocator.BatchLRPAllocationRequest(context.Background(), logger, "some-trace-id", enableContainerProxy, proxyMemoryAllocation, []rep.LRP{lrp1, lrp2})
+
+			_, _, arg := executorClient.AllocateContainersArgsForCall(0)
+			Expect(arg).To(HaveLen(2))
+			for _, request := range arg {
+				Expect(request.Tags).To(HaveKeyWithValue(rep.AuctionIDTag, "some-trace-id"))
+			}
+		})
+
+		Context("when the auction has no trace id", func() {
+			It("does not tag the containers with an auction", func() {
+				allocator.BatchLRPAllocationRequest(context.Background(), logger, "", enableContainerProxy, proxyMemoryAllocation, []rep.LRP{lrp1})
+
+				_, _, arg := executorClient.AllocateContainersArgsForCall(0)
+				Expect(arg).To(HaveLen(1))
+				Expect(arg[0].Tags).NotTo(HaveKey(rep.AuctionIDTag))
+			})
+		})
+
 		It("does not mark any LRP Auctions as failed", func() {
-			failedWork := allocator.BatchLRPAllocationRequest(logger, "some-trace-id", enableContainerProxy, proxyMemoryAllocation, []rep.LRP{lrp1, lrp2})
+			failedWork := allocator.BatchLRPAllocationRequest(context.Background(), logger, "some-trace-id", enableContainerProxy, proxyMemoryAllocation, []rep.LRP{lrp1, lrp2})
 			Expect(failedWork).To(BeEmpty())
 		})
 
@@ -103,9 +157,241 @@ var _ = Describe("ContainerAllocator", func() {
 			})
 
 			It("marks the corresponding LRP Auctions as failed", func() {
-				failedWork := allocator.BatchLRPAllocationRequest(logger, "some-trace-id", enableContainerProxy, proxyMemoryAllocation, []rep.LRP{lrp1, lrp2})
+				failedWork := allocator.BatchLRPAllocationRequest(context.Background(), logger, "some-trace-id", enableContainerProxy, proxyMemoryAllocation, []rep.LRP{lrp1, lrp2})
 				Expect(failedWork).To(ConsistOf(lrp2))
 			})
+
+			It("keeps the containers that were allocated", func() {
+				allocator.BatchLRPAllocationRequest(context.Background(), logger, "some-trace-id", enableContainerProxy, proxyMemoryAllocation, []rep.LRP{lrp1, lrp2})
+				Expect(executorClient.DeleteContainerCallCount()).To(BeZero())
+			})
+
+			Context("and the batch allocation policy is all-or-nothing", func() {
+				BeforeEach(func() {
+					batchPolicy = auctioncellrep.BatchAllocationPolicyAllOrNothing
+				})
+
+				It("marks every LRP in the batch as failed", func() {
+					failedWork := allocator.BatchLRPAllocationRequest(context.Background(), logger, "some-trace-id", enableContainerProxy, proxyMemoryAllocation, []rep.LRP{lrp1, lrp2})
+					Expect(failedWork).To(ConsistOf(lrp1, lrp2))
+				})
+
+				It("deletes the containers that were allocated", func() {
+					allocator.BatchLRPAllocationRequest(context.Background(), logger, "some-trace-id", enableContainerProxy, proxyMemoryAllocation, []rep.LRP{lrp1, lrp2})
+
+					Expect(executorClient.DeleteContainerCallCount()).To(Equal(1))
+					_, traceID, guid := executorClient.DeleteContainerArgsForCall(0)
+					Expect(traceID).To(Equal("some-trace-id"))
+					Expect(guid).To(Equal(allocationRequestFromLRP(lrp1).Guid))
+				})
+			})
+		})
+
+		Context("when the batch allocation policy is all-or-nothing and an LRP is rejected before allocation", func() {
+			BeforeEach(func() {
+				batchPolicy = auctioncellrep.BatchAllocationPolicyAllOrNothing
+				lrp2.MemoryMB = 8193
+			})
+
+			It("does not allocate any of the batch", func() {
+				failedWork := allocator.BatchLRPAllocationRequest(context.Background(), logger, "some-trace-id", enableContainerProxy, proxyMemoryAllocation, []rep.LRP{lrp1, lrp2})
+				Expect(failedWork).To(ConsistOf(lrp1, lrp2))
+				Expect(executorClient.AllocateContainersCallCount()).To(BeZero())
+			})
+		})
+
+		Context("when allocation retries are configured", func() {
+			var allocationRequest executor.AllocationRequest
+
+			BeforeEach(func() {
+				allocationRetries = 2
+				allocationRequest = allocationRequestFromLRP(lrp2)
+			})
+
+			Context("and a container fails to be allocated with a transient error", func() {
+				BeforeEach(func() {
+					transientFailure := executor.NewAllocationFailure(&allocationRequest, executor.ErrInsufficientResourcesAvailable.Error())
+					executorClient.AllocateContainersReturnsOnCall(0, []executor.AllocationFailure{transientFailure})
+					executorClient.AllocateContainersReturnsOnCall(1, []executor.AllocationFailure{transientFailure})
+					executorClient.AllocateContainersReturnsOnCall(2, []executor.AllocationFailure{})
+				})
+
+				It("retries only the failed allocation", func() {
+					failedWork := allocator.BatchLRPAllocationRequest(context.Background(), logger, "some-trace-id", enableContainerProxy, proxyMemoryAllocation, []rep.LRP{lrp1, lrp2})
+					Expect(failedWork).To(BeEmpty())
+
+					Expect(executorClient.AllocateContainersCallCount()).To(Equal(3))
+					_, traceID, arg := executorClient.AllocateContainersArgsForCall(1)
+					Expect(traceID).To(Equal("some-trace-id"))
+					Expect(arg).To(ConsistOf(allocationRequest))
+					Expect(logger).To(gbytes.Say("retrying-transient-allocation-failures"))
+				})
+
+				Context("and it keeps failing", func() {
+					BeforeEach(func() {
+						transientFailure := executor.NewAllocationFailure(&allocationRequest, executor.ErrInsufficientResourcesAvailable.Error())
+						executorClient.AllocateContainersReturnsOnCall(2, []executor.AllocationFailure{transientFailure})
+					})
+
+					It("gives up after the configured number of retries", func() {
+						failedWork := allocator.BatchLRPAllocationRequest(context.Background(), logger, "some-trace-id", enableContainerProxy, proxyMemoryAllocation, []rep.LRP{lrp1, lrp2})
+						Expect(failedWork).To(ConsistOf(lrp2))
+						Expect(executorClient.AllocateContainersCallCount()).To(Equal(3))
+					})
+
+					It("stops retrying once the context is done", func() {
+						ctx, cancel := context.WithCancel(context.Background())
+						cancel()
+
+						failedWork := allocator.BatchLRPAllocationRequest(ctx, logger, "some-trace-id", enableContainerProxy, proxyMemoryAllocation, []rep.LRP{lrp1, lrp2})
+						Expect(failedWork).To(ConsistOf(lrp2))
+						Expect(executorClient.AllocateContainersCallCount()).To(Equal(1))
+						Expect(logger).To(gbytes.Say("giving-up-retrying"))
+					})
+				})
+			})
+
+			Context("and waiting for a retry would take too long", func() {
+				BeforeEach(func() {
+					transientFailure := executor.NewAllocationFailure(&allocationRequest, executor.ErrInsufficientResourcesAvailable.Error())
+					executorClient.AllocateContainersReturns([]executor.AllocationFailure{transientFailure})
+				})
+
+				Context("for the deadline of the context", func() {
+					BeforeEach(func() {
+						retryInterval = time.Minute
+					})
+
+					It("gives up without waiting", func() {
+						ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+						defer cancel()
+
+						failedWork := allocator.BatchLRPAllocationRequest(ctx, logger, "some-trace-id", enableContainerProxy, proxyMemoryAllocation, []rep.LRP{lrp1, lrp2})
+						Expect(failedWork).To(ConsistOf(lrp2))
+						Expect(executorClient.AllocateContainersCallCount()).To(Equal(1))
+						Expect(logger).To(gbytes.Say("giving-up-retrying.*retry wait exceeded"))
+					})
+				})
+
+				Context("for the longest total wait", func() {
+					BeforeEach(func() {
+						retryInterval = time.Hour
+					})
+
+					It("gives up without waiting", func() {
+						failedWork := allocator.BatchLRPAllocationRequest(context.Background(), logger, "some-trace-id", enableContainerProxy, proxyMemoryAllocation, []rep.LRP{lrp1, lrp2})
+						Expect(failedWork).To(ConsistOf(lrp2))
+						Expect(executorClient.AllocateContainersCallCount()).To(Equal(1))
+						Expect(logger).To(gbytes.Say("giving-up-retrying.*retry wait exceeded"))
+					})
+				})
+			})
+
+			Context("and a container fails to be allocated with a permanent error", func() {
+				BeforeEach(func() {
+					allocationFailure := executor.NewAllocationFailure(&allocationRequest, commonErr.Error())
+					executorClient.AllocateContainersReturns([]executor.AllocationFailure{allocationFailure})
+				})
+
+				It("does not retry it", func() {
+					failedWork := allocator.BatchLRPAllocationRequest(context.Background(), logger, "some-trace-id", enableContainerProxy, proxyMemoryAllocation, []rep.LRP{lrp1, lrp2})
+					Expect(failedWork).To(ConsistOf(lrp2))
+					Expect(executorClient.AllocateContainersCallCount()).To(Equal(1))
+				})
+			})
+		})
+
+		Context("when a maximum number of instances per LRP is configured", func() {
+			var lrp3 rep.LRP
+
+			BeforeEach(func() {
+				maxInstancesPerLRP = 2
+				lrp3 = rep.NewLRP(
+					"ig-3",
+					models.NewActualLRPKey("process-guid", 2, "tests"),
+					rep.NewResource(2048, 1024, 100),
+					rep.NewPlacementConstraint(linuxRootFSURL, []string{}, []string{}),
+				)
+			})
+
+			Context("and the cell hosts fewer instances than the maximum", func() {
+				BeforeEach(func() {
+					executorClient.ListContainersReturns([]executor.Container{
+						{Guid: "other", Tags: executor.Tags{rep.LifecycleTag: rep.LRPLifecycle, rep.ProcessGuidTag: "other-process-guid"}},
+					}, nil)
+				})
+
+				It("allocates the instances up to the maximum", func() {
+					failedWork := allocator.BatchLRPAllocationRequest(context.Background(), logger, "some-trace-id", enableContainerProxy, proxyMemoryAllocation, []rep.LRP{lrp1, lrp2})
+					Expect(failedWork).To(BeEmpty())
+
+					_, _, arg := executorClient.AllocateContainersArgsForCall(0)
+					Expect(arg).To(HaveLen(2))
+				})
+
+				It("rejects the instances of the batch beyond the maximum", func() {
+					failedWork := allocator.BatchLRPAllocationRequest(context.Background(), logger, "some-trace-id", enableContainerProxy, proxyMemoryAllocation, []rep.LRP{lrp1, lrp2, lrp3})
+					Expect(failedWork).To(ConsistOf(lrp3))
+				})
+			})
+
+			Context("and the cell already hosts the maximum", func() {
+				BeforeEach(func() {
+					executorClient.ListContainersReturns([]executor.Container{
+						{Guid: "a", Tags: executor.Tags{rep.LifecycleTag: rep.LRPLifecycle, rep.ProcessGuidTag: "process-guid"}},
+						{Guid: "b", Tags: executor.Tags{rep.LifecycleTag: rep.LRPLifecycle, rep.ProcessGuidTag: "process-guid"}},
+					}, nil)
+				})
+
+				It("rejects further instances of that LRP", func() {
+					failedWork := allocator.BatchLRPAllocationRequest(context.Background(), logger, "some-trace-id", enableContainerProxy, proxyMemoryAllocation, []rep.LRP{lrp1})
+					Expect(failedWork).To(ConsistOf(lrp1))
+					Expect(executorClient.AllocateContainersCallCount()).To(BeZero())
+					Expect(logger).To(gbytes.Say("exceeds-max-instances-per-lrp"))
+				})
+			})
+
+			Context("and the containers cannot be listed", func() {
+				BeforeEach(func() {
+					executorClient.ListContainersReturns(nil, commonErr)
+				})
+
+				It("leaves the decision to the executor", func() {
+					failedWork := allocator.BatchLRPAllocationRequest(context.Background(), logger, "some-trace-id", enableContainerProxy, proxyMemoryAllocation, []rep.LRP{lrp1, lrp2, lrp3})
+					Expect(failedWork).To(BeEmpty())
+				})
+			})
+		})
+
+		Context("when allowed domains are configured", func() {
+			var lrp3 rep.LRP
+
+			BeforeEach(func() {
+				allowedDomains = []string{"tests", "other"}
+				lrp3 = rep.NewLRP(
+					"ig-3",
+					models.NewActualLRPKey("process-guid", 2, "untrusted"),
+					rep.NewResource(2048, 1024, 100),
+					rep.NewPlacementConstraint(linuxRootFSURL, []string{}, []string{}),
+				)
+			})
+
+			It("allocates the LRPs from allowed domains", func() {
+				failedWork := allocator.BatchLRPAllocationRequest(context.Background(), logger, "some-trace-id", enableContainerProxy, proxyMemoryAllocation, []rep.LRP{lrp1, lrp2})
+				Expect(failedWork).To(BeEmpty())
+
+				_, _, arg := executorClient.AllocateContainersArgsForCall(0)
+				Expect(arg).To(HaveLen(2))
+			})
+
+			It("rejects the LRPs from other domains", func() {
+				failedWork := allocator.BatchLRPAllocationRequest(context.Background(), logger, "some-trace-id", enableContainerProxy, proxyMemoryAllocation, []rep.LRP{lrp1, lrp3})
+				Expect(failedWork).To(ConsistOf(lrp3))
+
+				_, _, arg := executorClient.AllocateContainersArgsForCall(0)
+				Expect(arg).To(ConsistOf(allocationRequestFromLRP(lrp1)))
+				Expect(logger).To(gbytes.Say("domain-not-allowed.*untrusted"))
+			})
 		})
 
 		Context("when envoy needs to be placed in the container", func() {
@@ -115,7 +401,7 @@ var _ = Describe("ContainerAllocator", func() {
 			})
 
 			It("makes the correct allocation requests for all LRP Auctions with the additional memory allocation", func() {
-				allocator.BatchLRPAllocationRequest(logger, "some-trace-id", enableContainerProxy, proxyMemoryAllocation, []rep.LRP{lrp1, lrp2})
+				allocator.BatchLRPAllocationRequest(context.Background(), logger, "some-trace-id", enableContainerProxy, proxyMemoryAllocation, []rep.LRP{lrp1, lrp2})
 
 				Expect(executorClient.AllocateContainersCallCount()).To(Equal(1))
 				_, traceID, arg := executorClient.AllocateContainersArgsForCall(0)
@@ -132,13 +418,35 @@ var _ = Describe("ContainerAllocator", func() {
 				))
 			})
 
+			Context("when the proxy memory is overridden for an LRP's rootfs", func() {
+				BeforeEach(func() {
+					proxyMemoryByRootFS = auctioncellrep.ProxyMemoryByRootFS{linuxStack: 48}
+				})
+
+				It("uses the overridden proxy memory for that LRP and the global one for the others", func() {
+					allocator.BatchLRPAllocationRequest(context.Background(), logger, "some-trace-id", enableContainerProxy, proxyMemoryAllocation, []rep.LRP{lrp1, lrp2})
+
+					_, _, arg := executorClient.AllocateContainersArgsForCall(0)
+
+					allocationRequest1 := allocationRequestFromLRP(lrp1)
+					allocationRequest2 := allocationRequestFromLRP(lrp2)
+					allocationRequest1.MemoryMB += 48
+					allocationRequest2.MemoryMB += proxyMemoryAllocation
+
+					Expect(arg).To(ConsistOf(
+						allocationRequest1,
+						allocationRequest2,
+					))
+				})
+			})
+
 			Context("when the LRP has unlimited memory and additional memory is allocated for the proxy", func() {
 				BeforeEach(func() {
 					lrp1.MemoryMB = 0
 				})
 
 				It("requests an LRP with unlimited memory", func() {
-					allocator.BatchLRPAllocationRequest(logger, "some-trace-id", enableContainerProxy, proxyMemoryAllocation, []rep.LRP{lrp1, lrp2})
+					allocator.BatchLRPAllocationRequest(context.Background(), logger, "some-trace-id", enableContainerProxy, proxyMemoryAllocation, []rep.LRP{lrp1, lrp2})
 
 					expectedResource := executor.NewResource(0, int(lrp1.DiskMB), int(lrp1.MaxPids))
 
@@ -153,7 +461,7 @@ var _ = Describe("ContainerAllocator", func() {
 
 		Context("when no requests need to be made", func() {
 			It("doesn't make any requests to the executorClient", func() {
-				allocator.BatchLRPAllocationRequest(logger, "some-trace-id", enableContainerProxy, proxyMemoryAllocation, []rep.LRP{})
+				allocator.BatchLRPAllocationRequest(context.Background(), logger, "some-trace-id", enableContainerProxy, proxyMemoryAllocation, []rep.LRP{})
 				Expect(executorClient.AllocateContainersCallCount()).To(Equal(0))
 			})
 		})
@@ -187,7 +495,7 @@ var _ = Describe("ContainerAllocator", func() {
 				})
 
 				It("only makes container allocation requests for the remaining LRPs", func() {
-					allocator.BatchLRPAllocationRequest(logger, "some-trace-id", enableContainerProxy, proxyMemoryAllocation, []rep.LRP{validLRP, invalidLRP})
+					allocator.BatchLRPAllocationRequest(context.Background(), logger, "some-trace-id", enableContainerProxy, proxyMemoryAllocation, []rep.LRP{validLRP, invalidLRP})
 
 					Expect(executorClient.AllocateContainersCallCount()).To(Equal(1))
 					_, traceID, arg := executorClient.AllocateContainersArgsForCall(0)
@@ -198,7 +506,7 @@ var _ = Describe("ContainerAllocator", func() {
 				})
 
 				It("marks the other LRP as failed", func() {
-					failedLRPs := allocator.BatchLRPAllocationRequest(logger, "some-trace-id", enableContainerProxy, proxyMemoryAllocation, []rep.LRP{validLRP, invalidLRP})
+					failedLRPs := allocator.BatchLRPAllocationRequest(context.Background(), logger, "some-trace-id", enableContainerProxy, proxyMemoryAllocation, []rep.LRP{validLRP, invalidLRP})
 					Expect(failedLRPs).To(ConsistOf(invalidLRP))
 				})
 			})
@@ -209,7 +517,7 @@ var _ = Describe("ContainerAllocator", func() {
 				})
 
 				It("only makes container allocation requests for the LRPs with valid RootFS paths", func() {
-					allocator.BatchLRPAllocationRequest(logger, "some-trace-id", enableContainerProxy, proxyMemoryAllocation, []rep.LRP{validLRP, invalidLRP})
+					allocator.BatchLRPAllocationRequest(context.Background(), logger, "some-trace-id", enableContainerProxy, proxyMemoryAllocation, []rep.LRP{validLRP, invalidLRP})
 
 					Expect(executorClient.AllocateContainersCallCount()).To(Equal(1))
 					_, traceID, arg := executorClient.AllocateContainersArgsForCall(0)
@@ -220,19 +528,42 @@ var _ = Describe("ContainerAllocator", func() {
 				})
 
 				It("marks the LRPs with invalid RootFS paths as failed", func() {
-					failedLRPs := allocator.BatchLRPAllocationRequest(logger, "some-trace-id", enableContainerProxy, proxyMemoryAllocation, []rep.LRP{validLRP, invalidLRP})
+					failedLRPs := allocator.BatchLRPAllocationRequest(context.Background(), logger, "some-trace-id", enableContainerProxy, proxyMemoryAllocation, []rep.LRP{validLRP, invalidLRP})
 					Expect(failedLRPs).To(HaveLen(1))
 					Expect(failedLRPs).To(ContainElement(invalidLRP))
 				})
 			})
 
+			Context("when an LRP specifies a quarantined RootFS", func() {
+				BeforeEach(func() {
+					rootFSQuarantine.RecordMountFailure(logger, linuxPath)
+					invalidLRP.RootFs = "docker:///busybox"
+				})
+
+				It("only makes container allocation requests for the LRPs with other RootFSes", func() {
+					allocator.BatchLRPAllocationRequest(context.Background(), logger, "some-trace-id", enableContainerProxy, proxyMemoryAllocation, []rep.LRP{validLRP, invalidLRP})
+
+					Expect(executorClient.AllocateContainersCallCount()).To(Equal(1))
+					_, _, arg := executorClient.AllocateContainersArgsForCall(0)
+					Expect(arg).To(ConsistOf(
+						allocationRequestFromLRP(invalidLRP),
+					))
+				})
+
+				It("marks the LRPs with the quarantined RootFS as failed", func() {
+					failedLRPs := allocator.BatchLRPAllocationRequest(context.Background(), logger, "some-trace-id", enableContainerProxy, proxyMemoryAllocation, []rep.LRP{validLRP, invalidLRP})
+					Expect(failedLRPs).To(ConsistOf(validLRP))
+					Expect(logger).To(gbytes.Say("rootfs-quarantined"))
+				})
+			})
+
 			Context("when a LRP specifies a blank RootFS URL", func() {
 				BeforeEach(func() {
 					validLRP.RootFs = ""
 				})
 
 				It("makes the correct allocation request for it, passing along the blank path to the executor client", func() {
-					allocator.BatchLRPAllocationRequest(logger, "some-trace-id", enableContainerProxy, proxyMemoryAllocation, []rep.LRP{validLRP})
+					allocator.BatchLRPAllocationRequest(context.Background(), logger, "some-trace-id", enableContainerProxy, proxyMemoryAllocation, []rep.LRP{validLRP})
 
 					Expect(executorClient.AllocateContainersCallCount()).To(Equal(1))
 					_, traceID, arg := executorClient.AllocateContainersArgsForCall(0)
@@ -249,7 +580,7 @@ var _ = Describe("ContainerAllocator", func() {
 				})
 
 				It("makes the container allocation request with an unchanged rootfs url", func() {
-					allocator.BatchLRPAllocationRequest(logger, "some-trace-id", enableContainerProxy, proxyMemoryAllocation, []rep.LRP{validLRP})
+					allocator.BatchLRPAllocationRequest(context.Background(), logger, "some-trace-id", enableContainerProxy, proxyMemoryAllocation, []rep.LRP{validLRP})
 
 					Expect(executorClient.AllocateContainersCallCount()).To(Equal(1))
 					_, traceID, arg := executorClient.AllocateContainersArgsForCall(0)
@@ -280,7 +611,7 @@ var _ = Describe("ContainerAllocator", func() {
 		})
 
 		It("makes the correct allocation requests for all Tasks", func() {
-			allocator.BatchTaskAllocationRequest(logger, "some-trace-id", []rep.Task{task1, task2})
+			allocator.BatchTaskAllocationRequest(context.Background(), logger, "some-trace-id", []rep.Task{task1, task2})
 
 			Expect(executorClient.AllocateContainersCallCount()).To(Equal(1))
 			_, traceID, arg := executorClient.AllocateContainersArgsForCall(0)
@@ -291,13 +622,33 @@ var _ = Describe("ContainerAllocator", func() {
 			))
 		})
 
+		It("tags the containers with the auction that placed them", func() {
+			allocator.BatchTaskAllocationRequest(context.Background(), logger, "some-trace-id", []rep.Task{task1, task2})
+
+			_, _, arg := executorClient.AllocateContainersArgsForCall(0)
+			Expect(arg).To(HaveLen(2))
+			for _, request := range arg {
+				Expect(request.Tags).To(HaveKeyWithValue(rep.AuctionIDTag, "some-trace-id"))
+			}
+		})
+
+		Context("when the auction has no trace id", func() {
+			It("does not tag the containers with an auction", func() {
+				allocator.BatchTaskAllocationRequest(context.Background(), logger, "", []rep.Task{task1})
+
+				_, _, arg := executorClient.AllocateContainersArgsForCall(0)
+				Expect(arg).To(HaveLen(1))
+				Expect(arg[0].Tags).NotTo(HaveKey(rep.AuctionIDTag))
+			})
+		})
+
 		Context("when all containers can be successfully allocated", func() {
 			BeforeEach(func() {
 				executorClient.AllocateContainersReturns([]executor.AllocationFailure{})
 			})
 
 			It("does not mark any Tasks as failed", func() {
-				failedTasks := allocator.BatchTaskAllocationRequest(logger, "some-trace-id", []rep.Task{task1, task2})
+				failedTasks := allocator.BatchTaskAllocationRequest(context.Background(), logger, "some-trace-id", []rep.Task{task1, task2})
 				Expect(failedTasks).To(BeEmpty())
 			})
 		})
@@ -317,19 +668,137 @@ var _ = Describe("ContainerAllocator", func() {
 			})
 
 			It("marks the corresponding Tasks as failed", func() {
-				failedTasks := allocator.BatchTaskAllocationRequest(logger, "some-trace-id", []rep.Task{task1, task2})
+				failedTasks := allocator.BatchTaskAllocationRequest(context.Background(), logger, "some-trace-id", []rep.Task{task1, task2})
 				Expect(failedTasks).To(ConsistOf(task1))
 			})
 
 			It("logs the container allocation failure", func() {
-				allocator.BatchTaskAllocationRequest(logger, "some-trace-id", []rep.Task{task1, task2})
+				allocator.BatchTaskAllocationRequest(context.Background(), logger, "some-trace-id", []rep.Task{task1, task2})
 				Eventually(logger).Should(gbytes.Say("container-allocation-failure.*failed-request.*the-task-guid-1"))
 			})
+
+			Context("and allocation retries are configured", func() {
+				BeforeEach(func() {
+					allocationRetries = 1
+				})
+
+				It("does not retry a permanent failure", func() {
+					failedTasks := allocator.BatchTaskAllocationRequest(context.Background(), logger, "some-trace-id", []rep.Task{task1, task2})
+					Expect(failedTasks).To(ConsistOf(task1))
+					Expect(executorClient.AllocateContainersCallCount()).To(Equal(1))
+				})
+			})
+		})
+
+		Context("when a container fails to be allocated with a transient error and retries are configured", func() {
+			BeforeEach(func() {
+				allocationRetries = 1
+				resource := executor.NewResource(int(task1.MemoryMB), int(task1.DiskMB), int(task1.MaxPids))
+				allocationRequest := executor.NewAllocationRequest(task1.TaskGuid, &resource, false, executor.Tags{})
+				transientFailure := executor.NewAllocationFailure(&allocationRequest, executor.ErrFailureToCheckSpace.Error())
+				executorClient.AllocateContainersReturnsOnCall(0, []executor.AllocationFailure{transientFailure})
+				executorClient.AllocateContainersReturnsOnCall(1, []executor.AllocationFailure{})
+			})
+
+			It("retries the allocation", func() {
+				failedTasks := allocator.BatchTaskAllocationRequest(context.Background(), logger, "some-trace-id", []rep.Task{task1, task2})
+				Expect(failedTasks).To(BeEmpty())
+				Expect(executorClient.AllocateContainersCallCount()).To(Equal(2))
+			})
+		})
+
+		Context("when a per-task disk limit is configured", func() {
+			BeforeEach(func() {
+				maxPerTaskDiskMB = 1024
+			})
+
+			Context("and the tasks are under the limit", func() {
+				BeforeEach(func() {
+					task1.DiskMB = 512
+					task2.DiskMB = 1023
+				})
+
+				It("requests allocations for all of them", func() {
+					failedTasks := allocator.BatchTaskAllocationRequest(context.Background(), logger, "some-trace-id", []rep.Task{task1, task2})
+					Expect(failedTasks).To(BeEmpty())
+
+					_, _, arg := executorClient.AllocateContainersArgsForCall(0)
+					Expect(arg).To(HaveLen(2))
+				})
+			})
+
+			Context("and a task is exactly at the limit", func() {
+				BeforeEach(func() {
+					task2.DiskMB = 1024
+				})
+
+				It("requests an allocation for it", func() {
+					failedTasks := allocator.BatchTaskAllocationRequest(context.Background(), logger, "some-trace-id", []rep.Task{task1, task2})
+					Expect(failedTasks).To(BeEmpty())
+
+					_, _, arg := executorClient.AllocateContainersArgsForCall(0)
+					Expect(arg).To(ContainElement(allocationRequestFromTask(task2, `["pt-2"]`, `[]`)))
+				})
+			})
+
+			Context("and a task is over the limit", func() {
+				BeforeEach(func() {
+					task2.DiskMB = 1025
+				})
+
+				It("only requests allocations for the tasks under the limit", func() {
+					allocator.BatchTaskAllocationRequest(context.Background(), logger, "some-trace-id", []rep.Task{task1, task2})
+
+					_, _, arg := executorClient.AllocateContainersArgsForCall(0)
+					Expect(arg).To(ConsistOf(allocationRequestFromTask(task1, `["pt-1"]`, `["vd-1"]`)))
+				})
+
+				It("marks it as failed", func() {
+					failedTasks := allocator.BatchTaskAllocationRequest(context.Background(), logger, "some-trace-id", []rep.Task{task1, task2})
+					Expect(failedTasks).To(ConsistOf(task2))
+					Eventually(logger).Should(gbytes.Say("exceeds-max-per-task-disk.*the-task-guid-2"))
+				})
+			})
+		})
+
+		Context("when allowed domains are configured", func() {
+			BeforeEach(func() {
+				task2.Domain = "untrusted"
+			})
+
+			Context("and every task's domain is allowed", func() {
+				BeforeEach(func() {
+					allowedDomains = []string{"tests", "untrusted"}
+				})
+
+				It("requests allocations for all of them", func() {
+					failedTasks := allocator.BatchTaskAllocationRequest(context.Background(), logger, "some-trace-id", []rep.Task{task1, task2})
+					Expect(failedTasks).To(BeEmpty())
+
+					_, _, arg := executorClient.AllocateContainersArgsForCall(0)
+					Expect(arg).To(HaveLen(2))
+				})
+			})
+
+			Context("and a task's domain is not allowed", func() {
+				BeforeEach(func() {
+					allowedDomains = []string{"tests"}
+				})
+
+				It("marks it as failed without requesting an allocation", func() {
+					failedTasks := allocator.BatchTaskAllocationRequest(context.Background(), logger, "some-trace-id", []rep.Task{task1, task2})
+					Expect(failedTasks).To(ConsistOf(task2))
+
+					_, _, arg := executorClient.AllocateContainersArgsForCall(0)
+					Expect(arg).To(ConsistOf(allocationRequestFromTask(task1, `["pt-1"]`, `["vd-1"]`)))
+					Expect(logger).To(gbytes.Say("domain-not-allowed.*the-task-guid-2"))
+				})
+			})
 		})
 
 		Context("when no requests need to be made", func() {
 			It("doesn't make any requests to the executorClient", func() {
-				allocator.BatchTaskAllocationRequest(logger, "some-trace-id", []rep.Task{})
+				allocator.BatchTaskAllocationRequest(context.Background(), logger, "some-trace-id", []rep.Task{})
 				Expect(executorClient.AllocateContainersCallCount()).To(Equal(0))
 			})
 		})
@@ -354,7 +823,7 @@ var _ = Describe("ContainerAllocator", func() {
 				})
 
 				It("only makes container allocation requests for the remaining Tasks", func() {
-					allocator.BatchTaskAllocationRequest(logger, "some-trace-id", []rep.Task{validTask, invalidTask})
+					allocator.BatchTaskAllocationRequest(context.Background(), logger, "some-trace-id", []rep.Task{validTask, invalidTask})
 
 					Expect(executorClient.AllocateContainersCallCount()).To(Equal(1))
 					_, traceID, arg := executorClient.AllocateContainersArgsForCall(0)
@@ -365,7 +834,7 @@ var _ = Describe("ContainerAllocator", func() {
 				})
 
 				It("marks the Task as failed", func() {
-					failedTasks := allocator.BatchTaskAllocationRequest(logger, "some-trace-id", []rep.Task{validTask, invalidTask})
+					failedTasks := allocator.BatchTaskAllocationRequest(context.Background(), logger, "some-trace-id", []rep.Task{validTask, invalidTask})
 					Expect(failedTasks).To(ConsistOf(invalidTask))
 				})
 			})
@@ -376,7 +845,7 @@ var _ = Describe("ContainerAllocator", func() {
 				})
 
 				It("only makes container allocation requests for the tasks with valid RootFS paths", func() {
-					allocator.BatchTaskAllocationRequest(logger, "some-trace-id", []rep.Task{validTask, invalidTask})
+					allocator.BatchTaskAllocationRequest(context.Background(), logger, "some-trace-id", []rep.Task{validTask, invalidTask})
 
 					Expect(executorClient.AllocateContainersCallCount()).To(Equal(1))
 					_, traceID, arg := executorClient.AllocateContainersArgsForCall(0)
@@ -387,10 +856,52 @@ var _ = Describe("ContainerAllocator", func() {
 				})
 
 				It("marks the tasks with invalid RootFS paths as failed", func() {
-					failedTasks := allocator.BatchTaskAllocationRequest(logger, "some-trace-id", []rep.Task{validTask, invalidTask})
+					failedTasks := allocator.BatchTaskAllocationRequest(context.Background(), logger, "some-trace-id", []rep.Task{validTask, invalidTask})
 					Expect(failedTasks).To(HaveLen(1))
 					Expect(failedTasks).To(ContainElement(invalidTask))
 				})
+
+				It("logs that the RootFS is unavailable", func() {
+					allocator.BatchTaskAllocationRequest(context.Background(), logger, "some-trace-id", []rep.Task{validTask, invalidTask})
+					Expect(logger).To(gbytes.Say("rootfs-unavailable.*the-task-guid-2"))
+				})
+
+				It("counts the rejection", func() {
+					allocator.BatchTaskAllocationRequest(context.Background(), logger, "some-trace-id", []rep.Task{validTask, invalidTask})
+					Expect(fakeMetronClient.IncrementCounterCallCount()).To(Equal(1))
+					Expect(fakeMetronClient.IncrementCounterArgsForCall(0)).To(Equal("TaskRootFSUnavailableRejections"))
+				})
+			})
+
+			Context("when every Task specifies an available RootFS", func() {
+				It("does not count any rootfs rejections", func() {
+					failedTasks := allocator.BatchTaskAllocationRequest(context.Background(), logger, "some-trace-id", []rep.Task{validTask})
+					Expect(failedTasks).To(BeEmpty())
+					Expect(fakeMetronClient.IncrementCounterCallCount()).To(BeZero())
+					Expect(logger).NotTo(gbytes.Say("rootfs-unavailable"))
+				})
+			})
+
+			Context("when a Task specifies a quarantined RootFS", func() {
+				BeforeEach(func() {
+					rootFSQuarantine.RecordMountFailure(logger, linuxPath)
+					invalidTask.RootFs = "docker:///busybox"
+				})
+
+				It("only makes container allocation requests for the tasks with other RootFSes", func() {
+					allocator.BatchTaskAllocationRequest(context.Background(), logger, "some-trace-id", []rep.Task{validTask, invalidTask})
+
+					Expect(executorClient.AllocateContainersCallCount()).To(Equal(1))
+					_, _, arg := executorClient.AllocateContainersArgsForCall(0)
+					Expect(arg).To(ConsistOf(
+						allocationRequestFromTask(invalidTask, `["pt-2"]`, `[]`),
+					))
+				})
+
+				It("marks the tasks with the quarantined RootFS as failed", func() {
+					failedTasks := allocator.BatchTaskAllocationRequest(context.Background(), logger, "some-trace-id", []rep.Task{validTask, invalidTask})
+					Expect(failedTasks).To(ConsistOf(validTask))
+				})
 			})
 
 			Context("when a Task specifies a blank RootFS URL", func() {
@@ -399,7 +910,7 @@ var _ = Describe("ContainerAllocator", func() {
 				})
 
 				It("makes the correct allocation request for it, passing along the blank path to the executor client", func() {
-					allocator.BatchTaskAllocationRequest(logger, "some-trace-id", []rep.Task{validTask})
+					allocator.BatchTaskAllocationRequest(context.Background(), logger, "some-trace-id", []rep.Task{validTask})
 
 					Expect(executorClient.AllocateContainersCallCount()).To(Equal(1))
 					_, traceID, arg := executorClient.AllocateContainersArgsForCall(0)
@@ -411,6 +922,47 @@ var _ = Describe("ContainerAllocator", func() {
 			})
 		})
 	})
+
+	Describe("CheckLRPs", func() {
+		var lrp1, lrp2, lrp3 rep.LRP
+
+		BeforeEach(func() {
+			maxInstancesPerLRP = 1
+			lrp1 = rep.NewLRP("ig-1", models.NewActualLRPKey("process-guid", 0, "tests"), rep.NewResource(2048, 1024, 100), rep.NewPlacementConstraint(linuxRootFSURL, nil, nil))
+			lrp2 = rep.NewLRP("ig-2", models.NewActualLRPKey("process-guid", 1, "tests"), rep.NewResource(2048, 1024, 100), rep.NewPlacementConstraint(linuxRootFSURL, nil, nil))
+			lrp3 = rep.NewLRP("ig-3", models.NewActualLRPKey("other-process-guid", 0, "tests"), rep.NewResource(2048, 1024, 100), rep.NewPlacementConstraint(linuxRootFSURL, nil, nil))
+		})
+
+		It("returns why each LRP would be rejected, without allocating any", func() {
+			errs := allocator.CheckLRPs(logger, enableContainerProxy, proxyMemoryAllocation, []rep.LRP{lrp1, lrp2, lrp3})
+			Expect(errs).To(Equal([]error{nil, auctioncellrep.ErrExceedsMaxInstancesPerLRP, nil}))
+			Expect(executorClient.AllocateContainersCallCount()).To(BeZero())
+		})
+	})
+
+	Describe("CheckTasks", func() {
+		var task1, task2 rep.Task
+
+		BeforeEach(func() {
+			allowedDomains = []string{"tests"}
+			task1 = rep.NewTask("the-task-guid-1", "tests", rep.NewResource(2048, 1024, 100), rep.NewPlacementConstraint(linuxRootFSURL, nil, nil))
+			task2 = rep.NewTask("the-task-guid-2", "untrusted", rep.NewResource(2048, 1024, 100), rep.NewPlacementConstraint(linuxRootFSURL, nil, nil))
+		})
+
+		It("returns why each task would be rejected, without allocating any", func() {
+			errs := allocator.CheckTasks(logger, []rep.Task{task1, task2})
+			Expect(errs).To(Equal([]error{nil, auctioncellrep.ErrDomainNotAllowed}))
+			Expect(executorClient.AllocateContainersCallCount()).To(BeZero())
+		})
+
+		It("does not count unavailable rootfses", func() {
+			task2 = rep.NewTask("the-task-guid-2", "tests", rep.NewResource(2048, 1024, 100), rep.NewPlacementConstraint("preloaded:not-on-cell", nil, nil))
+
+			errs := allocator.CheckTasks(logger, []rep.Task{task1, task2})
+			Expect(errs[1]).To(MatchError(rep.ErrPreloadedRootFSNotFound))
+			Expect(fakeMetronClient.IncrementCounterCallCount()).To(BeZero())
+		})
+	})
 })
 
 func allocationRequestFromLRP(lrp rep.LRP) executor.AllocationRequest {
@@ -438,6 +990,7 @@ func allocationRequestFromLRP(lrp rep.LRP) executor.AllocationRequest {
 			rep.ProcessGuidTag:   lrp.ProcessGuid,
 			rep.ProcessIndexTag:  strconv.Itoa(int(lrp.Index)),
 			rep.InstanceGuidTag:  lrp.InstanceGUID,
+			rep.AuctionIDTag:     "some-trace-id",
 		},
 	)
 }
@@ -453,6 +1006,7 @@ func allocationRequestFromTask(task rep.Task, placementTags, volumeDrivers strin
 			rep.DomainTag:        task.Domain,
 			rep.PlacementTagsTag: placementTags,
 			rep.VolumeDriversTag: volumeDrivers,
+			rep.AuctionIDTag:     "some-trace-id",
 		},
 	)
 }
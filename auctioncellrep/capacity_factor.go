@@ -0,0 +1,70 @@
+package auctioncellrep
+
+import (
+	"errors"
+	"sync"
+
+	"code.cloudfoundry.org/executor"
+)
+
+var ErrInvalidCapacityFactor = errors.New("capacity factor must be between 0.0 and 1.0")
+
+// CapacityFactor scales down the capacity the cell advertises and accepts
+// work against, so new work drains off the cell ahead of maintenance without
+// evacuating it. A factor of 1.0 is the full capacity.
+type CapacityFactor struct {
+	lock   sync.RWMutex
+	factor float64
+}
+
+func NewCapacityFactor() *CapacityFactor {
+	return &CapacityFactor{factor: 1}
+}
+
+func (c *CapacityFactor) Set(factor float64) error {
+	if factor < 0 || factor > 1 {
+		return ErrInvalidCapacityFactor
+	}
+
+	c.lock.Lock()
+	c.factor = factor
+	c.lock.Unlock()
+	return nil
+}
+
+// Factor returns the current factor. A nil CapacityFactor is always 1.0.
+func (c *CapacityFactor) Factor() float64 {
+	if c == nil {
+		return 1
+	}
+
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	return c.factor
+}
+
+// Reduced reports whether the capacity is scaled down at all.
+func (c *CapacityFactor) Reduced() bool {
+	return c.Factor() < 1
+}
+
+// ScaleTotal returns the share of total the cell advertises.
+func (c *CapacityFactor) ScaleTotal(total executor.ExecutorResources) executor.ExecutorResources {
+	factor := c.Factor()
+	return executor.ExecutorResources{
+		MemoryMB:   int(float64(total.MemoryMB) * factor),
+		DiskMB:     int(float64(total.DiskMB) * factor),
+		Containers: int(float64(total.Containers) * factor),
+	}
+}
+
+// ScaleRemaining withholds from remaining the share of total the cell does
+// not advertise, never going below zero.
+func (c *CapacityFactor) ScaleRemaining(remaining, total executor.ExecutorResources) executor.ExecutorResources {
+	scaled := c.ScaleTotal(total)
+	return executor.ExecutorResources{
+		MemoryMB:   max(remaining.MemoryMB-(total.MemoryMB-scaled.MemoryMB), 0),
+		DiskMB:     max(remaining.DiskMB-(total.DiskMB-scaled.DiskMB), 0),
+		Containers: max(remaining.Containers-(total.Containers-scaled.Containers), 0),
+	}
+}
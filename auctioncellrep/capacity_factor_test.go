@@ -0,0 +1,53 @@
+package auctioncellrep_test
+
+import (
+	"code.cloudfoundry.org/executor"
+	"code.cloudfoundry.org/rep/auctioncellrep"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CapacityFactor", func() {
+	var (
+		capacityFactor *auctioncellrep.CapacityFactor
+		total          executor.ExecutorResources
+	)
+
+	BeforeEach(func() {
+		capacityFactor = auctioncellrep.NewCapacityFactor()
+		total = executor.ExecutorResources{MemoryMB: 1000, DiskMB: 2000, Containers: 10}
+	})
+
+	It("starts at full capacity", func() {
+		Expect(capacityFactor.Factor()).To(Equal(1.0))
+		Expect(capacityFactor.Reduced()).To(BeFalse())
+		Expect(capacityFactor.ScaleTotal(total)).To(Equal(total))
+	})
+
+	It("rejects factors outside of 0.0 to 1.0", func() {
+		Expect(capacityFactor.Set(-0.1)).To(MatchError(auctioncellrep.ErrInvalidCapacityFactor))
+		Expect(capacityFactor.Set(1.1)).To(MatchError(auctioncellrep.ErrInvalidCapacityFactor))
+		Expect(capacityFactor.Factor()).To(Equal(1.0))
+	})
+
+	Context("when the capacity is reduced", func() {
+		BeforeEach(func() {
+			Expect(capacityFactor.Set(0.5)).To(Succeed())
+		})
+
+		It("scales the total capacity", func() {
+			Expect(capacityFactor.Reduced()).To(BeTrue())
+			Expect(capacityFactor.ScaleTotal(total)).To(Equal(executor.ExecutorResources{MemoryMB: 500, DiskMB: 1000, Containers: 5}))
+		})
+
+		It("withholds the unadvertised capacity from the remaining capacity", func() {
+			remaining := executor.ExecutorResources{MemoryMB: 800, DiskMB: 900, Containers: 8}
+			Expect(capacityFactor.ScaleRemaining(remaining, total)).To(Equal(executor.ExecutorResources{MemoryMB: 300, DiskMB: 0, Containers: 3}))
+		})
+
+		It("restores the full capacity with a factor of 1.0", func() {
+			Expect(capacityFactor.Set(1)).To(Succeed())
+			Expect(capacityFactor.ScaleTotal(total)).To(Equal(total))
+		})
+	})
+})
@@ -0,0 +1,125 @@
+package auctioncellrep
+
+import (
+	"fmt"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	loggingclient "code.cloudfoundry.org/diego-logging-client"
+	"code.cloudfoundry.org/executor"
+	"code.cloudfoundry.org/lager/v3"
+)
+
+const containerAgeBucketMetricPrefix = "ContainerAgeBucket.le_"
+
+// DefaultContainerAgeBuckets are the bucket boundaries used when none are
+// configured.
+var DefaultContainerAgeBuckets = []time.Duration{
+	time.Minute,
+	10 * time.Minute,
+	time.Hour,
+	6 * time.Hour,
+	24 * time.Hour,
+	7 * 24 * time.Hour,
+}
+
+// ContainerAgeBucket is the number of running containers at most
+// UpperBound old. Buckets are cumulative, and the last one, with a zero
+// UpperBound, counts every running container.
+type ContainerAgeBucket struct {
+	UpperBound time.Duration
+	Count      int
+}
+
+func (b ContainerAgeBucket) metricName() string {
+	if b.UpperBound == 0 {
+		return containerAgeBucketMetricPrefix + "+Inf"
+	}
+	return containerAgeBucketMetricPrefix + b.UpperBound.String()
+}
+
+// ValidateContainerAgeBuckets checks that bucket boundaries are positive and
+// strictly increasing, so that each bucket has its own metric name and none
+// of them is mistaken for the final, unbounded one.
+func ValidateContainerAgeBuckets(boundaries []time.Duration) error {
+	for i, boundary := range boundaries {
+		if boundary <= 0 {
+			return fmt.Errorf("container age bucket %s is not positive", boundary)
+		}
+		if i > 0 && boundary <= boundaries[i-1] {
+			return fmt.Errorf("container age buckets must be strictly increasing: %s follows %s", boundary, boundaries[i-1])
+		}
+	}
+	return nil
+}
+
+// BucketContainerAges counts the running containers into the given age
+// buckets, whose boundaries must be valid, plus a final bucket counting all
+// of them. A container's age is measured from when it was allocated.
+func BucketContainerAges(now time.Time, containers []executor.Container, boundaries []time.Duration) []ContainerAgeBucket {
+	buckets := make([]ContainerAgeBucket, len(boundaries)+1)
+	for i, boundary := range boundaries {
+		buckets[i].UpperBound = boundary
+	}
+
+	for _, container := range containers {
+		if container.State != executor.StateRunning || container.AllocatedAt == 0 {
+			continue
+		}
+
+		age := now.Sub(time.Unix(0, container.AllocatedAt))
+		for i := range buckets {
+			if buckets[i].UpperBound == 0 || age <= buckets[i].UpperBound {
+				buckets[i].Count++
+			}
+		}
+	}
+	return buckets
+}
+
+// ContainerAgeReporter emits the age distribution of the cell's running
+// containers as one gauge per bucket.
+type ContainerAgeReporter struct {
+	logger         lager.Logger
+	clock          clock.Clock
+	boundaries     []time.Duration
+	executorClient executor.Client
+	metronClient   loggingclient.IngressClient
+}
+
+// NewContainerAgeReporter fails when the boundaries are not valid. Without
+// boundaries it uses DefaultContainerAgeBuckets.
+func NewContainerAgeReporter(logger lager.Logger, clock clock.Clock, boundaries []time.Duration, executorClient executor.Client, metronClient loggingclient.IngressClient) (*ContainerAgeReporter, error) {
+	if len(boundaries) == 0 {
+		boundaries = DefaultContainerAgeBuckets
+	}
+
+	err := ValidateContainerAgeBuckets(boundaries)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ContainerAgeReporter{
+		logger:         logger.Session("container-age-reporter"),
+		clock:          clock,
+		boundaries:     boundaries,
+		executorClient: executorClient,
+		metronClient:   metronClient,
+	}, nil
+}
+
+func (r *ContainerAgeReporter) Report() {
+	containers, err := r.executorClient.ListContainers(r.logger)
+	if err != nil {
+		r.logger.Error("failed-to-list-containers", err)
+		return
+	}
+
+	for _, bucket := range BucketContainerAges(r.clock.Now(), containers, r.boundaries) {
+		name := bucket.metricName()
+		err = r.metronClient.SendMetric(name, bucket.Count)
+		if err != nil {
+			r.logger.Error("failed-to-send-container-age-metric", err, lager.Data{"metric": name})
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package auctioncellrep_test
+
+import (
+	"errors"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	mfakes "code.cloudfoundry.org/diego-logging-client/testhelpers"
+	"code.cloudfoundry.org/executor"
+	fake_client "code.cloudfoundry.org/executor/fakes"
+	"code.cloudfoundry.org/lager/v3/lagertest"
+	"code.cloudfoundry.org/rep/auctioncellrep"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ContainerAgeReporter", func() {
+	var (
+		now        time.Time
+		containers []executor.Container
+		boundaries []time.Duration
+	)
+
+	runningFor := func(age time.Duration) executor.Container {
+		return executor.Container{State: executor.StateRunning, AllocatedAt: now.Add(-age).UnixNano()}
+	}
+
+	BeforeEach(func() {
+		now = time.Now()
+		boundaries = []time.Duration{time.Minute, time.Hour}
+		containers = []executor.Container{
+			runningFor(30 * time.Second),
+			runningFor(time.Minute),
+			runningFor(5 * time.Minute),
+			runningFor(2 * time.Hour),
+			{State: executor.StateRunning},
+			{State: executor.StateCompleted, AllocatedAt: now.Add(-time.Second).UnixNano()},
+		}
+	})
+
+	Describe("BucketContainerAges", func() {
+		It("counts running containers into cumulative buckets", func() {
+			Expect(auctioncellrep.BucketContainerAges(now, containers, boundaries)).To(Equal([]auctioncellrep.ContainerAgeBucket{
+				{UpperBound: time.Minute, Count: 2},
+				{UpperBound: time.Hour, Count: 3},
+				{UpperBound: 0, Count: 4},
+			}))
+		})
+	})
+
+	Describe("ValidateContainerAgeBuckets", func() {
+		It("accepts positive, strictly increasing boundaries", func() {
+			Expect(auctioncellrep.ValidateContainerAgeBuckets(boundaries)).To(Succeed())
+			Expect(auctioncellrep.ValidateContainerAgeBuckets(auctioncellrep.DefaultContainerAgeBuckets)).To(Succeed())
+		})
+
+		It("rejects a zero or negative boundary", func() {
+			Expect(auctioncellrep.ValidateContainerAgeBuckets([]time.Duration{0, time.Hour})).To(MatchError(ContainSubstring("not positive")))
+			Expect(auctioncellrep.ValidateContainerAgeBuckets([]time.Duration{time.Minute, -time.Hour})).To(MatchError(ContainSubstring("not positive")))
+		})
+
+		It("rejects unsorted or repeated boundaries", func() {
+			Expect(auctioncellrep.ValidateContainerAgeBuckets([]time.Duration{time.Hour, time.Minute})).To(MatchError(ContainSubstring("strictly increasing")))
+			Expect(auctioncellrep.ValidateContainerAgeBuckets([]time.Duration{time.Minute, time.Minute})).To(MatchError(ContainSubstring("strictly increasing")))
+		})
+	})
+
+	Describe("NewContainerAgeReporter", func() {
+		It("fails when the boundaries are not valid", func() {
+			_, err := auctioncellrep.NewContainerAgeReporter(lagertest.NewTestLogger("test"), fakeclock.NewFakeClock(now), []time.Duration{time.Minute, 0}, new(fake_client.FakeClient), new(mfakes.FakeIngressClient))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("reporting", func() {
+		var (
+			executorClient   *fake_client.FakeClient
+			fakeMetronClient *mfakes.FakeIngressClient
+			reporter         *auctioncellrep.ContainerAgeReporter
+		)
+
+		BeforeEach(func() {
+			executorClient = new(fake_client.FakeClient)
+			fakeMetronClient = new(mfakes.FakeIngressClient)
+			executorClient.ListContainersReturns(containers, nil)
+		})
+
+		JustBeforeEach(func() {
+			var err error
+			reporter, err = auctioncellrep.NewContainerAgeReporter(lagertest.NewTestLogger("test"), fakeclock.NewFakeClock(now), boundaries, executorClient, fakeMetronClient)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		gauges := func() map[string]int {
+			gauges := map[string]int{}
+			for i := 0; i < fakeMetronClient.SendMetricCallCount(); i++ {
+				name, value, _ := fakeMetronClient.SendMetricArgsForCall(i)
+				gauges[name] = value
+			}
+			return gauges
+		}
+
+		It("emits a gauge per bucket", func() {
+			reporter.Report()
+
+			Expect(fakeMetronClient.SendMetricCallCount()).To(Equal(3))
+			Expect(gauges()).To(Equal(map[string]int{
+				"ContainerAgeBucket.le_1m0s":   2,
+				"ContainerAgeBucket.le_1h0m0s": 3,
+				"ContainerAgeBucket.le_+Inf":   4,
+			}))
+		})
+
+		Context("when no boundaries are configured", func() {
+			BeforeEach(func() {
+				boundaries = nil
+			})
+
+			It("uses the default buckets", func() {
+				reporter.Report()
+				Expect(fakeMetronClient.SendMetricCallCount()).To(Equal(len(auctioncellrep.DefaultContainerAgeBuckets) + 1))
+				Expect(gauges()).To(HaveKeyWithValue("ContainerAgeBucket.le_168h0m0s", 4))
+			})
+		})
+
+		Context("when listing the containers fails", func() {
+			BeforeEach(func() {
+				executorClient.ListContainersReturns(nil, errors.New("boom"))
+			})
+
+			It("does not emit the gauges", func() {
+				reporter.Report()
+				Expect(fakeMetronClient.SendMetricCallCount()).To(BeZero())
+			})
+		})
+	})
+})
@@ -0,0 +1,95 @@
+package auctioncellrep
+
+import (
+	loggingclient "code.cloudfoundry.org/diego-logging-client"
+	"code.cloudfoundry.org/executor"
+	"code.cloudfoundry.org/lager/v3"
+)
+
+const (
+	containersReservedMetric     = "ContainersReserved"
+	containersInitializingMetric = "ContainersInitializing"
+	containersCreatedMetric      = "ContainersCreated"
+	containersRunningMetric      = "ContainersRunning"
+	containersCompletedMetric    = "ContainersCompleted"
+	containersCrashedMetric      = "ContainersCrashed"
+)
+
+// ContainerStateCounts is the number of the cell's containers in each
+// executor state. Crashed containers are the completed ones whose process
+// failed, and are also counted as completed.
+type ContainerStateCounts struct {
+	Reserved     int
+	Initializing int
+	Created      int
+	Running      int
+	Completed    int
+	Crashed      int
+}
+
+func CountContainerStates(containers []executor.Container) ContainerStateCounts {
+	var counts ContainerStateCounts
+	for _, container := range containers {
+		switch container.State {
+		case executor.StateReserved:
+			counts.Reserved++
+		case executor.StateInitializing:
+			counts.Initializing++
+		case executor.StateCreated:
+			counts.Created++
+		case executor.StateRunning:
+			counts.Running++
+		case executor.StateCompleted:
+			counts.Completed++
+			if container.RunResult.Failed {
+				counts.Crashed++
+			}
+		}
+	}
+	return counts
+}
+
+// ContainerStateReporter emits the number of the cell's containers in each
+// state as gauges.
+type ContainerStateReporter struct {
+	logger         lager.Logger
+	executorClient executor.Client
+	metronClient   loggingclient.IngressClient
+}
+
+func NewContainerStateReporter(logger lager.Logger, executorClient executor.Client, metronClient loggingclient.IngressClient) *ContainerStateReporter {
+	return &ContainerStateReporter{
+		logger:         logger.Session("container-state-reporter"),
+		executorClient: executorClient,
+		metronClient:   metronClient,
+	}
+}
+
+func (r *ContainerStateReporter) Report() {
+	containers, err := r.executorClient.ListContainers(r.logger)
+	if err != nil {
+		r.logger.Error("failed-to-list-containers", err)
+		return
+	}
+
+	counts := CountContainerStates(containers)
+	r.logger.Debug("counted-container-states", lager.Data{"counts": counts})
+
+	gauges := []struct {
+		name  string
+		count int
+	}{
+		{containersReservedMetric, counts.Reserved},
+		{containersInitializingMetric, counts.Initializing},
+		{containersCreatedMetric, counts.Created},
+		{containersRunningMetric, counts.Running},
+		{containersCompletedMetric, counts.Completed},
+		{containersCrashedMetric, counts.Crashed},
+	}
+	for _, gauge := range gauges {
+		err = r.metronClient.SendMetric(gauge.name, gauge.count)
+		if err != nil {
+			r.logger.Error("failed-to-send-container-state-metric", err, lager.Data{"metric": gauge.name})
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package auctioncellrep_test
+
+import (
+	"errors"
+
+	mfakes "code.cloudfoundry.org/diego-logging-client/testhelpers"
+	"code.cloudfoundry.org/executor"
+	fake_client "code.cloudfoundry.org/executor/fakes"
+	"code.cloudfoundry.org/lager/v3/lagertest"
+	"code.cloudfoundry.org/rep/auctioncellrep"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ContainerStateReporter", func() {
+	containers := []executor.Container{
+		{State: executor.StateReserved},
+		{State: executor.StateReserved},
+		{State: executor.StateInitializing},
+		{State: executor.StateCreated},
+		{State: executor.StateRunning},
+		{State: executor.StateRunning},
+		{State: executor.StateRunning},
+		{State: executor.StateCompleted},
+		{State: executor.StateCompleted, RunResult: executor.ContainerRunResult{Failed: true, FailureReason: "crashed"}},
+	}
+
+	Describe("CountContainerStates", func() {
+		It("counts the containers in each state", func() {
+			Expect(auctioncellrep.CountContainerStates(containers)).To(Equal(auctioncellrep.ContainerStateCounts{
+				Reserved:     2,
+				Initializing: 1,
+				Created:      1,
+				Running:      3,
+				Completed:    2,
+				Crashed:      1,
+			}))
+		})
+	})
+
+	Describe("reporting", func() {
+		var (
+			executorClient   *fake_client.FakeClient
+			fakeMetronClient *mfakes.FakeIngressClient
+			reporter         *auctioncellrep.ContainerStateReporter
+		)
+
+		BeforeEach(func() {
+			executorClient = new(fake_client.FakeClient)
+			fakeMetronClient = new(mfakes.FakeIngressClient)
+			executorClient.ListContainersReturns(containers, nil)
+		})
+
+		JustBeforeEach(func() {
+			reporter = auctioncellrep.NewContainerStateReporter(lagertest.NewTestLogger("test"), executorClient, fakeMetronClient)
+		})
+
+		gauges := func() map[string]int {
+			gauges := map[string]int{}
+			for i := 0; i < fakeMetronClient.SendMetricCallCount(); i++ {
+				name, value, _ := fakeMetronClient.SendMetricArgsForCall(i)
+				gauges[name] = value
+			}
+			return gauges
+		}
+
+		It("emits the per-state gauges", func() {
+			reporter.Report()
+
+			Expect(fakeMetronClient.SendMetricCallCount()).To(Equal(6))
+			Expect(gauges()).To(Equal(map[string]int{
+				"ContainersReserved":     2,
+				"ContainersInitializing": 1,
+				"ContainersCreated":      1,
+				"ContainersRunning":      3,
+				"ContainersCompleted":    2,
+				"ContainersCrashed":      1,
+			}))
+		})
+
+		It("reports states that emptied as zero", func() {
+			reporter.Report()
+			executorClient.ListContainersReturns(nil, nil)
+			reporter.Report()
+
+			Expect(fakeMetronClient.SendMetricCallCount()).To(Equal(12))
+			Expect(gauges()).To(HaveKeyWithValue("ContainersRunning", 0))
+		})
+
+		Context("when listing the containers fails", func() {
+			BeforeEach(func() {
+				executorClient.ListContainersReturns(nil, errors.New("boom"))
+			})
+
+			It("does not emit the gauges", func() {
+				reporter.Report()
+				Expect(fakeMetronClient.SendMetricCallCount()).To(BeZero())
+			})
+		})
+	})
+})
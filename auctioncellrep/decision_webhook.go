@@ -0,0 +1,189 @@
+package auctioncellrep
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/lager/v3"
+	"code.cloudfoundry.org/rep"
+)
+
+const (
+	decisionWebhookQueueSize      = 1024
+	decisionWebhookMaxAttempts    = 5
+	decisionWebhookInitialBackoff = time.Second
+	decisionWebhookMaxBackoff     = 30 * time.Second
+)
+
+type LRPDecision struct {
+	ProcessGuid string `json:"process_guid"`
+	Index       int32  `json:"index"`
+	Domain      string `json:"domain"`
+}
+
+type TaskDecision struct {
+	TaskGuid string `json:"task_guid"`
+	Domain   string `json:"domain"`
+}
+
+// PerformDecision describes which of the work offered to the cell in one
+// Perform it accepted and which it rejected.
+type PerformDecision struct {
+	CellID        string         `json:"cell_id"`
+	TraceID       string         `json:"trace_id,omitempty"`
+	Timestamp     time.Time      `json:"timestamp"`
+	AcceptedLRPs  []LRPDecision  `json:"accepted_lrps"`
+	RejectedLRPs  []LRPDecision  `json:"rejected_lrps"`
+	AcceptedTasks []TaskDecision `json:"accepted_tasks"`
+	RejectedTasks []TaskDecision `json:"rejected_tasks"`
+}
+
+// NewPerformDecision splits the work into what the cell accepted and what it
+// returned as failed.
+func NewPerformDecision(cellID, traceID string, timestamp time.Time, work, failedWork rep.Work) PerformDecision {
+	decision := PerformDecision{
+		CellID:        cellID,
+		TraceID:       traceID,
+		Timestamp:     timestamp,
+		AcceptedLRPs:  []LRPDecision{},
+		RejectedLRPs:  []LRPDecision{},
+		AcceptedTasks: []TaskDecision{},
+		RejectedTasks: []TaskDecision{},
+	}
+
+	rejectedLRPs := map[LRPDecision]struct{}{}
+	for _, lrp := range failedWork.LRPs {
+		rejectedLRPs[LRPDecision{ProcessGuid: lrp.ProcessGuid, Index: lrp.Index, Domain: lrp.Domain}] = struct{}{}
+	}
+	for _, lrp := range work.LRPs {
+		lrpDecision := LRPDecision{ProcessGuid: lrp.ProcessGuid, Index: lrp.Index, Domain: lrp.Domain}
+		if _, rejected := rejectedLRPs[lrpDecision]; rejected {
+			decision.RejectedLRPs = append(decision.RejectedLRPs, lrpDecision)
+		} else {
+			decision.AcceptedLRPs = append(decision.AcceptedLRPs, lrpDecision)
+		}
+	}
+
+	rejectedTasks := map[string]struct{}{}
+	for _, task := range failedWork.Tasks {
+		rejectedTasks[task.TaskGuid] = struct{}{}
+	}
+	for _, task := range work.Tasks {
+		taskDecision := TaskDecision{TaskGuid: task.TaskGuid, Domain: task.Domain}
+		if _, rejected := rejectedTasks[task.TaskGuid]; rejected {
+			decision.RejectedTasks = append(decision.RejectedTasks, taskDecision)
+		} else {
+			decision.AcceptedTasks = append(decision.AcceptedTasks, taskDecision)
+		}
+	}
+
+	return decision
+}
+
+// DecisionWebhook is an ifrit.Runner posting the cell's Perform decisions as
+// JSON to an external URL. Decisions are queued so the auction never waits
+// on the webhook, and a failed post is retried with exponential backoff a
+// bounded number of times before the decision is dropped.
+type DecisionWebhook struct {
+	logger     lager.Logger
+	clock      clock.Clock
+	httpClient *http.Client
+	url        string
+	cellID     string
+
+	decisions chan PerformDecision
+}
+
+func NewDecisionWebhook(logger lager.Logger, clock clock.Clock, httpClient *http.Client, url, cellID string) *DecisionWebhook {
+	return &DecisionWebhook{
+		logger:     logger.Session("decision-webhook"),
+		clock:      clock,
+		httpClient: httpClient,
+		url:        url,
+		cellID:     cellID,
+		decisions:  make(chan PerformDecision, decisionWebhookQueueSize),
+	}
+}
+
+// NotifyPerform queues the decision for the given Perform. It never blocks:
+// when the queue is full the decision is dropped.
+func (w *DecisionWebhook) NotifyPerform(logger lager.Logger, traceID string, work, failedWork rep.Work) {
+	decision := NewPerformDecision(w.cellID, traceID, w.clock.Now(), work, failedWork)
+
+	select {
+	case w.decisions <- decision:
+	default:
+		logger.Info("dropped-decision-because-queue-is-full", lager.Data{"queue-size": decisionWebhookQueueSize})
+	}
+}
+
+func (w *DecisionWebhook) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	close(ready)
+
+	for {
+		select {
+		case <-signals:
+			return nil
+		case decision := <-w.decisions:
+			if !w.deliver(decision, signals) {
+				return nil
+			}
+		}
+	}
+}
+
+// deliver posts the decision, retrying failures. It returns false when
+// signalled while waiting to retry.
+func (w *DecisionWebhook) deliver(decision PerformDecision, signals <-chan os.Signal) bool {
+	logger := w.logger.Session("deliver", lager.Data{"trace-id": decision.TraceID})
+
+	body, err := json.Marshal(decision)
+	if err != nil {
+		logger.Error("failed-to-marshal-decision", err)
+		return true
+	}
+
+	backoff := decisionWebhookInitialBackoff
+	for attempt := 1; ; attempt++ {
+		err = w.post(body)
+		if err == nil {
+			logger.Debug("posted-decision", lager.Data{"attempt": attempt})
+			return true
+		}
+
+		if attempt >= decisionWebhookMaxAttempts {
+			logger.Error("giving-up-posting-decision", err, lager.Data{"attempts": attempt})
+			return true
+		}
+		logger.Error("failed-to-post-decision", err, lager.Data{"attempt": attempt, "retry-in": backoff.String()})
+
+		select {
+		case <-signals:
+			return false
+		case <-w.clock.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > decisionWebhookMaxBackoff {
+			backoff = decisionWebhookMaxBackoff
+		}
+	}
+}
+
+func (w *DecisionWebhook) post(body []byte) error {
+	resp, err := w.httpClient.Post(w.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
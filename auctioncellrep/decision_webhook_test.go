@@ -0,0 +1,214 @@
+package auctioncellrep_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/lager/v3/lagertest"
+	"code.cloudfoundry.org/rep"
+	"code.cloudfoundry.org/rep/auctioncellrep"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/tedsuo/ifrit"
+)
+
+var _ = Describe("DecisionWebhook", func() {
+	var (
+		work, failedWork rep.Work
+		timestamp        time.Time
+	)
+
+	BeforeEach(func() {
+		resource := rep.NewResource(128, 256, 256)
+		placementConstraint := rep.NewPlacementConstraint("some-rootfs", nil, nil)
+		acceptedLRP := rep.NewLRP("ig-1", models.NewActualLRPKey("pg-1", 0, "domain"), resource, placementConstraint)
+		rejectedLRP := rep.NewLRP("ig-2", models.NewActualLRPKey("pg-1", 1, "domain"), resource, placementConstraint)
+		acceptedTask := rep.NewTask("tg-1", "domain", resource, placementConstraint)
+		rejectedTask := rep.NewTask("tg-2", "domain", resource, placementConstraint)
+
+		work = rep.Work{
+			LRPs:  []rep.LRP{acceptedLRP, rejectedLRP},
+			Tasks: []rep.Task{acceptedTask, rejectedTask},
+		}
+		failedWork = rep.Work{
+			LRPs:  []rep.LRP{rejectedLRP},
+			Tasks: []rep.Task{rejectedTask},
+		}
+		timestamp = time.Unix(1700000000, 0).UTC()
+	})
+
+	Describe("NewPerformDecision", func() {
+		It("splits the work into what was accepted and what was rejected", func() {
+			decision := auctioncellrep.NewPerformDecision("cell-id", "trace-id", timestamp, work, failedWork)
+
+			Expect(decision).To(Equal(auctioncellrep.PerformDecision{
+				CellID:        "cell-id",
+				TraceID:       "trace-id",
+				Timestamp:     timestamp,
+				AcceptedLRPs:  []auctioncellrep.LRPDecision{{ProcessGuid: "pg-1", Index: 0, Domain: "domain"}},
+				RejectedLRPs:  []auctioncellrep.LRPDecision{{ProcessGuid: "pg-1", Index: 1, Domain: "domain"}},
+				AcceptedTasks: []auctioncellrep.TaskDecision{{TaskGuid: "tg-1", Domain: "domain"}},
+				RejectedTasks: []auctioncellrep.TaskDecision{{TaskGuid: "tg-2", Domain: "domain"}},
+			}))
+		})
+	})
+
+	Describe("delivering decisions", func() {
+		var (
+			logger    *lagertest.TestLogger
+			fakeClock *fakeclock.FakeClock
+			server    *httptest.Server
+			webhook   *auctioncellrep.DecisionWebhook
+			process   ifrit.Process
+
+			lock       sync.Mutex
+			statuses   []int
+			received   []auctioncellrep.PerformDecision
+			requestsCh chan struct{}
+		)
+
+		requestCount := func() int {
+			lock.Lock()
+			defer lock.Unlock()
+			return len(received)
+		}
+
+		BeforeEach(func() {
+			logger = lagertest.NewTestLogger("test")
+			fakeClock = fakeclock.NewFakeClock(timestamp)
+			statuses = nil
+			received = nil
+			requestsCh = make(chan struct{}, 10)
+
+			server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var decision auctioncellrep.PerformDecision
+				// a malformed body fails the assertions on the received decision
+				_ = json.NewDecoder(r.Body).Decode(&decision)
+
+				lock.Lock()
+				received = append(received, decision)
+				status := http.StatusOK
+				if len(statuses) > 0 {
+					status = statuses[0]
+					statuses = statuses[1:]
+				}
+				lock.Unlock()
+
+				w.WriteHeader(status)
+				requestsCh <- struct{}{}
+			}))
+
+			webhook = auctioncellrep.NewDecisionWebhook(logger, fakeClock, http.DefaultClient, server.URL, "cell-id")
+		})
+
+		JustBeforeEach(func() {
+			process = ifrit.Invoke(webhook)
+		})
+
+		AfterEach(func() {
+			process.Signal(os.Interrupt)
+			Eventually(process.Wait()).Should(Receive())
+			server.Close()
+		})
+
+		It("posts the decision with the accepted and rejected work", func() {
+			webhook.NotifyPerform(logger, "trace-id", work, failedWork)
+
+			Eventually(requestsCh).Should(Receive())
+			lock.Lock()
+			defer lock.Unlock()
+			Expect(received).To(HaveLen(1))
+			Expect(received[0].CellID).To(Equal("cell-id"))
+			Expect(received[0].TraceID).To(Equal("trace-id"))
+			Expect(received[0].Timestamp).To(BeTemporally("==", timestamp))
+			Expect(received[0].AcceptedLRPs).To(ConsistOf(auctioncellrep.LRPDecision{ProcessGuid: "pg-1", Index: 0, Domain: "domain"}))
+			Expect(received[0].RejectedLRPs).To(ConsistOf(auctioncellrep.LRPDecision{ProcessGuid: "pg-1", Index: 1, Domain: "domain"}))
+			Expect(received[0].AcceptedTasks).To(ConsistOf(auctioncellrep.TaskDecision{TaskGuid: "tg-1", Domain: "domain"}))
+			Expect(received[0].RejectedTasks).To(ConsistOf(auctioncellrep.TaskDecision{TaskGuid: "tg-2", Domain: "domain"}))
+		})
+
+		Context("when posting fails", func() {
+			BeforeEach(func() {
+				statuses = []int{http.StatusInternalServerError, http.StatusBadGateway}
+			})
+
+			It("retries with backoff", func() {
+				webhook.NotifyPerform(logger, "trace-id", work, failedWork)
+
+				Eventually(requestsCh).Should(Receive())
+				Eventually(logger).Should(gbytes.Say("failed-to-post-decision"))
+
+				fakeClock.WaitForWatcherAndIncrement(time.Second)
+				Eventually(requestsCh).Should(Receive())
+
+				fakeClock.WaitForWatcherAndIncrement(time.Second)
+				Consistently(requestsCh).ShouldNot(Receive())
+				fakeClock.Increment(time.Second)
+				Eventually(requestsCh).Should(Receive())
+
+				Expect(requestCount()).To(Equal(3))
+			})
+		})
+
+		Context("when posting keeps failing", func() {
+			BeforeEach(func() {
+				for i := 0; i < 10; i++ {
+					statuses = append(statuses, http.StatusInternalServerError)
+				}
+			})
+
+			It("gives up after a bounded number of attempts", func() {
+				webhook.NotifyPerform(logger, "trace-id", work, failedWork)
+
+				Eventually(requestsCh).Should(Receive())
+				for i := 0; i < 4; i++ {
+					fakeClock.WaitForWatcherAndIncrement(time.Minute)
+					Eventually(requestsCh).Should(Receive())
+				}
+
+				Eventually(logger).Should(gbytes.Say("giving-up-posting-decision"))
+				Consistently(requestsCh).ShouldNot(Receive())
+				Expect(requestCount()).To(Equal(5))
+			})
+		})
+
+		Context("when the webhook is slow", func() {
+			var release chan struct{}
+
+			BeforeEach(func() {
+				release = make(chan struct{})
+				slowServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					<-release
+				}))
+				DeferCleanup(slowServer.Close)
+
+				webhook = auctioncellrep.NewDecisionWebhook(logger, fakeClock, http.DefaultClient, slowServer.URL, "cell-id")
+			})
+
+			AfterEach(func() {
+				close(release)
+			})
+
+			It("never blocks the caller, dropping decisions once the queue is full", func() {
+				done := make(chan struct{})
+				go func() {
+					defer GinkgoRecover()
+					for i := 0; i < 2000; i++ {
+						webhook.NotifyPerform(logger, "trace-id", work, failedWork)
+					}
+					close(done)
+				}()
+
+				Eventually(done).Should(BeClosed())
+				Expect(logger).To(gbytes.Say("dropped-decision-because-queue-is-full"))
+			})
+		})
+	})
+})
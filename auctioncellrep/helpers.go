@@ -1,6 +1,11 @@
 package auctioncellrep
 
-import uuid "github.com/nu7hatch/gouuid"
+import (
+	"net/url"
+
+	"code.cloudfoundry.org/bbs/models"
+	uuid "github.com/nu7hatch/gouuid"
+)
 
 func GenerateGuid() (string, error) {
 	guid, err := uuid.NewV4()
@@ -15,3 +20,27 @@ func GenerateGuid() (string, error) {
 
 	return guidString, nil
 }
+
+// ProxyMemoryByRootFS maps a preloaded stack name (or any other rootfs URL)
+// to the memory, in MB, reserved for the container proxy of containers using
+// it.
+type ProxyMemoryByRootFS map[string]int
+
+// ForRootFS returns the proxy memory for a container with the given rootfs
+// URL, or fallback when no override is configured for it.
+func (m ProxyMemoryByRootFS) ForRootFS(rootFS string, fallback int) int {
+	if len(m) == 0 {
+		return fallback
+	}
+
+	key := rootFS
+	u, err := url.Parse(rootFS)
+	if err == nil && (u.Scheme == models.PreloadedRootFSScheme || u.Scheme == models.PreloadedOCIRootFSScheme) {
+		key = u.Opaque
+	}
+
+	if memoryMB, ok := m[key]; ok {
+		return memoryMB
+	}
+	return fallback
+}
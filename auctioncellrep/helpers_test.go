@@ -1,6 +1,7 @@
 package auctioncellrep_test
 
 import (
+	"code.cloudfoundry.org/bbs/models"
 	"code.cloudfoundry.org/rep/auctioncellrep"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -14,4 +15,30 @@ var _ = Describe("Helpers", func() {
 			Expect(guid).To(HaveLen(28))
 		})
 	})
+
+	Context("ProxyMemoryByRootFS", func() {
+		var proxyMemory auctioncellrep.ProxyMemoryByRootFS
+
+		BeforeEach(func() {
+			proxyMemory = auctioncellrep.ProxyMemoryByRootFS{
+				"cflinuxfs4":          48,
+				"docker:///some/repo": 64,
+			}
+		})
+
+		It("returns the override for preloaded rootfses", func() {
+			Expect(proxyMemory.ForRootFS(models.PreloadedRootFS("cflinuxfs4"), 32)).To(Equal(48))
+			Expect(proxyMemory.ForRootFS(models.PreloadedOCIRootFSScheme+":cflinuxfs4?layer=https://blobstore/layer.tgz", 32)).To(Equal(48))
+		})
+
+		It("returns the override for other rootfs URLs", func() {
+			Expect(proxyMemory.ForRootFS("docker:///some/repo", 32)).To(Equal(64))
+		})
+
+		It("falls back when no override is configured", func() {
+			Expect(proxyMemory.ForRootFS(models.PreloadedRootFS("windows"), 32)).To(Equal(32))
+			Expect(proxyMemory.ForRootFS("", 32)).To(Equal(32))
+			Expect(auctioncellrep.ProxyMemoryByRootFS(nil).ForRootFS(models.PreloadedRootFS("cflinuxfs4"), 32)).To(Equal(32))
+		})
+	})
 })
@@ -0,0 +1,89 @@
+package auctioncellrep
+
+import (
+	"encoding/json"
+
+	loggingclient "code.cloudfoundry.org/diego-logging-client"
+	"code.cloudfoundry.org/executor"
+	"code.cloudfoundry.org/lager/v3"
+	"code.cloudfoundry.org/rep"
+)
+
+const placementTagFairnessMetric = "PlacementTagFairnessIndex"
+
+// PlacementTagAllocations returns the memory, in MB, allocated to the
+// containers of each placement tag. A container with several tags counts
+// towards each of them, and containers without tags are grouped under the
+// empty tag.
+func PlacementTagAllocations(containers []executor.Container) map[string]int {
+	allocations := map[string]int{}
+	for _, container := range containers {
+		var placementTags []string
+		if placementTagsJSON, ok := container.Tags[rep.PlacementTagsTag]; ok {
+			// malformed tags are reported when computing the cell state
+			_ = json.Unmarshal([]byte(placementTagsJSON), &placementTags)
+		}
+		if len(placementTags) == 0 {
+			placementTags = []string{""}
+		}
+
+		for _, tag := range placementTags {
+			allocations[tag] += container.MemoryMB
+		}
+	}
+	return allocations
+}
+
+// FairnessIndex returns Jain's fairness index of the allocations, between
+// 1/n for one of n tags taking everything and 1 for an even split. A cell
+// with fewer than two tags, or nothing allocated, is perfectly fair.
+func FairnessIndex(allocations map[string]int) float64 {
+	if len(allocations) < 2 {
+		return 1
+	}
+
+	var sum, sumOfSquares float64
+	for _, allocation := range allocations {
+		sum += float64(allocation)
+		sumOfSquares += float64(allocation) * float64(allocation)
+	}
+	if sumOfSquares == 0 {
+		return 1
+	}
+
+	return sum * sum / (float64(len(allocations)) * sumOfSquares)
+}
+
+// PlacementFairnessReporter emits the fairness index of the memory allocated
+// across the placement tags of the cell's containers. A low index flags one
+// tag dominating the cell.
+type PlacementFairnessReporter struct {
+	logger         lager.Logger
+	executorClient executor.Client
+	metronClient   loggingclient.IngressClient
+}
+
+func NewPlacementFairnessReporter(logger lager.Logger, executorClient executor.Client, metronClient loggingclient.IngressClient) *PlacementFairnessReporter {
+	return &PlacementFairnessReporter{
+		logger:         logger.Session("placement-fairness-reporter"),
+		executorClient: executorClient,
+		metronClient:   metronClient,
+	}
+}
+
+func (r *PlacementFairnessReporter) Report() {
+	containers, err := r.executorClient.ListContainers(r.logger)
+	if err != nil {
+		r.logger.Error("failed-to-list-containers", err)
+		return
+	}
+
+	allocations := PlacementTagAllocations(containers)
+	index := FairnessIndex(allocations)
+	r.logger.Debug("computed-fairness-index", lager.Data{"index": index, "allocations": allocations})
+
+	err = r.metronClient.SendComponentMetric(placementTagFairnessMetric, index, "Metric")
+	if err != nil {
+		r.logger.Error("failed-to-send-fairness-index-metric", err)
+	}
+}
@@ -0,0 +1,101 @@
+package auctioncellrep_test
+
+import (
+	"errors"
+
+	mfakes "code.cloudfoundry.org/diego-logging-client/testhelpers"
+	"code.cloudfoundry.org/executor"
+	fake_client "code.cloudfoundry.org/executor/fakes"
+	"code.cloudfoundry.org/lager/v3/lagertest"
+	"code.cloudfoundry.org/rep"
+	"code.cloudfoundry.org/rep/auctioncellrep"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PlacementFairness", func() {
+	taggedContainer := func(memoryMB int, placementTags string) executor.Container {
+		container := executor.Container{Resource: executor.Resource{MemoryMB: memoryMB}}
+		if placementTags != "" {
+			container.Tags = executor.Tags{rep.PlacementTagsTag: placementTags}
+		}
+		return container
+	}
+
+	Describe("PlacementTagAllocations", func() {
+		It("sums the memory allocated to each placement tag", func() {
+			allocations := auctioncellrep.PlacementTagAllocations([]executor.Container{
+				taggedContainer(256, `["tag-a"]`),
+				taggedContainer(512, `["tag-a","tag-b"]`),
+				taggedContainer(128, `[]`),
+				taggedContainer(64, ""),
+			})
+
+			Expect(allocations).To(Equal(map[string]int{
+				"tag-a": 768,
+				"tag-b": 512,
+				"":      192,
+			}))
+		})
+	})
+
+	Describe("FairnessIndex", func() {
+		It("is 1 when capacity is split evenly", func() {
+			Expect(auctioncellrep.FairnessIndex(map[string]int{"a": 512, "b": 512, "c": 512})).To(BeNumerically("~", 1.0, 0.0001))
+		})
+
+		It("is low when one tag dominates the cell", func() {
+			index := auctioncellrep.FairnessIndex(map[string]int{"a": 4096, "b": 64, "c": 64, "d": 64})
+			Expect(index).To(BeNumerically("<", 0.3))
+			Expect(index).To(BeNumerically(">=", 0.25))
+		})
+
+		It("is 1 with fewer than two tags", func() {
+			Expect(auctioncellrep.FairnessIndex(map[string]int{})).To(Equal(1.0))
+			Expect(auctioncellrep.FairnessIndex(map[string]int{"a": 1024})).To(Equal(1.0))
+		})
+
+		It("is 1 when nothing is allocated", func() {
+			Expect(auctioncellrep.FairnessIndex(map[string]int{"a": 0, "b": 0})).To(Equal(1.0))
+		})
+	})
+
+	Describe("PlacementFairnessReporter", func() {
+		var (
+			executorClient   *fake_client.FakeClient
+			fakeMetronClient *mfakes.FakeIngressClient
+			reporter         *auctioncellrep.PlacementFairnessReporter
+		)
+
+		BeforeEach(func() {
+			executorClient = new(fake_client.FakeClient)
+			fakeMetronClient = new(mfakes.FakeIngressClient)
+			executorClient.ListContainersReturns([]executor.Container{
+				taggedContainer(1024, `["tag-a"]`),
+				taggedContainer(1024, `["tag-b"]`),
+			}, nil)
+
+			reporter = auctioncellrep.NewPlacementFairnessReporter(lagertest.NewTestLogger("test"), executorClient, fakeMetronClient)
+		})
+
+		It("emits the fairness index", func() {
+			reporter.Report()
+
+			Expect(fakeMetronClient.SendComponentMetricCallCount()).To(Equal(1))
+			name, value, _ := fakeMetronClient.SendComponentMetricArgsForCall(0)
+			Expect(name).To(Equal("PlacementTagFairnessIndex"))
+			Expect(value).To(BeNumerically("~", 1.0, 0.0001))
+		})
+
+		Context("when listing the containers fails", func() {
+			BeforeEach(func() {
+				executorClient.ListContainersReturns(nil, errors.New("boom"))
+			})
+
+			It("does not emit the metric", func() {
+				reporter.Report()
+				Expect(fakeMetronClient.SendComponentMetricCallCount()).To(BeZero())
+			})
+		})
+	})
+})
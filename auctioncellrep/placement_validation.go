@@ -0,0 +1,113 @@
+package auctioncellrep
+
+import (
+	"context"
+
+	"code.cloudfoundry.org/lager/v3"
+	"code.cloudfoundry.org/rep"
+)
+
+const (
+	PlacementReasonInsufficientMemory  = "insufficient memory"
+	PlacementReasonExceedsCellCapacity = "exceeds cell capacity"
+	PlacementReasonClockSkewed         = "clock skewed"
+	PlacementReasonEvacuating          = "evacuating"
+	PlacementReasonDeadlineNear        = "deadline near"
+	PlacementReasonBatchRejected       = "batch rejected"
+)
+
+// ValidatePlacement computes which items of the work Perform would hand to
+// the executor. It goes through the same checks as Perform, up to the
+// allocator's, and leaves to the executor what Perform also does. Unlike
+// Perform it has no side effects: nothing is allocated, the cached state is
+// kept and the auction outcome is not recorded.
+func (a *AuctionCellRep) ValidatePlacement(ctx context.Context, logger lager.Logger, work rep.Work) (rep.PlacementValidation, error) {
+	logger = logger.Session("validate-placement", lager.Data{
+		"lrp-starts": len(work.LRPs),
+		"tasks":      len(work.Tasks),
+	})
+
+	remainingResources, totalResources, cordoned, err := a.availableResources(logger)
+	if err != nil {
+		return rep.PlacementValidation{}, err
+	}
+
+	fit := a.fitWork(logger, work, remainingResources, totalResources, cordoned)
+
+	if reason := a.workRefusal(ctx); reason != "" {
+		for i := range fit.lrpReasons {
+			fit.lrpReasons[i] = reason
+		}
+		for i := range fit.taskReasons {
+			fit.taskReasons[i] = reason
+		}
+	} else {
+		a.checkWorkFit(logger, fit)
+	}
+
+	validation := rep.PlacementValidation{
+		LRPs:      make([]rep.PlacementFit, 0, len(fit.lrps)),
+		Tasks:     make([]rep.PlacementFit, 0, len(fit.tasks)),
+		Remaining: a.convertResources(remainingResources),
+	}
+
+	for i, lrp := range fit.lrps {
+		validation.LRPs = append(validation.LRPs, placementFit(lrp.InstanceGUID, fit.lrpReasons[i]))
+		if fit.lrpReasons[i] == "" {
+			validation.Remaining.MemoryMB -= a.lrpMemoryMB(lrp)
+			validation.Remaining.DiskMB -= lrp.DiskMB
+			validation.Remaining.Containers--
+		}
+	}
+
+	for i, task := range fit.tasks {
+		validation.Tasks = append(validation.Tasks, placementFit(task.TaskGuid, fit.taskReasons[i]))
+		if fit.taskReasons[i] == "" {
+			validation.Remaining.MemoryMB -= task.MemoryMB
+			validation.Remaining.DiskMB -= task.DiskMB
+			validation.Remaining.Containers--
+		}
+	}
+
+	return validation, nil
+}
+
+// checkWorkFit runs the allocator's checks on the work that fits, recording
+// why the allocator would reject any of it.
+func (a *AuctionCellRep) checkWorkFit(logger lager.Logger, fit workFit) {
+	lrps, tasks := fit.fitting()
+
+	lrpIndexes := fittingIndexes(fit.lrpReasons)
+	for i, err := range a.allocator.CheckLRPs(logger, a.enableContainerProxy, a.proxyMemoryAllocation, lrps) {
+		if err != nil {
+			fit.lrpReasons[lrpIndexes[i]] = err.Error()
+		}
+	}
+
+	taskIndexes := fittingIndexes(fit.taskReasons)
+	for i, err := range a.allocator.CheckTasks(logger, tasks) {
+		if err != nil {
+			fit.taskReasons[taskIndexes[i]] = err.Error()
+		}
+	}
+
+	if a.batchAllocationPolicy == BatchAllocationPolicyAllOrNothing && fit.rejectsLRPs() {
+		fit.rejectLRPBatch()
+	}
+}
+
+// fittingIndexes maps the items returned by workFit.fitting back to their
+// index in the fit.
+func fittingIndexes(reasons []string) []int {
+	var indexes []int
+	for i, reason := range reasons {
+		if reason == "" {
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes
+}
+
+func placementFit(guid, reason string) rep.PlacementFit {
+	return rep.PlacementFit{Guid: guid, Fits: reason == "", Reason: reason}
+}
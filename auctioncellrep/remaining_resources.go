@@ -0,0 +1,56 @@
+package auctioncellrep
+
+import (
+	"code.cloudfoundry.org/executor"
+	"code.cloudfoundry.org/lager/v3"
+)
+
+const negativeRemainingResourcesMetric = "NegativeRemainingResources"
+
+// ClampRemainingResources raises any negative amount in remaining to zero.
+func ClampRemainingResources(remaining executor.ExecutorResources) executor.ExecutorResources {
+	return executor.ExecutorResources{
+		MemoryMB:   max(remaining.MemoryMB, 0),
+		DiskMB:     max(remaining.DiskMB, 0),
+		Containers: max(remaining.Containers, 0),
+	}
+}
+
+// remainingResources returns what the executor reports as remaining, never
+// advertising a negative amount.
+func (a *AuctionCellRep) remainingResources(logger lager.Logger) (executor.ExecutorResources, error) {
+	remaining, err := a.client.RemainingResources(logger)
+	if err != nil {
+		return remaining, err
+	}
+	return a.clampRemainingResources(logger, remaining), nil
+}
+
+// clampRemainingResources clamps remaining to zero. A negative amount means
+// the executor's accounting has drifted. The drift is logged and counted
+// once when it starts rather than on every call, and logged again once it
+// is over.
+func (a *AuctionCellRep) clampRemainingResources(logger lager.Logger, remaining executor.ExecutorResources) executor.ExecutorResources {
+	clamped := ClampRemainingResources(remaining)
+	drifted := clamped != remaining
+
+	a.remainingDriftLock.Lock()
+	wasDrifted := a.remainingDrifted
+	a.remainingDrifted = drifted
+	a.remainingDriftLock.Unlock()
+
+	switch {
+	case drifted && !wasDrifted:
+		logger.Info("clamped-negative-remaining-resources", lager.Data{
+			"reported-memory-mb":  remaining.MemoryMB,
+			"reported-disk-mb":    remaining.DiskMB,
+			"reported-containers": remaining.Containers,
+		})
+		if err := a.metronClient.IncrementCounter(negativeRemainingResourcesMetric); err != nil {
+			logger.Error("failed-to-increment-negative-remaining-resources-counter", err)
+		}
+	case !drifted && wasDrifted:
+		logger.Info("remaining-resources-no-longer-negative")
+	}
+	return clamped
+}
@@ -0,0 +1,194 @@
+package auctioncellrep
+
+import (
+	"errors"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/clock"
+	loggingclient "code.cloudfoundry.org/diego-logging-client"
+	"code.cloudfoundry.org/lager/v3"
+	"code.cloudfoundry.org/rep"
+)
+
+const quarantinedRootFSesMetric = "QuarantinedRootFSes"
+
+var ErrRootFSQuarantined = errors.New("rootfs is quarantined after repeated mount failures")
+
+// RootFSQuarantine counts the consecutive mount failures of each preloaded
+// rootfs. Once a rootfs reaches the threshold, the cell stops advertising it
+// and refuses work using it for the quarantine duration, after which its
+// failures are counted afresh. A non-positive duration keeps it quarantined
+// until the rep restarts, and a non-positive threshold never quarantines
+// anything.
+type RootFSQuarantine struct {
+	threshold    int
+	duration     time.Duration
+	stackPathMap rep.StackPathMap
+	clock        clock.Clock
+	metronClient loggingclient.IngressClient
+
+	lock        sync.Mutex
+	failures    map[string]int
+	quarantined map[string]time.Time
+}
+
+func NewRootFSQuarantine(threshold int, duration time.Duration, stackPathMap rep.StackPathMap, clock clock.Clock, metronClient loggingclient.IngressClient) *RootFSQuarantine {
+	return &RootFSQuarantine{
+		threshold:    threshold,
+		duration:     duration,
+		stackPathMap: stackPathMap,
+		clock:        clock,
+		metronClient: metronClient,
+		failures:     map[string]int{},
+		quarantined:  map[string]time.Time{},
+	}
+}
+
+// RecordMountFailure counts a failure to mount the rootfs at rootFSPath, as
+// reported on the executor container.
+func (q *RootFSQuarantine) RecordMountFailure(logger lager.Logger, rootFSPath string) {
+	if q.threshold <= 0 {
+		return
+	}
+
+	stack, ok := q.stackForPath(rootFSPath)
+	if !ok {
+		return
+	}
+
+	now := q.clock.Now()
+
+	q.lock.Lock()
+	released := q.releaseExpired(now)
+	_, alreadyQuarantined := q.quarantined[stack]
+	failures := 0
+	newlyQuarantined := false
+	if !alreadyQuarantined {
+		q.failures[stack]++
+		failures = q.failures[stack]
+		if failures >= q.threshold {
+			var until time.Time
+			if q.duration > 0 {
+				until = now.Add(q.duration)
+			}
+			q.quarantined[stack] = until
+			delete(q.failures, stack)
+			newlyQuarantined = true
+		}
+	}
+	quarantinedCount := len(q.quarantined)
+	q.lock.Unlock()
+
+	switch {
+	case newlyQuarantined:
+		logger.Error("quarantined-rootfs", ErrRootFSQuarantined, lager.Data{"rootfs": stack, "failures": failures, "duration": q.duration.String()})
+	case !alreadyQuarantined:
+		logger.Info("rootfs-mount-failed", lager.Data{"rootfs": stack, "failures": failures, "threshold": q.threshold})
+	}
+
+	if newlyQuarantined || released {
+		q.sendQuarantinedCount(logger, quarantinedCount)
+	}
+}
+
+// RecordMountSuccess resets the failure count of the rootfs at rootFSPath.
+func (q *RootFSQuarantine) RecordMountSuccess(logger lager.Logger, rootFSPath string) {
+	if q.threshold <= 0 {
+		return
+	}
+
+	stack, ok := q.stackForPath(rootFSPath)
+	if !ok {
+		return
+	}
+
+	q.lock.Lock()
+	released := q.releaseExpired(q.clock.Now())
+	delete(q.failures, stack)
+	quarantinedCount := len(q.quarantined)
+	q.lock.Unlock()
+
+	if released {
+		q.sendQuarantinedCount(logger, quarantinedCount)
+	}
+}
+
+// releaseExpired drops the rootfses whose quarantine has ended, reporting
+// whether there were any. The lock must be held.
+func (q *RootFSQuarantine) releaseExpired(now time.Time) bool {
+	released := false
+	for stack, until := range q.quarantined {
+		if !until.IsZero() && !now.Before(until) {
+			delete(q.quarantined, stack)
+			released = true
+		}
+	}
+	return released
+}
+
+func (q *RootFSQuarantine) sendQuarantinedCount(logger lager.Logger, count int) {
+	err := q.metronClient.SendComponentMetric(quarantinedRootFSesMetric, float64(count), "Metric")
+	if err != nil {
+		logger.Error("failed-to-send-quarantined-rootfses-metric", err)
+	}
+}
+
+// quarantinedAt reports whether stack is still quarantined at now. The lock
+// must be held.
+func (q *RootFSQuarantine) quarantinedAt(stack string, now time.Time) bool {
+	until, quarantined := q.quarantined[stack]
+	return quarantined && (until.IsZero() || now.Before(until))
+}
+
+// Quarantined reports whether work requesting the given rootfs URL must be
+// refused.
+func (q *RootFSQuarantine) Quarantined(rootFS string) bool {
+	u, err := url.Parse(rootFS)
+	if err != nil {
+		return false
+	}
+	if u.Scheme != models.PreloadedRootFSScheme && u.Scheme != models.PreloadedOCIRootFSScheme {
+		return false
+	}
+
+	now := q.clock.Now()
+
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	return q.quarantinedAt(u.Opaque, now)
+}
+
+// QuarantinedStacks returns the names of the quarantined rootfses, sorted.
+func (q *RootFSQuarantine) QuarantinedStacks() []string {
+	now := q.clock.Now()
+
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	stacks := make([]string, 0, len(q.quarantined))
+	for stack := range q.quarantined {
+		if q.quarantinedAt(stack, now) {
+			stacks = append(stacks, stack)
+		}
+	}
+	sort.Strings(stacks)
+	return stacks
+}
+
+func (q *RootFSQuarantine) stackForPath(rootFSPath string) (string, bool) {
+	u, err := url.Parse(rootFSPath)
+	if err != nil {
+		return "", false
+	}
+
+	for stack, path := range q.stackPathMap {
+		if rootFSPath == path || u.Path == path {
+			return stack, true
+		}
+	}
+	return "", false
+}
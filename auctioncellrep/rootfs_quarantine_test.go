@@ -0,0 +1,151 @@
+package auctioncellrep_test
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	mfakes "code.cloudfoundry.org/diego-logging-client/testhelpers"
+	"code.cloudfoundry.org/lager/v3/lagertest"
+	"code.cloudfoundry.org/rep"
+	"code.cloudfoundry.org/rep/auctioncellrep"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("RootFSQuarantine", func() {
+	var (
+		logger           *lagertest.TestLogger
+		fakeMetronClient *mfakes.FakeIngressClient
+		fakeClock        *fakeclock.FakeClock
+		threshold        int
+		duration         time.Duration
+		quarantine       *auctioncellrep.RootFSQuarantine
+	)
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("test")
+		fakeMetronClient = new(mfakes.FakeIngressClient)
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		threshold = 3
+		duration = 0
+	})
+
+	JustBeforeEach(func() {
+		quarantine = auctioncellrep.NewRootFSQuarantine(threshold, duration, rep.StackPathMap{
+			"linux": "/rootfs/linux",
+			"extra": "/extra/extra.tar",
+		}, fakeClock, fakeMetronClient)
+	})
+
+	It("does not quarantine a rootfs below the threshold", func() {
+		quarantine.RecordMountFailure(logger, "/rootfs/linux")
+		quarantine.RecordMountFailure(logger, "/rootfs/linux")
+
+		Expect(quarantine.Quarantined("preloaded:linux")).To(BeFalse())
+		Expect(quarantine.QuarantinedStacks()).To(BeEmpty())
+		Expect(fakeMetronClient.SendComponentMetricCallCount()).To(BeZero())
+	})
+
+	It("quarantines a rootfs once it reaches the threshold", func() {
+		for i := 0; i < threshold; i++ {
+			quarantine.RecordMountFailure(logger, "/extra/extra.tar")
+		}
+
+		Expect(quarantine.Quarantined("preloaded:extra")).To(BeTrue())
+		Expect(quarantine.Quarantined("preloaded+layer:extra?layer=https://blobstore/layer.tgz")).To(BeTrue())
+		Expect(quarantine.Quarantined("preloaded:linux")).To(BeFalse())
+		Expect(quarantine.QuarantinedStacks()).To(Equal([]string{"extra"}))
+		Expect(logger).To(gbytes.Say("quarantined-rootfs"))
+
+		Expect(fakeMetronClient.SendComponentMetricCallCount()).To(Equal(1))
+		name, value, _ := fakeMetronClient.SendComponentMetricArgsForCall(0)
+		Expect(name).To(Equal("QuarantinedRootFSes"))
+		Expect(value).To(Equal(1.0))
+	})
+
+	It("counts failures of layered containers against their preloaded rootfs", func() {
+		for i := 0; i < threshold; i++ {
+			quarantine.RecordMountFailure(logger, "preloaded+layer:/rootfs/linux?layer=https://blobstore/layer.tgz")
+		}
+
+		Expect(quarantine.Quarantined("preloaded:linux")).To(BeTrue())
+	})
+
+	It("starts counting over after a successful mount", func() {
+		quarantine.RecordMountFailure(logger, "/rootfs/linux")
+		quarantine.RecordMountFailure(logger, "/rootfs/linux")
+		quarantine.RecordMountSuccess(logger, "/rootfs/linux")
+		quarantine.RecordMountFailure(logger, "/rootfs/linux")
+		quarantine.RecordMountFailure(logger, "/rootfs/linux")
+
+		Expect(quarantine.Quarantined("preloaded:linux")).To(BeFalse())
+	})
+
+	It("ignores rootfses that are not preloaded", func() {
+		for i := 0; i < threshold; i++ {
+			quarantine.RecordMountFailure(logger, "docker:///busybox")
+		}
+
+		Expect(quarantine.Quarantined("docker:///busybox")).To(BeFalse())
+		Expect(quarantine.QuarantinedStacks()).To(BeEmpty())
+	})
+
+	It("keeps a rootfs quarantined when there is no duration", func() {
+		for i := 0; i < threshold; i++ {
+			quarantine.RecordMountFailure(logger, "/rootfs/linux")
+		}
+		fakeClock.Increment(24 * time.Hour)
+
+		Expect(quarantine.Quarantined("preloaded:linux")).To(BeTrue())
+	})
+
+	Context("when the quarantine has a duration", func() {
+		BeforeEach(func() {
+			duration = 10 * time.Minute
+		})
+
+		JustBeforeEach(func() {
+			for i := 0; i < threshold; i++ {
+				quarantine.RecordMountFailure(logger, "/rootfs/linux")
+			}
+		})
+
+		It("keeps the rootfs quarantined until it is over", func() {
+			fakeClock.Increment(duration - time.Second)
+			Expect(quarantine.Quarantined("preloaded:linux")).To(BeTrue())
+			Expect(quarantine.QuarantinedStacks()).To(Equal([]string{"linux"}))
+		})
+
+		It("releases the rootfs once it is over", func() {
+			fakeClock.Increment(duration)
+			Expect(quarantine.Quarantined("preloaded:linux")).To(BeFalse())
+			Expect(quarantine.QuarantinedStacks()).To(BeEmpty())
+		})
+
+		It("counts the failures of a released rootfs afresh", func() {
+			fakeClock.Increment(duration)
+			quarantine.RecordMountFailure(logger, "/rootfs/linux")
+			Expect(quarantine.Quarantined("preloaded:linux")).To(BeFalse())
+
+			Expect(fakeMetronClient.SendComponentMetricCallCount()).To(Equal(2))
+			name, value, _ := fakeMetronClient.SendComponentMetricArgsForCall(1)
+			Expect(name).To(Equal("QuarantinedRootFSes"))
+			Expect(value).To(Equal(0.0))
+		})
+	})
+
+	Context("when the threshold is not positive", func() {
+		BeforeEach(func() {
+			threshold = 0
+		})
+
+		It("never quarantines anything", func() {
+			for i := 0; i < 10; i++ {
+				quarantine.RecordMountFailure(logger, "/rootfs/linux")
+			}
+
+			Expect(quarantine.Quarantined("preloaded:linux")).To(BeFalse())
+		})
+	})
+})
@@ -0,0 +1,53 @@
+package auctioncellrep
+
+import (
+	"encoding/json"
+
+	"code.cloudfoundry.org/executor"
+	"code.cloudfoundry.org/rep"
+)
+
+// SegmentCapacities returns the capacity of each isolation segment in limits,
+// keyed by its placement tag. A container counts towards every segment whose
+// tag it was placed with, and what remains of a segment never goes below
+// zero.
+func SegmentCapacities(containers []executor.Container, limits map[string]rep.Resources) map[string]rep.SegmentCapacity {
+	if len(limits) == 0 {
+		return nil
+	}
+
+	allocated := make(map[string]rep.Resources, len(limits))
+	for _, container := range containers {
+		var placementTags []string
+		if placementTagsJSON, ok := container.Tags[rep.PlacementTagsTag]; ok {
+			// malformed tags are reported when computing the cell state
+			_ = json.Unmarshal([]byte(placementTagsJSON), &placementTags)
+		}
+
+		for _, tag := range placementTags {
+			if _, ok := limits[tag]; !ok {
+				continue
+			}
+			segment := allocated[tag]
+			segment.MemoryMB += int32(container.MemoryMB)
+			segment.DiskMB += int32(container.DiskMB)
+			segment.Containers++
+			allocated[tag] = segment
+		}
+	}
+
+	capacities := make(map[string]rep.SegmentCapacity, len(limits))
+	for tag, total := range limits {
+		used := allocated[tag]
+		capacities[tag] = rep.SegmentCapacity{
+			Total:     total,
+			Allocated: used,
+			Remaining: rep.Resources{
+				MemoryMB:   max(total.MemoryMB-used.MemoryMB, 0),
+				DiskMB:     max(total.DiskMB-used.DiskMB, 0),
+				Containers: max(total.Containers-used.Containers, 0),
+			},
+		}
+	}
+	return capacities
+}
@@ -0,0 +1,70 @@
+package auctioncellrep_test
+
+import (
+	"code.cloudfoundry.org/executor"
+	"code.cloudfoundry.org/rep"
+	"code.cloudfoundry.org/rep/auctioncellrep"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SegmentCapacities", func() {
+	container := func(memoryMB, diskMB int, placementTags string) executor.Container {
+		tags := executor.Tags{}
+		if placementTags != "" {
+			tags[rep.PlacementTagsTag] = placementTags
+		}
+		return executor.Container{Resource: executor.Resource{MemoryMB: memoryMB, DiskMB: diskMB}, Tags: tags}
+	}
+
+	var limits map[string]rep.Resources
+
+	BeforeEach(func() {
+		limits = map[string]rep.Resources{
+			"segment-a": {MemoryMB: 1024, DiskMB: 2048, Containers: 4},
+			"segment-b": {MemoryMB: 512, DiskMB: 512, Containers: 2},
+		}
+	})
+
+	It("subtracts the reservations of each segment's containers from its limit", func() {
+		containers := []executor.Container{
+			container(256, 512, `["segment-a"]`),
+			container(128, 256, `["segment-a"]`),
+			container(64, 64, `["segment-b"]`),
+			container(1000, 1000, `["other"]`),
+			container(1000, 1000, ""),
+		}
+
+		Expect(auctioncellrep.SegmentCapacities(containers, limits)).To(Equal(map[string]rep.SegmentCapacity{
+			"segment-a": {
+				Total:     rep.Resources{MemoryMB: 1024, DiskMB: 2048, Containers: 4},
+				Allocated: rep.Resources{MemoryMB: 384, DiskMB: 768, Containers: 2},
+				Remaining: rep.Resources{MemoryMB: 640, DiskMB: 1280, Containers: 2},
+			},
+			"segment-b": {
+				Total:     rep.Resources{MemoryMB: 512, DiskMB: 512, Containers: 2},
+				Allocated: rep.Resources{MemoryMB: 64, DiskMB: 64, Containers: 1},
+				Remaining: rep.Resources{MemoryMB: 448, DiskMB: 448, Containers: 1},
+			},
+		}))
+	})
+
+	It("counts a container with several segment tags towards each of them", func() {
+		capacities := auctioncellrep.SegmentCapacities([]executor.Container{container(100, 100, `["segment-a","segment-b"]`)}, limits)
+		Expect(capacities["segment-a"].Allocated).To(Equal(rep.Resources{MemoryMB: 100, DiskMB: 100, Containers: 1}))
+		Expect(capacities["segment-b"].Allocated).To(Equal(rep.Resources{MemoryMB: 100, DiskMB: 100, Containers: 1}))
+	})
+
+	It("never reports less than nothing remaining", func() {
+		capacities := auctioncellrep.SegmentCapacities([]executor.Container{
+			container(400, 400, `["segment-b"]`),
+			container(400, 400, `["segment-b"]`),
+			container(400, 400, `["segment-b"]`),
+		}, limits)
+		Expect(capacities["segment-b"].Remaining).To(Equal(rep.Resources{}))
+	})
+
+	It("reports nothing when no segment has a limit", func() {
+		Expect(auctioncellrep.SegmentCapacities([]executor.Container{container(1, 1, `["segment-a"]`)}, nil)).To(BeNil())
+	})
+})
@@ -206,6 +206,12 @@ func (c *client) State(logger lager.Logger) (CellState, error) {
 	return state, nil
 }
 
+// PerformDeadlineHeader carries how long the auctioneer is willing to wait
+// for a Perform, as a duration such as "10s". The rep gives up on work it
+// could not allocate in time rather than answering after the auctioneer has
+// stopped waiting.
+const PerformDeadlineHeader = "X-Perform-Deadline"
+
 func (c *client) Perform(logger lager.Logger, work Work) (Work, error) {
 	body, err := json.Marshal(work)
 	if err != nil {
@@ -216,6 +222,9 @@ func (c *client) Perform(logger lager.Logger, work Work) (Work, error) {
 	if err != nil {
 		return Work{}, err
 	}
+	if c.client.Timeout > 0 {
+		req.Header.Set(PerformDeadlineHeader, c.client.Timeout.String())
+	}
 
 	resp, err := c.client.Do(req)
 	if err != nil {
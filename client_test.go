@@ -169,6 +169,21 @@ var _ = Describe("Client", func() {
 		})
 	})
 
+	Describe("Perform", func() {
+		BeforeEach(func() {
+			fakeServer.RouteToHandler("POST", "/work", ghttp.CombineHandlers(
+				ghttp.VerifyHeader(http.Header{rep.PerformDeadlineHeader: []string{cfHttpTimeout.String()}}),
+				ghttp.RespondWithJSONEncoded(http.StatusOK, rep.Work{}),
+			))
+		})
+
+		It("tells the rep how long it will wait", func() {
+			_, err := client.Perform(lagertest.NewTestLogger("test"), rep.Work{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(fakeServer.ReceivedRequests()).To(HaveLen(1))
+		})
+	})
+
 	Describe("UpdateLRPInstance", func() {
 		var (
 			logger    = lagertest.NewTestLogger("test")
@@ -0,0 +1,12 @@
+package clockskew_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"testing"
+)
+
+func TestClockskew(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Clockskew Suite")
+}
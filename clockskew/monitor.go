@@ -0,0 +1,131 @@
+package clockskew
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	loggingclient "code.cloudfoundry.org/diego-logging-client"
+	"code.cloudfoundry.org/lager/v3"
+)
+
+const clockSkewMetric = "ClockSkew"
+
+// ErrClockSkewed is logged when the cell's clock drifts from the reference
+// by more than the threshold.
+var ErrClockSkewed = errors.New("cell clock is skewed beyond the threshold")
+
+// Monitor is an ifrit.Runner that periodically compares the cell's clock to a
+// reference time, emitting the skew as a metric and logging loudly while it
+// exceeds the threshold.
+type Monitor struct {
+	logger        lager.Logger
+	clock         clock.Clock
+	interval      time.Duration
+	threshold     time.Duration
+	metronClient  loggingclient.IngressClient
+	referenceTime func() (time.Time, error)
+
+	skewed atomic.Bool
+}
+
+// NewMonitor constructs a Monitor. referenceTime is called once per check;
+// pass HTTPDateReference for production use.
+func NewMonitor(
+	logger lager.Logger,
+	clk clock.Clock,
+	interval time.Duration,
+	threshold time.Duration,
+	metronClient loggingclient.IngressClient,
+	referenceTime func() (time.Time, error),
+) *Monitor {
+	return &Monitor{
+		logger:        logger.Session("clock-skew"),
+		clock:         clk,
+		interval:      interval,
+		threshold:     threshold,
+		metronClient:  metronClient,
+		referenceTime: referenceTime,
+	}
+}
+
+// Run implements ifrit.Runner. The clock is checked once immediately and
+// then on every interval.
+func (m *Monitor) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	m.check()
+	close(ready)
+
+	ticker := m.clock.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-signals:
+			return nil
+		case <-ticker.C():
+			m.check()
+		}
+	}
+}
+
+// Skewed reports whether the last successful check found the skew beyond the
+// threshold.
+func (m *Monitor) Skewed() bool {
+	return m.skewed.Load()
+}
+
+func (m *Monitor) check() {
+	before := m.clock.Now()
+	reference, err := m.referenceTime()
+	if err != nil {
+		m.logger.Error("failed-to-get-reference-time", err)
+		return
+	}
+	// compare against the middle of the request, so its latency does not
+	// count as skew
+	local := before.Add(m.clock.Since(before) / 2)
+
+	skew := local.Sub(reference)
+	if skew < 0 {
+		skew = -skew
+	}
+
+	err = m.metronClient.SendComponentMetric(clockSkewMetric, skew.Seconds(), "s")
+	if err != nil {
+		m.logger.Error("failed-to-send-clock-skew-metric", err)
+	}
+
+	data := lager.Data{"skew": skew.String(), "threshold": m.threshold.String(), "local": local, "reference": reference}
+	if skew > m.threshold {
+		m.logger.Error("clock-skew-exceeds-threshold", ErrClockSkewed, data)
+		m.skewed.Store(true)
+		return
+	}
+
+	if m.skewed.Swap(false) {
+		m.logger.Info("clock-skew-recovered", data)
+	}
+}
+
+// HTTPDateReference returns a reference time source reading the Date header
+// of a HEAD request to url. The header only has second precision, so the
+// threshold should be a few seconds at least.
+func HTTPDateReference(client *http.Client, url string) func() (time.Time, error) {
+	return func() (time.Time, error) {
+		resp, err := client.Head(url)
+		if err != nil {
+			return time.Time{}, err
+		}
+		resp.Body.Close()
+
+		date := resp.Header.Get("Date")
+		if date == "" {
+			return time.Time{}, fmt.Errorf("no Date header in response from %s", url)
+		}
+		return http.ParseTime(date)
+	}
+}
@@ -0,0 +1,162 @@
+package clockskew_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	mfakes "code.cloudfoundry.org/diego-logging-client/testhelpers"
+	"code.cloudfoundry.org/lager/v3/lagertest"
+	"code.cloudfoundry.org/rep/clockskew"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/tedsuo/ifrit"
+	ginkgomon "github.com/tedsuo/ifrit/ginkgomon_v2"
+)
+
+var _ = Describe("Monitor", func() {
+	var (
+		logger           *lagertest.TestLogger
+		fakeClock        *fakeclock.FakeClock
+		fakeMetronClient *mfakes.FakeIngressClient
+		interval         time.Duration
+		threshold        time.Duration
+
+		referenceLock sync.Mutex
+		offset        time.Duration
+		referenceErr  error
+
+		monitor *clockskew.Monitor
+		process ifrit.Process
+	)
+
+	setOffset := func(d time.Duration) {
+		referenceLock.Lock()
+		defer referenceLock.Unlock()
+		offset = d
+	}
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("test")
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		fakeMetronClient = new(mfakes.FakeIngressClient)
+		interval = time.Minute
+		threshold = 5 * time.Second
+		setOffset(0)
+		referenceErr = nil
+	})
+
+	JustBeforeEach(func() {
+		monitor = clockskew.NewMonitor(logger, fakeClock, interval, threshold, fakeMetronClient, func() (time.Time, error) {
+			referenceLock.Lock()
+			defer referenceLock.Unlock()
+			if referenceErr != nil {
+				return time.Time{}, referenceErr
+			}
+			return fakeClock.Now().Add(offset), nil
+		})
+		process = ginkgomon.Invoke(monitor)
+	})
+
+	AfterEach(func() {
+		ginkgomon.Interrupt(process)
+	})
+
+	Context("when the clock agrees with the reference", func() {
+		BeforeEach(func() {
+			setOffset(2 * time.Second)
+		})
+
+		It("emits the skew", func() {
+			Expect(fakeMetronClient.SendComponentMetricCallCount()).To(Equal(1))
+			name, value, _ := fakeMetronClient.SendComponentMetricArgsForCall(0)
+			Expect(name).To(Equal("ClockSkew"))
+			Expect(value).To(Equal(2.0))
+		})
+
+		It("does not report the clock as skewed", func() {
+			Expect(monitor.Skewed()).To(BeFalse())
+			Expect(logger).NotTo(gbytes.Say("clock-skew-exceeds-threshold"))
+		})
+	})
+
+	Context("when the clock is skewed beyond the threshold", func() {
+		BeforeEach(func() {
+			setOffset(-time.Minute)
+		})
+
+		It("logs a warning and emits the skew", func() {
+			Expect(logger).To(gbytes.Say("test.clock-skew.clock-skew-exceeds-threshold"))
+
+			Expect(fakeMetronClient.SendComponentMetricCallCount()).To(Equal(1))
+			name, value, _ := fakeMetronClient.SendComponentMetricArgsForCall(0)
+			Expect(name).To(Equal("ClockSkew"))
+			Expect(value).To(Equal(60.0))
+		})
+
+		It("reports the clock as skewed", func() {
+			Expect(monitor.Skewed()).To(BeTrue())
+		})
+
+		Context("and the clock recovers", func() {
+			It("stops reporting the clock as skewed", func() {
+				setOffset(0)
+				fakeClock.WaitForWatcherAndIncrement(interval)
+
+				Eventually(monitor.Skewed).Should(BeFalse())
+				Expect(logger).To(gbytes.Say("clock-skew-recovered"))
+			})
+		})
+	})
+
+	Context("when the clock becomes skewed later on", func() {
+		It("notices on the next check", func() {
+			Expect(monitor.Skewed()).To(BeFalse())
+
+			setOffset(10 * time.Second)
+			fakeClock.WaitForWatcherAndIncrement(interval)
+
+			Eventually(monitor.Skewed).Should(BeTrue())
+			Expect(fakeMetronClient.SendComponentMetricCallCount()).To(Equal(2))
+		})
+	})
+
+	Context("when the reference time cannot be fetched", func() {
+		BeforeEach(func() {
+			referenceErr = errors.New("boom")
+		})
+
+		It("logs the failure without emitting a skew", func() {
+			Expect(logger).To(gbytes.Say("failed-to-get-reference-time"))
+			Expect(fakeMetronClient.SendComponentMetricCallCount()).To(BeZero())
+			Expect(monitor.Skewed()).To(BeFalse())
+		})
+	})
+})
+
+var _ = Describe("HTTPDateReference", func() {
+	It("returns the time in the Date header of the response", func() {
+		date := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			Expect(r.Method).To(Equal("HEAD"))
+			w.Header().Set("Date", date.Format(http.TimeFormat))
+		}))
+		defer server.Close()
+
+		reference, err := clockskew.HTTPDateReference(http.DefaultClient, server.URL)()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reference.Equal(date)).To(BeTrue())
+	})
+
+	It("fails when the server cannot be reached", func() {
+		server := httptest.NewServer(http.NotFoundHandler())
+		server.Close()
+
+		_, err := clockskew.HTTPDateReference(http.DefaultClient, server.URL)()
+		Expect(err).To(HaveOccurred())
+	})
+})
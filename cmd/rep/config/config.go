@@ -1,11 +1,15 @@
 package config
 
 import (
+	"bytes"
+	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net"
 	"os"
 	"strings"
+	"time"
 
 	"code.cloudfoundry.org/debugserver"
 	loggingclient "code.cloudfoundry.org/diego-logging-client"
@@ -74,46 +78,172 @@ func (m RootFSes) MarshalJSON() (b []byte, err error) {
 	return json.Marshal(arr)
 }
 
+// SegmentLimits maps the placement tag of an isolation segment to the share
+// of the cell's capacity given to it.
+type SegmentLimits map[string]rep.Resources
+
+var tlsVersions = map[string]uint16{
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// ParseTLSVersion converts a configured TLS version such as "1.3" into its
+// crypto/tls constant. Versions older than 1.2 are not accepted.
+func ParseTLSVersion(version string) (uint16, error) {
+	v, ok := tlsVersions[version]
+	if !ok {
+		return 0, fmt.Errorf("Invalid TLS version %q: must be one of 1.2, 1.3", version)
+	}
+	return v, nil
+}
+
+// ValidateListenAddrs returns an error when the rep's two servers would try to
+// bind the same port on overlapping hosts. An empty or unspecified host
+// listens on every interface, so it overlaps with any other host.
+func ValidateListenAddrs(listenAddr, listenAddrSecurable string) error {
+	host, port, err := net.SplitHostPort(listenAddr)
+	if err != nil {
+		return fmt.Errorf("Invalid listen_addr %q: %w", listenAddr, err)
+	}
+	securableHost, securablePort, err := net.SplitHostPort(listenAddrSecurable)
+	if err != nil {
+		return fmt.Errorf("Invalid listen_addr_securable %q: %w", listenAddrSecurable, err)
+	}
+
+	if port != securablePort || port == "0" {
+		return nil
+	}
+
+	if isWildcardHost(host) || isWildcardHost(securableHost) || sameHost(host, securableHost) {
+		return fmt.Errorf("listen_addr %q and listen_addr_securable %q conflict: both listen on port %s", listenAddr, listenAddrSecurable, port)
+	}
+	return nil
+}
+
+func isWildcardHost(host string) bool {
+	if host == "" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsUnspecified()
+}
+
+func sameHost(a, b string) bool {
+	ipA, ipB := net.ParseIP(a), net.ParseIP(b)
+	if ipA != nil && ipB != nil {
+		return ipA.Equal(ipB)
+	}
+	return strings.EqualFold(a, b)
+}
+
 type RepConfig struct {
-	AdvertiseDomain                 string                `json:"advertise_domain,omitempty"`
-	BBSAddress                      string                `json:"bbs_address"`
-	BBSClientSessionCacheSize       int                   `json:"bbs_client_session_cache_size,omitempty"`
-	BBSMaxIdleConnsPerHost          int                   `json:"bbs_max_idle_conns_per_host,omitempty"`
-	BBSCACertFile                   string                `json:"bbs_ca_cert_file"`     // DEPRECATED. Kept around for dusts compatability
-	BBSClientCertFile               string                `json:"bbs_client_cert_file"` // DEPRECATED. Kept around for dusts compatability
-	BBSClientKeyFile                string                `json:"bbs_client_key_file"`  // DEPRECATED. Kept around for dusts compatability
-	CaCertFile                      string                `json:"ca_cert_file"`
-	CellAnnotations                 map[string]string     `json:"cell_annotations,omitempty"`
-	CellID                          string                `json:"cell_id"`
-	CellIndex                       int                   `json:"cell_index"`
-	CommunicationTimeout            durationjson.Duration `json:"communication_timeout,omitempty"`
-	EvacuationPollingInterval       durationjson.Duration `json:"evacuation_polling_interval,omitempty"`
-	EvacuationTimeout               durationjson.Duration `json:"evacuation_timeout,omitempty"`
-	ExtraRootfsDir                  string                `json:"extra_root_fs_dir"`
-	LayeringMode                    string                `json:"layering_mode,omitempty"`
-	ListenAddr                      string                `json:"listen_addr,omitempty"`
-	ListenAddrSecurable             string                `json:"listen_addr_securable,omitempty"`
-	LockRetryInterval               durationjson.Duration `json:"lock_retry_interval,omitempty"`
-	LockTTL                         durationjson.Duration `json:"lock_ttl,omitempty"`
-	OptionalPlacementTags           []string              `json:"optional_placement_tags"`
-	PlacementTags                   []string              `json:"placement_tags"`
-	PollingInterval                 durationjson.Duration `json:"polling_interval,omitempty"`
-	PreloadedRootFS                 RootFSes              `json:"preloaded_root_fs"`
-	RepURL                          string                `json:"rep_url,omitempty"`
-	SidecarRootFSPath               string                `json:"sidecar_root_fs_path"`
-	SidecarRootFS                   string                `json:"sidecar_root_fs"`
-	ServerCertFile                  string                `json:"server_cert_file"` // DEPRECATED. Kept around for dusts compatability
-	ServerKeyFile                   string                `json:"server_key_file"`  // DEPRECATED. Kept around for dusts compatability
-	CertFile                        string                `json:"cert_file"`
-	KeyFile                         string                `json:"key_file"`
-	SessionName                     string                `json:"session_name,omitempty"`
-	SupportedProviders              []string              `json:"supported_providers"`
-	Zone                            string                `json:"zone"`
-	ReportInterval                  durationjson.Duration `json:"report_interval,omitempty"`
-	DiskHealthCheckPaths            []string              `json:"disk_health_check_paths,omitempty"`
-	DiskHealthCheckInterval         durationjson.Duration `json:"disk_health_check_interval,omitempty"`
-	DiskHealthCheckFailureThreshold int                   `json:"disk_health_check_failure_threshold,omitempty"`
-	LoggregatorConfig               loggingclient.Config  `json:"loggregator"`
+	AdvertiseDomain                     string                  `json:"advertise_domain,omitempty"`
+	BBSAddress                          string                  `json:"bbs_address"`
+	BBSClientSessionCacheSize           int                     `json:"bbs_client_session_cache_size,omitempty"`
+	BBSMaxIdleConnsPerHost              int                     `json:"bbs_max_idle_conns_per_host,omitempty"`
+	BBSCACertFile                       string                  `json:"bbs_ca_cert_file"`     // DEPRECATED. Kept around for dusts compatability
+	BBSClientCertFile                   string                  `json:"bbs_client_cert_file"` // DEPRECATED. Kept around for dusts compatability
+	BBSClientKeyFile                    string                  `json:"bbs_client_key_file"`  // DEPRECATED. Kept around for dusts compatability
+	CaCertFile                          string                  `json:"ca_cert_file"`
+	CellAnnotations                     map[string]string       `json:"cell_annotations,omitempty"`
+	CellID                              string                  `json:"cell_id"`
+	CellIndex                           int                     `json:"cell_index"`
+	CommunicationTimeout                durationjson.Duration   `json:"communication_timeout,omitempty"`
+	EvacuationPollingInterval           durationjson.Duration   `json:"evacuation_polling_interval,omitempty"`
+	EvacuationTimeout                   durationjson.Duration   `json:"evacuation_timeout,omitempty"`
+	ExtraRootfsDir                      string                  `json:"extra_root_fs_dir"`
+	LayeringMode                        string                  `json:"layering_mode,omitempty"`
+	ListenAddr                          string                  `json:"listen_addr,omitempty"`
+	ListenAddrSecurable                 string                  `json:"listen_addr_securable,omitempty"`
+	LockRetryInterval                   durationjson.Duration   `json:"lock_retry_interval,omitempty"`
+	LockTTL                             durationjson.Duration   `json:"lock_ttl,omitempty"`
+	OptionalPlacementTags               []string                `json:"optional_placement_tags"`
+	PlacementTags                       []string                `json:"placement_tags"`
+	PollingInterval                     durationjson.Duration   `json:"polling_interval,omitempty"`
+	PreloadedRootFS                     RootFSes                `json:"preloaded_root_fs"`
+	RepURL                              string                  `json:"rep_url,omitempty"`
+	SidecarRootFSPath                   string                  `json:"sidecar_root_fs_path"`
+	SidecarRootFS                       string                  `json:"sidecar_root_fs"`
+	ServerCertFile                      string                  `json:"server_cert_file"` // DEPRECATED. Kept around for dusts compatability
+	ServerKeyFile                       string                  `json:"server_key_file"`  // DEPRECATED. Kept around for dusts compatability
+	CertFile                            string                  `json:"cert_file"`
+	KeyFile                             string                  `json:"key_file"`
+	SessionName                         string                  `json:"session_name,omitempty"`
+	SupportedProviders                  []string                `json:"supported_providers"`
+	Zone                                string                  `json:"zone"`
+	ReportInterval                      durationjson.Duration   `json:"report_interval,omitempty"`
+	DiskHealthCheckPaths                []string                `json:"disk_health_check_paths,omitempty"`
+	DiskHealthCheckInterval             durationjson.Duration   `json:"disk_health_check_interval,omitempty"`
+	DiskHealthCheckFailureThreshold     int                     `json:"disk_health_check_failure_threshold,omitempty"`
+	SlowRequestThreshold                durationjson.Duration   `json:"slow_request_threshold,omitempty"`
+	EvacuationExcludedDomains           []string                `json:"evacuation_excluded_domains,omitempty"`
+	TLSMinVersion                       string                  `json:"tls_min_version,omitempty"`
+	RequireExtraRootfsDir               bool                    `json:"require_extra_root_fs_dir,omitempty"`
+	MaxPerTaskDiskMB                    int                     `json:"max_per_task_disk_mb,omitempty"`
+	CompressPresencePayload             bool                    `json:"compress_presence_payload,omitempty"`
+	PresencePayloadCompressionThreshold int                     `json:"presence_payload_compression_threshold,omitempty"`
+	ProxyMemoryByRootFS                 map[string]int          `json:"proxy_memory_by_root_fs,omitempty"`
+	MaxPlacementTagsPerRequest          int                     `json:"max_placement_tags_per_request,omitempty"`
+	BulkSyncMaxRetries                  int                     `json:"bulk_sync_max_retries,omitempty"`
+	BulkSyncRetryInterval               durationjson.Duration   `json:"bulk_sync_retry_interval,omitempty"`
+	FeatureFlags                        map[string]bool         `json:"feature_flags,omitempty"`
+	ReconciliationPolicy                string                  `json:"reconciliation_policy,omitempty"`
+	MetricsWarmupPeriod                 durationjson.Duration   `json:"metrics_warmup_period,omitempty"`
+	MaxInstancesPerLRP                  int                     `json:"max_instances_per_lrp,omitempty"`
+	HeartbeatInterval                   durationjson.Duration   `json:"heartbeat_interval,omitempty"`
+	SessionTicketRotationInterval       durationjson.Duration   `json:"session_ticket_rotation_interval,omitempty"`
+	MemoryPressureEvictionEnabled       bool                    `json:"memory_pressure_eviction_enabled,omitempty"`
+	MemoryPressureEvictionThreshold     float64                 `json:"memory_pressure_eviction_threshold,omitempty"`
+	MemoryPressureCheckInterval         durationjson.Duration   `json:"memory_pressure_check_interval,omitempty"`
+	RequiredCertSANs                    []string                `json:"required_cert_sans,omitempty"`
+	LocalRegistryMirror                 string                  `json:"local_registry_mirror,omitempty"`
+	RequireMetron                       bool                    `json:"require_metron"`
+	EventBatchWindow                    durationjson.Duration   `json:"event_batch_window,omitempty"`
+	MaxExecutorRejections               int                     `json:"max_executor_rejections,omitempty"`
+	AllowZeroCapacity                   bool                    `json:"allow_zero_capacity,omitempty"`
+	RestartCountFile                    string                  `json:"restart_count_file,omitempty"`
+	AllowPrivilegedContainers           bool                    `json:"allow_privileged_containers"`
+	RootFSFailureThreshold              int                     `json:"root_fs_failure_threshold,omitempty"`
+	RootFSQuarantineDuration            durationjson.Duration   `json:"root_fs_quarantine_duration,omitempty"`
+	EvacuationHistorySize               int                     `json:"evacuation_history_size,omitempty"`
+	StateCacheTTL                       durationjson.Duration   `json:"state_cache_ttl,omitempty"`
+	StartupTaskPolicy                   string                  `json:"startup_task_policy,omitempty"`
+	ClockSkewReferenceURL               string                  `json:"clock_skew_reference_url,omitempty"`
+	ClockSkewThreshold                  durationjson.Duration   `json:"clock_skew_threshold,omitempty"`
+	ClockSkewCheckInterval              durationjson.Duration   `json:"clock_skew_check_interval,omitempty"`
+	RefuseAuctionsOnClockSkew           bool                    `json:"refuse_auctions_on_clock_skew,omitempty"`
+	AllocationRetries                   int                     `json:"allocation_retries,omitempty"`
+	AllocationRetryInterval             durationjson.Duration   `json:"allocation_retry_interval,omitempty"`
+	VerifyAdvertiseDomain               bool                    `json:"verify_advertise_domain,omitempty"`
+	RequireResolvableAdvertiseDomain    bool                    `json:"require_resolvable_advertise_domain,omitempty"`
+	GeneratorConcurrency                int                     `json:"generator_concurrency,omitempty"`
+	PlacementFairnessReportInterval     durationjson.Duration   `json:"placement_fairness_report_interval,omitempty"`
+	ExecutorCleanupTimeout              durationjson.Duration   `json:"executor_cleanup_timeout,omitempty"`
+	RemovedRootFSPolicy                 string                  `json:"removed_root_fs_policy,omitempty"`
+	DecisionWebhookURL                  string                  `json:"decision_webhook_url,omitempty"`
+	MaxExtraRootFS                      int                     `json:"max_extra_root_fs,omitempty"`
+	ContainerStateReportInterval        durationjson.Duration   `json:"container_state_report_interval,omitempty"`
+	ReadOnlyMode                        bool                    `json:"read_only_mode,omitempty"`
+	MaxOperationsPerBulkLoop            int                     `json:"max_operations_per_bulk_loop,omitempty"`
+	OrphanContainerGracePeriod          durationjson.Duration   `json:"orphan_container_grace_period,omitempty"`
+	OrphanContainerPolicy               string                  `json:"orphan_container_policy,omitempty"`
+	EmitStartupDurationMetric           bool                    `json:"emit_startup_duration_metric,omitempty"`
+	AllowedPortRange                    string                  `json:"allowed_port_range,omitempty"`
+	PresencePayloadSizeWarningThreshold int                     `json:"presence_payload_size_warning_threshold,omitempty"`
+	TrimOversizedPresencePayload        bool                    `json:"trim_oversized_presence_payload,omitempty"`
+	AllocationHistorySize               int                     `json:"allocation_history_size,omitempty"`
+	MaxCreatingDuration                 durationjson.Duration   `json:"max_creating_duration,omitempty"`
+	EmitRootFSCacheHitRate              bool                    `json:"emit_root_fs_cache_hit_rate,omitempty"`
+	ShutdownSignalActions               map[string]string       `json:"shutdown_signal_actions,omitempty"`
+	ContainerAgeReportInterval          durationjson.Duration   `json:"container_age_report_interval,omitempty"`
+	ContainerAgeBuckets                 []durationjson.Duration `json:"container_age_buckets,omitempty"`
+	AllowedDomains                      []string                `json:"allowed_domains,omitempty"`
+	BatchAllocationPolicy               string                  `json:"batch_allocation_policy,omitempty"`
+	IsolationSegmentLimits              SegmentLimits           `json:"isolation_segment_limits,omitempty"`
+	FileDescriptorReportInterval        durationjson.Duration   `json:"file_descriptor_report_interval,omitempty"`
+	SupportedSecurityProfiles           []string                `json:"supported_security_profiles,omitempty"`
+	AllocationStateFile                 string                  `json:"allocation_state_file,omitempty"`
+	LoggregatorConfig                   loggingclient.Config    `json:"loggregator"`
 	debugserver.DebugServerConfig
 	executorinit.ExecutorConfig
 	lagerflags.LagerConfig
@@ -121,20 +251,61 @@ type RepConfig struct {
 }
 
 func NewRepConfig(configPath string) (RepConfig, error) {
-	repConfig := RepConfig{}
-	configFile, err := os.Open(configPath)
+	repConfig := RepConfig{
+		RequireMetron:             true,
+		AllowPrivilegedContainers: true,
+		RootFSQuarantineDuration:  durationjson.Duration(10 * time.Minute),
+	}
+	configData, err := os.ReadFile(configPath)
 	if err != nil {
 		return RepConfig{}, err
 	}
 
-	defer configFile.Close()
-
-	decoder := json.NewDecoder(configFile)
+	decoder := json.NewDecoder(bytes.NewReader(configData))
 
 	err = decoder.Decode(&repConfig)
 	if err != nil {
-		return RepConfig{}, err
+		return RepConfig{}, describeDecodeError(configPath, configData, err)
 	}
 
 	return repConfig, nil
 }
+
+const snippetRadius = 20
+
+// describeDecodeError adds the position of the offending input, and the
+// config surrounding it, to JSON syntax and type errors.
+func describeDecodeError(configPath string, data []byte, err error) error {
+	var offset int64
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		offset = syntaxErr.Offset
+	case errors.As(err, &typeErr):
+		offset = typeErr.Offset
+	default:
+		return err
+	}
+
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+
+	// json reports the offset just past the offending byte
+	line, column := 1, 1
+	for _, b := range data[:max(offset-1, 0)] {
+		if b == '\n' {
+			line++
+			column = 1
+		} else {
+			column++
+		}
+	}
+
+	start := max(offset-snippetRadius, 0)
+	end := min(offset+snippetRadius, int64(len(data)))
+
+	return fmt.Errorf("invalid config file %s at offset %d (line %d, column %d) near %q: %w",
+		configPath, offset, line, column, string(data[start:end]), err)
+}
@@ -1,6 +1,9 @@
 package config_test
 
 import (
+	"crypto/tls"
+	"encoding/json"
+	"errors"
 	"os"
 	"time"
 
@@ -122,7 +125,75 @@ var _ = Describe("RepConfig", func() {
 			"report_interval": "2m",
 			"disk_health_check_paths": ["/var/vcap/data/rep", "/var/vcap/store"],
 			"disk_health_check_interval": "15s",
-			"disk_health_check_failure_threshold": 3
+			"disk_health_check_failure_threshold": 3,
+			"slow_request_threshold": "500ms",
+			"evacuation_excluded_domains": ["cell-local"],
+			"tls_min_version": "1.3",
+			"require_extra_root_fs_dir": true,
+			"max_per_task_disk_mb": 4096,
+			"compress_presence_payload": true,
+			"presence_payload_compression_threshold": 2048,
+			"proxy_memory_by_root_fs": {"cflinuxfs4": 48},
+			"max_placement_tags_per_request": 1000,
+			"bulk_sync_max_retries": 2,
+			"bulk_sync_retry_interval": "1s",
+			"feature_flags": {"fast-start": true},
+			"reconciliation_policy": "quarantine",
+			"metrics_warmup_period": "2m",
+			"max_instances_per_lrp": 2,
+			"heartbeat_interval": "30s",
+			"session_ticket_rotation_interval": "12h",
+			"memory_pressure_eviction_enabled": true,
+			"memory_pressure_eviction_threshold": 0.9,
+			"memory_pressure_check_interval": "10s",
+			"required_cert_sans": ["127.0.0.1", "rep.service.cf.internal"],
+			"local_registry_mirror": "127.0.0.1:5000",
+			"require_metron": false,
+			"event_batch_window": "250ms",
+			"max_executor_rejections": 5,
+			"allow_zero_capacity": true,
+			"restart_count_file": "/var/vcap/data/rep/restart_count",
+			"allow_privileged_containers": false,
+			"root_fs_failure_threshold": 3,
+			"root_fs_quarantine_duration": "10m",
+			"evacuation_history_size": 20,
+			"state_cache_ttl": "2s",
+			"startup_task_policy": "retry-complete",
+			"clock_skew_reference_url": "https://time.example.com",
+			"clock_skew_threshold": "10s",
+			"clock_skew_check_interval": "1m",
+			"refuse_auctions_on_clock_skew": true,
+			"allocation_retries": 2,
+			"allocation_retry_interval": "200ms",
+			"verify_advertise_domain": true,
+			"require_resolvable_advertise_domain": true,
+			"generator_concurrency": 8,
+			"placement_fairness_report_interval": "1m",
+			"executor_cleanup_timeout": "15s",
+			"removed_root_fs_policy": "crash-report",
+			"decision_webhook_url": "https://decisions.example.com/hook",
+			"max_extra_root_fs": 16,
+			"container_state_report_interval": "30s",
+			"read_only_mode": true,
+			"max_operations_per_bulk_loop": 500,
+			"orphan_container_grace_period": "5m",
+			"orphan_container_policy": "notify-then-reap",
+			"emit_startup_duration_metric": true,
+			"allowed_port_range": "8080-8090",
+			"presence_payload_size_warning_threshold": 65536,
+			"trim_oversized_presence_payload": true,
+			"allocation_history_size": 100,
+			"max_creating_duration": "10m",
+			"emit_root_fs_cache_hit_rate": true,
+			"shutdown_signal_actions": {"SIGTERM": "evacuate"},
+			"container_age_report_interval": "1m",
+			"container_age_buckets": ["1m", "1h"],
+			"allowed_domains": ["cf-apps", "cf-tasks"],
+			"batch_allocation_policy": "all-or-nothing",
+			"isolation_segment_limits": {"segment-a": {"memory_mb": 4096, "disk_mb": 8192, "containers": 20}},
+			"file_descriptor_report_interval": "1m",
+			"supported_security_profiles": ["seccomp/runtime-default"],
+			"allocation_state_file": "/var/vcap/data/rep/allocation-state.json"
 		}`
 	})
 
@@ -222,28 +293,96 @@ var _ = Describe("RepConfig", func() {
 			LagerConfig: lagerflags.LagerConfig{
 				LogLevel: lagerflags.DEBUG,
 			},
-			LayeringMode:                    "single-layer",
-			ListenAddr:                      "0.0.0.0:8080",
-			ListenAddrSecurable:             "0.0.0.0:8081",
-			LockRetryInterval:               durationjson.Duration(5 * time.Second),
-			LockTTL:                         durationjson.Duration(5 * time.Second),
-			OptionalPlacementTags:           []string{"otag1", "otag2"},
-			PlacementTags:                   []string{"tag1", "tag2"},
-			PollingInterval:                 durationjson.Duration(10 * time.Second),
-			PreloadedRootFS:                 []config.RootFS{{"test", "value"}, {"test2", "value2"}},
-			RepURL:                          "https://custom-rep-url:8443",
-			ExtraRootfsDir:                  "/var/vcap/data/rootfses",
-			SidecarRootFSPath:               "/var/vcap/packages/cflinuxfs4/rootfs.tar",
-			SidecarRootFS:                   "cflinuxfs4",
-			CertFile:                        "/tmp/server_cert",
-			KeyFile:                         "/tmp/server_key",
-			SessionName:                     "test",
-			SupportedProviders:              []string{"provider1", "provider2"},
-			Zone:                            "test-zone",
-			ReportInterval:                  durationjson.Duration(2 * time.Minute),
-			DiskHealthCheckPaths:            []string{"/var/vcap/data/rep", "/var/vcap/store"},
-			DiskHealthCheckInterval:         durationjson.Duration(15 * time.Second),
-			DiskHealthCheckFailureThreshold: 3,
+			LayeringMode:                        "single-layer",
+			ListenAddr:                          "0.0.0.0:8080",
+			ListenAddrSecurable:                 "0.0.0.0:8081",
+			LockRetryInterval:                   durationjson.Duration(5 * time.Second),
+			LockTTL:                             durationjson.Duration(5 * time.Second),
+			OptionalPlacementTags:               []string{"otag1", "otag2"},
+			PlacementTags:                       []string{"tag1", "tag2"},
+			PollingInterval:                     durationjson.Duration(10 * time.Second),
+			PreloadedRootFS:                     []config.RootFS{{"test", "value"}, {"test2", "value2"}},
+			RepURL:                              "https://custom-rep-url:8443",
+			ExtraRootfsDir:                      "/var/vcap/data/rootfses",
+			SidecarRootFSPath:                   "/var/vcap/packages/cflinuxfs4/rootfs.tar",
+			SidecarRootFS:                       "cflinuxfs4",
+			CertFile:                            "/tmp/server_cert",
+			KeyFile:                             "/tmp/server_key",
+			SessionName:                         "test",
+			SupportedProviders:                  []string{"provider1", "provider2"},
+			Zone:                                "test-zone",
+			ReportInterval:                      durationjson.Duration(2 * time.Minute),
+			DiskHealthCheckPaths:                []string{"/var/vcap/data/rep", "/var/vcap/store"},
+			DiskHealthCheckInterval:             durationjson.Duration(15 * time.Second),
+			DiskHealthCheckFailureThreshold:     3,
+			SlowRequestThreshold:                durationjson.Duration(500 * time.Millisecond),
+			EvacuationExcludedDomains:           []string{"cell-local"},
+			TLSMinVersion:                       "1.3",
+			RequireExtraRootfsDir:               true,
+			MaxPerTaskDiskMB:                    4096,
+			CompressPresencePayload:             true,
+			PresencePayloadCompressionThreshold: 2048,
+			ProxyMemoryByRootFS:                 map[string]int{"cflinuxfs4": 48},
+			MaxPlacementTagsPerRequest:          1000,
+			BulkSyncMaxRetries:                  2,
+			BulkSyncRetryInterval:               durationjson.Duration(time.Second),
+			FeatureFlags:                        map[string]bool{"fast-start": true},
+			ReconciliationPolicy:                "quarantine",
+			MetricsWarmupPeriod:                 durationjson.Duration(2 * time.Minute),
+			MaxInstancesPerLRP:                  2,
+			HeartbeatInterval:                   durationjson.Duration(30 * time.Second),
+			SessionTicketRotationInterval:       durationjson.Duration(12 * time.Hour),
+			MemoryPressureEvictionEnabled:       true,
+			MemoryPressureEvictionThreshold:     0.9,
+			MemoryPressureCheckInterval:         durationjson.Duration(10 * time.Second),
+			RequiredCertSANs:                    []string{"127.0.0.1", "rep.service.cf.internal"},
+			LocalRegistryMirror:                 "127.0.0.1:5000",
+			RequireMetron:                       false,
+			EventBatchWindow:                    durationjson.Duration(250 * time.Millisecond),
+			MaxExecutorRejections:               5,
+			AllowZeroCapacity:                   true,
+			RestartCountFile:                    "/var/vcap/data/rep/restart_count",
+			AllowPrivilegedContainers:           false,
+			RootFSFailureThreshold:              3,
+			RootFSQuarantineDuration:            durationjson.Duration(10 * time.Minute),
+			EvacuationHistorySize:               20,
+			StateCacheTTL:                       durationjson.Duration(2 * time.Second),
+			StartupTaskPolicy:                   "retry-complete",
+			ClockSkewReferenceURL:               "https://time.example.com",
+			ClockSkewThreshold:                  durationjson.Duration(10 * time.Second),
+			ClockSkewCheckInterval:              durationjson.Duration(time.Minute),
+			RefuseAuctionsOnClockSkew:           true,
+			AllocationRetries:                   2,
+			AllocationRetryInterval:             durationjson.Duration(200 * time.Millisecond),
+			VerifyAdvertiseDomain:               true,
+			RequireResolvableAdvertiseDomain:    true,
+			GeneratorConcurrency:                8,
+			PlacementFairnessReportInterval:     durationjson.Duration(time.Minute),
+			ExecutorCleanupTimeout:              durationjson.Duration(15 * time.Second),
+			RemovedRootFSPolicy:                 "crash-report",
+			DecisionWebhookURL:                  "https://decisions.example.com/hook",
+			MaxExtraRootFS:                      16,
+			ContainerStateReportInterval:        durationjson.Duration(30 * time.Second),
+			ReadOnlyMode:                        true,
+			MaxOperationsPerBulkLoop:            500,
+			OrphanContainerGracePeriod:          durationjson.Duration(5 * time.Minute),
+			OrphanContainerPolicy:               "notify-then-reap",
+			EmitStartupDurationMetric:           true,
+			AllowedPortRange:                    "8080-8090",
+			PresencePayloadSizeWarningThreshold: 65536,
+			TrimOversizedPresencePayload:        true,
+			AllocationHistorySize:               100,
+			MaxCreatingDuration:                 durationjson.Duration(10 * time.Minute),
+			EmitRootFSCacheHitRate:              true,
+			ShutdownSignalActions:               map[string]string{"SIGTERM": "evacuate"},
+			ContainerAgeReportInterval:          durationjson.Duration(time.Minute),
+			ContainerAgeBuckets:                 []durationjson.Duration{durationjson.Duration(time.Minute), durationjson.Duration(time.Hour)},
+			AllowedDomains:                      []string{"cf-apps", "cf-tasks"},
+			BatchAllocationPolicy:               "all-or-nothing",
+			IsolationSegmentLimits:              config.SegmentLimits{"segment-a": {MemoryMB: 4096, DiskMB: 8192, Containers: 20}},
+			FileDescriptorReportInterval:        durationjson.Duration(time.Minute),
+			SupportedSecurityProfiles:           []string{"seccomp/runtime-default"},
+			AllocationStateFile:                 "/var/vcap/data/rep/allocation-state.json",
 			LoggregatorConfig: loggingclient.Config{
 				APIPort:       1234,
 				CACertPath:    "ca-path",
@@ -275,6 +414,27 @@ var _ = Describe("RepConfig", func() {
 			Expect(err).To(HaveOccurred())
 		})
 
+		It("includes the position of the error and the surrounding config", func() {
+			_, err := config.NewRepConfig(configFilePath)
+			Expect(err).To(MatchError(ContainSubstring("at offset 2 (line 1, column 2)")))
+			Expect(err).To(MatchError(ContainSubstring(`near "{{"`)))
+		})
+
+		Context("because of a typo on a later line", func() {
+			BeforeEach(func() {
+				configData = "{\n\t\"cell_id\": \"cell_z1/10\",\n\t\"zone\" \"z1\"\n}"
+			})
+
+			It("reports the line and column of the typo", func() {
+				_, err := config.NewRepConfig(configFilePath)
+				Expect(err).To(MatchError(ContainSubstring("(line 3, column 9)")))
+				Expect(err).To(MatchError(ContainSubstring(`\"zone\" \"z1\"`)))
+
+				var syntaxErr *json.SyntaxError
+				Expect(errors.As(err, &syntaxErr)).To(BeTrue())
+			})
+		})
+
 		Context("because the communication_timeout is not valid", func() {
 			BeforeEach(func() {
 				configData = `{"communication_timeout": 4234342342}`
@@ -327,4 +487,96 @@ var _ = Describe("RepConfig", func() {
 			Expect(repConfig.RepURL).To(BeEmpty())
 		})
 	})
+
+	Context("when require_metron is not provided in config", func() {
+		BeforeEach(func() {
+			configData = `{
+				"cell_id" : "cell_z1/10"
+			}`
+		})
+
+		It("requires metron", func() {
+			repConfig, err := config.NewRepConfig(configFilePath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(repConfig.RequireMetron).To(BeTrue())
+		})
+	})
+
+	Context("when allow_privileged_containers is not provided in config", func() {
+		BeforeEach(func() {
+			configData = `{
+				"cell_id" : "cell_z1/10"
+			}`
+		})
+
+		It("allows privileged containers", func() {
+			repConfig, err := config.NewRepConfig(configFilePath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(repConfig.AllowPrivilegedContainers).To(BeTrue())
+		})
+	})
+
+	Context("when root_fs_quarantine_duration is not provided in config", func() {
+		BeforeEach(func() {
+			configData = `{
+				"cell_id" : "cell_z1/10"
+			}`
+		})
+
+		It("releases quarantined rootfses after ten minutes", func() {
+			repConfig, err := config.NewRepConfig(configFilePath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(repConfig.RootFSQuarantineDuration).To(Equal(durationjson.Duration(10 * time.Minute)))
+		})
+	})
+
+	Describe("ParseTLSVersion", func() {
+		It("parses the supported versions", func() {
+			version, err := config.ParseTLSVersion("1.2")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(version).To(Equal(uint16(tls.VersionTLS12)))
+
+			version, err = config.ParseTLSVersion("1.3")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(version).To(Equal(uint16(tls.VersionTLS13)))
+		})
+
+		It("rejects unknown or insecure versions", func() {
+			for _, version := range []string{"", "1.1", "1.0", "TLS1.3", "bogus"} {
+				_, err := config.ParseTLSVersion(version)
+				Expect(err).To(HaveOccurred(), "version %q", version)
+			}
+		})
+	})
+
+	Describe("ValidateListenAddrs", func() {
+		It("accepts addresses on distinct ports", func() {
+			Expect(config.ValidateListenAddrs("0.0.0.0:1800", "0.0.0.0:1801")).To(Succeed())
+			Expect(config.ValidateListenAddrs(":1800", ":1801")).To(Succeed())
+		})
+
+		It("accepts the same port on distinct hosts", func() {
+			Expect(config.ValidateListenAddrs("127.0.0.1:1800", "10.0.0.1:1800")).To(Succeed())
+		})
+
+		It("accepts ephemeral ports", func() {
+			Expect(config.ValidateListenAddrs("0.0.0.0:0", "0.0.0.0:0")).To(Succeed())
+		})
+
+		It("rejects identical addresses", func() {
+			err := config.ValidateListenAddrs("0.0.0.0:1800", "0.0.0.0:1800")
+			Expect(err).To(MatchError(ContainSubstring("conflict")))
+		})
+
+		It("rejects addresses resolving to the same host and port", func() {
+			Expect(config.ValidateListenAddrs(":1800", "127.0.0.1:1800")).NotTo(Succeed())
+			Expect(config.ValidateListenAddrs("127.0.0.1:1800", "[::]:1800")).NotTo(Succeed())
+			Expect(config.ValidateListenAddrs("[::1]:1800", "[0:0:0:0:0:0:0:1]:1800")).NotTo(Succeed())
+		})
+
+		It("rejects malformed addresses", func() {
+			Expect(config.ValidateListenAddrs("bogus", "0.0.0.0:1801")).NotTo(Succeed())
+			Expect(config.ValidateListenAddrs("0.0.0.0:1800", "bogus")).NotTo(Succeed())
+		})
+	})
 })
@@ -0,0 +1,102 @@
+package config
+
+import (
+	"fmt"
+
+	loggingclient "code.cloudfoundry.org/diego-logging-client"
+	"code.cloudfoundry.org/lager/v3"
+)
+
+// WarningCategory groups the non-fatal issues found in a config. Each
+// category is reported as its own counter.
+type WarningCategory string
+
+const (
+	WarningCategoryDeprecatedField WarningCategory = "DeprecatedField"
+	WarningCategoryRiskyValue      WarningCategory = "RiskyValue"
+)
+
+const configWarningMetricPrefix = "RepConfigWarning"
+
+// Warning is a config issue the rep can start with but operators should fix.
+type Warning struct {
+	Category WarningCategory
+	Message  string
+}
+
+// MetricName is the name of the counter incremented for warnings in this
+// category.
+func (c WarningCategory) MetricName() string {
+	return configWarningMetricPrefix + string(c)
+}
+
+// Warnings lists the deprecated fields set and the risky values used in the
+// config.
+func (c RepConfig) Warnings() []Warning {
+	warnings := []Warning{}
+
+	deprecated := []struct {
+		key, value, replacement string
+	}{
+		{"bbs_ca_cert_file", c.BBSCACertFile, "ca_cert_file"},
+		{"bbs_client_cert_file", c.BBSClientCertFile, "cert_file"},
+		{"bbs_client_key_file", c.BBSClientKeyFile, "key_file"},
+		{"server_cert_file", c.ServerCertFile, "cert_file"},
+		{"server_key_file", c.ServerKeyFile, "key_file"},
+	}
+	for _, field := range deprecated {
+		if field.value == "" {
+			continue
+		}
+		warnings = append(warnings, Warning{
+			Category: WarningCategoryDeprecatedField,
+			Message:  fmt.Sprintf("%s is deprecated and ignored, use %s instead", field.key, field.replacement),
+		})
+	}
+
+	if !c.RequireMetron {
+		warnings = append(warnings, Warning{
+			Category: WarningCategoryRiskyValue,
+			Message:  "require_metron is disabled, the cell may run without emitting metrics",
+		})
+	}
+
+	if c.MemoryPressureEvictionEnabled && c.MemoryPressureEvictionThreshold > 0 && c.MemoryPressureEvictionThreshold < 0.5 {
+		warnings = append(warnings, Warning{
+			Category: WarningCategoryRiskyValue,
+			Message:  fmt.Sprintf("memory_pressure_eviction_threshold of %.2f evicts containers while most of the cell's memory is free", c.MemoryPressureEvictionThreshold),
+		})
+	}
+
+	if c.DiskHealthCheckFailureThreshold == 1 {
+		warnings = append(warnings, Warning{
+			Category: WarningCategoryRiskyValue,
+			Message:  "disk_health_check_failure_threshold of 1 evacuates the cell on a single failed disk check",
+		})
+	}
+
+	return warnings
+}
+
+// ReportWarnings logs each warning and increments the counter of its
+// category by the number of warnings in it.
+func ReportWarnings(logger lager.Logger, metronClient loggingclient.IngressClient, warnings []Warning) {
+	logger = logger.Session("config-warnings")
+
+	counts := map[WarningCategory]uint64{}
+	order := []WarningCategory{}
+	for _, warning := range warnings {
+		logger.Info("config-warning", lager.Data{"category": warning.Category, "message": warning.Message})
+		if counts[warning.Category] == 0 {
+			order = append(order, warning.Category)
+		}
+		counts[warning.Category]++
+	}
+
+	for _, category := range order {
+		err := metronClient.IncrementCounterWithDelta(category.MetricName(), counts[category])
+		if err != nil {
+			logger.Error("failed-to-emit-config-warning-metric", err, lager.Data{"category": category})
+		}
+	}
+}
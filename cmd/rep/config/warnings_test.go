@@ -0,0 +1,102 @@
+package config_test
+
+import (
+	"errors"
+
+	mfakes "code.cloudfoundry.org/diego-logging-client/testhelpers"
+	"code.cloudfoundry.org/lager/v3/lagertest"
+	"code.cloudfoundry.org/rep/cmd/rep/config"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("Warnings", func() {
+	var repConfig config.RepConfig
+
+	BeforeEach(func() {
+		repConfig = config.RepConfig{RequireMetron: true}
+	})
+
+	It("has no warnings for a clean config", func() {
+		Expect(repConfig.Warnings()).To(BeEmpty())
+	})
+
+	It("warns about each deprecated field that is set", func() {
+		repConfig.BBSCACertFile = "/bbs/ca.crt"
+		repConfig.ServerCertFile = "/server.crt"
+
+		warnings := repConfig.Warnings()
+		Expect(warnings).To(HaveLen(2))
+		for _, warning := range warnings {
+			Expect(warning.Category).To(Equal(config.WarningCategoryDeprecatedField))
+		}
+		Expect(warnings[0].Message).To(ContainSubstring("bbs_ca_cert_file"))
+		Expect(warnings[1].Message).To(ContainSubstring("server_cert_file"))
+	})
+
+	It("warns about risky values", func() {
+		repConfig.RequireMetron = false
+		repConfig.MemoryPressureEvictionEnabled = true
+		repConfig.MemoryPressureEvictionThreshold = 0.3
+		repConfig.DiskHealthCheckFailureThreshold = 1
+
+		warnings := repConfig.Warnings()
+		Expect(warnings).To(HaveLen(3))
+		for _, warning := range warnings {
+			Expect(warning.Category).To(Equal(config.WarningCategoryRiskyValue))
+		}
+	})
+
+	Describe("ReportWarnings", func() {
+		var (
+			logger       *lagertest.TestLogger
+			metronClient *mfakes.FakeIngressClient
+		)
+
+		BeforeEach(func() {
+			logger = lagertest.NewTestLogger("test")
+			metronClient = new(mfakes.FakeIngressClient)
+		})
+
+		It("increments one counter per category by the number of warnings in it", func() {
+			config.ReportWarnings(logger, metronClient, []config.Warning{
+				{Category: config.WarningCategoryDeprecatedField, Message: "a"},
+				{Category: config.WarningCategoryRiskyValue, Message: "b"},
+				{Category: config.WarningCategoryDeprecatedField, Message: "c"},
+			})
+
+			Expect(metronClient.IncrementCounterWithDeltaCallCount()).To(Equal(2))
+			name, delta := metronClient.IncrementCounterWithDeltaArgsForCall(0)
+			Expect(name).To(Equal("RepConfigWarningDeprecatedField"))
+			Expect(delta).To(Equal(uint64(2)))
+			name, delta = metronClient.IncrementCounterWithDeltaArgsForCall(1)
+			Expect(name).To(Equal("RepConfigWarningRiskyValue"))
+			Expect(delta).To(Equal(uint64(1)))
+		})
+
+		It("logs each warning", func() {
+			config.ReportWarnings(logger, metronClient, []config.Warning{
+				{Category: config.WarningCategoryRiskyValue, Message: "something risky"},
+			})
+
+			Expect(logger).To(gbytes.Say("config-warning.*something risky"))
+		})
+
+		It("emits nothing when there are no warnings", func() {
+			config.ReportWarnings(logger, metronClient, nil)
+			Expect(metronClient.IncrementCounterWithDeltaCallCount()).To(BeZero())
+		})
+
+		It("keeps going when emitting a counter fails", func() {
+			metronClient.IncrementCounterWithDeltaReturns(errors.New("boom"))
+			config.ReportWarnings(logger, metronClient, []config.Warning{
+				{Category: config.WarningCategoryDeprecatedField, Message: "a"},
+				{Category: config.WarningCategoryRiskyValue, Message: "b"},
+			})
+
+			Expect(metronClient.IncrementCounterWithDeltaCallCount()).To(Equal(2))
+			Expect(logger).To(gbytes.Say("failed-to-emit-config-warning-metric"))
+		})
+	})
+})
@@ -0,0 +1,33 @@
+package config
+
+import (
+	loggingclient "code.cloudfoundry.org/diego-logging-client"
+	"code.cloudfoundry.org/lager/v3"
+)
+
+const zoneOverriddenMetric = "RepZoneOverridden"
+
+// ApplyZoneOverride replaces the configured zone with the one passed on the
+// command line, if any, and returns the zone the config file set.
+func (c *RepConfig) ApplyZoneOverride(zone string) string {
+	configZone := c.Zone
+	if zone != "" {
+		c.Zone = zone
+	}
+	return configZone
+}
+
+// ReportZoneOverride logs and counts the cell running in a different zone
+// than its config file set. An override naming the configured zone is not
+// reported.
+func ReportZoneOverride(logger lager.Logger, metronClient loggingclient.IngressClient, configZone, zone string) {
+	if configZone == zone {
+		return
+	}
+
+	logger.Info("zone-overridden", lager.Data{"config-zone": configZone, "zone": zone})
+	err := metronClient.IncrementCounter(zoneOverriddenMetric)
+	if err != nil {
+		logger.Error("failed-to-emit-zone-overridden-metric", err)
+	}
+}
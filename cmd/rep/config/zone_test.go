@@ -0,0 +1,66 @@
+package config_test
+
+import (
+	"errors"
+
+	mfakes "code.cloudfoundry.org/diego-logging-client/testhelpers"
+	"code.cloudfoundry.org/lager/v3/lagertest"
+	"code.cloudfoundry.org/rep/cmd/rep/config"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("Zone", func() {
+	Describe("ApplyZoneOverride", func() {
+		var repConfig config.RepConfig
+
+		BeforeEach(func() {
+			repConfig = config.RepConfig{Zone: "z1"}
+		})
+
+		It("replaces the zone and returns the configured one", func() {
+			Expect(repConfig.ApplyZoneOverride("z2")).To(Equal("z1"))
+			Expect(repConfig.Zone).To(Equal("z2"))
+		})
+
+		It("keeps the configured zone without an override", func() {
+			Expect(repConfig.ApplyZoneOverride("")).To(Equal("z1"))
+			Expect(repConfig.Zone).To(Equal("z1"))
+		})
+	})
+
+	Describe("ReportZoneOverride", func() {
+		var (
+			logger       *lagertest.TestLogger
+			metronClient *mfakes.FakeIngressClient
+		)
+
+		BeforeEach(func() {
+			logger = lagertest.NewTestLogger("test")
+			metronClient = new(mfakes.FakeIngressClient)
+		})
+
+		It("logs both zones and counts the override when it changes the zone", func() {
+			config.ReportZoneOverride(logger, metronClient, "z1", "z2")
+
+			Expect(logger).To(gbytes.Say(`zone-overridden.*"config-zone":"z1".*"zone":"z2"`))
+			Expect(metronClient.IncrementCounterCallCount()).To(Equal(1))
+			Expect(metronClient.IncrementCounterArgsForCall(0)).To(Equal("RepZoneOverridden"))
+		})
+
+		It("reports nothing when the override names the configured zone", func() {
+			config.ReportZoneOverride(logger, metronClient, "z1", "z1")
+
+			Expect(logger).NotTo(gbytes.Say("zone-overridden"))
+			Expect(metronClient.IncrementCounterCallCount()).To(BeZero())
+		})
+
+		It("logs when emitting the metric fails", func() {
+			metronClient.IncrementCounterReturns(errors.New("boom"))
+			config.ReportZoneOverride(logger, metronClient, "z1", "z2")
+
+			Expect(logger).To(gbytes.Say("failed-to-emit-zone-overridden-metric"))
+		})
+	})
+})
@@ -3,17 +3,15 @@ package main
 import (
 	"crypto/tls"
 	"crypto/x509"
-	"encoding/json"
 	"encoding/pem"
 	"errors"
 	"flag"
 	"fmt"
-	"io/fs"
 	"maps"
 	"net"
 	"net/http"
+	"net/url"
 	"os"
-	"path/filepath"
 	"strings"
 	"syscall"
 	"time"
@@ -33,16 +31,24 @@ import (
 	"code.cloudfoundry.org/locket/lock"
 	"code.cloudfoundry.org/locket/metrics/helpers"
 	locketmodels "code.cloudfoundry.org/locket/models"
-	"code.cloudfoundry.org/operationq"
 	"code.cloudfoundry.org/rep"
 	"code.cloudfoundry.org/rep/auctioncellrep"
+	"code.cloudfoundry.org/rep/clockskew"
 	"code.cloudfoundry.org/rep/cmd/rep/config"
 	"code.cloudfoundry.org/rep/diskcheck"
 	"code.cloudfoundry.org/rep/evacuation"
 	"code.cloudfoundry.org/rep/evacuation/evacuation_context"
+	"code.cloudfoundry.org/rep/eviction"
+	"code.cloudfoundry.org/rep/fdusage"
 	"code.cloudfoundry.org/rep/generator"
 	"code.cloudfoundry.org/rep/handlers"
 	"code.cloudfoundry.org/rep/harmonizer"
+	"code.cloudfoundry.org/rep/heartbeat"
+	"code.cloudfoundry.org/rep/periodic"
+	"code.cloudfoundry.org/rep/presence"
+	"code.cloudfoundry.org/rep/sessiontickets"
+	"code.cloudfoundry.org/rep/shutdown"
+	"code.cloudfoundry.org/rep/uptime"
 	"code.cloudfoundry.org/tlsconfig"
 	uuid "github.com/nu7hatch/gouuid"
 	"github.com/tedsuo/ifrit"
@@ -64,16 +70,16 @@ var zoneOverride = flag.String(
 )
 
 func main() {
+	processStartedAt := time.Now()
 	flag.Parse()
 
 	repConfig, err := config.NewRepConfig(*configFilePath)
 	if err != nil {
-		panic(err.Error())
+		fmt.Fprintf(os.Stderr, "failed to load config: %s\n", err)
+		os.Exit(1)
 	}
 
-	if *zoneOverride != "" {
-		repConfig.Zone = *zoneOverride
-	}
+	configZone := repConfig.ApplyZoneOverride(*zoneOverride)
 
 	clock := clock.NewClock()
 	logger, reconfigurableSink := lagerflags.NewFromConfig(repConfig.SessionName, repConfig.LagerConfig)
@@ -87,12 +93,67 @@ func main() {
 		os.Exit(1)
 	}
 
+	if repConfig.TLSMinVersion != "" {
+		_, err := config.ParseTLSVersion(repConfig.TLSMinVersion)
+		if err != nil {
+			logger.Fatal("invalid-tls-min-version", err)
+		}
+	}
+
+	reconciliationPolicy, err := generator.ParseReconciliationPolicy(repConfig.ReconciliationPolicy)
+	if err != nil {
+		logger.Fatal("invalid-reconciliation-policy", err)
+	}
+
+	startupTaskPolicy, err := generator.ParseStartupTaskPolicy(repConfig.StartupTaskPolicy)
+	if err != nil {
+		logger.Fatal("invalid-startup-task-policy", err)
+	}
+
+	removedRootFSPolicy, err := generator.ParseRemovedRootFSPolicy(repConfig.RemovedRootFSPolicy)
+	if err != nil {
+		logger.Fatal("invalid-removed-rootfs-policy", err)
+	}
+
+	orphanContainerPolicy, err := generator.ParseOrphanContainerPolicy(repConfig.OrphanContainerPolicy)
+	if err != nil {
+		logger.Fatal("invalid-orphan-container-policy", err)
+	}
+
+	allowedPortRange, err := generator.ParsePortRange(repConfig.AllowedPortRange)
+	if err != nil {
+		logger.Fatal("invalid-allowed-port-range", err)
+	}
+
+	batchAllocationPolicy, err := auctioncellrep.ParseBatchAllocationPolicy(repConfig.BatchAllocationPolicy)
+	if err != nil {
+		logger.Fatal("invalid-batch-allocation-policy", err)
+	}
+
+	shutdownActions, err := evacuation.ParseShutdownActions(repConfig.ShutdownSignalActions)
+	if err != nil {
+		logger.Fatal("invalid-shutdown-signal-actions", err)
+	}
+
+	err = config.ValidateListenAddrs(repConfig.ListenAddr, repConfig.ListenAddrSecurable)
+	if err != nil {
+		logger.Fatal("conflicting-listen-addresses", err)
+	}
+
 	metronClient, err := initializeMetron(logger, repConfig)
 	if err != nil {
-		logger.Error("failed-to-initialize-metron-client", err)
-		os.Exit(1)
+		if repConfig.RequireMetron {
+			logger.Error("failed-to-initialize-metron-client", err)
+			os.Exit(1)
+		}
+
+		logger.Error("failed-to-initialize-metron-client-continuing-without-metrics", err)
+		metronClient = noopMetronClient()
 	}
 
+	config.ReportWarnings(logger, metronClient, repConfig.Warnings())
+	config.ReportZoneOverride(logger, metronClient, configZone, repConfig.Zone)
+
 	rootFSMap := repConfig.PreloadedRootFS.StackPathMap()
 	sidecarRootFSPath := repConfig.SidecarRootFSPath
 	sidecarRootFS := repConfig.SidecarRootFS
@@ -105,22 +166,32 @@ func main() {
 	}
 
 	preloadedRootFSes := rep.StackPathMap(maps.Clone(rootFSMap))
-	extraRootFSes := make(rep.StackPathMap)
-	walkDirErr := filepath.WalkDir(repConfig.ExtraRootfsDir, func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			return err
+	extraRootFSes, extraRootFSLoads, walkDirErr := rep.LoadExtraRootFSes(repConfig.ExtraRootfsDir, repConfig.MaxExtraRootFS)
+	maxExtraRootFSReached := false
+	for _, load := range extraRootFSLoads {
+		switch {
+		case load.Loaded:
+			rootFSMap[load.Name] = load.Path
+			delete(preloadedRootFSes, load.Name)
+		case load.Reason == rep.ExtraRootFSMaxReachedReason:
+			if !maxExtraRootFSReached {
+				maxExtraRootFSReached = true
+				logger.Info("max-extra-rootfs-reached", lager.Data{
+					"extra-rootfs-dir": repConfig.ExtraRootfsDir,
+					"max-extra-rootfs": repConfig.MaxExtraRootFS,
+					"first-skipped":    load.Path,
+				})
+			}
+		default:
+			logger.Info("extra-rootfs-not-loaded", lager.Data{"path": load.Path, "reason": load.Reason})
 		}
-		ext := filepath.Ext(path)
-		if strings.EqualFold(ext, ".tar") {
-			key := strings.TrimSuffix(filepath.Base(path), ext)
-			rootFSMap[key] = path
-			delete(preloadedRootFSes, key)
-			extraRootFSes[key] = path
-		}
-		return nil
-	})
+	}
 	if walkDirErr != nil {
+		if repConfig.RequireExtraRootfsDir {
+			logger.Fatal("missing-extra-rootfs", walkDirErr, lager.Data{"extra-rootfs-dir": repConfig.ExtraRootfsDir})
+		}
 		logger.Debug("missing-extra-rootfs", lager.Data{"error": walkDirErr})
+		extraRootFSLoads = []rep.ExtraRootFSLoad{}
 	}
 
 	if sidecarRootFSPath == "" && sidecarRootFS != "" {
@@ -159,13 +230,19 @@ func main() {
 		time.Sleep(2 * time.Second)
 		executorClient, containerMetricsProvider, executorMembers, err = executorinit.Initialize(logger, repConfig.ExecutorConfig, repConfig.CellID, repConfig.Zone, rootFSMap, sidecarRootFSPath, metronClient, clock)
 	}
-	defer executorClient.Cleanup(logger)
+	defer shutdown.CleanupExecutor(logger, clock, executorClient, time.Duration(repConfig.ExecutorCleanupTimeout))
 
 	evacuatable, evacuationReporter, evacuationNotifier := evacuation_context.New()
 
 	// only one outstanding operation per container is necessary
-	queue := operationq.NewSlidingQueue(1)
+	queue := harmonizer.NewBoundedQueue(repConfig.GeneratorConcurrency)
 
+	evacuationHistorySize := repConfig.EvacuationHistorySize
+	if evacuationHistorySize <= 0 {
+		evacuationHistorySize = 10
+		logger.Info("evacuation-history-size-defaulted", lager.Data{"size": evacuationHistorySize})
+	}
+	evacuationHistory := evacuation.NewHistory(evacuationHistorySize)
 	evacuator := evacuation.NewEvacuator(
 		logger,
 		clock,
@@ -174,13 +251,73 @@ func main() {
 		repConfig.CellID,
 		time.Duration(repConfig.EvacuationTimeout),
 		time.Duration(repConfig.EvacuationPollingInterval),
+		repConfig.EvacuationExcludedDomains,
+		evacuationHistory,
 	)
 
-	bbsClient := initializeBBSClient(logger, repConfig)
+	bbsClientConfig := newBBSClientConfig(repConfig)
+	bbsClient := initializeBBSClient(logger, bbsClientConfig)
 	url := repURL(repConfig)
+	if repConfig.VerifyAdvertiseDomain {
+		verifyRepURLResolves(logger, url, repConfig.RequireResolvableAdvertiseDomain)
+	}
 	address := repAddress(logger, repConfig)
-	cellPresence := initializeCellPresence(address, executorClient, logger, repConfig, preloadedRootFSesWithVersions, extraRootFSesWithVersions, url)
-	batchContainerAllocator := auctioncellrep.NewContainerAllocator(auctioncellrep.GenerateGuid, rootFSMap, executorClient)
+	capacityFactor := auctioncellrep.NewCapacityFactor()
+	verifyCellCapacity(logger, executorClient, repConfig)
+	locketClient, err := locket.NewClient(logger, repConfig.ClientLocketConfig)
+	if err != nil {
+		logger.Fatal("failed-to-construct-locket-client", err)
+	}
+	presenceOwner, err := uuid.NewV4()
+	if err != nil {
+		logger.Fatal("failed-to-generate-guid", err)
+	}
+	auditingLocketClient := presence.NewAuditingLocketClient(logger, clock, metronClient, locketClient, repConfig.CellID, presenceOwner.String())
+	cellPresence := presence.NewRegistrar(logger, func() (ifrit.Runner, models.CellPresence, error) {
+		return initializeCellPresence(address, auditingLocketClient, presenceOwner.String(), executorClient, logger, repConfig, preloadedRootFSesWithVersions, extraRootFSesWithVersions, url, capacityFactor)
+	})
+	rootFSQuarantine := auctioncellrep.NewRootFSQuarantine(repConfig.RootFSFailureThreshold, time.Duration(repConfig.RootFSQuarantineDuration), rootFSMap, clock, metronClient)
+	var clockSkewMonitor *clockskew.Monitor
+	var clockSkewReporter auctioncellrep.ClockSkewReporter
+	if repConfig.ClockSkewReferenceURL != "" {
+		clockSkewInterval := time.Duration(repConfig.ClockSkewCheckInterval)
+		if clockSkewInterval <= 0 {
+			clockSkewInterval = time.Minute
+			logger.Info("clock-skew-check-interval-defaulted", lager.Data{"interval": clockSkewInterval.String()})
+		}
+		clockSkewThreshold := time.Duration(repConfig.ClockSkewThreshold)
+		if clockSkewThreshold <= 0 {
+			clockSkewThreshold = 30 * time.Second
+			logger.Info("clock-skew-threshold-defaulted", lager.Data{"threshold": clockSkewThreshold.String()})
+		}
+		clockSkewMonitor = clockskew.NewMonitor(
+			logger,
+			clock,
+			clockSkewInterval,
+			clockSkewThreshold,
+			metronClient,
+			clockskew.HTTPDateReference(&http.Client{Timeout: 10 * time.Second}, repConfig.ClockSkewReferenceURL),
+		)
+		if repConfig.RefuseAuctionsOnClockSkew {
+			clockSkewReporter = clockSkewMonitor
+		}
+	}
+
+	allocationRetryInterval := time.Duration(repConfig.AllocationRetryInterval)
+	if allocationRetryInterval <= 0 {
+		allocationRetryInterval = 100 * time.Millisecond
+		logger.Info("allocation-retry-interval-defaulted", lager.Data{"interval": allocationRetryInterval.String()})
+	}
+	batchContainerAllocator := auctioncellrep.NewContainerAllocator(auctioncellrep.GenerateGuid, rootFSMap, executorClient, metronClient, clock, auctioncellrep.ContainerAllocatorOptions{
+		MaxPerTaskDiskMB:    repConfig.MaxPerTaskDiskMB,
+		ProxyMemoryByRootFS: repConfig.ProxyMemoryByRootFS,
+		MaxInstancesPerLRP:  repConfig.MaxInstancesPerLRP,
+		AllowedDomains:      repConfig.AllowedDomains,
+		RootFSQuarantine:    rootFSQuarantine,
+		AllocationRetries:   repConfig.AllocationRetries,
+		RetryInterval:       allocationRetryInterval,
+		BatchPolicy:         batchAllocationPolicy,
+	})
 	auctionCellRep := auctioncellrep.New(
 		repConfig.CellID,
 		repConfig.CellIndex,
@@ -196,14 +333,93 @@ func main() {
 		repConfig.ProxyMemoryAllocationMB,
 		repConfig.EnableContainerProxy,
 		batchContainerAllocator,
+		clock,
+		metronClient,
+		auctioncellrep.Options{
+			ProxyMemoryByRootFS:   repConfig.ProxyMemoryByRootFS,
+			MetricsWarmupPeriod:   time.Duration(repConfig.MetricsWarmupPeriod),
+			RootFSQuarantine:      rootFSQuarantine,
+			StateCacheTTL:         time.Duration(repConfig.StateCacheTTL),
+			ClockSkewReporter:     clockSkewReporter,
+			CapacityFactor:        capacityFactor,
+			SegmentLimits:         repConfig.IsolationSegmentLimits,
+			BatchAllocationPolicy: batchAllocationPolicy,
+		},
 	)
 
 	requestTypes := []string{
-		"State", "ContainerMetrics", "Perform", "Reset", "UpdateLRPInstance", "StopLRPInstance", "CancelTask", // over https only
+		"State", "ContainerMetrics", "Perform", "Reset", "UpdateLRPInstance", "StopLRPInstance", "CancelTask", "Domains", "Tasks", "SupportedProviders", "CancelTasksByDomain", "LRPUsage", // over https only
 	}
 	requestMetrics := helpers.NewRequestMetricsNotifier(logger, clock, metronClient, time.Duration(repConfig.ReportInterval), requestTypes)
-	httpServer := initializeServer(auctionCellRep, executorClient, evacuatable, requestMetrics, logger, repConfig, false)
-	httpsServer := initializeServer(auctionCellRep, executorClient, evacuatable, requestMetrics, logger, repConfig, true)
+	uptimeTracker, err := uptime.NewTracker(logger, clock, repConfig.RestartCountFile)
+	if err != nil {
+		logger.Fatal("failed-to-track-uptime", err)
+	}
+
+	var decisionWebhook *auctioncellrep.DecisionWebhook
+	var decisionNotifier handlers.DecisionNotifier
+	if repConfig.DecisionWebhookURL != "" {
+		decisionWebhook = auctioncellrep.NewDecisionWebhook(logger, clock, &http.Client{Timeout: 10 * time.Second}, repConfig.DecisionWebhookURL, repConfig.CellID)
+		decisionNotifier = decisionWebhook
+	}
+
+	var allocationHistory handlers.AllocationHistory
+	var allocationStateKeeper *auctioncellrep.AllocationStateKeeper
+	if repConfig.AllocationHistorySize > 0 {
+		history := auctioncellrep.NewAllocationHistory(clock, repConfig.AllocationHistorySize)
+		allocationHistory = history
+		if repConfig.AllocationStateFile != "" {
+			allocationStateKeeper = auctioncellrep.NewAllocationStateKeeper(logger, history, executorClient, repConfig.AllocationStateFile)
+		}
+		if decisionNotifier != nil {
+			decisionNotifier = handlers.DecisionNotifiers{decisionNotifier, history}
+		} else {
+			decisionNotifier = history
+		}
+	}
+
+	lastCallers := handlers.NewLastCallers(clock)
+	readOnlyMode := handlers.NewReadOnlyMode(repConfig.ReadOnlyMode)
+	if repConfig.ReadOnlyMode {
+		logger.Info("starting-in-read-only-mode")
+	}
+	handlerOptions := handlers.Options{
+		MaxPlacementTagsPerRequest: repConfig.MaxPlacementTagsPerRequest,
+		PresenceRegistrar:          cellPresence,
+		UptimeReporter:             uptimeTracker,
+		SupportedProviders:         repConfig.SupportedProviders,
+		EvacuationHistory:          evacuationHistory,
+		EvacuationReporter:         evacuationReporter,
+		CapacityFactor:             capacityFactor,
+		StateInvalidator:           auctionCellRep,
+		DecisionNotifier:           decisionNotifier,
+		LastCallers:                lastCallers,
+		ReadOnlyMode:               readOnlyMode,
+		AllocationHistory:          allocationHistory,
+		BBSClientInfo:              handlers.NewBBSClientInfo(bbsClientConfig),
+		ExtraRootFSLoads:           extraRootFSLoads,
+	}
+	httpServer := initializeServer(auctionCellRep, executorClient, evacuatable, requestMetrics, handlerOptions, logger, repConfig, false)
+	httpsServer := initializeServer(auctionCellRep, executorClient, evacuatable, requestMetrics, handlerOptions, logger, repConfig, true)
+
+	var orphanContainerReaper *generator.OrphanContainerReaper
+	if repConfig.OrphanContainerGracePeriod > 0 {
+		orphanContainerReaper = generator.NewOrphanContainerReaper(clock, time.Duration(repConfig.OrphanContainerGracePeriod), orphanContainerPolicy)
+	}
+
+	var stuckCreatingDetector *generator.StuckCreatingDetector
+	if repConfig.MaxCreatingDuration > 0 {
+		stuckCreatingDetector = generator.NewStuckCreatingDetector(clock, time.Duration(repConfig.MaxCreatingDuration))
+	}
+
+	var rootFSMountRecorder generator.RootFSMountRecorder = rootFSQuarantine
+	if repConfig.EmitRootFSCacheHitRate {
+		if repConfig.LayeringMode == rep.LayeringModeTwoLayer {
+			rootFSMountRecorder = generator.RootFSMountRecorders{rootFSQuarantine, generator.NewRootFSCacheTracker(metronClient)}
+		} else {
+			logger.Info("not-emitting-rootfs-cache-hit-rate-without-two-layer-mode", lager.Data{"layering-mode": repConfig.LayeringMode})
+		}
+	}
 
 	opGenerator := generator.New(
 		repConfig.CellID,
@@ -214,6 +430,19 @@ func main() {
 		executorClient,
 		metronClient,
 		evacuationReporter,
+		generator.Options{
+			ReconciliationPolicy:      reconciliationPolicy,
+			StartupTaskPolicy:         startupTaskPolicy,
+			MaxExecutorRejections:     repConfig.MaxExecutorRejections,
+			AllowPrivilegedContainers: repConfig.AllowPrivilegedContainers,
+			RootFSMountRecorder:       rootFSMountRecorder,
+			StateInvalidator:          auctionCellRep,
+			RemovedRootFSPolicy:       removedRootFSPolicy,
+			OrphanReaper:              orphanContainerReaper,
+			AllowedPortRange:          allowedPortRange,
+			StuckCreating:             stuckCreatingDetector,
+			EvacuationExcludedDomains: repConfig.EvacuationExcludedDomains,
+		},
 	)
 
 	cleanup := evacuation.NewEvacuationCleanup(
@@ -236,6 +465,9 @@ func main() {
 		opGenerator,
 		queue,
 		metronClient,
+		repConfig.BulkSyncMaxRetries,
+		time.Duration(repConfig.BulkSyncRetryInterval),
+		repConfig.MaxOperationsPerBulkLoop,
 	)
 
 	members := grouper.Members{
@@ -244,13 +476,41 @@ func main() {
 		{Name: "https_server", Runner: httpsServer},
 		{Name: "evacuation-cleanup", Runner: cleanup},
 		{Name: "bulker", Runner: bulker},
-		{Name: "event-consumer", Runner: harmonizer.NewEventConsumer(logger, opGenerator, queue)},
+		{Name: "event-consumer", Runner: harmonizer.NewEventConsumer(logger, opGenerator, queue, clock, time.Duration(repConfig.EventBatchWindow))},
 		{Name: "evacuator", Runner: evacuator},
 		{Name: "request-metrics-notifier", Runner: requestMetrics},
 	}
 
+	if allocationStateKeeper != nil {
+		members = append(grouper.Members{{Name: "allocation-state-keeper", Runner: allocationStateKeeper}}, members...)
+	}
+
 	members = append(executorMembers, members...)
 
+	if repConfig.HeartbeatInterval > 0 {
+		beater := heartbeat.NewBeater(logger, executorClient, repConfig.CellID)
+		members = append(members, grouper.Member{Name: "heartbeat", Runner: periodic.NewRunner(clock, time.Duration(repConfig.HeartbeatInterval), beater.Beat)})
+	}
+
+	if repConfig.MemoryPressureEvictionEnabled {
+		evictionInterval := time.Duration(repConfig.MemoryPressureCheckInterval)
+		if evictionInterval <= 0 {
+			evictionInterval = 10 * time.Second
+			logger.Info("memory-pressure-check-interval-defaulted", lager.Data{"interval": evictionInterval.String()})
+		}
+		evictor := eviction.NewEvictor(
+			logger,
+			clock,
+			executorClient,
+			containerMetricsProvider,
+			bbsClient,
+			repConfig.CellID,
+			evictionInterval,
+			repConfig.MemoryPressureEvictionThreshold,
+		)
+		members = append(members, grouper.Member{Name: "memory-pressure-evictor", Runner: evictor})
+	}
+
 	if len(repConfig.DiskHealthCheckPaths) > 0 {
 		diskInterval := time.Duration(repConfig.DiskHealthCheckInterval)
 		if diskInterval <= 0 {
@@ -274,6 +534,41 @@ func main() {
 		members = append(members, grouper.Member{Name: "disk-check", Runner: diskCheckRunner})
 	}
 
+	if clockSkewMonitor != nil {
+		members = append(members, grouper.Member{Name: "clock-skew-monitor", Runner: clockSkewMonitor})
+	}
+
+	if repConfig.PlacementFairnessReportInterval > 0 {
+		placementFairnessReporter := auctioncellrep.NewPlacementFairnessReporter(logger, executorClient, metronClient)
+		members = append(members, grouper.Member{Name: "placement-fairness-reporter", Runner: periodic.NewRunner(clock, time.Duration(repConfig.PlacementFairnessReportInterval), placementFairnessReporter.Report)})
+	}
+
+	if repConfig.ContainerStateReportInterval > 0 {
+		containerStateReporter := auctioncellrep.NewContainerStateReporter(logger, executorClient, metronClient)
+		members = append(members, grouper.Member{Name: "container-state-reporter", Runner: periodic.NewRunner(clock, time.Duration(repConfig.ContainerStateReportInterval), containerStateReporter.Report)})
+	}
+
+	if repConfig.ContainerAgeReportInterval > 0 {
+		ageBuckets := make([]time.Duration, 0, len(repConfig.ContainerAgeBuckets))
+		for _, bucket := range repConfig.ContainerAgeBuckets {
+			ageBuckets = append(ageBuckets, time.Duration(bucket))
+		}
+		containerAgeReporter, err := auctioncellrep.NewContainerAgeReporter(logger, clock, ageBuckets, executorClient, metronClient)
+		if err != nil {
+			logger.Fatal("invalid-container-age-buckets", err)
+		}
+		members = append(members, grouper.Member{Name: "container-age-reporter", Runner: periodic.NewRunner(clock, time.Duration(repConfig.ContainerAgeReportInterval), containerAgeReporter.Report)})
+	}
+
+	if repConfig.FileDescriptorReportInterval > 0 {
+		fdUsageReporter := fdusage.NewReporter(logger, metronClient)
+		members = append(members, grouper.Member{Name: "fd-usage-reporter", Runner: periodic.NewRunner(clock, time.Duration(repConfig.FileDescriptorReportInterval), fdUsageReporter.Report)})
+	}
+
+	if decisionWebhook != nil {
+		members = append(members, grouper.Member{Name: "decision-webhook", Runner: decisionWebhook})
+	}
+
 	if repConfig.DebugAddress != "" {
 		members = append(grouper.Members{
 			{Name: "debug-server", Runner: debugserver.Runner(repConfig.DebugAddress, reconfigurableSink)},
@@ -282,9 +577,18 @@ func main() {
 
 	group := grouper.NewOrdered(os.Interrupt, members)
 
-	monitor := ifrit.Invoke(sigmon.New(group))
+	monitor := ifrit.Invoke(sigmon.New(evacuation.NewSignalRouter(logger, group, evacuatable, shutdownActions)))
 
 	logger.Info("started", lager.Data{"cell-id": repConfig.CellID})
+	if repConfig.EmitStartupDurationMetric {
+		// Invoke also returns when the group exits before it is ready, and
+		// that startup never completed
+		select {
+		case <-monitor.Ready():
+			uptime.ReportStartupComplete(logger, clock, metronClient, processStartedAt)
+		default:
+		}
+	}
 
 	err = <-monitor.Wait()
 	if err != nil {
@@ -295,57 +599,87 @@ func main() {
 	logger.Info("exited")
 }
 
+// verifyCellCapacity fails fast when the executor reports no capacity at
+// all, unless that is allowed.
+func verifyCellCapacity(logger lager.Logger, executorClient executor.Client, repConfig config.RepConfig) {
+	resources, err := executorClient.TotalResources(logger)
+	if err != nil {
+		logger.Fatal("failed-to-get-total-resources", err)
+	}
+	err = verifyTotalCapacity(resources)
+	if err != nil {
+		if !repConfig.AllowZeroCapacity {
+			logger.Fatal("zero-total-capacity", err)
+		}
+		logger.Info("allowing-zero-total-capacity", lager.Data{"error": err.Error()})
+	}
+}
+
+// initializeCellPresence builds the cell presence. It runs again whenever the
+// presence is reregistered, so it returns errors rather than exiting, and
+// every presence it builds shares locketClient and owner so a rebuilt one can
+// take over the lock from its predecessor.
 func initializeCellPresence(
 	address string,
+	locketClient locketmodels.LocketClient,
+	owner string,
 	executorClient executor.Client,
 	logger lager.Logger,
 	repConfig config.RepConfig,
 	preloadedRootFSesWithVersions []string,
 	extraRootFSesWithVersions []string,
 	repUrl string,
-) ifrit.Runner {
-	locketClient, err := locket.NewClient(logger, repConfig.ClientLocketConfig)
-	if err != nil {
-		logger.Fatal("failed-to-construct-locket-client", err)
-	}
-
-	guid, err := uuid.NewV4()
-	if err != nil {
-		logger.Fatal("failed-to-generate-guid", err)
-	}
-
+	capacityFactor *auctioncellrep.CapacityFactor,
+) (ifrit.Runner, models.CellPresence, error) {
 	resources, err := executorClient.TotalResources(logger)
 	if err != nil {
-		logger.Fatal("failed-to-get-total-resources", err)
+		logger.Error("failed-to-get-total-resources", err)
+		return nil, models.CellPresence{}, err
 	}
+	resources = capacityFactor.ScaleTotal(resources)
 	cellCapacity := models.NewCellCapacity(int32(resources.MemoryMB), int32(resources.DiskMB), int32(resources.Containers))
+	annotations := presence.AnnotateFeatureFlags(repConfig.CellAnnotations, repConfig.FeatureFlags)
+	annotations = presence.AnnotateRegistryMirror(annotations, repConfig.LocalRegistryMirror)
+	annotations = presence.AnnotatePrivilegedContainers(annotations, repConfig.AllowPrivilegedContainers)
+	annotations = presence.AnnotateSecurityProfiles(annotations, repConfig.SupportedSecurityProfiles)
 	cellPresence := models.NewCellPresence(repConfig.CellID, address, repUrl,
 		repConfig.Zone, cellCapacity, repConfig.SupportedProviders,
 		preloadedRootFSesWithVersions, extraRootFSesWithVersions, repConfig.PlacementTags, repConfig.OptionalPlacementTags,
-		repConfig.CellAnnotations)
-
-	payload, err := json.Marshal(cellPresence)
+		annotations)
+
+	value, cellPresence, err := presence.EncodePresence(logger, cellPresence,
+		repConfig.CompressPresencePayload, repConfig.PresencePayloadCompressionThreshold,
+		presence.PayloadSizeLimit{
+			WarningThreshold: repConfig.PresencePayloadSizeWarningThreshold,
+			Trim:             repConfig.TrimOversizedPresencePayload,
+		})
 	if err != nil {
-		logger.Fatal("failed-to-encode-cell-presence", err)
+		logger.Error("failed-to-encode-cell-presence", err)
+		return nil, models.CellPresence{}, err
 	}
 
 	lockPayload := &locketmodels.Resource{
 		Key:      repConfig.CellID,
-		Owner:    guid.String(),
-		Value:    string(payload),
+		Owner:    owner,
+		Value:    value,
 		TypeCode: locketmodels.PRESENCE,
 		Type:     locketmodels.PresenceType,
 	}
 
 	logger.Debug("presence-payload", lager.Data{"payload": lockPayload})
-	return lock.NewPresenceRunner(
+	handoffLocketClient := presence.NewHandoffLocketClient(locketClient)
+
+	lockTTLInSeconds := int64(time.Duration(repConfig.LockTTL) / time.Second)
+	presenceRunner := lock.NewPresenceRunner(
 		logger,
-		locketClient,
+		handoffLocketClient,
 		lockPayload,
-		int64(time.Duration(repConfig.LockTTL)/time.Second),
+		lockTTLInSeconds,
 		clock.NewClock(),
 		locket.RetryInterval,
 	)
+
+	return presence.NewRenewingRunner(presenceRunner, handoffLocketClient, lockPayload, lockTTLInSeconds), cellPresence, nil
 }
 
 func initializeServer(
@@ -353,24 +687,18 @@ func initializeServer(
 	executorClient executor.Client,
 	evacuatable evacuation_context.Evacuatable,
 	requestMetrics helpers.RequestMetrics,
+	handlerOptions handlers.Options,
 	logger lager.Logger,
 	repConfig config.RepConfig,
 	networkAccessible bool,
 ) ifrit.Runner {
-	handlers := handlers.New(auctionCellRep, auctionCellRep, executorClient, evacuatable, requestMetrics, logger, networkAccessible)
-	routes := rep.NewRoutes(networkAccessible)
-	router, err := rata.NewRouter(routes, handlers)
-	if err != nil {
-		logger.Fatal("failed-to-construct-router", err)
-	}
-
 	listenAddress := repConfig.ListenAddr
 	if networkAccessible {
 		listenAddress = repConfig.ListenAddrSecurable
 	}
 
 	if !networkAccessible {
-		err = verifyCertificate(repConfig.CertFile)
+		err := verifyCertificate(repConfig.CertFile, repConfig.RequiredCertSANs)
 		if err != nil {
 			logger.Fatal("tls-configuration-failed", err)
 		}
@@ -383,7 +711,44 @@ func initializeServer(
 	if err != nil {
 		logger.Fatal("tls-configuration-failed", err)
 	}
-	return startTLSServer(listenAddress, router, tlsConfig)
+
+	if repConfig.TLSMinVersion != "" {
+		minVersion, err := config.ParseTLSVersion(repConfig.TLSMinVersion)
+		if err != nil {
+			logger.Fatal("invalid-tls-min-version", err)
+		}
+		tlsConfig.MinVersion = minVersion
+		if tlsConfig.MaxVersion != 0 && tlsConfig.MaxVersion < minVersion {
+			tlsConfig.MaxVersion = minVersion
+		}
+	}
+
+	handlerOptions.TLSInfo, err = handlers.NewTLSInfo(tlsConfig, repConfig.CaCertFile)
+	if err != nil {
+		logger.Fatal("failed-to-summarize-tls-configuration", err)
+	}
+
+	handlers := handlers.WithSlowRequestLogging(
+		handlers.New(auctionCellRep, auctionCellRep, executorClient, evacuatable, requestMetrics, logger, networkAccessible, handlerOptions),
+		logger,
+		time.Duration(repConfig.SlowRequestThreshold),
+	)
+	routes := rep.NewRoutes(networkAccessible)
+	router, err := rata.NewRouter(routes, handlers)
+	if err != nil {
+		logger.Fatal("failed-to-construct-router", err)
+	}
+
+	server := startTLSServer(listenAddress, router, tlsConfig)
+	if repConfig.SessionTicketRotationInterval <= 0 {
+		return server
+	}
+
+	rotator := sessiontickets.NewRotator(logger, clock.NewClock(), tlsConfig, time.Duration(repConfig.SessionTicketRotationInterval))
+	return grouper.NewOrdered(os.Interrupt, grouper.Members{
+		{Name: "session-ticket-rotator", Runner: rotator},
+		{Name: "server", Runner: server},
+	})
 }
 
 func startTLSServer(addr string, handler http.Handler, tlsConfig *tls.Config) ifrit.Runner {
@@ -404,11 +769,8 @@ func startTLSServer(addr string, handler http.Handler, tlsConfig *tls.Config) if
 	})
 }
 
-func initializeBBSClient(
-	logger lager.Logger,
-	repConfig config.RepConfig,
-) bbs.InternalClient {
-	bbsClient, err := bbs.NewClientWithConfig(bbs.ClientConfig{
+func newBBSClientConfig(repConfig config.RepConfig) bbs.ClientConfig {
+	return bbs.ClientConfig{
 		URL:                    repConfig.BBSAddress,
 		IsTLS:                  true,
 		CAFile:                 repConfig.CaCertFile,
@@ -417,7 +779,14 @@ func initializeBBSClient(
 		ClientSessionCacheSize: repConfig.BBSClientSessionCacheSize,
 		MaxIdleConnsPerHost:    repConfig.BBSMaxIdleConnsPerHost,
 		RequestTimeout:         time.Duration(repConfig.CommunicationTimeout),
-	})
+	}
+}
+
+func initializeBBSClient(
+	logger lager.Logger,
+	bbsClientConfig bbs.ClientConfig,
+) bbs.InternalClient {
+	bbsClient, err := bbs.NewClientWithConfig(bbsClientConfig)
 	if err != nil {
 		logger.Fatal("failed-to-configure-secure-BBS-client", err)
 	}
@@ -436,6 +805,27 @@ func repURL(config config.RepConfig) string {
 	return fmt.Sprintf("https://%s.%s:%s", repHost(config.CellID), config.AdvertiseDomain, port)
 }
 
+// verifyRepURLResolves looks up the host of the URL the cell advertises, so
+// a typo in the advertise domain does not leave the cell silently
+// unreachable.
+func verifyRepURLResolves(logger lager.Logger, repURL string, required bool) {
+	logger = logger.Session("verify-rep-url")
+
+	u, err := url.Parse(repURL)
+	if err == nil {
+		_, err = net.LookupHost(u.Hostname())
+	}
+	if err != nil {
+		if required {
+			logger.Fatal("unresolvable-rep-url", err, lager.Data{"rep-url": repURL})
+		}
+		logger.Error("unresolvable-rep-url", err, lager.Data{"rep-url": repURL})
+		return
+	}
+
+	logger.Info("resolved-rep-url", lager.Data{"rep-url": repURL})
+}
+
 func repAddress(logger lager.Logger, config config.RepConfig) string {
 	ip, err := localip.LocalIP()
 	if err != nil {
@@ -459,7 +849,29 @@ func initializeMetron(logger lager.Logger, repConfig config.RepConfig) (loggingc
 	return client, nil
 }
 
-func verifyCertificate(serverCertFile string) error {
+// verifyTotalCapacity fails when the executor reports no memory, disk or
+// containers, which usually means it is misconfigured. Such a cell would
+// advertise itself but never win any work.
+func verifyTotalCapacity(resources executor.ExecutorResources) error {
+	if resources.MemoryMB > 0 && resources.DiskMB > 0 && resources.Containers > 0 {
+		return nil
+	}
+	return fmt.Errorf("executor reported zero total capacity: memory %d MB, disk %d MB, %d containers",
+		resources.MemoryMB, resources.DiskMB, resources.Containers)
+}
+
+// noopMetronClient returns a client that drops every metric. The logging
+// client hands one out whenever the v2 API is disabled.
+func noopMetronClient() loggingclient.IngressClient {
+	client, _ := loggingclient.NewIngressClient(loggingclient.Config{UseV2API: false})
+	return client
+}
+
+// defaultRequiredCertSANs are the SANs the localhost server certificate has to
+// carry when RequiredCertSANs is not configured.
+var defaultRequiredCertSANs = []string{"127.0.0.1"}
+
+func verifyCertificate(serverCertFile string, requiredSANs []string) error {
 	certBytes, err := os.ReadFile(serverCertFile)
 	if err != nil {
 		return err
@@ -484,13 +896,38 @@ func verifyCertificate(serverCertFile string) error {
 		return fmt.Errorf("failed parsing cert: %s", err)
 	}
 
-	for _, ip := range certs[0].IPAddresses {
-		if ip.Equal(net.ParseIP("127.0.0.1")) {
-			return nil
+	if len(requiredSANs) == 0 {
+		requiredSANs = defaultRequiredCertSANs
+	}
+
+	for _, san := range requiredSANs {
+		if !certificateHasSAN(certs[0], san) {
+			if net.ParseIP(san) != nil {
+				return fmt.Errorf("invalid SAN metadata. certificate needs to contain %s for IP SAN metadata.", san)
+			}
+			return fmt.Errorf("invalid SAN metadata. certificate needs to contain %s for DNS SAN metadata.", san)
+		}
+	}
+
+	return nil
+}
+
+func certificateHasSAN(cert *x509.Certificate, san string) bool {
+	if ip := net.ParseIP(san); ip != nil {
+		for _, certIP := range cert.IPAddresses {
+			if certIP.Equal(ip) {
+				return true
+			}
 		}
+		return false
 	}
 
-	return errors.New("invalid SAN metadata. certificate needs to contain 127.0.0.1 for IP SAN metadata.")
+	for _, name := range cert.DNSNames {
+		if strings.EqualFold(name, san) {
+			return true
+		}
+	}
+	return false
 }
 
 func isTransientSilkError(err error) bool {
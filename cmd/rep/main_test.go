@@ -2,6 +2,7 @@ package main_test
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -217,6 +218,7 @@ var _ = Describe("The Rep", func() {
 			EvacuationPollingInterval: durationjson.Duration(10 * time.Second),
 			LockTTL:                   durationjson.Duration(locket.DefaultSessionTTL),
 			SessionName:               "rep",
+			AllowPrivilegedContainers: true,
 
 			ClientLocketConfig: locketrunner.ClientLocketConfig(),
 		}
@@ -268,6 +270,67 @@ var _ = Describe("The Rep", func() {
 			})
 		})
 
+		Context("when the extra rootfs directory is missing", func() {
+			BeforeEach(func() {
+				repConfig.ExtraRootfsDir = "/path/that/does/not/exist"
+			})
+
+			It("logs and keeps running by default", func() {
+				Consistently(runner.Session).ShouldNot(Exit())
+			})
+
+			Context("and it is required", func() {
+				BeforeEach(func() {
+					repConfig.RequireExtraRootfsDir = true
+				})
+
+				It("logs that the directory is missing and exits non zero", func() {
+					Eventually(runner.Session).Should(Exit(2))
+					Expect(runner.Session).To(gbytes.Say("missing-extra-rootfs"))
+				})
+			})
+		})
+
+		Context("when the advertise domain is verified", func() {
+			BeforeEach(func() {
+				repConfig.VerifyAdvertiseDomain = true
+			})
+
+			Context("and the rep url resolves", func() {
+				BeforeEach(func() {
+					repConfig.RepURL = "https://localhost:9876"
+					repConfig.RequireResolvableAdvertiseDomain = true
+				})
+
+				It("logs the resolution and keeps running", func() {
+					Eventually(runner.Session).Should(gbytes.Say("resolved-rep-url"))
+					Consistently(runner.Session).ShouldNot(Exit())
+				})
+			})
+
+			Context("and the rep url does not resolve", func() {
+				BeforeEach(func() {
+					repConfig.AdvertiseDomain = "cell.invalid"
+				})
+
+				It("logs and keeps running by default", func() {
+					Eventually(runner.Session).Should(gbytes.Say("unresolvable-rep-url"))
+					Consistently(runner.Session).ShouldNot(Exit())
+				})
+
+				Context("and resolution is required", func() {
+					BeforeEach(func() {
+						repConfig.RequireResolvableAdvertiseDomain = true
+					})
+
+					It("logs that the url does not resolve and exits non zero", func() {
+						Eventually(runner.Session).Should(Exit(2))
+						Expect(runner.Session).To(gbytes.Say("unresolvable-rep-url"))
+					})
+				})
+			})
+		})
+
 		Context("when the SAN is set to localhost instead of 127.0.0.1", func() {
 			BeforeEach(func() {
 				caFile = path.Join(basePath, "dnssan-certs", "server-ca.crt")
@@ -288,6 +351,18 @@ var _ = Describe("The Rep", func() {
 			})
 		})
 
+		Context("when the server cert is missing one of the configured required SANs", func() {
+			BeforeEach(func() {
+				repConfig.RequiredCertSANs = []string{"127.0.0.1", "rep.service.cf.internal"}
+			})
+
+			It("exits with status code 2", func() {
+				Eventually(runner.Session.Buffer()).Should(gbytes.Say("tls-configuration-failed"))
+				Eventually(runner.Session.Buffer()).Should(gbytes.Say("rep.service.cf.internal"))
+				Eventually(runner.Session.ExitCode).Should(Equal(2))
+			})
+		})
+
 		Context("when the server cert does not have the correct SANs", func() {
 			BeforeEach(func() {
 				caFile = path.Join(basePath, "rouge-certs", "server-ca.crt")
@@ -334,6 +409,165 @@ var _ = Describe("The Rep", func() {
 		})
 	})
 
+	Context("when a TLS minimum version is configured", func() {
+		BeforeEach(func() {
+			fakeGarden.Start()
+		})
+
+		Context("and it is valid", func() {
+			BeforeEach(func() {
+				repConfig.TLSMinVersion = "1.3"
+			})
+
+			JustBeforeEach(func() {
+				Eventually(runner.Session, 2).Should(gbytes.Say("rep.started"))
+			})
+
+			It("refuses connections below the minimum version", func() {
+				tls12Config := client.Transport.(*http.Transport).TLSClientConfig.Clone()
+				tls12Config.MaxVersion = tls.VersionTLS12
+				tls12Client := &http.Client{Transport: &http.Transport{TLSClientConfig: tls12Config}}
+				_, err := tls12Client.Get(fmt.Sprintf("https://127.0.0.1:%d/state", serverPortSecurable))
+				Expect(err).To(HaveOccurred())
+
+				resp, err := client.Get(fmt.Sprintf("https://127.0.0.1:%d/state", serverPortSecurable))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			})
+		})
+
+		Context("and it is invalid", func() {
+			BeforeEach(func() {
+				repConfig.TLSMinVersion = "1.0"
+			})
+
+			It("fails fast at startup", func() {
+				Eventually(runner.Session.Buffer()).Should(gbytes.Say("invalid-tls-min-version"))
+				Eventually(runner.Session.ExitCode).Should(Equal(2))
+			})
+		})
+	})
+
+	Context("when TLS session ticket rotation is configured", func() {
+		BeforeEach(func() {
+			fakeGarden.Start()
+			repConfig.SessionTicketRotationInterval = durationjson.Duration(time.Hour)
+		})
+
+		JustBeforeEach(func() {
+			Eventually(runner.Session, 2).Should(gbytes.Say("rep.started"))
+		})
+
+		It("serves requests", func() {
+			resp, err := client.Get(fmt.Sprintf("https://127.0.0.1:%d/state", serverPortSecurable))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+		})
+	})
+
+	Context("when required cert SANs are configured", func() {
+		BeforeEach(func() {
+			fakeGarden.Start()
+		})
+
+		Context("and the server cert has all of them", func() {
+			BeforeEach(func() {
+				repConfig.RequiredCertSANs = []string{"127.0.0.1", "*.bbs.service.cf.internal"}
+			})
+
+			It("starts", func() {
+				Eventually(runner.Session, 2).Should(gbytes.Say("rep.started"))
+			})
+		})
+
+		Context("and the server cert only has a matching DNS SAN", func() {
+			BeforeEach(func() {
+				caFile = path.Join(basePath, "dnssan-certs", "server-ca.crt")
+				certFile = path.Join(basePath, "dnssan-certs", "server.crt")
+				keyFile = path.Join(basePath, "dnssan-certs", "server.key")
+				repConfig.CaCertFile = caFile
+				repConfig.CertFile = certFile
+				repConfig.KeyFile = keyFile
+
+				repConfig.PathToTLSCACert = caFile
+				repConfig.PathToTLSCert = certFile
+				repConfig.PathToTLSKey = keyFile
+
+				repConfig.RequiredCertSANs = []string{"server"}
+			})
+
+			It("starts", func() {
+				Eventually(runner.Session, 2).Should(gbytes.Say("rep.started"))
+			})
+		})
+	})
+
+	Context("when the metron client fails to initialize", func() {
+		BeforeEach(func() {
+			fakeGarden.Start()
+			repConfig.LoggregatorConfig.UseV2API = true
+			repConfig.LoggregatorConfig.CACertPath = "/does/not/exist"
+		})
+
+		Context("and metron is required", func() {
+			BeforeEach(func() {
+				repConfig.RequireMetron = true
+			})
+
+			It("exits with status code 1", func() {
+				Eventually(runner.Session.Buffer()).Should(gbytes.Say("failed-to-initialize-metron-client"))
+				Eventually(runner.Session.ExitCode).Should(Equal(1))
+			})
+		})
+
+		Context("and metron is not required", func() {
+			BeforeEach(func() {
+				repConfig.RequireMetron = false
+			})
+
+			It("starts without metrics and keeps running", func() {
+				Eventually(runner.Session.Buffer()).Should(gbytes.Say("failed-to-initialize-metron-client-continuing-without-metrics"))
+				Eventually(runner.Session, 2).Should(gbytes.Say("rep.started"))
+				Consistently(runner.Session).ShouldNot(Exit())
+			})
+		})
+	})
+
+	Context("when the executor reports zero total capacity", func() {
+		BeforeEach(func() {
+			fakeGarden.RouteToHandler("GET", "/capacity", ghttp.RespondWithJSONEncoded(http.StatusOK,
+				garden.Capacity{MemoryInBytes: 0, DiskInBytes: 20 * 1024 * 1024 * 1024, MaxContainers: 4}))
+			fakeGarden.Start()
+		})
+
+		It("fails fast at startup", func() {
+			Eventually(runner.Session.Buffer()).Should(gbytes.Say("zero-total-capacity"))
+			Eventually(runner.Session.ExitCode).Should(Equal(2))
+		})
+
+		Context("and zero capacity is allowed", func() {
+			BeforeEach(func() {
+				repConfig.AllowZeroCapacity = true
+			})
+
+			It("starts", func() {
+				Eventually(runner.Session.Buffer()).Should(gbytes.Say("allowing-zero-total-capacity"))
+				Eventually(runner.Session, 2).Should(gbytes.Say("rep.started"))
+			})
+		})
+	})
+
+	Context("when the reconciliation policy is invalid", func() {
+		BeforeEach(func() {
+			repConfig.ReconciliationPolicy = "shrug"
+		})
+
+		It("fails fast at startup", func() {
+			Eventually(runner.Session.Buffer()).Should(gbytes.Say("invalid-reconciliation-policy"))
+			Eventually(runner.Session.ExitCode).Should(Equal(2))
+		})
+	})
+
 	Context("validates chained certs", func() {
 		BeforeEach(func() {
 			fakeGarden.Start()
@@ -461,6 +695,33 @@ var _ = Describe("The Rep", func() {
 						ContainSubstring(fmt.Sprintf(`"image":{"uri":"%s"}`, rootfsTar)),
 					)))
 			})
+
+			Context("when there are more extra rootfses than the configured maximum", func() {
+				var skippedRootfsTar string
+
+				BeforeEach(func() {
+					skippedRootfsTar = filepath.Join(extraRootfs, "zz-skipped-rootfs.tar")
+					file, err := os.Create(skippedRootfsTar)
+					Expect(err).NotTo(HaveOccurred())
+					defer file.Close()
+
+					repConfig.MaxExtraRootFS = 1
+				})
+
+				It("stops loading extra rootfses at the maximum and warns", func() {
+					Eventually(runner.Session).Should(gbytes.Say("max-extra-rootfs-reached"))
+
+					var createRequests []string
+					for i := 0; i < 3; i++ {
+						var createRequest string
+						Eventually(createRequestReceived).Should(Receive(&createRequest))
+						createRequests = append(createRequests, createRequest)
+					}
+					Expect(createRequests).To(ContainElement(ContainSubstring(fmt.Sprintf(`"image":{"uri":"%s"}`, rootfsTar))))
+					Expect(createRequests).NotTo(ContainElement(ContainSubstring(skippedRootfsTar)))
+					Consistently(createRequestReceived).ShouldNot(Receive(ContainSubstring(skippedRootfsTar)))
+				})
+			})
 		})
 	})
 
@@ -1168,6 +1429,41 @@ dYbCU/DMZjsv+Pt9flhj7ELLo+WKHyI767hJSq9A7IT3GzFt8iGiEAt1qj2yS0DX
 			})
 		})
 
+		Context("when the startup duration metric is enabled", func() {
+			BeforeEach(func() {
+				repConfig.EmitStartupDurationMetric = true
+			})
+
+			It("emits the time the rep took to become ready", func() {
+				startupDuration := func() (float64, bool) {
+					for {
+						select {
+						case envelope := <-testMetricsChan:
+							if metric, ok := envelope.GetGauge().GetMetrics()["RepStartupDuration"]; ok {
+								return metric.GetValue(), true
+							}
+						default:
+							return 0, false
+						}
+					}
+				}
+
+				var duration float64
+				Eventually(func() bool {
+					var emitted bool
+					duration, emitted = startupDuration()
+					return emitted
+				}).Should(BeTrue())
+				Expect(duration).To(BeNumerically(">", 0))
+				Expect(duration).To(BeNumerically("<", float64(time.Minute)))
+
+				Consistently(func() bool {
+					_, emitted := startupDuration()
+					return emitted
+				}).Should(BeFalse())
+			})
+		})
+
 		Describe("when a Ping request comes in", func() {
 			It("responds with 200 OK", func() {
 				resp, err := client.Get(fmt.Sprintf("https://127.0.0.1:%d/ping", serverPort))
@@ -29,6 +29,19 @@ const (
 
 	VolumeDriversTag = "volume-drivers"
 	PlacementTagsTag = "placement-tags"
+
+	// PriorityTag holds an integer eviction priority. Containers without it
+	// have priority 0, and lower priorities are evicted first.
+	PriorityTag = "priority"
+
+	// EvacuationPreStopTimeoutTag holds a duration, such as "30s", that a
+	// container is given to shut down gracefully when it is still on the cell
+	// once the evacuation times out.
+	EvacuationPreStopTimeoutTag = "evacuation-pre-stop-timeout"
+
+	// AuctionIDTag holds the trace ID of the auction that placed the
+	// container on the cell.
+	AuctionIDTag = "auction-id"
 )
 
 var (
@@ -13,6 +13,7 @@ import (
 	loggingclient "code.cloudfoundry.org/diego-logging-client"
 	"code.cloudfoundry.org/executor"
 	"code.cloudfoundry.org/lager/v3"
+	"code.cloudfoundry.org/rep"
 )
 
 const (
@@ -21,6 +22,20 @@ const (
 
 var strandedEvacuatingActualLRPsMetric = "StrandedEvacuatingActualLRPs"
 
+const (
+	destructionReasonEvacuationTimeout = "evacuation-timeout"
+	destructionReasonCleanupOnShutdown = "cleanup-on-shutdown"
+	destructionReasonOrphan            = "orphan"
+)
+
+// the logging client has no support for tagged counters, so each destruction
+// reason is emitted as its own counter
+var containerDestructionMetrics = map[string]string{
+	destructionReasonEvacuationTimeout: "ContainerDestructionsEvacuationTimeout",
+	destructionReasonCleanupOnShutdown: "ContainerDestructionsCleanupOnShutdown",
+	destructionReasonOrphan:            "ContainerDestructionsOrphan",
+}
+
 type EvacuationCleanup struct {
 	clock          clock.Clock
 	logger         lager.Logger
@@ -94,7 +109,7 @@ func (e *EvacuationCleanup) Run(signals <-chan os.Signal, ready chan<- struct{})
 	checkRunningContainersTimer := e.clock.NewTicker(1 * time.Second)
 	containersSignalled := make(chan struct{})
 	containersDeleted := make(chan struct{})
-	go e.deleteRunningContainers(logger, traceID, containersSignalled)
+	go e.deleteRunningContainers(logger, traceID, actualLRPs, containersSignalled)
 	go e.checkRunningContainers(logger, checkRunningContainersTimer.C(), containersSignalled, containersDeleted)
 
 	select {
@@ -140,7 +155,27 @@ func (e *EvacuationCleanup) checkRunningContainers(
 	}
 }
 
-func (e *EvacuationCleanup) deleteRunningContainers(logger lager.Logger, traceID string, containersSignalled chan<- struct{}) {
+// destructionReason categorizes why a container is destroyed during cleanup:
+// LRPs whose evacuation never completed, LRPs the BBS no longer knows about,
+// and everything else being cleaned up as part of a normal shutdown.
+func destructionReason(container executor.Container, actualLRPsByInstanceGuid map[string]*models.ActualLRP) string {
+	if container.Tags[rep.LifecycleTag] != rep.LRPLifecycle {
+		return destructionReasonCleanupOnShutdown
+	}
+
+	actualLRP, found := actualLRPsByInstanceGuid[container.Tags[rep.InstanceGuidTag]]
+	if !found {
+		return destructionReasonOrphan
+	}
+
+	if actualLRP.GetPresence() == models.ActualLRP_Evacuating {
+		return destructionReasonEvacuationTimeout
+	}
+
+	return destructionReasonCleanupOnShutdown
+}
+
+func (e *EvacuationCleanup) deleteRunningContainers(logger lager.Logger, traceID string, actualLRPs []*models.ActualLRP, containersSignalled chan<- struct{}) {
 	defer close(containersSignalled)
 
 	containers, err := e.executorClient.ListContainers(logger)
@@ -149,6 +184,15 @@ func (e *EvacuationCleanup) deleteRunningContainers(logger lager.Logger, traceID
 		return
 	}
 
+	actualLRPsByInstanceGuid := make(map[string]*models.ActualLRP, len(actualLRPs))
+	for _, actualLRP := range actualLRPs {
+		existing, found := actualLRPsByInstanceGuid[actualLRP.InstanceGuid]
+		if found && existing.GetPresence() == models.ActualLRP_Evacuating {
+			continue
+		}
+		actualLRPsByInstanceGuid[actualLRP.InstanceGuid] = actualLRP
+	}
+
 	logger.Info("sending-signal-to-containers")
 
 	var wg sync.WaitGroup
@@ -159,14 +203,22 @@ func (e *EvacuationCleanup) deleteRunningContainers(logger lager.Logger, traceID
 		if metricError != nil {
 			logger.Debug("failed-sending-app-log-for-instance-evacuation-timeout", lager.Data{"error": metricError})
 		}
+		reason := destructionReason(container, actualLRPsByInstanceGuid)
 		wg.Add(1)
-		go func(logger lager.Logger, traceID string, containerGuid string) {
+		go func(logger lager.Logger, traceID string, containerGuid string, reason string) {
 			defer wg.Done()
 			err := e.executorClient.DeleteContainer(logger, traceID, containerGuid)
 			if err != nil {
-				logger.Error("failed-to-delete-container", err, lager.Data{"container-guid": containerGuid})
+				logger.Error("failed-to-delete-container", err, lager.Data{"container-guid": containerGuid, "reason": reason})
+				return
+			}
+
+			logger.Info("destroyed-container", lager.Data{"container-guid": containerGuid, "reason": reason})
+			err = e.metronClient.IncrementCounter(containerDestructionMetrics[reason])
+			if err != nil {
+				logger.Error("failed-sending-container-destruction-metric", err, lager.Data{"reason": reason})
 			}
-		}(logger, traceID, container.Guid)
+		}(logger, traceID, container.Guid, reason)
 	}
 
 	logger.Info("sent-signal-to-containers")
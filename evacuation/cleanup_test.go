@@ -16,6 +16,7 @@ import (
 	"code.cloudfoundry.org/executor/fakes"
 	"code.cloudfoundry.org/lager/v3"
 	"code.cloudfoundry.org/lager/v3/lagertest"
+	"code.cloudfoundry.org/rep"
 	"code.cloudfoundry.org/rep/evacuation"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -196,6 +197,73 @@ var _ = Describe("EvacuationCleanup", func() {
 				Expect(msg).To(Equal(fmt.Sprintf("Cell %s reached evacuation timeout for instance %s", cellID, "container2")))
 			})
 
+			Describe("emitting container destruction reasons", func() {
+				BeforeEach(func() {
+					running := model_helpers.NewValidActualLRP("running-process-guid", 0)
+					running.InstanceGuid = "running-instance-guid"
+					evacuating := model_helpers.NewValidEvacuatingActualLRP("evacuating-process-guid", 0)
+					evacuating.InstanceGuid = "evacuating-instance-guid"
+					fakeBBSClient.ActualLRPsReturns([]*models.ActualLRP{running, evacuating}, nil)
+
+					lrpTags := func(instanceGuid string) executor.Tags {
+						return executor.Tags{
+							rep.LifecycleTag:    rep.LRPLifecycle,
+							rep.InstanceGuidTag: instanceGuid,
+						}
+					}
+
+					fakeExecutorClient.ListContainersReturnsOnCall(0,
+						[]executor.Container{
+							{Guid: "running-container", State: executor.StateRunning, Tags: lrpTags("running-instance-guid")},
+							{Guid: "evacuating-container", State: executor.StateRunning, Tags: lrpTags("evacuating-instance-guid")},
+							{Guid: "orphaned-container", State: executor.StateRunning, Tags: lrpTags("unknown-instance-guid")},
+							{Guid: "task-container", State: executor.StateRunning, Tags: executor.Tags{rep.LifecycleTag: rep.TaskLifecycle}},
+						},
+						nil,
+					)
+				})
+
+				It("increments a counter for the reason each container was destroyed", func() {
+					Eventually(errCh).Should(Receive(BeNil()))
+
+					Expect(fakeMetronClient.IncrementCounterCallCount()).To(Equal(4))
+					counters := []string{}
+					for i := 0; i < fakeMetronClient.IncrementCounterCallCount(); i++ {
+						counters = append(counters, fakeMetronClient.IncrementCounterArgsForCall(i))
+					}
+					Expect(counters).To(ConsistOf(
+						"ContainerDestructionsCleanupOnShutdown",
+						"ContainerDestructionsEvacuationTimeout",
+						"ContainerDestructionsOrphan",
+						"ContainerDestructionsCleanupOnShutdown",
+					))
+				})
+
+				It("logs the reason each container was destroyed", func() {
+					Eventually(logger).Should(gbytes.Say("destroyed-container"))
+					Eventually(errCh).Should(Receive(BeNil()))
+					Expect(logger.Buffer().Contents()).To(ContainSubstring(`"container-guid":"evacuating-container","reason":"evacuation-timeout"`))
+					Expect(logger.Buffer().Contents()).To(ContainSubstring(`"container-guid":"orphaned-container","reason":"orphan"`))
+					Expect(logger.Buffer().Contents()).To(ContainSubstring(`"container-guid":"running-container","reason":"cleanup-on-shutdown"`))
+				})
+
+				Context("when a container fails to be deleted", func() {
+					BeforeEach(func() {
+						fakeExecutorClient.DeleteContainerStub = func(_ lager.Logger, _ string, guid string) error {
+							if guid == "orphaned-container" {
+								return errors.New("some-error")
+							}
+							return nil
+						}
+					})
+
+					It("does not count it", func() {
+						Eventually(errCh).Should(Receive(BeNil()))
+						Expect(fakeMetronClient.IncrementCounterCallCount()).To(Equal(3))
+					})
+				})
+			})
+
 			Describe("when DeleteContainer hangs", func() {
 				BeforeEach(func() {
 					fakeExecutorClient.DeleteContainerStub = func(lager.Logger, string, string) error {
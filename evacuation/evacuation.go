@@ -2,14 +2,20 @@ package evacuation
 
 import (
 	"os"
+	"sync"
 	"time"
 
 	"code.cloudfoundry.org/clock"
 	"code.cloudfoundry.org/executor"
 	"code.cloudfoundry.org/lager/v3"
+	"code.cloudfoundry.org/rep"
 	"code.cloudfoundry.org/rep/evacuation/evacuation_context"
 )
 
+// preStopPollInterval is how often the evacuator checks whether containers
+// running a pre-stop hook have stopped.
+const preStopPollInterval = time.Second
+
 type Evacuator struct {
 	logger             lager.Logger
 	clock              clock.Clock
@@ -18,6 +24,14 @@ type Evacuator struct {
 	cellID             string
 	evacuationTimeout  time.Duration
 	pollingInterval    time.Duration
+	excludedDomains    map[string]struct{}
+	history            *History
+
+	progressLock        sync.Mutex
+	containersDestroyed int
+	containersToDrain   int
+	containersRemaining int
+	polled              bool
 }
 
 func NewEvacuator(
@@ -28,7 +42,14 @@ func NewEvacuator(
 	cellID string,
 	evacuationTimeout time.Duration,
 	pollingInterval time.Duration,
+	excludedDomains []string,
+	history *History,
 ) *Evacuator {
+	domains := make(map[string]struct{}, len(excludedDomains))
+	for _, domain := range excludedDomains {
+		domains[domain] = struct{}{}
+	}
+
 	return &Evacuator{
 		logger:             logger,
 		clock:              clock,
@@ -37,6 +58,8 @@ func NewEvacuator(
 		cellID:             cellID,
 		evacuationTimeout:  evacuationTimeout,
 		pollingInterval:    pollingInterval,
+		excludedDomains:    domains,
+		history:            history,
 	}
 }
 
@@ -57,6 +80,7 @@ func (e *Evacuator) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
 		logger.Info("notified-of-evacuation")
 	}
 
+	startedAt := e.clock.Now()
 	timer := e.clock.NewTimer(e.evacuationTimeout)
 	defer timer.Stop()
 
@@ -66,16 +90,48 @@ func (e *Evacuator) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
 	select {
 	case <-doneCh:
 		logger.Info("evacuation-complete")
+		e.recordSummary(logger, startedAt, OutcomeCompleted)
 		return nil
 	case <-timer.C():
 		logger.Error("failed-to-evacuate-before-timeout", nil)
+		signal := e.runPreStopHooks(logger, signals)
+		if signal != nil {
+			logger.Info("signaled", lager.Data{"signal": signal.String()})
+		}
+		e.recordSummary(logger, startedAt, OutcomeTimedOut)
 		return nil
 	case signal := <-signals:
 		logger.Info("signaled", lager.Data{"signal": signal.String()})
+		e.recordSummary(logger, startedAt, OutcomeInterrupted)
 		return nil
 	}
 }
 
+// recordSummary logs the summary of the evacuation as well as recording it,
+// since the history does not outlive the rep.
+func (e *Evacuator) recordSummary(logger lager.Logger, startedAt time.Time, outcome string) {
+	e.progressLock.Lock()
+	summary := Summary{
+		StartedAt:           startedAt,
+		FinishedAt:          e.clock.Now(),
+		Outcome:             outcome,
+		ContainersDrained:   max(e.containersToDrain-e.containersRemaining, 0),
+		ContainersDestroyed: e.containersDestroyed,
+		ContainersRemaining: e.containersRemaining,
+	}
+	e.progressLock.Unlock()
+
+	logger.Info("evacuation-summary", lager.Data{
+		"started-at":           summary.StartedAt,
+		"finished-at":          summary.FinishedAt,
+		"outcome":              summary.Outcome,
+		"containers-drained":   summary.ContainersDrained,
+		"containers-destroyed": summary.ContainersDestroyed,
+		"containers-remaining": summary.ContainersRemaining,
+	})
+	e.history.Record(summary)
+}
+
 func (e *Evacuator) evacuate(logger lager.Logger, doneCh chan<- struct{}) {
 	logger = logger.Session("evacuating")
 	logger.Info("started")
@@ -107,5 +163,122 @@ func (e *Evacuator) allContainersEvacuated(logger lager.Logger) bool {
 		return false
 	}
 
+	containers = e.destroyExcludedContainers(logger, containers)
+
+	e.progressLock.Lock()
+	if !e.polled {
+		e.containersToDrain = len(containers)
+		e.polled = true
+	}
+	e.containersRemaining = len(containers)
+	e.progressLock.Unlock()
+
 	return len(containers) == 0
 }
+
+// runPreStopHooks runs once the evacuation times out, since only then is it
+// certain that the remaining containers were not replaced and will be
+// destroyed with the cell. It stops the containers declaring a pre-stop
+// timeout, which has the executor signal their processes to terminate, and
+// waits for them to stop until their timeout. It returns the signal that
+// interrupted the wait, if any.
+func (e *Evacuator) runPreStopHooks(logger lager.Logger, signals <-chan os.Signal) os.Signal {
+	containers, err := e.executorClient.ListContainers(logger)
+	if err != nil {
+		logger.Error("failed-to-list-containers", err)
+		return nil
+	}
+
+	deadlines := map[string]time.Time{}
+	traceID := "" // evacuation is not originated through API
+	for _, container := range containers {
+		value, ok := container.Tags[rep.EvacuationPreStopTimeoutTag]
+		if !ok {
+			continue
+		}
+
+		timeout, err := time.ParseDuration(value)
+		if err != nil || timeout <= 0 {
+			logger.Error("invalid-pre-stop-timeout", err, lager.Data{"container-guid": container.Guid, "timeout": value})
+			continue
+		}
+
+		logger.Info("running-pre-stop-hook", lager.Data{"container-guid": container.Guid, "timeout": timeout.String()})
+		err = e.executorClient.StopContainer(logger, traceID, container.Guid)
+		if err != nil {
+			logger.Error("failed-to-stop-container", err, lager.Data{"container-guid": container.Guid})
+			continue
+		}
+		deadlines[container.Guid] = e.clock.Now().Add(timeout)
+	}
+
+	timer := e.clock.NewTimer(preStopPollInterval)
+	defer timer.Stop()
+
+	for {
+		for guid, deadline := range deadlines {
+			container, err := e.executorClient.GetContainer(logger, traceID, guid)
+			if err == executor.ErrContainerNotFound || (err == nil && container.State == executor.StateCompleted) {
+				logger.Info("pre-stop-hook-completed", lager.Data{"container-guid": guid})
+				delete(deadlines, guid)
+				continue
+			}
+			if err != nil {
+				logger.Error("failed-to-get-container", err, lager.Data{"container-guid": guid})
+			}
+
+			if !e.clock.Now().Before(deadline) {
+				logger.Info("pre-stop-hook-timed-out", lager.Data{"container-guid": guid})
+				delete(deadlines, guid)
+			}
+		}
+
+		if len(deadlines) == 0 {
+			return nil
+		}
+
+		select {
+		case <-timer.C():
+			timer.Reset(preStopPollInterval)
+		case signal := <-signals:
+			return signal
+		}
+	}
+}
+
+// destroyExcludedContainers deletes the containers whose domain opted out of
+// evacuation. They are meant to die with the cell, so there is no point in
+// rescheduling them elsewhere. It runs on every poll since such containers
+// may still be starting when the evacuation begins, and returns the
+// containers it did not destroy.
+func (e *Evacuator) destroyExcludedContainers(logger lager.Logger, containers []executor.Container) []executor.Container {
+	if len(e.excludedDomains) == 0 {
+		return containers
+	}
+
+	remaining := make([]executor.Container, 0, len(containers))
+	destroyed := 0
+	traceID := "" // evacuation is not originated through API
+	for _, container := range containers {
+		domain := container.Tags[rep.DomainTag]
+		if _, excluded := e.excludedDomains[domain]; !excluded {
+			remaining = append(remaining, container)
+			continue
+		}
+
+		logger.Info("destroying-excluded-container", lager.Data{"container-guid": container.Guid, "domain": domain})
+		err := e.executorClient.DeleteContainer(logger, traceID, container.Guid)
+		if err != nil {
+			logger.Error("failed-to-delete-container", err, lager.Data{"container-guid": container.Guid})
+			remaining = append(remaining, container)
+			continue
+		}
+		destroyed++
+	}
+
+	e.progressLock.Lock()
+	e.containersDestroyed += destroyed
+	e.progressLock.Unlock()
+
+	return remaining
+}
@@ -1,15 +1,23 @@
 package evacuation_context
 
-import "sync"
+import (
+	"sync"
+	"time"
+)
 
 //go:generate counterfeiter -o fake_evacuation_context/fake_evacuatable.go . Evacuatable
 type Evacuatable interface {
-	Evacuate()
+	// Evacuate starts evacuating the cell. It reports false if the cell was
+	// already evacuating, in which case it does nothing.
+	Evacuate() bool
 }
 
 //go:generate counterfeiter -o fake_evacuation_context/fake_evacuation_reporter.go . EvacuationReporter
 type EvacuationReporter interface {
 	Evacuating() bool
+	// EvacuatingSince returns when the evacuation started, or the zero time
+	// if the cell is not evacuating.
+	EvacuatingSince() time.Time
 }
 
 //go:generate counterfeiter -o fake_evacuation_context/fake_evacuation_notifier.go . EvacuationNotifier
@@ -18,8 +26,9 @@ type EvacuationNotifier interface {
 }
 
 type evacuationContext struct {
-	evacuated chan struct{}
-	mu        sync.Mutex
+	evacuated   chan struct{}
+	evacuatedAt time.Time
+	mu          sync.Mutex
 }
 
 func New() (Evacuatable, EvacuationReporter, EvacuationNotifier) {
@@ -30,14 +39,17 @@ func New() (Evacuatable, EvacuationReporter, EvacuationNotifier) {
 	return evacuationContext, evacuationContext, evacuationContext
 }
 
-func (e *evacuationContext) Evacuate() {
+func (e *evacuationContext) Evacuate() bool {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
 	select {
 	case <-e.evacuated:
+		return false
 	default:
+		e.evacuatedAt = time.Now()
 		close(e.evacuated)
+		return true
 	}
 }
 
@@ -50,6 +62,13 @@ func (e *evacuationContext) Evacuating() bool {
 	}
 }
 
+func (e *evacuationContext) EvacuatingSince() time.Time {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	return e.evacuatedAt
+}
+
 func (e *evacuationContext) EvacuateNotify() <-chan struct{} {
 	return e.evacuated
 }
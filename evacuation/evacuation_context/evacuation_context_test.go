@@ -3,6 +3,7 @@ package evacuation_context_test
 import (
 	"runtime"
 	"sync"
+	"time"
 
 	"code.cloudfoundry.org/rep/evacuation/evacuation_context"
 
@@ -27,6 +28,10 @@ var _ = Describe("EvacuationContext", func() {
 				Expect(evacuationReporter.Evacuating()).To(BeFalse())
 			})
 
+			It("does not report when the evacuation started", func() {
+				Expect(evacuationReporter.EvacuatingSince()).To(BeZero())
+			})
+
 			It("does not close the channel provided by the evacuation notifier", func() {
 				evacuateNotify := evacuationNotifier.EvacuateNotify()
 				Consistently(evacuateNotify).ShouldNot(BeClosed())
@@ -39,12 +44,32 @@ var _ = Describe("EvacuationContext", func() {
 				Expect(evacuationReporter.Evacuating()).To(BeTrue())
 			})
 
+			It("reports when the evacuation started", func() {
+				before := time.Now()
+				evacuatable.Evacuate()
+				evacuatable.Evacuate()
+				Expect(evacuationReporter.EvacuatingSince()).To(BeTemporally(">=", before))
+				Expect(evacuationReporter.EvacuatingSince()).To(BeTemporally("<=", time.Now()))
+			})
+
 			It("closes the channel provided by the evacuation notifier", func() {
 				evacuateNotify := evacuationNotifier.EvacuateNotify()
 				Consistently(evacuateNotify).ShouldNot(BeClosed())
 				evacuatable.Evacuate()
 				Eventually(evacuateNotify).Should(BeClosed())
 			})
+
+			It("reports that the first call started the evacuation", func() {
+				Expect(evacuatable.Evacuate()).To(BeTrue())
+			})
+
+			It("reports that later calls were no-ops", func() {
+				evacuatable.Evacuate()
+				since := evacuationReporter.EvacuatingSince()
+
+				Expect(evacuatable.Evacuate()).To(BeFalse())
+				Expect(evacuationReporter.EvacuatingSince()).To(Equal(since))
+			})
 		})
 
 		Context("when Evacuate is called repeatedly", func() {
@@ -8,24 +8,36 @@ import (
 )
 
 type FakeEvacuatable struct {
-	EvacuateStub        func()
+	EvacuateStub        func() bool
 	evacuateMutex       sync.RWMutex
 	evacuateArgsForCall []struct {
 	}
+	evacuateReturns struct {
+		result1 bool
+	}
+	evacuateReturnsOnCall map[int]struct {
+		result1 bool
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
 
-func (fake *FakeEvacuatable) Evacuate() {
+func (fake *FakeEvacuatable) Evacuate() bool {
 	fake.evacuateMutex.Lock()
+	ret, specificReturn := fake.evacuateReturnsOnCall[len(fake.evacuateArgsForCall)]
 	fake.evacuateArgsForCall = append(fake.evacuateArgsForCall, struct {
 	}{})
 	stub := fake.EvacuateStub
+	fakeReturns := fake.evacuateReturns
 	fake.recordInvocation("Evacuate", []interface{}{})
 	fake.evacuateMutex.Unlock()
 	if stub != nil {
-		fake.EvacuateStub()
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
 	}
+	return fakeReturns.result1
 }
 
 func (fake *FakeEvacuatable) EvacuateCallCount() int {
@@ -34,12 +46,35 @@ func (fake *FakeEvacuatable) EvacuateCallCount() int {
 	return len(fake.evacuateArgsForCall)
 }
 
-func (fake *FakeEvacuatable) EvacuateCalls(stub func()) {
+func (fake *FakeEvacuatable) EvacuateCalls(stub func() bool) {
 	fake.evacuateMutex.Lock()
 	defer fake.evacuateMutex.Unlock()
 	fake.EvacuateStub = stub
 }
 
+func (fake *FakeEvacuatable) EvacuateReturns(result1 bool) {
+	fake.evacuateMutex.Lock()
+	defer fake.evacuateMutex.Unlock()
+	fake.EvacuateStub = nil
+	fake.evacuateReturns = struct {
+		result1 bool
+	}{result1}
+}
+
+func (fake *FakeEvacuatable) EvacuateReturnsOnCall(i int, result1 bool) {
+	fake.evacuateMutex.Lock()
+	defer fake.evacuateMutex.Unlock()
+	fake.EvacuateStub = nil
+	if fake.evacuateReturnsOnCall == nil {
+		fake.evacuateReturnsOnCall = make(map[int]struct {
+			result1 bool
+		})
+	}
+	fake.evacuateReturnsOnCall[i] = struct {
+		result1 bool
+	}{result1}
+}
+
 func (fake *FakeEvacuatable) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
@@ -3,6 +3,7 @@ package fake_evacuation_context
 
 import (
 	"sync"
+	"time"
 
 	"code.cloudfoundry.org/rep/evacuation/evacuation_context"
 )
@@ -18,6 +19,16 @@ type FakeEvacuationReporter struct {
 	evacuatingReturnsOnCall map[int]struct {
 		result1 bool
 	}
+	EvacuatingSinceStub        func() time.Time
+	evacuatingSinceMutex       sync.RWMutex
+	evacuatingSinceArgsForCall []struct {
+	}
+	evacuatingSinceReturns struct {
+		result1 time.Time
+	}
+	evacuatingSinceReturnsOnCall map[int]struct {
+		result1 time.Time
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -75,11 +86,66 @@ func (fake *FakeEvacuationReporter) EvacuatingReturnsOnCall(i int, result1 bool)
 	}{result1}
 }
 
+func (fake *FakeEvacuationReporter) EvacuatingSince() time.Time {
+	fake.evacuatingSinceMutex.Lock()
+	ret, specificReturn := fake.evacuatingSinceReturnsOnCall[len(fake.evacuatingSinceArgsForCall)]
+	fake.evacuatingSinceArgsForCall = append(fake.evacuatingSinceArgsForCall, struct {
+	}{})
+	stub := fake.EvacuatingSinceStub
+	fakeReturns := fake.evacuatingSinceReturns
+	fake.recordInvocation("EvacuatingSince", []interface{}{})
+	fake.evacuatingSinceMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeEvacuationReporter) EvacuatingSinceCallCount() int {
+	fake.evacuatingSinceMutex.RLock()
+	defer fake.evacuatingSinceMutex.RUnlock()
+	return len(fake.evacuatingSinceArgsForCall)
+}
+
+func (fake *FakeEvacuationReporter) EvacuatingSinceCalls(stub func() time.Time) {
+	fake.evacuatingSinceMutex.Lock()
+	defer fake.evacuatingSinceMutex.Unlock()
+	fake.EvacuatingSinceStub = stub
+}
+
+func (fake *FakeEvacuationReporter) EvacuatingSinceReturns(result1 time.Time) {
+	fake.evacuatingSinceMutex.Lock()
+	defer fake.evacuatingSinceMutex.Unlock()
+	fake.EvacuatingSinceStub = nil
+	fake.evacuatingSinceReturns = struct {
+		result1 time.Time
+	}{result1}
+}
+
+func (fake *FakeEvacuationReporter) EvacuatingSinceReturnsOnCall(i int, result1 time.Time) {
+	fake.evacuatingSinceMutex.Lock()
+	defer fake.evacuatingSinceMutex.Unlock()
+	fake.EvacuatingSinceStub = nil
+	if fake.evacuatingSinceReturnsOnCall == nil {
+		fake.evacuatingSinceReturnsOnCall = make(map[int]struct {
+			result1 time.Time
+		})
+	}
+	fake.evacuatingSinceReturnsOnCall[i] = struct {
+		result1 time.Time
+	}{result1}
+}
+
 func (fake *FakeEvacuationReporter) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
 	fake.evacuatingMutex.RLock()
 	defer fake.evacuatingMutex.RUnlock()
+	fake.evacuatingSinceMutex.RLock()
+	defer fake.evacuatingSinceMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
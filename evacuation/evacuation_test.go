@@ -3,6 +3,7 @@ package evacuation_test
 import (
 	"errors"
 	"os"
+	"sync"
 	"time"
 
 	"code.cloudfoundry.org/clock/fakeclock"
@@ -17,6 +18,7 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
 )
 
 var _ = Describe("Evacuation", func() {
@@ -38,6 +40,9 @@ var _ = Describe("Evacuation", func() {
 
 		errChan chan error
 
+		excludedDomains []string
+		history         *evacuation.History
+
 		TaskTags   map[string]string
 		LRPTags    map[string]string
 		containers []executor.Container
@@ -49,7 +54,23 @@ var _ = Describe("Evacuation", func() {
 		executorClient = &fakes.FakeClient{}
 
 		evacuatable, _, evacuationNotifier = evacuation_context.New()
+		excludedDomains = nil
+		history = evacuation.NewHistory(5)
 
+		TaskTags = map[string]string{rep.LifecycleTag: rep.TaskLifecycle}
+		LRPTags = map[string]string{
+			rep.LifecycleTag:    rep.LRPLifecycle,
+			rep.DomainTag:       "domain",
+			rep.ProcessGuidTag:  "process-guid",
+			rep.ProcessIndexTag: "2",
+		}
+		containers = []executor.Container{
+			{Guid: "guid-1", State: executor.StateRunning, Tags: TaskTags},
+			{Guid: "guid-2", State: executor.StateRunning, Tags: LRPTags},
+		}
+	})
+
+	JustBeforeEach(func() {
 		evacuator = evacuation.NewEvacuator(
 			logger,
 			fakeClock,
@@ -58,6 +79,8 @@ var _ = Describe("Evacuation", func() {
 			cellID,
 			evacuationTimeout,
 			pollingInterval,
+			excludedDomains,
+			history,
 		)
 
 		process = ifrit.Invoke(evacuator)
@@ -69,18 +92,6 @@ var _ = Describe("Evacuation", func() {
 		go func() {
 			localErrChan <- <-evacuationProcess.Wait()
 		}()
-
-		TaskTags = map[string]string{rep.LifecycleTag: rep.TaskLifecycle}
-		LRPTags = map[string]string{
-			rep.LifecycleTag:    rep.LRPLifecycle,
-			rep.DomainTag:       "domain",
-			rep.ProcessGuidTag:  "process-guid",
-			rep.ProcessIndexTag: "2",
-		}
-		containers = []executor.Container{
-			{Guid: "guid-1", State: executor.StateRunning, Tags: TaskTags},
-			{Guid: "guid-2", State: executor.StateRunning, Tags: LRPTags},
-		}
 	})
 
 	Describe("before evacuating", func() {
@@ -121,6 +132,30 @@ var _ = Describe("Evacuation", func() {
 					Eventually(errChan).Should(Receive(BeNil()))
 				})
 
+				It("records a summary of the evacuation", func() {
+					startedAt := fakeClock.Now()
+					Eventually(executorClient.ListContainersCallCount).Should(Equal(1))
+					fakeClock.WaitForNWatchersAndIncrement(pollingInterval, 2)
+					Eventually(errChan).Should(Receive(BeNil()))
+
+					Expect(history.Evacuations()).To(Equal([]evacuation.Summary{{
+						StartedAt:           startedAt,
+						FinishedAt:          startedAt.Add(pollingInterval),
+						Outcome:             evacuation.OutcomeCompleted,
+						ContainersDrained:   2,
+						ContainersDestroyed: 0,
+						ContainersRemaining: 0,
+					}}))
+				})
+
+				It("logs the summary of the evacuation", func() {
+					Eventually(executorClient.ListContainersCallCount).Should(Equal(1))
+					fakeClock.WaitForNWatchersAndIncrement(pollingInterval, 2)
+					Eventually(errChan).Should(Receive(BeNil()))
+
+					Expect(logger).To(gbytes.Say(`evacuation-summary.*"containers-drained":2.*"outcome":"completed"`))
+				})
+
 				Context("when the executor client returns an error", func() {
 					BeforeEach(func() {
 						index := 0
@@ -144,6 +179,185 @@ var _ = Describe("Evacuation", func() {
 				})
 			})
 
+			Context("and some of them are in domains excluded from evacuation", func() {
+				var (
+					listLock sync.Mutex
+					listed   []executor.Container
+				)
+
+				excludedContainer := func(guid string) executor.Container {
+					return executor.Container{
+						Guid:  guid,
+						State: executor.StateRunning,
+						Tags: map[string]string{
+							rep.LifecycleTag:    rep.LRPLifecycle,
+							rep.DomainTag:       "cell-local",
+							rep.ProcessGuidTag:  "other-process-guid",
+							rep.ProcessIndexTag: "0",
+						},
+					}
+				}
+
+				BeforeEach(func() {
+					excludedDomains = []string{"cell-local"}
+
+					listed = append(append([]executor.Container{}, containers...), excludedContainer("guid-3"))
+					executorClient.ListContainersStub = func(lager.Logger) ([]executor.Container, error) {
+						listLock.Lock()
+						defer listLock.Unlock()
+						return append([]executor.Container{}, listed...), nil
+					}
+					executorClient.DeleteContainerStub = func(_ lager.Logger, _ string, guid string) error {
+						listLock.Lock()
+						defer listLock.Unlock()
+						for i, container := range listed {
+							if container.Guid == guid {
+								listed = append(listed[:i], listed[i+1:]...)
+								break
+							}
+						}
+						return nil
+					}
+				})
+
+				It("destroys the containers in the excluded domains", func() {
+					Eventually(executorClient.DeleteContainerCallCount).Should(Equal(1))
+					_, _, guid := executorClient.DeleteContainerArgsForCall(0)
+					Expect(guid).To(Equal("guid-3"))
+				})
+
+				It("records the destroyed containers in the summary", func() {
+					Eventually(logger).Should(gbytes.Say("evacuation-incomplete"))
+					process.Signal(os.Interrupt)
+					Eventually(errChan).Should(Receive(BeNil()))
+
+					evacuations := history.Evacuations()
+					Expect(evacuations).To(HaveLen(1))
+					Expect(evacuations[0].Outcome).To(Equal(evacuation.OutcomeInterrupted))
+					Expect(evacuations[0].ContainersDestroyed).To(Equal(1))
+					Expect(evacuations[0].ContainersRemaining).To(Equal(2))
+				})
+
+				It("leaves the containers in other domains to be evacuated", func() {
+					Eventually(executorClient.ListContainersCallCount).Should(Equal(1))
+					fakeClock.WaitForNWatchersAndIncrement(pollingInterval, 2)
+					Eventually(executorClient.ListContainersCallCount).Should(Equal(2))
+					Consistently(executorClient.DeleteContainerCallCount).Should(Equal(1))
+				})
+
+				Context("when another one shows up after the evacuation has started", func() {
+					It("destroys it as well", func() {
+						Eventually(logger).Should(gbytes.Say("evacuation-incomplete"))
+
+						listLock.Lock()
+						listed = append(listed, excludedContainer("guid-4"))
+						listLock.Unlock()
+
+						fakeClock.WaitForNWatchersAndIncrement(pollingInterval, 2)
+						Eventually(executorClient.DeleteContainerCallCount).Should(Equal(2))
+						_, _, guid := executorClient.DeleteContainerArgsForCall(1)
+						Expect(guid).To(Equal("guid-4"))
+
+						Eventually(logger).Should(gbytes.Say("evacuation-incomplete"))
+						process.Signal(os.Interrupt)
+						Eventually(errChan).Should(Receive(BeNil()))
+
+						evacuations := history.Evacuations()
+						Expect(evacuations).To(HaveLen(1))
+						Expect(evacuations[0].ContainersDestroyed).To(Equal(2))
+					})
+				})
+			})
+
+			Context("and some of them declare a pre-stop timeout", func() {
+				var preStopTimeout string
+
+				BeforeEach(func() {
+					preStopTimeout = "10s"
+					executorClient.GetContainerReturns(executor.Container{Guid: "guid-2", State: executor.StateRunning}, nil)
+					executorClient.ListContainersStub = func(lager.Logger) ([]executor.Container, error) {
+						hookTags := map[string]string{rep.EvacuationPreStopTimeoutTag: preStopTimeout}
+						for k, v := range LRPTags {
+							hookTags[k] = v
+						}
+						return []executor.Container{
+							containers[0],
+							{Guid: "guid-2", State: executor.StateRunning, Tags: hookTags},
+						}, nil
+					}
+				})
+
+				It("does not stop them while they are being evacuated", func() {
+					Eventually(executorClient.ListContainersCallCount).Should(Equal(1))
+					fakeClock.WaitForNWatchersAndIncrement(pollingInterval, 2)
+					Eventually(executorClient.ListContainersCallCount).Should(Equal(2))
+					Consistently(executorClient.StopContainerCallCount).Should(BeZero())
+				})
+
+				Context("when the evacuation times out", func() {
+					JustBeforeEach(func() {
+						Eventually(fakeClock.WatcherCount).Should(Equal(2))
+						fakeClock.WaitForNWatchersAndIncrement(evacuationTimeout, 2)
+					})
+
+					It("stops only those containers", func() {
+						Eventually(executorClient.StopContainerCallCount).Should(Equal(1))
+						_, _, guid := executorClient.StopContainerArgsForCall(0)
+						Expect(guid).To(Equal("guid-2"))
+						Consistently(executorClient.StopContainerCallCount).Should(Equal(1))
+					})
+
+					It("waits for them to stop before exiting", func() {
+						Eventually(executorClient.GetContainerCallCount).Should(Equal(1))
+						Consistently(errChan).ShouldNot(Receive())
+
+						executorClient.GetContainerReturns(executor.Container{Guid: "guid-2", State: executor.StateCompleted}, nil)
+						fakeClock.WaitForNWatchersAndIncrement(time.Second, 2)
+						Eventually(logger).Should(gbytes.Say("pre-stop-hook-completed"))
+						Eventually(errChan).Should(Receive(BeNil()))
+					})
+
+					It("stops waiting once their timeout elapses", func() {
+						Eventually(executorClient.GetContainerCallCount).Should(Equal(1))
+
+						fakeClock.WaitForNWatchersAndIncrement(10*time.Second, 2)
+						Eventually(logger).Should(gbytes.Say("pre-stop-hook-timed-out"))
+						Eventually(errChan).Should(Receive(BeNil()))
+					})
+
+					It("stops waiting when signaled", func() {
+						Eventually(executorClient.GetContainerCallCount).Should(Equal(1))
+
+						process.Signal(os.Interrupt)
+						Eventually(errChan).Should(Receive(BeNil()))
+						Expect(history.Evacuations()[0].Outcome).To(Equal(evacuation.OutcomeTimedOut))
+					})
+
+					Context("when the timeout is invalid", func() {
+						BeforeEach(func() {
+							preStopTimeout = "soon"
+						})
+
+						It("does not stop the container", func() {
+							Eventually(logger).Should(gbytes.Say("invalid-pre-stop-timeout"))
+							Eventually(errChan).Should(Receive(BeNil()))
+							Expect(executorClient.StopContainerCallCount()).To(Equal(0))
+						})
+					})
+				})
+			})
+
+			Context("and no domains are excluded from evacuation", func() {
+				BeforeEach(func() {
+					executorClient.ListContainersReturns(containers, nil)
+				})
+
+				It("does not destroy any containers", func() {
+					Eventually(executorClient.ListContainersCallCount).Should(Equal(1))
+					Consistently(executorClient.DeleteContainerCallCount).Should(Equal(0))
+				})
+			})
+
 			Context("and are not all destroyed before the timeout elapses", func() {
 				BeforeEach(func() {
 					executorClient.ListContainersReturns(containers, nil)
@@ -158,6 +372,18 @@ var _ = Describe("Evacuation", func() {
 					Eventually(errChan).Should(Receive(BeNil()))
 				})
 
+				It("records the containers left behind in the summary", func() {
+					Eventually(fakeClock.WatcherCount).Should(Equal(2))
+					fakeClock.WaitForNWatchersAndIncrement(evacuationTimeout+time.Second, 2)
+					Eventually(errChan).Should(Receive(BeNil()))
+
+					evacuations := history.Evacuations()
+					Expect(evacuations).To(HaveLen(1))
+					Expect(evacuations[0].Outcome).To(Equal(evacuation.OutcomeTimedOut))
+					Expect(evacuations[0].ContainersDrained).To(Equal(0))
+					Expect(evacuations[0].ContainersRemaining).To(Equal(2))
+				})
+
 				Context("when signaled", func() {
 					It("exits", func() {
 						process.Signal(os.Interrupt)
@@ -0,0 +1,62 @@
+package evacuation
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	OutcomeCompleted   = "completed"
+	OutcomeTimedOut    = "timed-out"
+	OutcomeInterrupted = "interrupted"
+)
+
+// Summary describes how an evacuation of the cell went. Drained containers
+// went away on their own, destroyed ones were in domains excluded from
+// evacuation, and remaining ones were still there when the evacuation ended.
+type Summary struct {
+	StartedAt           time.Time `json:"started_at"`
+	FinishedAt          time.Time `json:"finished_at"`
+	Outcome             string    `json:"outcome"`
+	ContainersDrained   int       `json:"containers_drained"`
+	ContainersDestroyed int       `json:"containers_destroyed"`
+	ContainersRemaining int       `json:"containers_remaining"`
+}
+
+// History keeps the summaries of the most recent evacuations in memory.
+type History struct {
+	size int
+
+	lock      sync.Mutex
+	summaries []Summary
+}
+
+// NewHistory returns a History holding at most size summaries. Older ones
+// are dropped first.
+func NewHistory(size int) *History {
+	return &History{size: size}
+}
+
+func (h *History) Record(summary Summary) {
+	if h.size <= 0 {
+		return
+	}
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.summaries = append(h.summaries, summary)
+	if len(h.summaries) > h.size {
+		h.summaries = h.summaries[len(h.summaries)-h.size:]
+	}
+}
+
+// Evacuations returns the recorded summaries, oldest first.
+func (h *History) Evacuations() []Summary {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	summaries := make([]Summary, len(h.summaries))
+	copy(summaries, h.summaries)
+	return summaries
+}
@@ -0,0 +1,29 @@
+package evacuation_test
+
+import (
+	"code.cloudfoundry.org/rep/evacuation"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("History", func() {
+	It("keeps only the most recent evacuations, oldest first", func() {
+		history := evacuation.NewHistory(2)
+		history.Record(evacuation.Summary{Outcome: "first"})
+		history.Record(evacuation.Summary{Outcome: "second"})
+		history.Record(evacuation.Summary{Outcome: "third"})
+
+		Expect(history.Evacuations()).To(Equal([]evacuation.Summary{
+			{Outcome: "second"},
+			{Outcome: "third"},
+		}))
+	})
+
+	It("does not keep anything when its size is not positive", func() {
+		history := evacuation.NewHistory(0)
+		history.Record(evacuation.Summary{Outcome: "first"})
+
+		Expect(history.Evacuations()).To(BeEmpty())
+	})
+})
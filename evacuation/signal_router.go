@@ -0,0 +1,106 @@
+package evacuation
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+
+	"code.cloudfoundry.org/lager/v3"
+	"code.cloudfoundry.org/rep/evacuation/evacuation_context"
+	"github.com/tedsuo/ifrit"
+)
+
+// ShutdownAction is what the rep does when it receives a shutdown signal.
+type ShutdownAction string
+
+const (
+	// ShutdownActionStop stops the rep immediately.
+	ShutdownActionStop ShutdownAction = "stop"
+	// ShutdownActionEvacuate evacuates the cell, and the rep stops once the
+	// evacuation finishes, as when evacuation is requested over HTTP.
+	ShutdownActionEvacuate ShutdownAction = "evacuate"
+)
+
+var shutdownSignals = map[string]os.Signal{
+	"SIGINT":  syscall.SIGINT,
+	"SIGTERM": syscall.SIGTERM,
+}
+
+// ParseShutdownActions validates a configured mapping of signal names, such
+// as "SIGTERM", to the action they trigger. Signals left out stop the rep.
+func ParseShutdownActions(configured map[string]string) (map[os.Signal]ShutdownAction, error) {
+	actions := map[os.Signal]ShutdownAction{}
+	for name, action := range configured {
+		signal, ok := shutdownSignals[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown shutdown signal %q: must be SIGINT or SIGTERM", name)
+		}
+
+		switch ShutdownAction(action) {
+		case ShutdownActionStop, ShutdownActionEvacuate:
+			actions[signal] = ShutdownAction(action)
+		default:
+			return nil, fmt.Errorf("unknown shutdown action %q for %s: must be one of %q or %q",
+				action, name, ShutdownActionStop, ShutdownActionEvacuate)
+		}
+	}
+	return actions, nil
+}
+
+// SignalRouter runs the rep's members and decides what each signal it
+// receives does. Signals mapped to ShutdownActionEvacuate start evacuating
+// the cell, the members stopping on their own once it finishes; a further
+// such signal received while evacuating stops them immediately. Every other
+// signal is passed on to the members, as is any signal received before they
+// are all ready, since there is nothing to evacuate yet.
+type SignalRouter struct {
+	logger      lager.Logger
+	runner      ifrit.Runner
+	evacuatable evacuation_context.Evacuatable
+	actions     map[os.Signal]ShutdownAction
+}
+
+func NewSignalRouter(logger lager.Logger, runner ifrit.Runner, evacuatable evacuation_context.Evacuatable, actions map[os.Signal]ShutdownAction) *SignalRouter {
+	return &SignalRouter{
+		logger:      logger.Session("signal-router"),
+		runner:      runner,
+		evacuatable: evacuatable,
+		actions:     actions,
+	}
+}
+
+func (r *SignalRouter) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	process := ifrit.Background(r.runner)
+	membersReady := process.Ready()
+	exited := process.Wait()
+
+	for membersReady != nil {
+		select {
+		case <-membersReady:
+			membersReady = nil
+
+		case signal := <-signals:
+			r.logger.Info("stopping-on-signal-before-ready", lager.Data{"signal": signal.String()})
+			process.Signal(signal)
+
+		case err := <-exited:
+			return err
+		}
+	}
+	close(ready)
+
+	for {
+		select {
+		case signal := <-signals:
+			if r.actions[signal] == ShutdownActionEvacuate && r.evacuatable.Evacuate() {
+				r.logger.Info("evacuating-on-signal", lager.Data{"signal": signal.String()})
+				continue
+			}
+			r.logger.Info("stopping-on-signal", lager.Data{"signal": signal.String()})
+			process.Signal(signal)
+
+		case err := <-exited:
+			return err
+		}
+	}
+}
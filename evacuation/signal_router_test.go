@@ -0,0 +1,114 @@
+package evacuation_test
+
+import (
+	"os"
+	"syscall"
+
+	"code.cloudfoundry.org/lager/v3/lagertest"
+	"code.cloudfoundry.org/rep/evacuation"
+	"code.cloudfoundry.org/rep/evacuation/evacuation_context/fake_evacuation_context"
+	"github.com/tedsuo/ifrit"
+	"github.com/tedsuo/ifrit/ginkgomon_v2"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ParseShutdownActions", func() {
+	It("maps signal names to actions", func() {
+		actions, err := evacuation.ParseShutdownActions(map[string]string{"SIGTERM": "evacuate", "SIGINT": "stop"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(actions).To(Equal(map[os.Signal]evacuation.ShutdownAction{
+			syscall.SIGTERM: evacuation.ShutdownActionEvacuate,
+			syscall.SIGINT:  evacuation.ShutdownActionStop,
+		}))
+	})
+
+	It("rejects unknown signals", func() {
+		_, err := evacuation.ParseShutdownActions(map[string]string{"SIGHUP": "stop"})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("rejects unknown actions", func() {
+		_, err := evacuation.ParseShutdownActions(map[string]string{"SIGTERM": "drain"})
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("SignalRouter", func() {
+	var (
+		fakeEvacuatable *fake_evacuation_context.FakeEvacuatable
+		received        chan os.Signal
+		process         ifrit.Process
+	)
+
+	BeforeEach(func() {
+		fakeEvacuatable = &fake_evacuation_context.FakeEvacuatable{}
+		fakeEvacuatable.EvacuateReturns(true)
+		received = make(chan os.Signal, 1)
+
+		runner := ifrit.RunFunc(func(signals <-chan os.Signal, ready chan<- struct{}) error {
+			close(ready)
+			signal := <-signals
+			received <- signal
+			return nil
+		})
+
+		router := evacuation.NewSignalRouter(lagertest.NewTestLogger("test"), runner, fakeEvacuatable, map[os.Signal]evacuation.ShutdownAction{
+			syscall.SIGTERM: evacuation.ShutdownActionEvacuate,
+		})
+		process = ginkgomon_v2.Invoke(router)
+	})
+
+	AfterEach(func() {
+		ginkgomon_v2.Kill(process)
+	})
+
+	It("evacuates on a signal mapped to evacuate without stopping the members", func() {
+		process.Signal(syscall.SIGTERM)
+		Eventually(fakeEvacuatable.EvacuateCallCount).Should(Equal(1))
+		Consistently(received).ShouldNot(Receive())
+	})
+
+	It("stops the members when already evacuating", func() {
+		fakeEvacuatable.EvacuateReturns(false)
+		process.Signal(syscall.SIGTERM)
+		Eventually(received).Should(Receive(Equal(syscall.SIGTERM)))
+		Eventually(process.Wait()).Should(Receive(BeNil()))
+	})
+
+	It("passes other signals on to the members", func() {
+		process.Signal(syscall.SIGINT)
+		Eventually(received).Should(Receive(Equal(syscall.SIGINT)))
+		Eventually(process.Wait()).Should(Receive(BeNil()))
+		Expect(fakeEvacuatable.EvacuateCallCount()).To(Equal(0))
+	})
+
+	Context("when the members are not ready yet", func() {
+		var notReadyProcess ifrit.Process
+
+		BeforeEach(func() {
+			runner := ifrit.RunFunc(func(signals <-chan os.Signal, ready chan<- struct{}) error {
+				signal := <-signals
+				received <- signal
+				return nil
+			})
+
+			router := evacuation.NewSignalRouter(lagertest.NewTestLogger("test"), runner, fakeEvacuatable, map[os.Signal]evacuation.ShutdownAction{
+				syscall.SIGTERM: evacuation.ShutdownActionEvacuate,
+			})
+			notReadyProcess = ifrit.Background(router)
+		})
+
+		AfterEach(func() {
+			ginkgomon_v2.Kill(notReadyProcess)
+		})
+
+		It("passes every signal on to the members", func() {
+			notReadyProcess.Signal(syscall.SIGTERM)
+			Eventually(received).Should(Receive(Equal(syscall.SIGTERM)))
+			Eventually(notReadyProcess.Wait()).Should(Receive(BeNil()))
+			Expect(fakeEvacuatable.EvacuateCallCount()).To(Equal(0))
+		})
+	})
+})
@@ -0,0 +1,12 @@
+package eviction_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"testing"
+)
+
+func TestEviction(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Eviction Suite")
+}
@@ -0,0 +1,192 @@
+package eviction
+
+import (
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"code.cloudfoundry.org/bbs"
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/executor"
+	"code.cloudfoundry.org/lager/v3"
+	"code.cloudfoundry.org/rep"
+)
+
+// EvictionReason is reported to the BBS as the crash reason of evicted LRPs.
+const EvictionReason = "evicted under memory pressure"
+
+// DefaultThreshold is the fraction of the cell's memory in use above which
+// containers are evicted when no threshold is configured.
+const DefaultThreshold = 0.95
+
+// Evictor is an ifrit.Runner that periodically compares the memory used by
+// the cell's containers with its total memory. Once usage goes above the
+// threshold it evicts LRP containers, lowest priority first, until usage is
+// back under it. Evicted instances are crashed in the BBS so they get
+// rescheduled, rather than being left for the kernel OOM killer.
+type Evictor struct {
+	logger                   lager.Logger
+	clock                    clock.Clock
+	executorClient           executor.Client
+	containerMetricsProvider rep.ContainerMetricsProvider
+	bbsClient                bbs.InternalClient
+	cellID                   string
+	interval                 time.Duration
+	threshold                float64
+}
+
+func NewEvictor(
+	logger lager.Logger,
+	clk clock.Clock,
+	executorClient executor.Client,
+	containerMetricsProvider rep.ContainerMetricsProvider,
+	bbsClient bbs.InternalClient,
+	cellID string,
+	interval time.Duration,
+	threshold float64,
+) *Evictor {
+	if threshold <= 0 || threshold > 1 {
+		threshold = DefaultThreshold
+	}
+
+	return &Evictor{
+		logger:                   logger.Session("memory-pressure-evictor"),
+		clock:                    clk,
+		executorClient:           executorClient,
+		containerMetricsProvider: containerMetricsProvider,
+		bbsClient:                bbsClient,
+		cellID:                   cellID,
+		interval:                 interval,
+		threshold:                threshold,
+	}
+}
+
+func (e *Evictor) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	close(ready)
+
+	ticker := e.clock.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-signals:
+			return nil
+		case <-ticker.C():
+			e.evict(e.logger.Session("check"))
+		}
+	}
+}
+
+type candidate struct {
+	container   executor.Container
+	priority    int
+	memoryBytes uint64
+}
+
+func (e *Evictor) evict(logger lager.Logger) {
+	total, err := e.executorClient.TotalResources(logger)
+	if err != nil {
+		logger.Error("failed-to-get-total-resources", err)
+		return
+	}
+
+	containers, err := e.executorClient.ListContainers(logger)
+	if err != nil {
+		logger.Error("failed-to-list-containers", err)
+		return
+	}
+
+	metrics := e.containerMetricsProvider.Metrics()
+
+	var usedBytes uint64
+	candidates := []candidate{}
+	for _, container := range containers {
+		containerMetrics, ok := metrics[container.Guid]
+		if !ok {
+			continue
+		}
+		usedBytes += containerMetrics.MemoryUsageBytes
+
+		if container.Tags[rep.LifecycleTag] != rep.LRPLifecycle {
+			continue
+		}
+		candidates = append(candidates, candidate{
+			container:   container,
+			priority:    priority(container),
+			memoryBytes: containerMetrics.MemoryUsageBytes,
+		})
+	}
+
+	limitBytes := uint64(float64(total.MemoryMB) * 1024 * 1024 * e.threshold)
+	if usedBytes <= limitBytes {
+		return
+	}
+
+	logger.Info("memory-pressure-detected", lager.Data{"used-bytes": usedBytes, "limit-bytes": limitBytes})
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].priority != candidates[j].priority {
+			return candidates[i].priority < candidates[j].priority
+		}
+		return candidates[i].memoryBytes > candidates[j].memoryBytes
+	})
+
+	traceID := "" // evictions are not originated through API
+	for _, c := range candidates {
+		if usedBytes <= limitBytes {
+			return
+		}
+
+		if e.evictContainer(logger, traceID, c) {
+			usedBytes -= c.memoryBytes
+		}
+	}
+}
+
+func (e *Evictor) evictContainer(logger lager.Logger, traceID string, c candidate) bool {
+	logger = logger.Session("evicting-container", lager.Data{
+		"container-guid": c.container.Guid,
+		"priority":       c.priority,
+		"memory-bytes":   c.memoryBytes,
+	})
+
+	lrpKey, err := rep.ActualLRPKeyFromTags(c.container.Tags)
+	if err != nil {
+		logger.Error("failed-to-generate-lrp-key", err)
+		return false
+	}
+
+	instanceKey, err := rep.ActualLRPInstanceKeyFromContainer(c.container, e.cellID)
+	if err != nil {
+		logger.Error("failed-to-generate-instance-key", err)
+		return false
+	}
+
+	err = e.bbsClient.CrashActualLRP(logger, traceID, lrpKey, instanceKey, EvictionReason)
+	if err != nil {
+		logger.Error("failed-to-crash-actual-lrp", err)
+		return false
+	}
+
+	err = e.executorClient.DeleteContainer(logger, traceID, c.container.Guid)
+	if err != nil {
+		logger.Error("failed-to-delete-container", err)
+	}
+
+	logger.Info("evicted")
+	return true
+}
+
+func priority(container executor.Container) int {
+	value, ok := container.Tags[rep.PriorityTag]
+	if !ok {
+		return 0
+	}
+
+	p, err := strconv.Atoi(value)
+	if err != nil {
+		return 0
+	}
+	return p
+}
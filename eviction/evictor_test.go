@@ -0,0 +1,150 @@
+package eviction_test
+
+import (
+	"errors"
+	"time"
+
+	"code.cloudfoundry.org/bbs/fake_bbs"
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/executor"
+	"code.cloudfoundry.org/executor/containermetrics"
+	fakeexecutor "code.cloudfoundry.org/executor/fakes"
+	"code.cloudfoundry.org/lager/v3/lagertest"
+	"code.cloudfoundry.org/rep"
+	"code.cloudfoundry.org/rep/auctioncellrep/auctioncellrepfakes"
+	"code.cloudfoundry.org/rep/eviction"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/tedsuo/ifrit"
+	ginkgomon "github.com/tedsuo/ifrit/ginkgomon_v2"
+)
+
+const mebibyte = 1024 * 1024
+
+var _ = Describe("Evictor", func() {
+	const interval = 10 * time.Second
+
+	var (
+		logger                   *lagertest.TestLogger
+		fakeClock                *fakeclock.FakeClock
+		executorClient           *fakeexecutor.FakeClient
+		containerMetricsProvider *auctioncellrepfakes.FakeContainerMetricsProvider
+		bbsClient                *fake_bbs.FakeInternalClient
+		containers               []executor.Container
+		usage                    map[string]*containermetrics.CachedContainerMetrics
+		process                  ifrit.Process
+	)
+
+	lrpContainer := func(guid, priority string) executor.Container {
+		tags := executor.Tags{
+			rep.LifecycleTag:    rep.LRPLifecycle,
+			rep.DomainTag:       "domain",
+			rep.ProcessGuidTag:  "process-" + guid,
+			rep.ProcessIndexTag: "0",
+			rep.InstanceGuidTag: "instance-" + guid,
+		}
+		if priority != "" {
+			tags[rep.PriorityTag] = priority
+		}
+		return executor.Container{Guid: guid, Tags: tags}
+	}
+
+	addContainer := func(container executor.Container, usedMB uint64) {
+		containers = append(containers, container)
+		usage[container.Guid] = &containermetrics.CachedContainerMetrics{MemoryUsageBytes: usedMB * mebibyte}
+	}
+
+	evictedGuids := func() []string {
+		guids := []string{}
+		for i := 0; i < executorClient.DeleteContainerCallCount(); i++ {
+			_, _, guid := executorClient.DeleteContainerArgsForCall(i)
+			guids = append(guids, guid)
+		}
+		return guids
+	}
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("test")
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		executorClient = new(fakeexecutor.FakeClient)
+		containerMetricsProvider = new(auctioncellrepfakes.FakeContainerMetricsProvider)
+		bbsClient = new(fake_bbs.FakeInternalClient)
+		containers = nil
+		usage = map[string]*containermetrics.CachedContainerMetrics{}
+
+		executorClient.TotalResourcesReturns(executor.ExecutorResources{MemoryMB: 1000}, nil)
+	})
+
+	JustBeforeEach(func() {
+		executorClient.ListContainersReturns(containers, nil)
+		containerMetricsProvider.MetricsReturns(usage)
+
+		evictor := eviction.NewEvictor(logger, fakeClock, executorClient, containerMetricsProvider, bbsClient, "cell-id", interval, 0.9)
+		process = ginkgomon.Invoke(evictor)
+		fakeClock.WaitForWatcherAndIncrement(interval)
+	})
+
+	AfterEach(func() {
+		ginkgomon.Interrupt(process)
+	})
+
+	Context("when memory usage is under the threshold", func() {
+		BeforeEach(func() {
+			addContainer(lrpContainer("a", ""), 400)
+			addContainer(lrpContainer("b", ""), 400)
+		})
+
+		It("does not evict anything", func() {
+			Consistently(executorClient.DeleteContainerCallCount).Should(BeZero())
+			Expect(bbsClient.CrashActualLRPCallCount()).To(BeZero())
+		})
+	})
+
+	Context("when memory usage is over the threshold", func() {
+		BeforeEach(func() {
+			addContainer(lrpContainer("high", "10"), 300)
+			addContainer(lrpContainer("low-small", "1"), 100)
+			addContainer(lrpContainer("low-large", "1"), 200)
+			addContainer(lrpContainer("default", ""), 150)
+			addContainer(executor.Container{Guid: "task", Tags: executor.Tags{rep.LifecycleTag: rep.TaskLifecycle}}, 250)
+		})
+
+		It("evicts the lowest priority containers until usage is back under it", func() {
+			// 1000MB in use against a 900MB limit: evicting the default
+			// priority container brings it down to 850MB
+			Eventually(evictedGuids).Should(Equal([]string{"default"}))
+			Consistently(evictedGuids).Should(Equal([]string{"default"}))
+		})
+
+		It("crashes the evicted instances in the BBS so they get rescheduled", func() {
+			Eventually(bbsClient.CrashActualLRPCallCount).Should(Equal(1))
+			_, _, key, instanceKey, reason := bbsClient.CrashActualLRPArgsForCall(0)
+			Expect(key.ProcessGuid).To(Equal("process-default"))
+			Expect(instanceKey.InstanceGuid).To(Equal("instance-default"))
+			Expect(instanceKey.CellId).To(Equal("cell-id"))
+			Expect(reason).To(Equal(eviction.EvictionReason))
+		})
+
+		Context("and more memory needs to be freed", func() {
+			BeforeEach(func() {
+				addContainer(lrpContainer("extra", "5"), 200)
+			})
+
+			It("evicts by ascending priority, largest first within a priority", func() {
+				// 1200MB in use against a 900MB limit
+				Eventually(evictedGuids).Should(Equal([]string{"default", "low-large"}))
+				Consistently(evictedGuids).Should(Equal([]string{"default", "low-large"}))
+			})
+		})
+
+		Context("and crashing an instance fails", func() {
+			BeforeEach(func() {
+				bbsClient.CrashActualLRPReturnsOnCall(0, errors.New("boom"))
+			})
+
+			It("keeps the container and moves on to the next one", func() {
+				Eventually(evictedGuids).Should(Equal([]string{"low-large"}))
+			})
+		})
+	})
+})
@@ -0,0 +1 @@
+package eviction // import "code.cloudfoundry.org/rep/eviction"
@@ -0,0 +1,82 @@
+package rep
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ExtraRootFSMaxReachedReason is the reason given for the tarballs skipped
+// once the maximum number of extra rootfses has been loaded.
+const ExtraRootFSMaxReachedReason = "max extra rootfs reached"
+
+// ExtraRootFSLoad is the outcome of loading one tarball found in the extra
+// rootfs directory. Reason explains why a tarball was not loaded.
+type ExtraRootFSLoad struct {
+	Path   string `json:"path"`
+	Name   string `json:"name"`
+	Loaded bool   `json:"loaded"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// LoadExtraRootFSes walks dir for rootfs tarballs, named after their file
+// without the .tar extension. A positive maxExtraRootFS caps how many are
+// loaded. Entries that cannot be read are skipped, and a tarball is replaced
+// by a later one of the same name. The outcome for every tarball is returned
+// along with the loaded ones. An error is only returned when dir itself
+// cannot be walked.
+func LoadExtraRootFSes(dir string, maxExtraRootFS int) (StackPathMap, []ExtraRootFSLoad, error) {
+	extraRootFSes := make(StackPathMap)
+	loads := []ExtraRootFSLoad{}
+	loadIndex := map[string]int{}
+
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == dir {
+				return err
+			}
+			loads = append(loads, ExtraRootFSLoad{Path: path, Reason: err.Error()})
+			if d != nil && d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		ext := filepath.Ext(path)
+		if d.IsDir() || !strings.EqualFold(ext, ".tar") {
+			return nil
+		}
+
+		load := ExtraRootFSLoad{Path: path, Name: strings.TrimSuffix(filepath.Base(path), ext)}
+		if maxExtraRootFS > 0 && len(extraRootFSes) >= maxExtraRootFS {
+			load.Reason = ExtraRootFSMaxReachedReason
+		} else if err := checkReadable(path); err != nil {
+			load.Reason = err.Error()
+		} else {
+			if previous, ok := loadIndex[load.Name]; ok {
+				loads[previous].Loaded = false
+				loads[previous].Reason = fmt.Sprintf("replaced by %s", path)
+			}
+			load.Loaded = true
+			loadIndex[load.Name] = len(loads)
+			extraRootFSes[load.Name] = path
+		}
+		loads = append(loads, load)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return extraRootFSes, loads, nil
+}
+
+func checkReadable(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,74 @@
+package rep_test
+
+import (
+	"os"
+	"path/filepath"
+
+	"code.cloudfoundry.org/rep"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("LoadExtraRootFSes", func() {
+	var dir string
+
+	writeTarball := func(path string) string {
+		Expect(os.MkdirAll(filepath.Dir(path), 0755)).To(Succeed())
+		Expect(os.WriteFile(path, nil, 0644)).To(Succeed())
+		return path
+	}
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+	})
+
+	It("loads every readable tarball and reports the ones that were skipped", func() {
+		first := writeTarball(filepath.Join(dir, "a-rootfs.tar"))
+		second := writeTarball(filepath.Join(dir, "nested", "b-rootfs.TAR"))
+		writeTarball(filepath.Join(dir, "notes.txt"))
+		dangling := filepath.Join(dir, "c-rootfs.tar")
+		Expect(os.Symlink(filepath.Join(dir, "missing.tar"), dangling)).To(Succeed())
+
+		extraRootFSes, loads, err := rep.LoadExtraRootFSes(dir, 0)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(extraRootFSes).To(Equal(rep.StackPathMap{"a-rootfs": first, "b-rootfs": second}))
+
+		Expect(loads).To(HaveLen(3))
+		Expect(loads[0]).To(Equal(rep.ExtraRootFSLoad{Path: first, Name: "a-rootfs", Loaded: true}))
+		Expect(loads[1].Path).To(Equal(dangling))
+		Expect(loads[1].Loaded).To(BeFalse())
+		Expect(loads[1].Reason).To(ContainSubstring("no such file"))
+		Expect(loads[2]).To(Equal(rep.ExtraRootFSLoad{Path: second, Name: "b-rootfs", Loaded: true}))
+	})
+
+	It("reports a tarball replaced by a later one of the same name", func() {
+		replaced := writeTarball(filepath.Join(dir, "a", "rootfs.tar"))
+		replacement := writeTarball(filepath.Join(dir, "b", "rootfs.tar"))
+
+		extraRootFSes, loads, err := rep.LoadExtraRootFSes(dir, 0)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(extraRootFSes).To(Equal(rep.StackPathMap{"rootfs": replacement}))
+		Expect(loads).To(Equal([]rep.ExtraRootFSLoad{
+			{Path: replaced, Name: "rootfs", Loaded: false, Reason: "replaced by " + replacement},
+			{Path: replacement, Name: "rootfs", Loaded: true},
+		}))
+	})
+
+	It("skips the tarballs beyond the maximum", func() {
+		loaded := writeTarball(filepath.Join(dir, "a-rootfs.tar"))
+		skipped := writeTarball(filepath.Join(dir, "b-rootfs.tar"))
+
+		extraRootFSes, loads, err := rep.LoadExtraRootFSes(dir, 1)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(extraRootFSes).To(Equal(rep.StackPathMap{"a-rootfs": loaded}))
+		Expect(loads).To(Equal([]rep.ExtraRootFSLoad{
+			{Path: loaded, Name: "a-rootfs", Loaded: true},
+			{Path: skipped, Name: "b-rootfs", Loaded: false, Reason: rep.ExtraRootFSMaxReachedReason},
+		}))
+	})
+
+	It("fails when the directory cannot be walked", func() {
+		_, _, err := rep.LoadExtraRootFSes(filepath.Join(dir, "missing"), 0)
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,13 @@
+package fdusage_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestFDUsage(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "FD Usage Suite")
+}
@@ -0,0 +1 @@
+package fdusage // import "code.cloudfoundry.org/rep/fdusage"
@@ -0,0 +1,46 @@
+package fdusage
+
+import (
+	"math"
+
+	loggingclient "code.cloudfoundry.org/diego-logging-client"
+	"code.cloudfoundry.org/lager/v3"
+)
+
+const (
+	openFileDescriptorsMetric = "OpenFileDescriptors"
+	fileDescriptorLimitMetric = "FileDescriptorLimit"
+)
+
+// Reporter emits the rep's open file descriptor count and limit as gauges.
+type Reporter struct {
+	logger       lager.Logger
+	metronClient loggingclient.IngressClient
+}
+
+func NewReporter(logger lager.Logger, metronClient loggingclient.IngressClient) *Reporter {
+	return &Reporter{
+		logger:       logger.Session("fd-usage-reporter"),
+		metronClient: metronClient,
+	}
+}
+
+func (r *Reporter) Report() {
+	usage, err := Read()
+	if err != nil {
+		r.logger.Error("failed-to-read-fd-usage", err)
+		return
+	}
+
+	err = r.metronClient.SendMetric(openFileDescriptorsMetric, usage.Open)
+	if err != nil {
+		r.logger.Error("failed-to-send-open-fds-metric", err)
+	}
+
+	// an unlimited process reports the largest limit a gauge can carry
+	limit := int(min(usage.Limit, math.MaxInt))
+	err = r.metronClient.SendMetric(fileDescriptorLimitMetric, limit)
+	if err != nil {
+		r.logger.Error("failed-to-send-fd-limit-metric", err)
+	}
+}
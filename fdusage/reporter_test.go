@@ -0,0 +1,50 @@
+package fdusage_test
+
+import (
+	"os"
+
+	mfakes "code.cloudfoundry.org/diego-logging-client/testhelpers"
+	"code.cloudfoundry.org/lager/v3/lagertest"
+	"code.cloudfoundry.org/rep/fdusage"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Read", func() {
+	It("reports a plausible usage for the current process", func() {
+		usage, err := fdusage.Read()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(usage.Open).To(BeNumerically(">=", 3))
+		Expect(usage.Limit).To(BeNumerically(">=", uint64(usage.Open)))
+	})
+
+	It("counts newly opened files", func() {
+		before, err := fdusage.Read()
+		Expect(err).NotTo(HaveOccurred())
+
+		file, err := os.Open(os.DevNull)
+		Expect(err).NotTo(HaveOccurred())
+		defer file.Close()
+
+		after, err := fdusage.Read()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(after.Open).To(BeNumerically(">", before.Open))
+	})
+})
+
+var _ = Describe("Reporter", func() {
+	It("emits the open descriptor count and limit", func() {
+		fakeMetronClient := new(mfakes.FakeIngressClient)
+		fdusage.NewReporter(lagertest.NewTestLogger("test"), fakeMetronClient).Report()
+
+		Expect(fakeMetronClient.SendMetricCallCount()).To(Equal(2))
+
+		name, open, _ := fakeMetronClient.SendMetricArgsForCall(0)
+		Expect(name).To(Equal("OpenFileDescriptors"))
+		Expect(open).To(BeNumerically(">", 0))
+
+		name, limit, _ := fakeMetronClient.SendMetricArgsForCall(1)
+		Expect(name).To(Equal("FileDescriptorLimit"))
+		Expect(limit).To(BeNumerically(">=", open))
+	})
+})
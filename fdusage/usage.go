@@ -0,0 +1,8 @@
+package fdusage
+
+// Usage is the number of file descriptors the process has open, and the
+// most it may have open at once.
+type Usage struct {
+	Open  int
+	Limit uint64
+}
@@ -0,0 +1,25 @@
+//go:build linux || darwin
+
+package fdusage
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// Read returns the file descriptor usage of the current process.
+func Read() (Usage, error) {
+	entries, err := os.ReadDir("/dev/fd")
+	if err != nil {
+		return Usage{}, err
+	}
+
+	var limit unix.Rlimit
+	if err := unix.Getrlimit(unix.RLIMIT_NOFILE, &limit); err != nil {
+		return Usage{}, err
+	}
+
+	// reading the directory holds one descriptor open that is closed again
+	return Usage{Open: len(entries) - 1, Limit: limit.Cur}, nil
+}
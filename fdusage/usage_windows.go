@@ -0,0 +1,11 @@
+//go:build windows
+
+package fdusage
+
+import "errors"
+
+// Read is not implemented on Windows, which has no per-process file
+// descriptor limit comparable to RLIMIT_NOFILE.
+func Read() (Usage, error) {
+	return Usage{}, errors.New("file descriptor usage is not supported on windows")
+}
@@ -0,0 +1,38 @@
+package generator
+
+import (
+	"sync"
+	"time"
+)
+
+// firstSeenTracker remembers, across bulk loops, when each guid was first
+// reported to it. The zero value is ready to use.
+type firstSeenTracker struct {
+	lock      sync.Mutex
+	firstSeen map[string]time.Time
+}
+
+// expired records guids as seen at now and returns those first seen at
+// least threshold ago. Guids missing from guids are forgotten, so a guid
+// reported again later starts over.
+func (t *firstSeenTracker) expired(now time.Time, guids []string, threshold time.Duration) []string {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	seen := make(map[string]time.Time, len(guids))
+	expired := []string{}
+	for _, guid := range guids {
+		firstSeen, ok := t.firstSeen[guid]
+		if !ok {
+			firstSeen = now
+		}
+		seen[guid] = firstSeen
+
+		if now.Sub(firstSeen) >= threshold {
+			expired = append(expired, guid)
+		}
+	}
+	t.firstSeen = seen
+
+	return expired
+}
@@ -2,7 +2,11 @@
 package generator
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
 
 	"code.cloudfoundry.org/bbs"
 	"code.cloudfoundry.org/bbs/models"
@@ -27,13 +31,53 @@ type Generator interface {
 	OperationStream(lager.Logger) (<-chan operationq.Operation, error)
 }
 
+// RootFSMountRecorder is told whether the containers the executor reports on
+// managed to mount their rootfs.
+type RootFSMountRecorder interface {
+	RecordMountFailure(logger lager.Logger, rootFSPath string)
+	RecordMountSuccess(logger lager.Logger, rootFSPath string)
+}
+
+// StateInvalidator is told whenever a container changes, since the cell's
+// advertised state may no longer reflect it.
+type StateInvalidator interface {
+	InvalidateState()
+}
+
 type generator struct {
-	cellID            string
-	bbs               bbs.InternalClient
-	executorClient    executor.Client
-	lrpProcessor      internal.LRPProcessor
-	taskProcessor     internal.TaskProcessor
-	containerDelegate internal.ContainerDelegate
+	cellID              string
+	bbs                 bbs.InternalClient
+	executorClient      executor.Client
+	lrpProcessor        internal.LRPProcessor
+	taskProcessor       internal.TaskProcessor
+	containerDelegate   internal.ContainerDelegate
+	startupTaskPolicy   StartupTaskPolicy
+	rootFSMountRecorder RootFSMountRecorder
+	stateInvalidator    StateInvalidator
+	removedRootFSPolicy RemovedRootFSPolicy
+	metronClient        loggingclient.IngressClient
+	orphanReaper        *OrphanContainerReaper
+	stuckCreating       *StuckCreatingDetector
+}
+
+const (
+	containersWithRemovedRootFSMetric = "ContainersWithRemovedRootFS"
+	containersStuckCreatingMetric     = "ContainersStuckCreating"
+)
+
+// Options holds the optional behaviour of the generator.
+type Options struct {
+	ReconciliationPolicy      ReconciliationPolicy
+	StartupTaskPolicy         StartupTaskPolicy
+	MaxExecutorRejections     int
+	AllowPrivilegedContainers bool
+	RootFSMountRecorder       RootFSMountRecorder
+	StateInvalidator          StateInvalidator
+	RemovedRootFSPolicy       RemovedRootFSPolicy
+	OrphanReaper              *OrphanContainerReaper
+	AllowedPortRange          PortRange
+	StuckCreating             *StuckCreatingDetector
+	EvacuationExcludedDomains []string
 }
 
 func New(
@@ -45,18 +89,33 @@ func New(
 	executorClient executor.Client,
 	metronClient loggingclient.IngressClient,
 	evacuationReporter evacuation_context.EvacuationReporter,
+	options Options,
 ) Generator {
 	containerDelegate := internal.NewContainerDelegate(executorClient)
-	lrpProcessor := internal.NewLRPProcessor(bbs, containerDelegate, metronClient, cellID, availabilityZone, stackPathMap, layeringMode, evacuationReporter)
-	taskProcessor := internal.NewTaskProcessor(bbs, containerDelegate, cellID, stackPathMap, layeringMode)
+	invalidContainerHandler := internal.NewInvalidContainerHandler(containerDelegate, options.ReconciliationPolicy)
+	rejectionTracker := internal.NewExecutorRejectionTracker(options.MaxExecutorRejections)
+	lrpProcessor := internal.NewLRPProcessor(bbs, containerDelegate, metronClient, cellID, availabilityZone, stackPathMap, layeringMode, evacuationReporter, options.EvacuationExcludedDomains, invalidContainerHandler, rejectionTracker, options.AllowPrivilegedContainers, options.AllowedPortRange)
+	taskProcessor := internal.NewTaskProcessor(bbs, containerDelegate, cellID, stackPathMap, layeringMode, invalidContainerHandler, options.AllowPrivilegedContainers)
+
+	rootFSMountRecorder := options.RootFSMountRecorder
+	if rootFSMountRecorder == nil {
+		rootFSMountRecorder = RootFSMountRecorders{}
+	}
 
 	return &generator{
-		cellID:            cellID,
-		bbs:               bbs,
-		executorClient:    executorClient,
-		lrpProcessor:      lrpProcessor,
-		taskProcessor:     taskProcessor,
-		containerDelegate: containerDelegate,
+		cellID:              cellID,
+		bbs:                 bbs,
+		executorClient:      executorClient,
+		lrpProcessor:        lrpProcessor,
+		taskProcessor:       taskProcessor,
+		containerDelegate:   containerDelegate,
+		startupTaskPolicy:   options.StartupTaskPolicy,
+		rootFSMountRecorder: rootFSMountRecorder,
+		stateInvalidator:    options.StateInvalidator,
+		removedRootFSPolicy: options.RemovedRootFSPolicy,
+		metronClient:        metronClient,
+		orphanReaper:        options.OrphanReaper,
+		stuckCreating:       options.StuckCreating,
 	}
 }
 
@@ -134,11 +193,54 @@ func (g *generator) BatchOperations(logger lager.Logger) (map[string]operationq.
 	batch := make(map[string]operationq.Operation)
 
 	// create operations for processes with containers
-	for guid := range containers {
+	removedRootFSCount := 0
+	removedRootFSes := map[string]bool{}
+	for guid, container := range containers {
+		if rootFSRemoved(container.RootFSPath, removedRootFSes) {
+			removedRootFSCount++
+			logger.Info("found-container-with-removed-rootfs", lager.Data{
+				"container-guid": guid,
+				"rootfs":         container.RootFSPath,
+				"policy":         g.removedRootFSPolicy,
+			})
+			if g.removedRootFSPolicy == RemovedRootFSPolicyCrashReport {
+				batch[guid] = NewRemovedRootFSOperation(logger, traceID, g.cellID, g.bbs, g.containerDelegate, guid)
+				continue
+			}
+		}
 		// bulker batch operations are not originated with trace ID
 		batch[guid] = g.operationFromContainer(logger, traceID, guid)
 	}
 
+	err = g.metronClient.SendComponentMetric(containersWithRemovedRootFSMetric, float64(removedRootFSCount), "Metric")
+	if err != nil {
+		logger.Error("failed-to-send-containers-with-removed-rootfs-metric", err)
+	}
+
+	// replace the operations for containers orphaned beyond their grace period
+	notifyBBS := g.orphanReaper.Policy() == OrphanContainerPolicyNotifyThenReap
+	for _, guid := range g.orphanReaper.Expired(orphanedContainers(containers, instanceLRPs, evacuatingLRPs, tasks)) {
+		logger.Info("reaping-orphaned-container", lager.Data{
+			"container-guid": guid,
+			"policy":         g.orphanReaper.Policy(),
+		})
+		batch[guid] = NewOrphanContainerOperation(logger, traceID, g.cellID, g.bbs, g.containerDelegate, notifyBBS, guid)
+	}
+
+	// replace the operations for containers initializing for too long
+	if g.stuckCreating != nil {
+		stuck := g.stuckCreating.Stuck(containers)
+		for _, guid := range stuck {
+			logger.Info("found-container-stuck-creating", lager.Data{"container-guid": guid})
+			batch[guid] = NewStuckCreatingOperation(logger, traceID, g.cellID, g.bbs, g.containerDelegate, guid)
+		}
+
+		err = g.metronClient.SendComponentMetric(containersStuckCreatingMetric, float64(len(stuck)), "Metric")
+		if err != nil {
+			logger.Error("failed-to-send-containers-stuck-creating-metric", err)
+		}
+	}
+
 	// create operations for instance lrps with no containers
 	for guid, lrp := range instanceLRPs {
 		if _, foundContainer := batch[guid]; foundContainer {
@@ -163,7 +265,7 @@ func (g *generator) BatchOperations(logger lager.Logger) (map[string]operationq.
 	for guid := range tasks {
 		_, found := batch[guid]
 		if !found {
-			batch[guid] = NewResidualTaskOperation(logger, traceID, guid, g.cellID, g.bbs, g.containerDelegate)
+			batch[guid] = NewResidualTaskOperation(logger, traceID, guid, g.cellID, g.bbs, g.containerDelegate, g.startupTaskPolicy)
 		}
 	}
 
@@ -171,6 +273,38 @@ func (g *generator) BatchOperations(logger lager.Logger) (map[string]operationq.
 	return batch, nil
 }
 
+// orphanedContainers returns the guids of the claimed containers with no LRP
+// or task record for this cell in the BBS. Reserved containers are skipped:
+// the BBS only records the cell of an LRP or task once the rep claims it.
+func orphanedContainers(containers map[string]executor.Container, instanceLRPs, evacuatingLRPs map[string]models.ActualLRP, tasks map[string]*models.Task) []string {
+	orphans := []string{}
+	for guid, container := range containers {
+		switch container.State {
+		case executor.StateInitializing, executor.StateCreated, executor.StateRunning:
+		default:
+			continue
+		}
+
+		switch container.Tags[rep.LifecycleTag] {
+		case rep.LRPLifecycle:
+			_, instance := instanceLRPs[guid]
+			_, evacuating := evacuatingLRPs[guid]
+			if instance || evacuating {
+				continue
+			}
+		case rep.TaskLifecycle:
+			if _, ok := tasks[guid]; ok {
+				continue
+			}
+		default:
+			continue
+		}
+
+		orphans = append(orphans, guid)
+	}
+	return orphans
+}
+
 func (g *generator) OperationStream(logger lager.Logger) (<-chan operationq.Operation, error) {
 	streamLogger := logger.Session("operation-stream")
 
@@ -203,6 +337,10 @@ func (g *generator) OperationStream(logger lager.Logger) (<-chan operationq.Oper
 			}
 
 			container := lifecycle.Container()
+			g.recordRootFSMount(streamLogger, container)
+			if g.stateInvalidator != nil {
+				g.stateInvalidator.InvalidateState()
+			}
 			opChan <- g.operationFromContainer(logger, lifecycle.TraceID(), container.Guid)
 		}
 	}()
@@ -210,6 +348,55 @@ func (g *generator) OperationStream(logger lager.Logger) (<-chan operationq.Oper
 	return opChan, nil
 }
 
+// ContainerInitializationFailedReason is the failure reason the executor
+// gives a container that garden could not create, which is how a rootfs that
+// does not mount is reported.
+const ContainerInitializationFailedReason = "failed to initialize container"
+
+// recordRootFSMount reports running containers as successful rootfs mounts
+// and containers that could not be created as failed ones. Containers that
+// fail for any other reason, even retryably, say nothing about their rootfs.
+func (g *generator) recordRootFSMount(logger lager.Logger, container executor.Container) {
+	switch {
+	case container.State == executor.StateRunning:
+		g.rootFSMountRecorder.RecordMountSuccess(logger, container.RootFSPath)
+	case container.State == executor.StateCompleted && container.RunResult.Failed && container.RunResult.FailureReason == ContainerInitializationFailedReason:
+		g.rootFSMountRecorder.RecordMountFailure(logger, container.RootFSPath)
+	}
+}
+
+// rootFSRemoved reports whether the preloaded rootfs a container was created
+// with no longer exists on disk. It is checked again on every bulk loop, as
+// a rootfs can be removed while the rep and its containers keep running.
+// Containers using any other kind of rootfs, such as docker images, are never
+// reported. Results are kept in removed so each path is checked once a loop.
+func rootFSRemoved(rootFSPath string, removed map[string]bool) bool {
+	if rootFSPath == "" {
+		return false
+	}
+
+	u, err := url.Parse(rootFSPath)
+	if err != nil {
+		return false
+	}
+
+	path := rootFSPath
+	switch u.Scheme {
+	case "":
+	case models.PreloadedOCIRootFSScheme:
+		path = u.Path
+	default:
+		return false
+	}
+
+	if result, ok := removed[path]; ok {
+		return result
+	}
+	_, err = os.Stat(path)
+	removed[path] = errors.Is(err, fs.ErrNotExist)
+	return removed[path]
+}
+
 func (g *generator) operationFromContainer(logger lager.Logger, traceID string, guid string) operationq.Operation {
 	return NewContainerOperation(logger, traceID, g.lrpProcessor, g.taskProcessor, g.containerDelegate, guid)
 }
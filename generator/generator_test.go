@@ -2,12 +2,20 @@ package generator_test
 
 import (
 	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"time"
 
 	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/clock/fakeclock"
+	mfakes "code.cloudfoundry.org/diego-logging-client/testhelpers"
 	"code.cloudfoundry.org/executor"
 	efakes "code.cloudfoundry.org/executor/fakes"
 	"code.cloudfoundry.org/operationq"
 	"code.cloudfoundry.org/rep"
+	"code.cloudfoundry.org/rep/auctioncellrep"
 	"code.cloudfoundry.org/rep/evacuation/evacuation_context/fake_evacuation_context"
 	"code.cloudfoundry.org/rep/generator"
 	. "github.com/onsi/ginkgo/v2"
@@ -21,11 +29,30 @@ func (BogusEvent) EventType() executor.EventType {
 	return executor.EventTypeInvalid
 }
 
+type countingStateInvalidator struct {
+	invalidations int32
+}
+
+func (i *countingStateInvalidator) InvalidateState() {
+	atomic.AddInt32(&i.invalidations, 1)
+}
+
+func (i *countingStateInvalidator) Invalidations() int32 {
+	return atomic.LoadInt32(&i.invalidations)
+}
+
 var _ = Describe("Generator", func() {
 	var (
-		cellID             string
-		availabilityZone   string
-		fakeExecutorClient *efakes.FakeClient
+		cellID              string
+		availabilityZone    string
+		fakeExecutorClient  *efakes.FakeClient
+		rootFSQuarantine    *auctioncellrep.RootFSQuarantine
+		stateInvalidator    *countingStateInvalidator
+		fakeMetronClient    *mfakes.FakeIngressClient
+		stackPathMap        rep.StackPathMap
+		removedRootFSPolicy generator.RemovedRootFSPolicy
+		orphanReaper        *generator.OrphanContainerReaper
+		stuckCreating       *generator.StuckCreatingDetector
 
 		opGenerator generator.Generator
 	)
@@ -34,8 +61,27 @@ var _ = Describe("Generator", func() {
 		cellID = "some-cell-id"
 		availabilityZone = "some-zone"
 		fakeExecutorClient = new(efakes.FakeClient)
+		rootFSQuarantine = auctioncellrep.NewRootFSQuarantine(2, 0, rep.StackPathMap{"linux": "/rootfs/linux"}, clock.NewClock(), new(mfakes.FakeIngressClient))
+		stateInvalidator = &countingStateInvalidator{}
+		fakeMetronClient = new(mfakes.FakeIngressClient)
+		stackPathMap = rep.StackPathMap{}
+		removedRootFSPolicy = generator.RemovedRootFSPolicyKeep
+		orphanReaper = nil
+		stuckCreating = nil
+	})
+
+	JustBeforeEach(func() {
 		fakeEvacuationReporter := &fake_evacuation_context.FakeEvacuationReporter{}
-		opGenerator = generator.New(cellID, availabilityZone, rep.StackPathMap{}, "", fakeBBS, fakeExecutorClient, nil, fakeEvacuationReporter)
+		opGenerator = generator.New(cellID, availabilityZone, stackPathMap, "", fakeBBS, fakeExecutorClient, fakeMetronClient, fakeEvacuationReporter, generator.Options{
+			ReconciliationPolicy:      generator.ReconciliationPolicyLogOnly,
+			StartupTaskPolicy:         generator.StartupTaskPolicyFail,
+			AllowPrivilegedContainers: true,
+			RootFSMountRecorder:       rootFSQuarantine,
+			StateInvalidator:          stateInvalidator,
+			RemovedRootFSPolicy:       removedRootFSPolicy,
+			OrphanReaper:              orphanReaper,
+			StuckCreating:             stuckCreating,
+		})
 	})
 
 	Describe("BatchOperations", func() {
@@ -182,6 +228,195 @@ var _ = Describe("Generator", func() {
 				Expect(batch[guid]).To(BeAssignableToTypeOf(new(generator.ResidualTaskOperation)))
 			})
 
+			It("reports no containers with a removed rootfs", func() {
+				Expect(fakeMetronClient.SendComponentMetricCallCount()).To(Equal(1))
+				name, value, _ := fakeMetronClient.SendComponentMetricArgsForCall(0)
+				Expect(name).To(Equal("ContainersWithRemovedRootFS"))
+				Expect(value).To(Equal(0.0))
+			})
+		})
+
+		Context("when containers use a rootfs that was removed from the cell", func() {
+			var rootFSPath, removedRootFSPath string
+
+			BeforeEach(func() {
+				rootFSDir := GinkgoT().TempDir()
+				rootFSPath = filepath.Join(rootFSDir, "linux")
+				removedRootFSPath = filepath.Join(rootFSDir, "removed")
+				Expect(os.WriteFile(rootFSPath, []byte{}, 0600)).To(Succeed())
+
+				fakeExecutorClient.ListContainersReturns([]executor.Container{
+					{Guid: "guid-present-rootfs", Resource: executor.Resource{RootFSPath: rootFSPath}},
+					{Guid: "guid-present-oci-rootfs", Resource: executor.Resource{RootFSPath: "preloaded+layer:" + rootFSPath + "?layer=https://blobstore/layer.tgz"}},
+					{Guid: "guid-docker-rootfs", Resource: executor.Resource{RootFSPath: "docker:///busybox"}},
+					{Guid: "guid-removed-rootfs", Resource: executor.Resource{RootFSPath: removedRootFSPath}},
+					{Guid: "guid-removed-oci-rootfs", Resource: executor.Resource{RootFSPath: "preloaded+layer:" + removedRootFSPath + "?layer=https://blobstore/layer.tgz"}},
+				}, nil)
+			})
+
+			It("emits the number of containers with a removed rootfs", func() {
+				Expect(fakeMetronClient.SendComponentMetricCallCount()).To(Equal(1))
+				name, value, _ := fakeMetronClient.SendComponentMetricArgsForCall(0)
+				Expect(name).To(Equal("ContainersWithRemovedRootFS"))
+				Expect(value).To(Equal(2.0))
+			})
+
+			It("logs the containers with a removed rootfs", func() {
+				Expect(logger).To(Say(sessionName + ".found-container-with-removed-rootfs"))
+			})
+
+			Context("when the policy is keep", func() {
+				It("returns container operations for all of the containers", func() {
+					Expect(batch).To(HaveLen(5))
+					for _, op := range batch {
+						Expect(op).To(BeAssignableToTypeOf(new(generator.ContainerOperation)))
+					}
+				})
+			})
+
+			Context("when the policy is crash-report", func() {
+				BeforeEach(func() {
+					removedRootFSPolicy = generator.RemovedRootFSPolicyCrashReport
+				})
+
+				It("returns removed rootfs operations for the containers with a removed rootfs", func() {
+					Expect(batch["guid-removed-rootfs"]).To(BeAssignableToTypeOf(new(generator.RemovedRootFSOperation)))
+					Expect(batch["guid-removed-oci-rootfs"]).To(BeAssignableToTypeOf(new(generator.RemovedRootFSOperation)))
+				})
+
+				It("returns container operations for the other containers", func() {
+					Expect(batch["guid-present-rootfs"]).To(BeAssignableToTypeOf(new(generator.ContainerOperation)))
+					Expect(batch["guid-present-oci-rootfs"]).To(BeAssignableToTypeOf(new(generator.ContainerOperation)))
+					Expect(batch["guid-docker-rootfs"]).To(BeAssignableToTypeOf(new(generator.ContainerOperation)))
+				})
+
+				It("notices a rootfs removed after an earlier bulk loop", func() {
+					Expect(os.Remove(rootFSPath)).To(Succeed())
+
+					batch, batchErr = opGenerator.BatchOperations(logger)
+					Expect(batchErr).NotTo(HaveOccurred())
+					Expect(batch["guid-present-rootfs"]).To(BeAssignableToTypeOf(new(generator.RemovedRootFSOperation)))
+					Expect(batch["guid-present-oci-rootfs"]).To(BeAssignableToTypeOf(new(generator.RemovedRootFSOperation)))
+				})
+			})
+		})
+
+		Context("when claimed containers have no record in the bbs", func() {
+			var fakeClock *fakeclock.FakeClock
+
+			BeforeEach(func() {
+				fakeClock = fakeclock.NewFakeClock(time.Now())
+				fakeExecutorClient.ListContainersReturns([]executor.Container{
+					{Guid: "guid-orphaned-lrp", State: executor.StateRunning, Tags: executor.Tags{rep.LifecycleTag: rep.LRPLifecycle}},
+					{Guid: "guid-orphaned-task", State: executor.StateCreated, Tags: executor.Tags{rep.LifecycleTag: rep.TaskLifecycle}},
+					{Guid: "guid-reserved-lrp", State: executor.StateReserved, Tags: executor.Tags{rep.LifecycleTag: rep.LRPLifecycle}},
+					{Guid: "guid-lrp", State: executor.StateRunning, Tags: executor.Tags{rep.LifecycleTag: rep.LRPLifecycle}},
+				}, nil)
+				fakeBBS.ActualLRPsReturns([]*models.ActualLRP{
+					{ActualLRPInstanceKey: models.NewActualLRPInstanceKey("guid-lrp", cellID)},
+				}, nil)
+			})
+
+			Context("when no orphan grace period is configured", func() {
+				It("returns container operations for all of the containers", func() {
+					Expect(batch).To(HaveLen(4))
+					for _, op := range batch {
+						Expect(op).To(BeAssignableToTypeOf(new(generator.ContainerOperation)))
+					}
+				})
+			})
+
+			Context("when an orphan grace period is configured", func() {
+				BeforeEach(func() {
+					orphanReaper = generator.NewOrphanContainerReaper(fakeClock, time.Minute, generator.OrphanContainerPolicyReap)
+				})
+
+				It("leaves the containers alone during the grace period", func() {
+					for _, op := range batch {
+						Expect(op).To(BeAssignableToTypeOf(new(generator.ContainerOperation)))
+					}
+				})
+
+				Context("and the grace period has elapsed", func() {
+					JustBeforeEach(func() {
+						fakeClock.Increment(time.Minute)
+						batch, batchErr = opGenerator.BatchOperations(logger)
+					})
+
+					It("returns orphan container operations for the orphaned containers", func() {
+						Expect(batchErr).NotTo(HaveOccurred())
+						Expect(batch["guid-orphaned-lrp"]).To(BeAssignableToTypeOf(new(generator.OrphanContainerOperation)))
+						Expect(batch["guid-orphaned-task"]).To(BeAssignableToTypeOf(new(generator.OrphanContainerOperation)))
+						Expect(logger).To(Say(sessionName + ".reaping-orphaned-container"))
+					})
+
+					It("returns container operations for the reserved and recorded containers", func() {
+						Expect(batch["guid-reserved-lrp"]).To(BeAssignableToTypeOf(new(generator.ContainerOperation)))
+						Expect(batch["guid-lrp"]).To(BeAssignableToTypeOf(new(generator.ContainerOperation)))
+					})
+				})
+			})
+		})
+
+		Context("when containers are initializing", func() {
+			var fakeClock *fakeclock.FakeClock
+
+			BeforeEach(func() {
+				fakeClock = fakeclock.NewFakeClock(time.Now())
+				fakeExecutorClient.ListContainersReturns([]executor.Container{
+					{Guid: "guid-creating-lrp", State: executor.StateInitializing, Tags: executor.Tags{rep.LifecycleTag: rep.LRPLifecycle}},
+					{Guid: "guid-running-lrp", State: executor.StateRunning, Tags: executor.Tags{rep.LifecycleTag: rep.LRPLifecycle}},
+				}, nil)
+				fakeBBS.ActualLRPsReturns([]*models.ActualLRP{
+					{ActualLRPInstanceKey: models.NewActualLRPInstanceKey("guid-creating-lrp", cellID)},
+					{ActualLRPInstanceKey: models.NewActualLRPInstanceKey("guid-running-lrp", cellID)},
+				}, nil)
+			})
+
+			Context("when no maximum creating duration is configured", func() {
+				It("returns container operations for all of the containers", func() {
+					Expect(batch).To(HaveLen(2))
+					for _, op := range batch {
+						Expect(op).To(BeAssignableToTypeOf(new(generator.ContainerOperation)))
+					}
+				})
+			})
+
+			Context("when a maximum creating duration is configured", func() {
+				BeforeEach(func() {
+					stuckCreating = generator.NewStuckCreatingDetector(fakeClock, time.Minute)
+				})
+
+				It("leaves the containers alone within the duration", func() {
+					for _, op := range batch {
+						Expect(op).To(BeAssignableToTypeOf(new(generator.ContainerOperation)))
+					}
+
+					_, value, _ := fakeMetronClient.SendComponentMetricArgsForCall(1)
+					Expect(value).To(Equal(0.0))
+				})
+
+				Context("and a container has been creating for longer", func() {
+					JustBeforeEach(func() {
+						fakeClock.Increment(time.Minute)
+						batch, batchErr = opGenerator.BatchOperations(logger)
+					})
+
+					It("returns a stuck creating operation for it", func() {
+						Expect(batchErr).NotTo(HaveOccurred())
+						Expect(batch["guid-creating-lrp"]).To(BeAssignableToTypeOf(new(generator.StuckCreatingOperation)))
+						Expect(batch["guid-running-lrp"]).To(BeAssignableToTypeOf(new(generator.ContainerOperation)))
+						Expect(logger).To(Say(sessionName + ".found-container-stuck-creating"))
+					})
+
+					It("emits the number of containers stuck creating", func() {
+						Expect(fakeMetronClient.SendComponentMetricCallCount()).To(Equal(4))
+						name, value, _ := fakeMetronClient.SendComponentMetricArgsForCall(3)
+						Expect(name).To(Equal("ContainersStuckCreating"))
+						Expect(value).To(Equal(1.0))
+					})
+				})
+			})
 		})
 
 		Context("when retrieving data fails", func() {
@@ -319,6 +554,28 @@ var _ = Describe("Generator", func() {
 							Eventually(stream).Should(Receive(&operation))
 							Expect(operation.Key()).To(Equal(container.Guid))
 						})
+
+						It("invalidates the cell state", func() {
+							Eventually(stream).Should(Receive())
+							Expect(stateInvalidator.Invalidations()).To(BeEquivalentTo(1))
+						})
+
+						Context("when the operation executes", func() {
+							BeforeEach(func() {
+								container.Tags[rep.InstanceGuidTag] = "some-instance-guid"
+								fakeExecutorClient.GetContainerReturns(container, nil)
+							})
+
+							It("carries the event's trace ID into the BBS calls", func() {
+								var operation operationq.Operation
+								Eventually(stream).Should(Receive(&operation))
+								operation.Execute()
+
+								Expect(fakeBBS.CrashActualLRPCallCount()).To(Equal(1))
+								_, traceID, _, _, _ := fakeBBS.CrashActualLRPArgsForCall(0)
+								Expect(traceID).To(Equal("some-trace-id"))
+							})
+						})
 					})
 
 					Context("when the lifecycle is Task", func() {
@@ -343,6 +600,54 @@ var _ = Describe("Generator", func() {
 					})
 				})
 
+				Context("when containers keep failing to mount their rootfs", func() {
+					var container executor.Container
+
+					BeforeEach(func() {
+						container = executor.Container{
+							Guid:      "some-task-guid",
+							State:     executor.StateCompleted,
+							Resource:  executor.Resource{RootFSPath: "/rootfs/linux"},
+							RunResult: executor.ContainerRunResult{Failed: true, Retryable: true, FailureReason: generator.ContainerInitializationFailedReason},
+							Tags:      executor.Tags{rep.LifecycleTag: rep.TaskLifecycle},
+						}
+					})
+
+					It("quarantines the rootfs", func() {
+						receivedEvents <- executor.NewContainerCompleteEvent(container, "")
+						Eventually(stream).Should(Receive())
+						Expect(rootFSQuarantine.Quarantined("preloaded:linux")).To(BeFalse())
+
+						receivedEvents <- executor.NewContainerCompleteEvent(container, "")
+						Eventually(stream).Should(Receive())
+						Expect(rootFSQuarantine.Quarantined("preloaded:linux")).To(BeTrue())
+					})
+
+					It("starts counting over when a container using the rootfs runs", func() {
+						receivedEvents <- executor.NewContainerCompleteEvent(container, "")
+						Eventually(stream).Should(Receive())
+
+						running := container
+						running.State = executor.StateRunning
+						running.RunResult = executor.ContainerRunResult{}
+						receivedEvents <- executor.NewContainerRunningEvent(running, "")
+						Eventually(stream).Should(Receive())
+
+						receivedEvents <- executor.NewContainerCompleteEvent(container, "")
+						Eventually(stream).Should(Receive())
+						Expect(rootFSQuarantine.Quarantined("preloaded:linux")).To(BeFalse())
+					})
+
+					It("does not count containers that failed for another reason", func() {
+						container.RunResult.FailureReason = "out of memory"
+						receivedEvents <- executor.NewContainerCompleteEvent(container, "")
+						Eventually(stream).Should(Receive())
+						receivedEvents <- executor.NewContainerCompleteEvent(container, "")
+						Eventually(stream).Should(Receive())
+						Expect(rootFSQuarantine.Quarantined("preloaded:linux")).To(BeFalse())
+					})
+				})
+
 				Context("when the event is not a lifecycle event", func() {
 					BeforeEach(func() {
 						receivedEvents <- BogusEvent{}
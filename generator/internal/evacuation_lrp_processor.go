@@ -19,15 +19,24 @@ type evacuationLRPProcessor struct {
 	cellID              string
 	availabilityZone    string
 	evacuatedContainers sync.Map
+	invalidHandler      *InvalidContainerHandler
+	excludedDomains     map[string]struct{}
 }
 
-func newEvacuationLRPProcessor(bbsClient bbs.InternalClient, containerDelegate ContainerDelegate, metronClient loggingclient.IngressClient, cellID string, availabilityZone string) LRPProcessor {
+func newEvacuationLRPProcessor(bbsClient bbs.InternalClient, containerDelegate ContainerDelegate, metronClient loggingclient.IngressClient, cellID string, availabilityZone string, invalidHandler *InvalidContainerHandler, excludedDomains []string) LRPProcessor {
+	domains := make(map[string]struct{}, len(excludedDomains))
+	for _, domain := range excludedDomains {
+		domains[domain] = struct{}{}
+	}
+
 	return &evacuationLRPProcessor{
 		bbsClient:         bbsClient,
 		containerDelegate: containerDelegate,
 		metronClient:      metronClient,
 		cellID:            cellID,
 		availabilityZone:  availabilityZone,
+		invalidHandler:    invalidHandler,
+		excludedDomains:   domains,
 	}
 }
 
@@ -38,6 +47,13 @@ func (p *evacuationLRPProcessor) Process(logger lager.Logger, traceID string, co
 	})
 	logger.Debug("start")
 
+	// the evacuator destroys the containers whose domain opted out of
+	// evacuation, so requesting a replacement for them would only race it
+	if domain := container.Tags[rep.DomainTag]; p.isExcluded(domain) {
+		logger.Debug("skipping-container-excluded-from-evacuation", lager.Data{"domain": domain})
+		return
+	}
+
 	lrpKey, err := rep.ActualLRPKeyFromTags(container.Tags)
 	if err != nil {
 		logger.Error("failed-to-generate-lrp-key", err)
@@ -68,6 +84,11 @@ func (p *evacuationLRPProcessor) Process(logger lager.Logger, traceID string, co
 	}
 }
 
+func (p *evacuationLRPProcessor) isExcluded(domain string) bool {
+	_, excluded := p.excludedDomains[domain]
+	return excluded
+}
+
 func (p *evacuationLRPProcessor) processReservedContainer(logger lager.Logger, traceID string, lrpContainer *lrpContainer) {
 	logger = logger.Session("process-reserved-container")
 	p.evacuateClaimedLRPContainer(logger, traceID, lrpContainer)
@@ -135,7 +156,7 @@ func (p *evacuationLRPProcessor) processCompletedContainer(logger lager.Logger,
 
 func (p *evacuationLRPProcessor) processInvalidContainer(logger lager.Logger, traceID string, lrpContainer *lrpContainer) {
 	logger = logger.Session("process-invalid-container")
-	logger.Error("not-processing-container-in-invalid-state", nil)
+	p.invalidHandler.Handle(logger, traceID, lrpContainer.Container)
 }
 
 func (p *evacuationLRPProcessor) evacuateClaimedLRPContainer(logger lager.Logger, traceID string, lrpContainer *lrpContainer) {
@@ -57,7 +57,7 @@ var _ = Describe("EvacuationLrpProcessor", func() {
 
 			fakeMetronClient = new(mfakes.FakeIngressClient)
 
-			lrpProcessor = internal.NewLRPProcessor(fakeBBS, fakeContainerDelegate, fakeMetronClient, localCellID, localAvailabilityZone, rep.StackPathMap{}, "", fakeEvacuationReporter)
+			lrpProcessor = internal.NewLRPProcessor(fakeBBS, fakeContainerDelegate, fakeMetronClient, localCellID, localAvailabilityZone, rep.StackPathMap{}, "", fakeEvacuationReporter, nil, internal.NewInvalidContainerHandler(fakeContainerDelegate, internal.ReconciliationPolicyLogOnly), internal.NewExecutorRejectionTracker(0), true, internal.PortRange{})
 
 			processGuid = "process-guid"
 			desiredLRP = models.DesiredLRP{
@@ -95,6 +95,31 @@ var _ = Describe("EvacuationLrpProcessor", func() {
 			lrpProcessor.Process(logger, "some-trace-id", container)
 		})
 
+		Context("when the container's domain is excluded from evacuation", func() {
+			BeforeEach(func() {
+				lrpProcessor = internal.NewLRPProcessor(fakeBBS, fakeContainerDelegate, fakeMetronClient, localCellID, localAvailabilityZone, rep.StackPathMap{}, "", fakeEvacuationReporter, []string{desiredLRP.Domain}, internal.NewInvalidContainerHandler(fakeContainerDelegate, internal.ReconciliationPolicyLogOnly), internal.NewExecutorRejectionTracker(0), true, internal.PortRange{})
+				container.State = executor.StateRunning
+			})
+
+			It("leaves the container to the evacuator", func() {
+				Expect(fakeBBS.EvacuateRunningActualLRPCallCount()).To(Equal(0))
+				Expect(fakeMetronClient.SendAppLogCallCount()).To(Equal(0))
+				Expect(fakeContainerDelegate.DeleteContainerCallCount()).To(Equal(0))
+			})
+
+			Context("and the container has completed", func() {
+				BeforeEach(func() {
+					container.State = executor.StateCompleted
+				})
+
+				It("does not evacuate the lrp", func() {
+					Expect(fakeBBS.EvacuateStoppedActualLRPCallCount()).To(Equal(0))
+					Expect(fakeBBS.EvacuateCrashedActualLRPCallCount()).To(Equal(0))
+					Expect(fakeContainerDelegate.DeleteContainerCallCount()).To(Equal(0))
+				})
+			})
+		})
+
 		Context("when the container is Reserved", func() {
 			BeforeEach(func() {
 				container.State = executor.StateReserved
@@ -0,0 +1,70 @@
+package internal
+
+import (
+	"fmt"
+	"sync"
+
+	"code.cloudfoundry.org/bbs/models"
+)
+
+// ExecutorRejectedReason is the crash reason reported to the BBS for LRP
+// instances the executor kept refusing to run.
+const ExecutorRejectedReason = "executor repeatedly rejected the container"
+
+// ExecutorRejectionTracker counts how many times in a row the executor
+// refused to run each LRP instance. Removing a rejected instance makes the
+// BBS ask for it again, possibly on the same cell, so without a limit a
+// container the executor cannot run is retried forever.
+type ExecutorRejectionTracker struct {
+	maxRejections int
+
+	lock       sync.Mutex
+	rejections map[string]int
+}
+
+// NewExecutorRejectionTracker returns a tracker giving up on an instance
+// after maxRejections consecutive rejections. A non-positive maxRejections
+// never gives up.
+func NewExecutorRejectionTracker(maxRejections int) *ExecutorRejectionTracker {
+	return &ExecutorRejectionTracker{
+		maxRejections: maxRejections,
+		rejections:    map[string]int{},
+	}
+}
+
+// Rejected records a rejection for the instance and reports whether the
+// limit was reached. Reaching the limit starts the count over.
+func (t *ExecutorRejectionTracker) Rejected(key *models.ActualLRPKey) (int, bool) {
+	if t.maxRejections <= 0 {
+		return 0, false
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	id := rejectionID(key)
+	t.rejections[id]++
+	count := t.rejections[id]
+	if count < t.maxRejections {
+		return count, false
+	}
+
+	delete(t.rejections, id)
+	return count, true
+}
+
+// Accepted forgets the rejections of an instance the executor ran.
+func (t *ExecutorRejectionTracker) Accepted(key *models.ActualLRPKey) {
+	if t.maxRejections <= 0 {
+		return
+	}
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	delete(t.rejections, rejectionID(key))
+}
+
+func rejectionID(key *models.ActualLRPKey) string {
+	return fmt.Sprintf("%s/%d", key.ProcessGuid, key.Index)
+}
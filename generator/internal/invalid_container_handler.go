@@ -0,0 +1,60 @@
+package internal
+
+import (
+	"sync"
+
+	"code.cloudfoundry.org/executor"
+	"code.cloudfoundry.org/lager/v3"
+)
+
+// ReconciliationPolicy decides what happens to containers found in a state
+// the processors do not know how to reconcile.
+type ReconciliationPolicy string
+
+const (
+	// ReconciliationPolicyLogOnly logs the container on every pass and leaves
+	// it alone.
+	ReconciliationPolicyLogOnly ReconciliationPolicy = "log-only"
+	// ReconciliationPolicyReap deletes the container.
+	ReconciliationPolicyReap ReconciliationPolicy = "reap"
+	// ReconciliationPolicyQuarantine keeps the container, and its resources,
+	// for investigation and stops reconciling it until the rep restarts.
+	ReconciliationPolicyQuarantine ReconciliationPolicy = "quarantine"
+)
+
+type InvalidContainerHandler struct {
+	containerDelegate ContainerDelegate
+	policy            ReconciliationPolicy
+	quarantined       sync.Map
+}
+
+func NewInvalidContainerHandler(containerDelegate ContainerDelegate, policy ReconciliationPolicy) *InvalidContainerHandler {
+	if policy == "" {
+		policy = ReconciliationPolicyLogOnly
+	}
+
+	return &InvalidContainerHandler{
+		containerDelegate: containerDelegate,
+		policy:            policy,
+	}
+}
+
+func (h *InvalidContainerHandler) Handle(logger lager.Logger, traceID string, container executor.Container) {
+	switch h.policy {
+	case ReconciliationPolicyReap:
+		logger.Info("reaping-container-in-invalid-state")
+		h.containerDelegate.DeleteContainer(logger, traceID, container.Guid)
+	case ReconciliationPolicyQuarantine:
+		logger.Error("quarantining-container-in-invalid-state", nil)
+		h.quarantined.Store(container.Guid, struct{}{})
+	default:
+		logger.Error("not-processing-container-in-invalid-state", nil)
+	}
+}
+
+// Quarantined reports whether the container was quarantined and must be left
+// alone by the processors.
+func (h *InvalidContainerHandler) Quarantined(guid string) bool {
+	_, ok := h.quarantined.Load(guid)
+	return ok
+}
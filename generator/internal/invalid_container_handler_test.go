@@ -0,0 +1,101 @@
+package internal_test
+
+import (
+	"code.cloudfoundry.org/bbs/fake_bbs"
+	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/executor"
+	"code.cloudfoundry.org/lager/v3/lagertest"
+	"code.cloudfoundry.org/rep"
+	"code.cloudfoundry.org/rep/evacuation/evacuation_context/fake_evacuation_context"
+	"code.cloudfoundry.org/rep/generator/internal"
+	"code.cloudfoundry.org/rep/generator/internal/fake_internal"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	. "github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("InvalidContainerHandler", func() {
+	var (
+		logger            *lagertest.TestLogger
+		containerDelegate *fake_internal.FakeContainerDelegate
+		policy            internal.ReconciliationPolicy
+		handler           *internal.InvalidContainerHandler
+		container         executor.Container
+	)
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("test")
+		containerDelegate = new(fake_internal.FakeContainerDelegate)
+		policy = internal.ReconciliationPolicyLogOnly
+		container = executor.Container{Guid: "container-guid", State: executor.StateInvalid}
+	})
+
+	JustBeforeEach(func() {
+		handler = internal.NewInvalidContainerHandler(containerDelegate, policy)
+		handler.Handle(logger, "some-trace-id", container)
+	})
+
+	Context("with the log-only policy", func() {
+		It("logs the container and leaves it alone", func() {
+			Expect(logger).To(Say("not-processing-container-in-invalid-state"))
+			Expect(containerDelegate.DeleteContainerCallCount()).To(BeZero())
+			Expect(handler.Quarantined(container.Guid)).To(BeFalse())
+		})
+	})
+
+	Context("with no policy", func() {
+		BeforeEach(func() {
+			policy = ""
+		})
+
+		It("behaves as log-only", func() {
+			Expect(logger).To(Say("not-processing-container-in-invalid-state"))
+			Expect(containerDelegate.DeleteContainerCallCount()).To(BeZero())
+		})
+	})
+
+	Context("with the reap policy", func() {
+		BeforeEach(func() {
+			policy = internal.ReconciliationPolicyReap
+		})
+
+		It("deletes the container", func() {
+			Expect(logger).To(Say("reaping-container-in-invalid-state"))
+			Expect(containerDelegate.DeleteContainerCallCount()).To(Equal(1))
+			_, traceID, guid := containerDelegate.DeleteContainerArgsForCall(0)
+			Expect(traceID).To(Equal("some-trace-id"))
+			Expect(guid).To(Equal("container-guid"))
+		})
+	})
+
+	Context("with the quarantine policy", func() {
+		BeforeEach(func() {
+			policy = internal.ReconciliationPolicyQuarantine
+		})
+
+		It("quarantines the container without deleting it", func() {
+			Expect(logger).To(Say("quarantining-container-in-invalid-state"))
+			Expect(containerDelegate.DeleteContainerCallCount()).To(BeZero())
+			Expect(handler.Quarantined(container.Guid)).To(BeTrue())
+			Expect(handler.Quarantined("other-guid")).To(BeFalse())
+		})
+
+		It("stops the processors from reconciling the container", func() {
+			evacuationReporter := new(fake_evacuation_context.FakeEvacuationReporter)
+			bbsClient := new(fake_bbs.FakeInternalClient)
+			lrpProcessor := internal.NewLRPProcessor(bbsClient, containerDelegate, nil, "cell-id", "zone", rep.StackPathMap{}, "", evacuationReporter, nil, handler, internal.NewExecutorRejectionTracker(0), true, internal.PortRange{})
+
+			lrpKey := models.NewActualLRPKey("process-guid", 0, "domain")
+			instanceKey := models.NewActualLRPInstanceKey("instance-guid", "cell-id")
+			lrpContainer := newLRPContainer(lrpKey, instanceKey, models.ActualLRPNetInfo{})
+			lrpContainer.Guid = container.Guid
+			lrpContainer.State = executor.StateCompleted
+
+			lrpProcessor.Process(logger, "some-trace-id", lrpContainer)
+			Expect(logger).To(Say("skipping-quarantined-container"))
+			Expect(bbsClient.CrashActualLRPCallCount()).To(BeZero())
+			Expect(bbsClient.RemoveActualLRPCallCount()).To(BeZero())
+			Expect(containerDelegate.DeleteContainerCallCount()).To(BeZero())
+		})
+	})
+})
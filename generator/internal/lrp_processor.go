@@ -34,6 +34,7 @@ type lrpProcessor struct {
 	evacuationReporter  evacuation_context.EvacuationReporter
 	ordinaryProcessor   LRPProcessor
 	evacuationProcessor LRPProcessor
+	invalidHandler      *InvalidContainerHandler
 }
 
 func NewLRPProcessor(
@@ -45,17 +46,28 @@ func NewLRPProcessor(
 	stackPathMap rep.StackPathMap,
 	layeringMode string,
 	evacuationReporter evacuation_context.EvacuationReporter,
+	evacuationExcludedDomains []string,
+	invalidHandler *InvalidContainerHandler,
+	rejectionTracker *ExecutorRejectionTracker,
+	allowPrivileged bool,
+	allowedPortRange PortRange,
 ) LRPProcessor {
-	ordinaryProcessor := newOrdinaryLRPProcessor(bbsClient, containerDelegate, cellID, availabilityZone, stackPathMap, layeringMode)
-	evacuationProcessor := newEvacuationLRPProcessor(bbsClient, containerDelegate, metronClient, cellID, availabilityZone)
+	ordinaryProcessor := newOrdinaryLRPProcessor(bbsClient, containerDelegate, cellID, availabilityZone, stackPathMap, layeringMode, invalidHandler, rejectionTracker, allowPrivileged, allowedPortRange)
+	evacuationProcessor := newEvacuationLRPProcessor(bbsClient, containerDelegate, metronClient, cellID, availabilityZone, invalidHandler, evacuationExcludedDomains)
 	return &lrpProcessor{
 		evacuationReporter:  evacuationReporter,
 		ordinaryProcessor:   ordinaryProcessor,
 		evacuationProcessor: evacuationProcessor,
+		invalidHandler:      invalidHandler,
 	}
 }
 
 func (p *lrpProcessor) Process(logger lager.Logger, traceID string, container executor.Container) {
+	if p.invalidHandler.Quarantined(container.Guid) {
+		logger.Debug("skipping-quarantined-container", lager.Data{"container-guid": container.Guid})
+		return
+	}
+
 	if p.evacuationReporter.Evacuating() {
 		p.evacuationProcessor.Process(logger, traceID, container)
 	} else {
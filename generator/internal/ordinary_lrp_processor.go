@@ -17,6 +17,10 @@ type ordinaryLRPProcessor struct {
 	stackPathMap               rep.StackPathMap
 	layeringMode               string
 	runRequestConversionHelper rep.RunRequestConversionHelper
+	invalidContainerHandler    *InvalidContainerHandler
+	rejectionTracker           *ExecutorRejectionTracker
+	allowPrivileged            bool
+	allowedPortRange           PortRange
 }
 
 func newOrdinaryLRPProcessor(
@@ -26,6 +30,10 @@ func newOrdinaryLRPProcessor(
 	availabilityZone string,
 	stackPathMap rep.StackPathMap,
 	layeringMode string,
+	invalidContainerHandler *InvalidContainerHandler,
+	rejectionTracker *ExecutorRejectionTracker,
+	allowPrivileged bool,
+	allowedPortRange PortRange,
 ) LRPProcessor {
 	runRequestConversionHelper := rep.RunRequestConversionHelper{ECRHelper: ecrhelper.NewECRHelper()}
 
@@ -37,6 +45,10 @@ func newOrdinaryLRPProcessor(
 		stackPathMap:               stackPathMap,
 		layeringMode:               layeringMode,
 		runRequestConversionHelper: runRequestConversionHelper,
+		invalidContainerHandler:    invalidContainerHandler,
+		rejectionTracker:           rejectionTracker,
+		allowPrivileged:            allowPrivileged,
+		allowedPortRange:           allowedPortRange,
 	}
 }
 
@@ -92,6 +104,30 @@ func (p *ordinaryLRPProcessor) processReservedContainer(logger lager.Logger, tra
 		return
 	}
 
+	if desired.Privileged && !p.allowPrivileged {
+		logger.Error("privileged-container-not-allowed", nil)
+		err := p.bbsClient.CrashActualLRP(logger, traceID, lrpContainer.ActualLRPKey, lrpContainer.ActualLRPInstanceKey, PrivilegedContainersNotAllowedReason)
+		if err != nil {
+			logger.Error("failed-to-crash-actual-lrp", err)
+		}
+		p.containerDelegate.DeleteContainer(logger, traceID, lrpContainer.Guid)
+		return
+	}
+
+	if disallowedPorts := p.allowedPortRange.DisallowedPorts(desired.Ports); len(disallowedPorts) > 0 {
+		logger.Error("ports-not-allowed", nil, lager.Data{
+			"disallowed-ports": disallowedPorts,
+			"allowed-min-port": p.allowedPortRange.Min,
+			"allowed-max-port": p.allowedPortRange.Max,
+		})
+		err := p.bbsClient.CrashActualLRP(logger, traceID, lrpContainer.ActualLRPKey, lrpContainer.ActualLRPInstanceKey, PortsNotAllowedReason)
+		if err != nil {
+			logger.Error("failed-to-crash-actual-lrp", err)
+		}
+		p.containerDelegate.DeleteContainer(logger, traceID, lrpContainer.Guid)
+		return
+	}
+
 	runReq, err := p.runRequestConversionHelper.NewRunRequestFromDesiredLRP(lrpContainer.Guid, desired, lrpContainer.ActualLRPKey, lrpContainer.ActualLRPInstanceKey, p.stackPathMap, p.layeringMode)
 	if err != nil {
 		logger.Error("failed-to-construct-run-request", err)
@@ -99,10 +135,22 @@ func (p *ordinaryLRPProcessor) processReservedContainer(logger lager.Logger, tra
 	}
 	ok = p.containerDelegate.RunContainer(logger, traceID, &runReq)
 	if !ok {
+		rejections, exhausted := p.rejectionTracker.Rejected(lrpContainer.ActualLRPKey)
+		if exhausted {
+			logger.Error("executor-rejections-exhausted", nil, lager.Data{"rejections": rejections})
+			err := p.bbsClient.CrashActualLRP(logger, traceID, lrpContainer.ActualLRPKey, lrpContainer.ActualLRPInstanceKey, ExecutorRejectedReason)
+			if err != nil {
+				logger.Error("failed-to-crash-actual-lrp", err)
+			}
+			return
+		}
+
 		// #nosec G104 - ignore errors cleaning up the failed container
 		p.bbsClient.RemoveActualLRP(logger, traceID, lrpContainer.ActualLRPKey, lrpContainer.ActualLRPInstanceKey)
 		return
 	}
+
+	p.rejectionTracker.Accepted(lrpContainer.ActualLRPKey)
 }
 
 func (p *ordinaryLRPProcessor) processInitializingContainer(logger lager.Logger, traceID string, lrpContainer *lrpContainer) {
@@ -158,7 +206,7 @@ func (p *ordinaryLRPProcessor) processCompletedContainer(logger lager.Logger, tr
 
 func (p *ordinaryLRPProcessor) processInvalidContainer(logger lager.Logger, traceID string, lrpContainer *lrpContainer) {
 	logger = logger.Session("process-invalid-container")
-	logger.Error("not-processing-container-in-invalid-state", nil)
+	p.invalidContainerHandler.Handle(logger, traceID, lrpContainer.Container)
 }
 
 func (p *ordinaryLRPProcessor) claimLRPContainer(logger lager.Logger, traceID string, lrpContainer *lrpContainer) bool {
@@ -39,7 +39,7 @@ var _ = Describe("OrdinaryLRPProcessor", func() {
 		containerDelegate = new(fake_internal.FakeContainerDelegate)
 		evacuationReporter = &fake_evacuation_context.FakeEvacuationReporter{}
 		evacuationReporter.EvacuatingReturns(false)
-		processor = internal.NewLRPProcessor(bbsClient, containerDelegate, nil, expectedCellID, expectedAvailabilityZone, rep.StackPathMap{}, "", evacuationReporter)
+		processor = internal.NewLRPProcessor(bbsClient, containerDelegate, nil, expectedCellID, expectedAvailabilityZone, rep.StackPathMap{}, "", evacuationReporter, nil, internal.NewInvalidContainerHandler(containerDelegate, internal.ReconciliationPolicyLogOnly), internal.NewExecutorRejectionTracker(0), true, internal.PortRange{})
 		logger = lagertest.NewTestLogger("test")
 	})
 
@@ -162,6 +162,139 @@ var _ = Describe("OrdinaryLRPProcessor", func() {
 							Expect(actualLRPKey.Index).To(Equal(expectedLrpKey.Index))
 							Expect(*instanceKey).To(Equal(expectedInstanceKey))
 						})
+
+						It("does not crash the actual LRP", func() {
+							Expect(bbsClient.CrashActualLRPCallCount()).To(BeZero())
+						})
+
+						Context("and the executor rejections are limited", func() {
+							BeforeEach(func() {
+								processor = internal.NewLRPProcessor(bbsClient, containerDelegate, nil, expectedCellID, expectedAvailabilityZone, rep.StackPathMap{}, "", evacuationReporter, nil, internal.NewInvalidContainerHandler(containerDelegate, internal.ReconciliationPolicyLogOnly), internal.NewExecutorRejectionTracker(3), true, internal.PortRange{})
+							})
+
+							It("removes the actual LRP until the limit is reached", func() {
+								processor.Process(logger, "some-trace-id", container)
+								Expect(bbsClient.RemoveActualLRPCallCount()).To(Equal(2))
+								Expect(bbsClient.CrashActualLRPCallCount()).To(BeZero())
+							})
+
+							It("crashes the actual LRP once the limit is reached", func() {
+								processor.Process(logger, "some-trace-id", container)
+								processor.Process(logger, "some-trace-id", container)
+
+								Expect(bbsClient.RemoveActualLRPCallCount()).To(Equal(2))
+								Expect(bbsClient.CrashActualLRPCallCount()).To(Equal(1))
+								_, traceID, actualLRPKey, instanceKey, reason := bbsClient.CrashActualLRPArgsForCall(0)
+								Expect(traceID).To(Equal("some-trace-id"))
+								Expect(actualLRPKey.ProcessGuid).To(Equal(expectedLrpKey.ProcessGuid))
+								Expect(actualLRPKey.Index).To(Equal(expectedLrpKey.Index))
+								Expect(*instanceKey).To(Equal(expectedInstanceKey))
+								Expect(reason).To(Equal(internal.ExecutorRejectedReason))
+								Expect(logger).To(Say("executor-rejections-exhausted"))
+							})
+
+							It("starts counting over when the executor runs the container", func() {
+								containerDelegate.RunContainerReturns(true)
+								processor.Process(logger, "some-trace-id", container)
+
+								containerDelegate.RunContainerReturns(false)
+								processor.Process(logger, "some-trace-id", container)
+								processor.Process(logger, "some-trace-id", container)
+
+								Expect(bbsClient.CrashActualLRPCallCount()).To(BeZero())
+							})
+						})
+					})
+
+					Context("when the desired LRP is privileged", func() {
+						BeforeEach(func() {
+							desiredLRP.Privileged = true
+						})
+
+						It("runs the container", func() {
+							Expect(containerDelegate.RunContainerCallCount()).To(Equal(1))
+							Expect(bbsClient.CrashActualLRPCallCount()).To(BeZero())
+						})
+
+						Context("and the cell does not allow privileged containers", func() {
+							BeforeEach(func() {
+								processor = internal.NewLRPProcessor(bbsClient, containerDelegate, nil, expectedCellID, expectedAvailabilityZone, rep.StackPathMap{}, "", evacuationReporter, nil, internal.NewInvalidContainerHandler(containerDelegate, internal.ReconciliationPolicyLogOnly), internal.NewExecutorRejectionTracker(0), false, internal.PortRange{})
+							})
+
+							It("does not run the container", func() {
+								Expect(containerDelegate.RunContainerCallCount()).To(BeZero())
+							})
+
+							It("crashes the actual LRP", func() {
+								Expect(bbsClient.CrashActualLRPCallCount()).To(Equal(1))
+								_, traceID, actualLRPKey, instanceKey, reason := bbsClient.CrashActualLRPArgsForCall(0)
+								Expect(traceID).To(Equal("some-trace-id"))
+								Expect(actualLRPKey.ProcessGuid).To(Equal(expectedLrpKey.ProcessGuid))
+								Expect(*instanceKey).To(Equal(expectedInstanceKey))
+								Expect(reason).To(Equal(internal.PrivilegedContainersNotAllowedReason))
+								Expect(logger).To(Say("privileged-container-not-allowed"))
+							})
+
+							It("deletes the container", func() {
+								Expect(containerDelegate.DeleteContainerCallCount()).To(Equal(1))
+								_, _, containerGuid := containerDelegate.DeleteContainerArgsForCall(0)
+								Expect(containerGuid).To(Equal(container.Guid))
+							})
+
+							Context("and the desired LRP is not privileged", func() {
+								BeforeEach(func() {
+									desiredLRP.Privileged = false
+								})
+
+								It("runs the container", func() {
+									Expect(containerDelegate.RunContainerCallCount()).To(Equal(1))
+									Expect(bbsClient.CrashActualLRPCallCount()).To(BeZero())
+								})
+							})
+						})
+					})
+
+					Context("when the cell restricts the ports LRPs may request", func() {
+						BeforeEach(func() {
+							processor = internal.NewLRPProcessor(bbsClient, containerDelegate, nil, expectedCellID, expectedAvailabilityZone, rep.StackPathMap{}, "", evacuationReporter, nil, internal.NewInvalidContainerHandler(containerDelegate, internal.ReconciliationPolicyLogOnly), internal.NewExecutorRejectionTracker(0), true, internal.PortRange{Min: 8080, Max: 8090})
+						})
+
+						Context("and the desired LRP requests ports in the range", func() {
+							BeforeEach(func() {
+								desiredLRP.Ports = []uint32{8080, 8090}
+							})
+
+							It("runs the container", func() {
+								Expect(containerDelegate.RunContainerCallCount()).To(Equal(1))
+								Expect(bbsClient.CrashActualLRPCallCount()).To(BeZero())
+							})
+						})
+
+						Context("and the desired LRP requests a port outside the range", func() {
+							BeforeEach(func() {
+								desiredLRP.Ports = []uint32{8080, 2222}
+							})
+
+							It("does not run the container", func() {
+								Expect(containerDelegate.RunContainerCallCount()).To(BeZero())
+							})
+
+							It("crashes the actual LRP with the reason", func() {
+								Expect(bbsClient.CrashActualLRPCallCount()).To(Equal(1))
+								_, traceID, actualLRPKey, instanceKey, reason := bbsClient.CrashActualLRPArgsForCall(0)
+								Expect(traceID).To(Equal("some-trace-id"))
+								Expect(actualLRPKey.ProcessGuid).To(Equal(expectedLrpKey.ProcessGuid))
+								Expect(*instanceKey).To(Equal(expectedInstanceKey))
+								Expect(reason).To(Equal(internal.PortsNotAllowedReason))
+								Expect(logger).To(Say("ports-not-allowed.*2222"))
+							})
+
+							It("deletes the container", func() {
+								Expect(containerDelegate.DeleteContainerCallCount()).To(Equal(1))
+								_, _, containerGuid := containerDelegate.DeleteContainerArgsForCall(0)
+								Expect(containerGuid).To(Equal(container.Guid))
+							})
+						})
 					})
 				})
 
@@ -0,0 +1,31 @@
+package internal
+
+// PortsNotAllowedReason is reported to the BBS for LRPs requesting container
+// ports outside the range the cell allows.
+const PortsNotAllowedReason = "requested ports are outside the range allowed on this cell"
+
+// PortRange is an inclusive range of container ports. The zero PortRange
+// allows every port.
+type PortRange struct {
+	Min uint32
+	Max uint32
+}
+
+func (r PortRange) unrestricted() bool {
+	return r.Min == 0 && r.Max == 0
+}
+
+// DisallowedPorts returns the ports outside the range.
+func (r PortRange) DisallowedPorts(ports []uint32) []uint32 {
+	if r.unrestricted() {
+		return nil
+	}
+
+	var disallowed []uint32
+	for _, port := range ports {
+		if port < r.Min || port > r.Max {
+			disallowed = append(disallowed, port)
+		}
+	}
+	return disallowed
+}
@@ -13,6 +13,11 @@ const TaskCompletionReasonMissingContainer = "task container does not exist"
 const TaskCompletionReasonFailedToRunContainer = "failed to run container"
 const TaskCompletionReasonInvalidTransition = "invalid state transition"
 const TaskCompletionReasonFailedToFetchResult = "failed to fetch result"
+const TaskCompletionReasonDestroyedOnStartup = "task destroyed because its container did not survive a cell restart"
+
+// PrivilegedContainersNotAllowedReason is reported to the BBS for privileged
+// LRP instances and tasks placed on a cell that does not allow them.
+const PrivilegedContainersNotAllowedReason = "privileged containers are not allowed on this cell"
 
 //go:generate counterfeiter -o fake_internal/fake_task_processor.go task_processor.go TaskProcessor
 
@@ -27,9 +32,11 @@ type taskProcessor struct {
 	stackPathMap               rep.StackPathMap
 	layeringMode               string
 	runRequestConversionHelper rep.RunRequestConversionHelper
+	invalidHandler             *InvalidContainerHandler
+	allowPrivileged            bool
 }
 
-func NewTaskProcessor(bbs bbs.InternalClient, containerDelegate ContainerDelegate, cellID string, stackPathMap rep.StackPathMap, layeringMode string) TaskProcessor {
+func NewTaskProcessor(bbs bbs.InternalClient, containerDelegate ContainerDelegate, cellID string, stackPathMap rep.StackPathMap, layeringMode string, invalidHandler *InvalidContainerHandler, allowPrivileged bool) TaskProcessor {
 	runRequestConversionHelper := rep.RunRequestConversionHelper{ECRHelper: ecrhelper.NewECRHelper()}
 
 	return &taskProcessor{
@@ -39,6 +46,8 @@ func NewTaskProcessor(bbs bbs.InternalClient, containerDelegate ContainerDelegat
 		stackPathMap:               stackPathMap,
 		layeringMode:               layeringMode,
 		runRequestConversionHelper: runRequestConversionHelper,
+		invalidHandler:             invalidHandler,
+		allowPrivileged:            allowPrivileged,
 	}
 }
 
@@ -51,6 +60,11 @@ func (p *taskProcessor) Process(logger lager.Logger, traceID string, container e
 	logger.Debug("starting")
 	defer logger.Debug("finished")
 
+	if p.invalidHandler.Quarantined(container.Guid) {
+		logger.Debug("skipping-quarantined-container")
+		return
+	}
+
 	switch container.State {
 	case executor.StateReserved:
 		logger.Debug("processing-reserved-container")
@@ -67,6 +81,8 @@ func (p *taskProcessor) Process(logger lager.Logger, traceID string, container e
 	case executor.StateCompleted:
 		logger.Debug("processing-completed-container")
 		p.processCompletedContainer(logger, traceID, container)
+	default:
+		p.invalidHandler.Handle(logger.Session("process-invalid-container"), traceID, container)
 	}
 }
 
@@ -82,6 +98,16 @@ func (p *taskProcessor) processActiveContainer(logger lager.Logger, traceID stri
 		return
 	}
 
+	if task.Privileged && !p.allowPrivileged {
+		logger.Error("privileged-container-not-allowed", nil)
+		err = p.bbsClient.CompleteTask(logger, traceID, container.Guid, p.cellID, true, PrivilegedContainersNotAllowedReason, "")
+		if err != nil {
+			logger.Error("failed-completing-task", err)
+		}
+		p.containerDelegate.DeleteContainer(logger, traceID, container.Guid)
+		return
+	}
+
 	runReq, err := p.runRequestConversionHelper.NewRunRequestFromTask(task, p.stackPathMap, p.layeringMode)
 	if err != nil {
 		logger.Error("failed-to-construct-run-request", err)
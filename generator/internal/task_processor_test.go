@@ -14,6 +14,7 @@ import (
 	"code.cloudfoundry.org/rep/generator/internal/fake_internal"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
 )
 
 var processor internal.TaskProcessor
@@ -39,7 +40,7 @@ var _ = Describe("TaskProcessor", func() {
 		expectedCellID = "the-cell"
 		taskGuid = "the-guid"
 
-		processor = internal.NewTaskProcessor(bbsClient, containerDelegate, expectedCellID, rep.StackPathMap{}, "")
+		processor = internal.NewTaskProcessor(bbsClient, containerDelegate, expectedCellID, rep.StackPathMap{}, "", internal.NewInvalidContainerHandler(containerDelegate, internal.ReconciliationPolicyLogOnly), true)
 
 		task = model_helpers.NewValidTask(taskGuid)
 		runRequestConversionHelper := rep.RunRequestConversionHelper{ECRHelper: &fakeecrhelper.FakeECRHelper{}}
@@ -125,6 +126,57 @@ var _ = Describe("TaskProcessor", func() {
 			})
 		})
 
+		Context("when the task is privileged", func() {
+			BeforeEach(func() {
+				task.Privileged = true
+			})
+
+			It("runs the container", func() {
+				Expect(containerDelegate.RunContainerCallCount()).To(Equal(1))
+				Expect(bbsClient.CompleteTaskCallCount()).To(BeZero())
+			})
+
+			Context("and the cell does not allow privileged containers", func() {
+				BeforeEach(func() {
+					processor = internal.NewTaskProcessor(bbsClient, containerDelegate, expectedCellID, rep.StackPathMap{}, "", internal.NewInvalidContainerHandler(containerDelegate, internal.ReconciliationPolicyLogOnly), false)
+				})
+
+				It("does not run the container", func() {
+					Expect(containerDelegate.RunContainerCallCount()).To(BeZero())
+				})
+
+				It("completes the task with failure", func() {
+					Expect(bbsClient.CompleteTaskCallCount()).To(Equal(1))
+					_, traceID, guid, cellId, failed, reason, _ := bbsClient.CompleteTaskArgsForCall(0)
+					Expect(traceID).To(Equal("some-trace-id"))
+					Expect(guid).To(Equal(taskGuid))
+					Expect(cellId).To(Equal(expectedCellID))
+					Expect(failed).To(BeTrue())
+					Expect(reason).To(Equal(internal.PrivilegedContainersNotAllowedReason))
+					Expect(logger).To(gbytes.Say("privileged-container-not-allowed"))
+				})
+
+				It("deletes the container", func() {
+					Expect(containerDelegate.DeleteContainerCallCount()).To(Equal(1))
+					_, traceID, guid := containerDelegate.DeleteContainerArgsForCall(0)
+					Expect(traceID).To(Equal("some-trace-id"))
+					Expect(guid).To(Equal(taskGuid))
+				})
+			})
+		})
+
+		Context("when the task is not privileged and the cell does not allow privileged containers", func() {
+			BeforeEach(func() {
+				task.Privileged = false
+				processor = internal.NewTaskProcessor(bbsClient, containerDelegate, expectedCellID, rep.StackPathMap{}, "", internal.NewInvalidContainerHandler(containerDelegate, internal.ReconciliationPolicyLogOnly), false)
+			})
+
+			It("runs the container", func() {
+				Expect(containerDelegate.RunContainerCallCount()).To(Equal(1))
+				Expect(bbsClient.CompleteTaskCallCount()).To(BeZero())
+			})
+		})
+
 		Context("when starting the task fails", func() {
 			Context("because of an invalid state transition", func() {
 				BeforeEach(func() {
@@ -5,6 +5,7 @@ import (
 
 	"code.cloudfoundry.org/bbs"
 	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/executor"
 	"code.cloudfoundry.org/lager/v3"
 	"code.cloudfoundry.org/rep"
 	"code.cloudfoundry.org/rep/generator/internal"
@@ -173,6 +174,7 @@ type ResidualTaskOperation struct {
 	CellId            string
 	bbsClient         bbs.InternalClient
 	containerDelegate internal.ContainerDelegate
+	policy            StartupTaskPolicy
 }
 
 func NewResidualTaskOperation(
@@ -182,6 +184,7 @@ func NewResidualTaskOperation(
 	cellId string,
 	bbsClient bbs.InternalClient,
 	containerDelegate internal.ContainerDelegate,
+	policy StartupTaskPolicy,
 ) *ResidualTaskOperation {
 	return &ResidualTaskOperation{
 		logger:            logger,
@@ -190,6 +193,7 @@ func NewResidualTaskOperation(
 		CellId:            cellId,
 		bbsClient:         bbsClient,
 		containerDelegate: containerDelegate,
+		policy:            policy,
 	}
 }
 
@@ -210,6 +214,22 @@ func (o *ResidualTaskOperation) Execute() {
 		return
 	}
 
+	if o.policy == StartupTaskPolicyRetryComplete {
+		err := o.bbsClient.RejectTask(logger, o.traceID, o.TaskGuid, internal.TaskCompletionReasonMissingContainer)
+		if err != nil {
+			logger.Error("failed-to-reject-task", err)
+		}
+		return
+	}
+
+	if o.policy == StartupTaskPolicyDestroy {
+		err := o.bbsClient.CompleteTask(logger, o.traceID, o.TaskGuid, o.CellId, true, internal.TaskCompletionReasonDestroyedOnStartup, "")
+		if err != nil {
+			logger.Error("failed-to-complete-task", err)
+		}
+		return
+	}
+
 	err := o.bbsClient.CompleteTask(logger, o.traceID, o.TaskGuid, o.CellId, true, internal.TaskCompletionReasonMissingContainer, internal.TaskCompletionReasonMissingContainer)
 	if err != nil {
 		logger.Error("failed-to-complete-task", err)
@@ -282,3 +302,222 @@ func (o *ContainerOperation) Execute() {
 		return
 	}
 }
+
+// failContainerWork crashes the LRP or fails the task running in container,
+// giving the BBS reason. It reports false, having logged why, when the
+// container's tags do not tell which LRP or task it runs, and otherwise
+// returns the error reporting the work to the BBS.
+func failContainerWork(logger lager.Logger, traceID, cellID string, bbsClient bbs.InternalClient, container executor.Container, reason string) (bool, error) {
+	switch container.Tags[rep.LifecycleTag] {
+	case rep.LRPLifecycle:
+		actualLRPKey, err := rep.ActualLRPKeyFromTags(container.Tags)
+		if err != nil {
+			logger.Error("failed-to-generate-lrp-key", err)
+			return false, nil
+		}
+		actualLRPInstanceKey, err := rep.ActualLRPInstanceKeyFromContainer(container, cellID)
+		if err != nil {
+			logger.Error("failed-to-generate-instance-key", err)
+			return false, nil
+		}
+		return true, bbsClient.CrashActualLRP(logger, traceID, actualLRPKey, actualLRPInstanceKey, reason)
+
+	case rep.TaskLifecycle:
+		return true, bbsClient.CompleteTask(logger, traceID, container.Guid, cellID, true, reason, reason)
+
+	default:
+		logger.Error("failed-to-process-container-with-unknown-lifecycle", fmt.Errorf("unknown lifecycle: %s", container.Tags[rep.LifecycleTag]))
+		return false, nil
+	}
+}
+
+const removedRootFSReason = "container rootfs was removed from the cell"
+
+// RemovedRootFSOperation crashes the LRP or fails the task running in a
+// container whose rootfs was removed from the cell, then deletes the
+// container.
+type RemovedRootFSOperation struct {
+	logger            lager.Logger
+	traceID           string
+	cellID            string
+	bbsClient         bbs.InternalClient
+	containerDelegate internal.ContainerDelegate
+	Guid              string
+}
+
+func NewRemovedRootFSOperation(
+	logger lager.Logger,
+	traceID string,
+	cellID string,
+	bbsClient bbs.InternalClient,
+	containerDelegate internal.ContainerDelegate,
+	guid string,
+) *RemovedRootFSOperation {
+	return &RemovedRootFSOperation{
+		logger:            logger,
+		traceID:           traceID,
+		cellID:            cellID,
+		bbsClient:         bbsClient,
+		containerDelegate: containerDelegate,
+		Guid:              guid,
+	}
+}
+
+func (o *RemovedRootFSOperation) Key() string {
+	return o.Guid
+}
+
+func (o *RemovedRootFSOperation) Execute() {
+	logger := o.logger.Session("executing-removed-rootfs-operation", lager.Data{
+		"container-guid": o.Guid,
+	})
+	logger.Info("starting")
+	defer logger.Info("finished")
+
+	container, ok := o.containerDelegate.GetContainer(logger, o.Guid)
+	if !ok {
+		logger.Info("skipped-because-container-does-not-exist")
+		return
+	}
+
+	identified, err := failContainerWork(logger, o.traceID, o.cellID, o.bbsClient, container, removedRootFSReason)
+	if !identified {
+		return
+	}
+	if err != nil {
+		logger.Error("failed-to-fail-work", err)
+	}
+
+	o.containerDelegate.DeleteContainer(logger, o.traceID, o.Guid)
+}
+
+const orphanContainerReason = "container has no record in the bbs"
+
+// OrphanContainerOperation deletes a container that has had no LRP or task
+// record in the BBS for longer than its grace period. When notifyBBS is set
+// it first crashes the LRP or fails the task, in case the BBS was only behind.
+type OrphanContainerOperation struct {
+	logger            lager.Logger
+	traceID           string
+	cellID            string
+	bbsClient         bbs.InternalClient
+	containerDelegate internal.ContainerDelegate
+	notifyBBS         bool
+	Guid              string
+}
+
+func NewOrphanContainerOperation(
+	logger lager.Logger,
+	traceID string,
+	cellID string,
+	bbsClient bbs.InternalClient,
+	containerDelegate internal.ContainerDelegate,
+	notifyBBS bool,
+	guid string,
+) *OrphanContainerOperation {
+	return &OrphanContainerOperation{
+		logger:            logger,
+		traceID:           traceID,
+		cellID:            cellID,
+		bbsClient:         bbsClient,
+		containerDelegate: containerDelegate,
+		notifyBBS:         notifyBBS,
+		Guid:              guid,
+	}
+}
+
+func (o *OrphanContainerOperation) Key() string {
+	return o.Guid
+}
+
+func (o *OrphanContainerOperation) Execute() {
+	logger := o.logger.Session("executing-orphan-container-operation", lager.Data{
+		"container-guid": o.Guid,
+		"notify-bbs":     o.notifyBBS,
+	})
+	logger.Info("starting")
+	defer logger.Info("finished")
+
+	container, ok := o.containerDelegate.GetContainer(logger, o.Guid)
+	if !ok {
+		logger.Info("skipped-because-container-does-not-exist")
+		return
+	}
+
+	// the BBS is expected to reject the report when it has no record of the
+	// container, so failures are only logged
+	if o.notifyBBS {
+		_, err := failContainerWork(logger, o.traceID, o.cellID, o.bbsClient, container, orphanContainerReason)
+		if err != nil {
+			logger.Info("failed-to-fail-work", lager.Data{"error": err.Error()})
+		}
+	}
+
+	o.containerDelegate.DeleteContainer(logger, o.traceID, o.Guid)
+}
+
+const stuckCreatingReason = "container was stuck creating"
+
+// StuckCreatingOperation crashes the LRP or fails the task whose container
+// has been initializing for too long, then deletes the container so that its
+// reserved capacity is freed and the work is rescheduled elsewhere. A
+// container that finished initializing in the meantime is left alone.
+type StuckCreatingOperation struct {
+	logger            lager.Logger
+	traceID           string
+	cellID            string
+	bbsClient         bbs.InternalClient
+	containerDelegate internal.ContainerDelegate
+	Guid              string
+}
+
+func NewStuckCreatingOperation(
+	logger lager.Logger,
+	traceID string,
+	cellID string,
+	bbsClient bbs.InternalClient,
+	containerDelegate internal.ContainerDelegate,
+	guid string,
+) *StuckCreatingOperation {
+	return &StuckCreatingOperation{
+		logger:            logger,
+		traceID:           traceID,
+		cellID:            cellID,
+		bbsClient:         bbsClient,
+		containerDelegate: containerDelegate,
+		Guid:              guid,
+	}
+}
+
+func (o *StuckCreatingOperation) Key() string {
+	return o.Guid
+}
+
+func (o *StuckCreatingOperation) Execute() {
+	logger := o.logger.Session("executing-stuck-creating-operation", lager.Data{
+		"container-guid": o.Guid,
+	})
+	logger.Info("starting")
+	defer logger.Info("finished")
+
+	container, ok := o.containerDelegate.GetContainer(logger, o.Guid)
+	if !ok {
+		logger.Info("skipped-because-container-does-not-exist")
+		return
+	}
+
+	if container.State != executor.StateInitializing {
+		logger.Info("skipped-because-container-is-no-longer-creating", lager.Data{"state": container.State})
+		return
+	}
+
+	identified, err := failContainerWork(logger, o.traceID, o.cellID, o.bbsClient, container, stuckCreatingReason)
+	if !identified {
+		return
+	}
+	if err != nil {
+		logger.Error("failed-to-fail-work", err)
+	}
+
+	o.containerDelegate.DeleteContainer(logger, o.traceID, o.Guid)
+}
@@ -259,13 +259,18 @@ var _ = Describe("Operation", func() {
 			containerDelegate     *fake_internal.FakeContainerDelegate
 			residualTaskOperation *generator.ResidualTaskOperation
 			taskGuid, cellId      string
+			policy                generator.StartupTaskPolicy
 		)
 
 		BeforeEach(func() {
 			taskGuid = "the-task-guid"
 			cellId = "the-cell-id"
 			containerDelegate = new(fake_internal.FakeContainerDelegate)
-			residualTaskOperation = generator.NewResidualTaskOperation(logger, "some-trace-id", taskGuid, cellId, fakeBBS, containerDelegate)
+			policy = generator.StartupTaskPolicyFail
+		})
+
+		JustBeforeEach(func() {
+			residualTaskOperation = generator.NewResidualTaskOperation(logger, "some-trace-id", taskGuid, cellId, fakeBBS, containerDelegate, policy)
 		})
 
 		Describe("Key", func() {
@@ -317,6 +322,70 @@ var _ = Describe("Operation", func() {
 						Expect(logger).To(Say(sessionName + ".failed-to-complete-task"))
 					})
 				})
+
+				It("does not delete the task", func() {
+					Expect(fakeBBS.DeleteTaskCallCount()).To(Equal(0))
+				})
+
+				Context("when the policy is retry-complete", func() {
+					BeforeEach(func() {
+						policy = generator.StartupTaskPolicyRetryComplete
+					})
+
+					It("rejects the task so it can be placed again", func() {
+						Expect(fakeBBS.RejectTaskCallCount()).To(Equal(1))
+						_, traceID, actualTaskGuid, reason := fakeBBS.RejectTaskArgsForCall(0)
+						Expect(traceID).To(Equal("some-trace-id"))
+						Expect(actualTaskGuid).To(Equal(taskGuid))
+						Expect(reason).To(Equal(internal.TaskCompletionReasonMissingContainer))
+					})
+
+					It("does not complete the task", func() {
+						Expect(fakeBBS.CompleteTaskCallCount()).To(Equal(0))
+					})
+
+					Context("when rejecting the task fails", func() {
+						BeforeEach(func() {
+							fakeBBS.RejectTaskReturns(errors.New("failed"))
+						})
+
+						It("logs the failure", func() {
+							Expect(logger).To(Say(sessionName + ".failed-to-reject-task"))
+						})
+					})
+				})
+
+				Context("when the policy is destroy", func() {
+					BeforeEach(func() {
+						policy = generator.StartupTaskPolicyDestroy
+					})
+
+					It("completes the task as destroyed", func() {
+						Expect(fakeBBS.CompleteTaskCallCount()).To(Equal(1))
+						_, traceID, actualTaskGuid, actualCellId, failed, actualFailureReason, result := fakeBBS.CompleteTaskArgsForCall(0)
+						Expect(traceID).To(Equal("some-trace-id"))
+						Expect(actualTaskGuid).To(Equal(taskGuid))
+						Expect(actualCellId).To(Equal(cellId))
+						Expect(failed).To(BeTrue())
+						Expect(actualFailureReason).To(Equal(internal.TaskCompletionReasonDestroyedOnStartup))
+						Expect(result).To(BeEmpty())
+					})
+
+					It("leaves resolving and deleting the task to the BBS", func() {
+						Expect(fakeBBS.ResolvingTaskCallCount()).To(Equal(0))
+						Expect(fakeBBS.DeleteTaskCallCount()).To(Equal(0))
+					})
+
+					Context("when completing the task fails", func() {
+						BeforeEach(func() {
+							fakeBBS.CompleteTaskReturns(errors.New("failed"))
+						})
+
+						It("logs the failure", func() {
+							Expect(logger).To(Say(sessionName + ".failed-to-complete-task"))
+						})
+					})
+				})
 			})
 
 			Context("when the container exists", func() {
@@ -335,6 +404,231 @@ var _ = Describe("Operation", func() {
 		})
 	})
 
+	Describe("RemovedRootFSOperation", func() {
+		var (
+			containerDelegate      *fake_internal.FakeContainerDelegate
+			removedRootFSOperation *generator.RemovedRootFSOperation
+			containerGuid, cellId  string
+		)
+
+		BeforeEach(func() {
+			containerGuid = "the-container-guid"
+			cellId = "the-cell-id"
+			containerDelegate = new(fake_internal.FakeContainerDelegate)
+			removedRootFSOperation = generator.NewRemovedRootFSOperation(logger, "some-trace-id", cellId, fakeBBS, containerDelegate, containerGuid)
+		})
+
+		Describe("Key", func() {
+			It("returns the Guid", func() {
+				Expect(removedRootFSOperation.Key()).To(Equal("the-container-guid"))
+			})
+		})
+
+		Describe("Execute", func() {
+			const sessionName = "test.executing-removed-rootfs-operation"
+
+			JustBeforeEach(func() {
+				removedRootFSOperation.Execute()
+			})
+
+			It("logs its execution lifecycle", func() {
+				Expect(logger).To(Say(sessionName + ".starting"))
+				Expect(logger).To(Say(sessionName + ".finished"))
+			})
+
+			Context("when the container does not exist", func() {
+				BeforeEach(func() {
+					containerDelegate.GetContainerReturns(executor.Container{}, false)
+				})
+
+				It("does nothing", func() {
+					Expect(fakeBBS.CrashActualLRPCallCount()).To(Equal(0))
+					Expect(fakeBBS.CompleteTaskCallCount()).To(Equal(0))
+					Expect(containerDelegate.DeleteContainerCallCount()).To(Equal(0))
+				})
+			})
+
+			Context("when the container runs an LRP", func() {
+				BeforeEach(func() {
+					containerDelegate.GetContainerReturns(executor.Container{
+						Guid: containerGuid,
+						Tags: executor.Tags{
+							rep.LifecycleTag:    rep.LRPLifecycle,
+							rep.DomainTag:       "the-domain",
+							rep.ProcessGuidTag:  "the-process-guid",
+							rep.ProcessIndexTag: "2",
+							rep.InstanceGuidTag: "the-instance-guid",
+						},
+					}, true)
+				})
+
+				It("crashes the actual lrp and deletes the container", func() {
+					Expect(fakeBBS.CrashActualLRPCallCount()).To(Equal(1))
+					_, traceID, lrpKey, instanceKey, reason := fakeBBS.CrashActualLRPArgsForCall(0)
+					Expect(traceID).To(Equal("some-trace-id"))
+					Expect(*lrpKey).To(Equal(models.NewActualLRPKey("the-process-guid", 2, "the-domain")))
+					Expect(*instanceKey).To(Equal(models.NewActualLRPInstanceKey("the-instance-guid", cellId)))
+					Expect(reason).To(ContainSubstring("rootfs was removed"))
+
+					Expect(containerDelegate.DeleteContainerCallCount()).To(Equal(1))
+					_, _, guid := containerDelegate.DeleteContainerArgsForCall(0)
+					Expect(guid).To(Equal(containerGuid))
+				})
+
+				Context("when crashing the actual lrp fails", func() {
+					BeforeEach(func() {
+						fakeBBS.CrashActualLRPReturns(errors.New("failed"))
+					})
+
+					It("logs the failure and still deletes the container", func() {
+						Expect(logger).To(Say(sessionName + ".failed-to-fail-work"))
+						Expect(containerDelegate.DeleteContainerCallCount()).To(Equal(1))
+					})
+				})
+			})
+
+			Context("when the container runs a task", func() {
+				BeforeEach(func() {
+					containerDelegate.GetContainerReturns(executor.Container{
+						Guid: containerGuid,
+						Tags: executor.Tags{rep.LifecycleTag: rep.TaskLifecycle},
+					}, true)
+				})
+
+				It("fails the task and deletes the container", func() {
+					Expect(fakeBBS.CompleteTaskCallCount()).To(Equal(1))
+					_, traceID, taskGuid, actualCellId, failed, failureReason, _ := fakeBBS.CompleteTaskArgsForCall(0)
+					Expect(traceID).To(Equal("some-trace-id"))
+					Expect(taskGuid).To(Equal(containerGuid))
+					Expect(actualCellId).To(Equal(cellId))
+					Expect(failed).To(BeTrue())
+					Expect(failureReason).To(ContainSubstring("rootfs was removed"))
+
+					Expect(containerDelegate.DeleteContainerCallCount()).To(Equal(1))
+				})
+			})
+
+			Context("when the container has an unknown lifecycle", func() {
+				BeforeEach(func() {
+					containerDelegate.GetContainerReturns(executor.Container{Guid: containerGuid}, true)
+				})
+
+				It("logs the failure and leaves the container alone", func() {
+					Expect(logger).To(Say(sessionName + ".failed-to-process-container-with-unknown-lifecycle"))
+					Expect(containerDelegate.DeleteContainerCallCount()).To(Equal(0))
+				})
+			})
+		})
+	})
+
+	Describe("StuckCreatingOperation", func() {
+		var (
+			containerDelegate      *fake_internal.FakeContainerDelegate
+			stuckCreatingOperation *generator.StuckCreatingOperation
+			containerGuid, cellId  string
+		)
+
+		BeforeEach(func() {
+			containerGuid = "the-container-guid"
+			cellId = "the-cell-id"
+			containerDelegate = new(fake_internal.FakeContainerDelegate)
+			stuckCreatingOperation = generator.NewStuckCreatingOperation(logger, "some-trace-id", cellId, fakeBBS, containerDelegate, containerGuid)
+		})
+
+		Describe("Key", func() {
+			It("returns the Guid", func() {
+				Expect(stuckCreatingOperation.Key()).To(Equal("the-container-guid"))
+			})
+		})
+
+		Describe("Execute", func() {
+			const sessionName = "test.executing-stuck-creating-operation"
+
+			JustBeforeEach(func() {
+				stuckCreatingOperation.Execute()
+			})
+
+			Context("when the container does not exist", func() {
+				BeforeEach(func() {
+					containerDelegate.GetContainerReturns(executor.Container{}, false)
+				})
+
+				It("does nothing", func() {
+					Expect(fakeBBS.CrashActualLRPCallCount()).To(Equal(0))
+					Expect(fakeBBS.CompleteTaskCallCount()).To(Equal(0))
+					Expect(containerDelegate.DeleteContainerCallCount()).To(Equal(0))
+				})
+			})
+
+			Context("when the container finished initializing in the meantime", func() {
+				BeforeEach(func() {
+					containerDelegate.GetContainerReturns(executor.Container{
+						Guid:  containerGuid,
+						State: executor.StateCreated,
+						Tags:  executor.Tags{rep.LifecycleTag: rep.TaskLifecycle},
+					}, true)
+				})
+
+				It("leaves the container alone", func() {
+					Expect(logger).To(Say(sessionName + ".skipped-because-container-is-no-longer-creating"))
+					Expect(fakeBBS.CompleteTaskCallCount()).To(Equal(0))
+					Expect(containerDelegate.DeleteContainerCallCount()).To(Equal(0))
+				})
+			})
+
+			Context("when the container runs an LRP", func() {
+				BeforeEach(func() {
+					containerDelegate.GetContainerReturns(executor.Container{
+						Guid:  containerGuid,
+						State: executor.StateInitializing,
+						Tags: executor.Tags{
+							rep.LifecycleTag:    rep.LRPLifecycle,
+							rep.DomainTag:       "the-domain",
+							rep.ProcessGuidTag:  "the-process-guid",
+							rep.ProcessIndexTag: "2",
+							rep.InstanceGuidTag: "the-instance-guid",
+						},
+					}, true)
+				})
+
+				It("crashes the actual lrp and deletes the container", func() {
+					Expect(fakeBBS.CrashActualLRPCallCount()).To(Equal(1))
+					_, traceID, lrpKey, instanceKey, reason := fakeBBS.CrashActualLRPArgsForCall(0)
+					Expect(traceID).To(Equal("some-trace-id"))
+					Expect(*lrpKey).To(Equal(models.NewActualLRPKey("the-process-guid", 2, "the-domain")))
+					Expect(*instanceKey).To(Equal(models.NewActualLRPInstanceKey("the-instance-guid", cellId)))
+					Expect(reason).To(ContainSubstring("stuck creating"))
+
+					Expect(containerDelegate.DeleteContainerCallCount()).To(Equal(1))
+					_, _, guid := containerDelegate.DeleteContainerArgsForCall(0)
+					Expect(guid).To(Equal(containerGuid))
+				})
+			})
+
+			Context("when the container runs a task", func() {
+				BeforeEach(func() {
+					containerDelegate.GetContainerReturns(executor.Container{
+						Guid:  containerGuid,
+						State: executor.StateInitializing,
+						Tags:  executor.Tags{rep.LifecycleTag: rep.TaskLifecycle},
+					}, true)
+				})
+
+				It("fails the task and deletes the container", func() {
+					Expect(fakeBBS.CompleteTaskCallCount()).To(Equal(1))
+					_, traceID, taskGuid, actualCellId, failed, failureReason, _ := fakeBBS.CompleteTaskArgsForCall(0)
+					Expect(traceID).To(Equal("some-trace-id"))
+					Expect(taskGuid).To(Equal(containerGuid))
+					Expect(actualCellId).To(Equal(cellId))
+					Expect(failed).To(BeTrue())
+					Expect(failureReason).To(ContainSubstring("stuck creating"))
+
+					Expect(containerDelegate.DeleteContainerCallCount()).To(Equal(1))
+				})
+			})
+		})
+	})
+
 	Describe("ContainerOperation", func() {
 		var (
 			containerDelegate  *fake_internal.FakeContainerDelegate
@@ -463,4 +757,105 @@ var _ = Describe("Operation", func() {
 			})
 		})
 	})
+	Describe("OrphanContainerOperation", func() {
+		var (
+			containerDelegate     *fake_internal.FakeContainerDelegate
+			notifyBBS             bool
+			containerGuid, cellId string
+		)
+
+		BeforeEach(func() {
+			containerGuid = "the-container-guid"
+			cellId = "the-cell-id"
+			containerDelegate = new(fake_internal.FakeContainerDelegate)
+			notifyBBS = false
+		})
+
+		JustBeforeEach(func() {
+			generator.NewOrphanContainerOperation(logger, "some-trace-id", cellId, fakeBBS, containerDelegate, notifyBBS, containerGuid).Execute()
+		})
+
+		Context("when the container does not exist", func() {
+			BeforeEach(func() {
+				containerDelegate.GetContainerReturns(executor.Container{}, false)
+			})
+
+			It("does nothing", func() {
+				Expect(fakeBBS.CrashActualLRPCallCount()).To(Equal(0))
+				Expect(containerDelegate.DeleteContainerCallCount()).To(Equal(0))
+			})
+		})
+
+		Context("when the container runs an LRP", func() {
+			BeforeEach(func() {
+				containerDelegate.GetContainerReturns(executor.Container{
+					Guid: containerGuid,
+					Tags: executor.Tags{
+						rep.LifecycleTag:    rep.LRPLifecycle,
+						rep.DomainTag:       "the-domain",
+						rep.ProcessGuidTag:  "the-process-guid",
+						rep.ProcessIndexTag: "2",
+						rep.InstanceGuidTag: "the-instance-guid",
+					},
+				}, true)
+			})
+
+			It("reaps the container without notifying the bbs", func() {
+				Expect(fakeBBS.CrashActualLRPCallCount()).To(Equal(0))
+				Expect(containerDelegate.DeleteContainerCallCount()).To(Equal(1))
+				_, traceID, guid := containerDelegate.DeleteContainerArgsForCall(0)
+				Expect(traceID).To(Equal("some-trace-id"))
+				Expect(guid).To(Equal(containerGuid))
+			})
+
+			Context("when notifying the bbs", func() {
+				BeforeEach(func() {
+					notifyBBS = true
+				})
+
+				It("crashes the actual lrp, then reaps the container", func() {
+					Expect(fakeBBS.CrashActualLRPCallCount()).To(Equal(1))
+					_, traceID, lrpKey, instanceKey, reason := fakeBBS.CrashActualLRPArgsForCall(0)
+					Expect(traceID).To(Equal("some-trace-id"))
+					Expect(*lrpKey).To(Equal(models.NewActualLRPKey("the-process-guid", 2, "the-domain")))
+					Expect(*instanceKey).To(Equal(models.NewActualLRPInstanceKey("the-instance-guid", cellId)))
+					Expect(reason).To(ContainSubstring("no record in the bbs"))
+
+					Expect(containerDelegate.DeleteContainerCallCount()).To(Equal(1))
+				})
+
+				Context("when the bbs has no record of the lrp", func() {
+					BeforeEach(func() {
+						fakeBBS.CrashActualLRPReturns(models.ErrResourceNotFound)
+					})
+
+					It("still reaps the container", func() {
+						Expect(containerDelegate.DeleteContainerCallCount()).To(Equal(1))
+					})
+				})
+			})
+		})
+
+		Context("when the container runs a task", func() {
+			BeforeEach(func() {
+				notifyBBS = true
+				containerDelegate.GetContainerReturns(executor.Container{
+					Guid: containerGuid,
+					Tags: executor.Tags{rep.LifecycleTag: rep.TaskLifecycle},
+				}, true)
+			})
+
+			It("fails the task, then reaps the container", func() {
+				Expect(fakeBBS.CompleteTaskCallCount()).To(Equal(1))
+				_, traceID, taskGuid, actualCellID, failed, failureReason, _ := fakeBBS.CompleteTaskArgsForCall(0)
+				Expect(traceID).To(Equal("some-trace-id"))
+				Expect(taskGuid).To(Equal(containerGuid))
+				Expect(actualCellID).To(Equal(cellId))
+				Expect(failed).To(BeTrue())
+				Expect(failureReason).To(ContainSubstring("no record in the bbs"))
+
+				Expect(containerDelegate.DeleteContainerCallCount()).To(Equal(1))
+			})
+		})
+	})
 })
@@ -0,0 +1,74 @@
+package generator
+
+import (
+	"fmt"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+)
+
+// OrphanContainerPolicy decides what the generator does with containers that
+// have no LRP or task record in the BBS once their grace period elapses.
+type OrphanContainerPolicy string
+
+const (
+	// OrphanContainerPolicyReap deletes the container.
+	OrphanContainerPolicyReap OrphanContainerPolicy = "reap"
+	// OrphanContainerPolicyNotifyThenReap crashes the LRP or fails the task
+	// in the BBS before deleting the container, so that a record the BBS had
+	// not yet reported is transitioned rather than left pointing at a
+	// container that no longer exists.
+	OrphanContainerPolicyNotifyThenReap OrphanContainerPolicy = "notify-then-reap"
+)
+
+// ParseOrphanContainerPolicy validates a configured policy. An empty policy
+// reaps the containers.
+func ParseOrphanContainerPolicy(policy string) (OrphanContainerPolicy, error) {
+	switch OrphanContainerPolicy(policy) {
+	case "":
+		return OrphanContainerPolicyReap, nil
+	case OrphanContainerPolicyReap, OrphanContainerPolicyNotifyThenReap:
+		return OrphanContainerPolicy(policy), nil
+	default:
+		return "", fmt.Errorf("unknown orphan container policy %q: must be one of %q or %q",
+			policy, OrphanContainerPolicyReap, OrphanContainerPolicyNotifyThenReap)
+	}
+}
+
+// OrphanContainerReaper tracks how long containers have been without a BBS
+// record across bulk loops, so that only those orphaned for longer than the
+// grace period are reaped. A nil reaper never reaps anything.
+type OrphanContainerReaper struct {
+	clock       clock.Clock
+	gracePeriod time.Duration
+	policy      OrphanContainerPolicy
+	orphans     firstSeenTracker
+}
+
+func NewOrphanContainerReaper(clock clock.Clock, gracePeriod time.Duration, policy OrphanContainerPolicy) *OrphanContainerReaper {
+	return &OrphanContainerReaper{
+		clock:       clock,
+		gracePeriod: gracePeriod,
+		policy:      policy,
+	}
+}
+
+// Policy returns what to do with the containers returned by Expired.
+func (r *OrphanContainerReaper) Policy() OrphanContainerPolicy {
+	if r == nil {
+		return OrphanContainerPolicyReap
+	}
+	return r.policy
+}
+
+// Expired records the guids of the containers currently without a BBS
+// record and returns those that have been orphaned for longer than the grace
+// period. Containers missing from guids are forgotten, so a container that
+// regains its record starts a new grace period if it is orphaned again.
+func (r *OrphanContainerReaper) Expired(guids []string) []string {
+	if r == nil {
+		return nil
+	}
+
+	return r.orphans.expired(r.clock.Now(), guids, r.gracePeriod)
+}
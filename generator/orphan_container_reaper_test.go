@@ -0,0 +1,68 @@
+package generator_test
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/rep/generator"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ParseOrphanContainerPolicy", func() {
+	DescribeTable("accepts the known policies",
+		func(configured string, expected generator.OrphanContainerPolicy) {
+			policy, err := generator.ParseOrphanContainerPolicy(configured)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(policy).To(Equal(expected))
+		},
+		Entry("unset", "", generator.OrphanContainerPolicyReap),
+		Entry("reap", "reap", generator.OrphanContainerPolicyReap),
+		Entry("notify-then-reap", "notify-then-reap", generator.OrphanContainerPolicyNotifyThenReap),
+	)
+
+	It("rejects unknown policies", func() {
+		_, err := generator.ParseOrphanContainerPolicy("shrug")
+		Expect(err).To(MatchError(ContainSubstring(`unknown orphan container policy "shrug"`)))
+	})
+})
+
+var _ = Describe("OrphanContainerReaper", func() {
+	var (
+		fakeClock *fakeclock.FakeClock
+		reaper    *generator.OrphanContainerReaper
+	)
+
+	BeforeEach(func() {
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		reaper = generator.NewOrphanContainerReaper(fakeClock, time.Minute, generator.OrphanContainerPolicyReap)
+	})
+
+	It("expires containers only once they have been orphaned for the grace period", func() {
+		Expect(reaper.Expired([]string{"guid-1"})).To(BeEmpty())
+
+		fakeClock.Increment(30 * time.Second)
+		Expect(reaper.Expired([]string{"guid-1", "guid-2"})).To(BeEmpty())
+
+		fakeClock.Increment(30 * time.Second)
+		Expect(reaper.Expired([]string{"guid-1", "guid-2"})).To(ConsistOf("guid-1"))
+
+		fakeClock.Increment(30 * time.Second)
+		Expect(reaper.Expired([]string{"guid-1", "guid-2"})).To(ConsistOf("guid-1", "guid-2"))
+	})
+
+	It("restarts the grace period of a container that regained its record", func() {
+		reaper.Expired([]string{"guid-1"})
+		fakeClock.Increment(time.Minute)
+
+		Expect(reaper.Expired([]string{})).To(BeEmpty())
+		Expect(reaper.Expired([]string{"guid-1"})).To(BeEmpty())
+	})
+
+	Context("when the reaper is nil", func() {
+		It("never expires anything", func() {
+			var nilReaper *generator.OrphanContainerReaper
+			Expect(nilReaper.Expired([]string{"guid-1"})).To(BeEmpty())
+		})
+	})
+})
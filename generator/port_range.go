@@ -0,0 +1,40 @@
+package generator
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"code.cloudfoundry.org/rep/generator/internal"
+)
+
+// PortRange is the inclusive range of container ports LRPs may request on the
+// cell. The zero PortRange allows every port.
+type PortRange = internal.PortRange
+
+// ParsePortRange parses a configured range such as "8080-8090". An empty
+// range allows every port.
+func ParsePortRange(portRange string) (PortRange, error) {
+	if portRange == "" {
+		return PortRange{}, nil
+	}
+
+	lower, upper, found := strings.Cut(portRange, "-")
+	if !found {
+		return PortRange{}, fmt.Errorf("invalid port range %q: must be of the form MIN-MAX", portRange)
+	}
+
+	minPort, err := strconv.ParseUint(strings.TrimSpace(lower), 10, 16)
+	if err != nil {
+		return PortRange{}, fmt.Errorf("invalid port range %q: %w", portRange, err)
+	}
+	maxPort, err := strconv.ParseUint(strings.TrimSpace(upper), 10, 16)
+	if err != nil {
+		return PortRange{}, fmt.Errorf("invalid port range %q: %w", portRange, err)
+	}
+	if minPort == 0 || minPort > maxPort {
+		return PortRange{}, fmt.Errorf("invalid port range %q: must satisfy 0 < MIN <= MAX", portRange)
+	}
+
+	return PortRange{Min: uint32(minPort), Max: uint32(maxPort)}, nil
+}
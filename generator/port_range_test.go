@@ -0,0 +1,43 @@
+package generator_test
+
+import (
+	"code.cloudfoundry.org/rep/generator"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ParsePortRange", func() {
+	DescribeTable("accepts valid ranges",
+		func(configured string, expected generator.PortRange) {
+			portRange, err := generator.ParsePortRange(configured)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(portRange).To(Equal(expected))
+		},
+		Entry("unset", "", generator.PortRange{}),
+		Entry("a range", "8080-8090", generator.PortRange{Min: 8080, Max: 8090}),
+		Entry("a single port", "8080-8080", generator.PortRange{Min: 8080, Max: 8080}),
+	)
+
+	DescribeTable("rejects invalid ranges",
+		func(configured string) {
+			_, err := generator.ParsePortRange(configured)
+			Expect(err).To(MatchError(ContainSubstring("invalid port range")))
+		},
+		Entry("no separator", "8080"),
+		Entry("not a number", "a-b"),
+		Entry("out of bounds", "8080-70000"),
+		Entry("reversed", "8090-8080"),
+		Entry("starting at zero", "0-8080"),
+	)
+})
+
+var _ = Describe("PortRange", func() {
+	It("allows every port when unset", func() {
+		Expect(generator.PortRange{}.DisallowedPorts([]uint32{1, 65535})).To(BeEmpty())
+	})
+
+	It("returns the ports outside the range", func() {
+		portRange := generator.PortRange{Min: 8080, Max: 8090}
+		Expect(portRange.DisallowedPorts([]uint32{8079, 8080, 8090, 8091})).To(Equal([]uint32{8079, 8091}))
+	})
+})
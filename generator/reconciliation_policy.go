@@ -0,0 +1,31 @@
+package generator
+
+import (
+	"fmt"
+
+	"code.cloudfoundry.org/rep/generator/internal"
+)
+
+// ReconciliationPolicy decides what the generator does with containers found
+// in a state it does not know how to reconcile.
+type ReconciliationPolicy = internal.ReconciliationPolicy
+
+const (
+	ReconciliationPolicyLogOnly    = internal.ReconciliationPolicyLogOnly
+	ReconciliationPolicyReap       = internal.ReconciliationPolicyReap
+	ReconciliationPolicyQuarantine = internal.ReconciliationPolicyQuarantine
+)
+
+// ParseReconciliationPolicy validates a configured policy. An empty policy
+// keeps the historical log-only behaviour.
+func ParseReconciliationPolicy(policy string) (ReconciliationPolicy, error) {
+	switch ReconciliationPolicy(policy) {
+	case "":
+		return ReconciliationPolicyLogOnly, nil
+	case ReconciliationPolicyLogOnly, ReconciliationPolicyReap, ReconciliationPolicyQuarantine:
+		return ReconciliationPolicy(policy), nil
+	default:
+		return "", fmt.Errorf("unknown reconciliation policy %q: must be one of %q, %q or %q",
+			policy, ReconciliationPolicyLogOnly, ReconciliationPolicyReap, ReconciliationPolicyQuarantine)
+	}
+}
@@ -0,0 +1,26 @@
+package generator_test
+
+import (
+	"code.cloudfoundry.org/rep/generator"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ParseReconciliationPolicy", func() {
+	DescribeTable("accepts the known policies",
+		func(configured string, expected generator.ReconciliationPolicy) {
+			policy, err := generator.ParseReconciliationPolicy(configured)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(policy).To(Equal(expected))
+		},
+		Entry("unset", "", generator.ReconciliationPolicyLogOnly),
+		Entry("log-only", "log-only", generator.ReconciliationPolicyLogOnly),
+		Entry("reap", "reap", generator.ReconciliationPolicyReap),
+		Entry("quarantine", "quarantine", generator.ReconciliationPolicyQuarantine),
+	)
+
+	It("rejects unknown policies", func() {
+		_, err := generator.ParseReconciliationPolicy("shrug")
+		Expect(err).To(MatchError(ContainSubstring(`unknown reconciliation policy "shrug"`)))
+	})
+})
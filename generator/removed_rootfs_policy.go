@@ -0,0 +1,31 @@
+package generator
+
+import "fmt"
+
+// RemovedRootFSPolicy decides what the generator does with containers whose
+// preloaded rootfs no longer exists on disk, such as after a stack was
+// removed from the cell while they ran.
+type RemovedRootFSPolicy string
+
+const (
+	// RemovedRootFSPolicyKeep leaves the containers running and only reports
+	// them.
+	RemovedRootFSPolicyKeep RemovedRootFSPolicy = "keep"
+	// RemovedRootFSPolicyCrashReport crashes LRPs and fails tasks running on
+	// the removed rootfs, then deletes their containers.
+	RemovedRootFSPolicyCrashReport RemovedRootFSPolicy = "crash-report"
+)
+
+// ParseRemovedRootFSPolicy validates a configured policy. An empty policy
+// keeps the containers.
+func ParseRemovedRootFSPolicy(policy string) (RemovedRootFSPolicy, error) {
+	switch RemovedRootFSPolicy(policy) {
+	case "":
+		return RemovedRootFSPolicyKeep, nil
+	case RemovedRootFSPolicyKeep, RemovedRootFSPolicyCrashReport:
+		return RemovedRootFSPolicy(policy), nil
+	default:
+		return "", fmt.Errorf("unknown removed rootfs policy %q: must be one of %q or %q",
+			policy, RemovedRootFSPolicyKeep, RemovedRootFSPolicyCrashReport)
+	}
+}
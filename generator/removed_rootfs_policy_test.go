@@ -0,0 +1,25 @@
+package generator_test
+
+import (
+	"code.cloudfoundry.org/rep/generator"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ParseRemovedRootFSPolicy", func() {
+	DescribeTable("accepts the known policies",
+		func(configured string, expected generator.RemovedRootFSPolicy) {
+			policy, err := generator.ParseRemovedRootFSPolicy(configured)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(policy).To(Equal(expected))
+		},
+		Entry("unset", "", generator.RemovedRootFSPolicyKeep),
+		Entry("keep", "keep", generator.RemovedRootFSPolicyKeep),
+		Entry("crash-report", "crash-report", generator.RemovedRootFSPolicyCrashReport),
+	)
+
+	It("rejects unknown policies", func() {
+		_, err := generator.ParseRemovedRootFSPolicy("shrug")
+		Expect(err).To(MatchError(ContainSubstring(`unknown removed rootfs policy "shrug"`)))
+	})
+})
@@ -0,0 +1,72 @@
+package generator
+
+import (
+	"sync"
+
+	loggingclient "code.cloudfoundry.org/diego-logging-client"
+	"code.cloudfoundry.org/lager/v3"
+)
+
+const rootFSCacheHitRateMetric = "RootFSCacheHitRate"
+
+// RootFSMountRecorders tells each of its members in turn.
+type RootFSMountRecorders []RootFSMountRecorder
+
+func (r RootFSMountRecorders) RecordMountFailure(logger lager.Logger, rootFSPath string) {
+	for _, recorder := range r {
+		recorder.RecordMountFailure(logger, rootFSPath)
+	}
+}
+
+func (r RootFSMountRecorders) RecordMountSuccess(logger lager.Logger, rootFSPath string) {
+	for _, recorder := range r {
+		recorder.RecordMountSuccess(logger, rootFSPath)
+	}
+}
+
+// RootFSCacheTracker emits how often containers start from a rootfs that an
+// earlier container on the cell already started from, and whose layers are
+// therefore expected to be cached. The executor does not report cache hits
+// itself, so the first start of each rootfs since the rep started counts as
+// a miss and every later one as a hit.
+type RootFSCacheTracker struct {
+	metronClient loggingclient.IngressClient
+
+	lock    sync.Mutex
+	started map[string]struct{}
+	starts  uint64
+	hits    uint64
+}
+
+func NewRootFSCacheTracker(metronClient loggingclient.IngressClient) *RootFSCacheTracker {
+	return &RootFSCacheTracker{
+		metronClient: metronClient,
+		started:      map[string]struct{}{},
+	}
+}
+
+// RecordMountSuccess records a container start and emits the hit rate.
+func (t *RootFSCacheTracker) RecordMountSuccess(logger lager.Logger, rootFSPath string) {
+	if rootFSPath == "" {
+		return
+	}
+
+	t.lock.Lock()
+	t.starts++
+	if _, ok := t.started[rootFSPath]; ok {
+		t.hits++
+	} else {
+		t.started[rootFSPath] = struct{}{}
+	}
+	rate := float64(t.hits) / float64(t.starts)
+	t.lock.Unlock()
+
+	err := t.metronClient.SendComponentMetric(rootFSCacheHitRateMetric, rate, "ratio")
+	if err != nil {
+		logger.Error("failed-to-send-rootfs-cache-hit-rate-metric", err)
+	}
+}
+
+// RecordMountFailure does nothing: containers that never started are not
+// counted.
+func (t *RootFSCacheTracker) RecordMountFailure(logger lager.Logger, rootFSPath string) {}
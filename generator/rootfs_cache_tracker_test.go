@@ -0,0 +1,62 @@
+package generator_test
+
+import (
+	mfakes "code.cloudfoundry.org/diego-logging-client/testhelpers"
+	"code.cloudfoundry.org/lager/v3/lagertest"
+	"code.cloudfoundry.org/rep/generator"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RootFSCacheTracker", func() {
+	var (
+		logger           *lagertest.TestLogger
+		fakeMetronClient *mfakes.FakeIngressClient
+		tracker          *generator.RootFSCacheTracker
+	)
+
+	lastRate := func() float64 {
+		count := fakeMetronClient.SendComponentMetricCallCount()
+		ExpectWithOffset(1, count).To(BeNumerically(">", 0))
+		name, value, unit := fakeMetronClient.SendComponentMetricArgsForCall(count - 1)
+		ExpectWithOffset(1, name).To(Equal("RootFSCacheHitRate"))
+		ExpectWithOffset(1, unit).To(Equal("ratio"))
+		return value
+	}
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("test")
+		fakeMetronClient = new(mfakes.FakeIngressClient)
+		tracker = generator.NewRootFSCacheTracker(fakeMetronClient)
+	})
+
+	It("counts the first start of a rootfs as a miss and later ones as hits", func() {
+		tracker.RecordMountSuccess(logger, "/rootfs/linux")
+		Expect(lastRate()).To(Equal(0.0))
+
+		tracker.RecordMountSuccess(logger, "/rootfs/linux")
+		Expect(lastRate()).To(Equal(0.5))
+
+		tracker.RecordMountSuccess(logger, "docker:///busybox")
+		Expect(lastRate()).To(BeNumerically("~", 1.0/3))
+
+		tracker.RecordMountSuccess(logger, "docker:///busybox")
+		Expect(lastRate()).To(Equal(0.5))
+	})
+
+	It("ignores failed mounts and containers without a rootfs", func() {
+		tracker.RecordMountFailure(logger, "/rootfs/linux")
+		tracker.RecordMountSuccess(logger, "")
+		Expect(fakeMetronClient.SendComponentMetricCallCount()).To(BeZero())
+	})
+
+	Describe("RootFSMountRecorders", func() {
+		It("records the mount with every recorder", func() {
+			other := generator.NewRootFSCacheTracker(fakeMetronClient)
+			recorders := generator.RootFSMountRecorders{tracker, other}
+
+			recorders.RecordMountSuccess(logger, "/rootfs/linux")
+			Expect(fakeMetronClient.SendComponentMetricCallCount()).To(Equal(2))
+		})
+	})
+})
@@ -0,0 +1,34 @@
+package generator
+
+import "fmt"
+
+// StartupTaskPolicy decides what the generator does with tasks the BBS has
+// running on this cell but for which the cell has no container, such as
+// after the rep restarts.
+type StartupTaskPolicy string
+
+const (
+	// StartupTaskPolicyFail completes the task as failed.
+	StartupTaskPolicyFail StartupTaskPolicy = "fail"
+	// StartupTaskPolicyRetryComplete rejects the task so the BBS places it
+	// again, completing it as failed once it runs out of retries.
+	StartupTaskPolicyRetryComplete StartupTaskPolicy = "retry-complete"
+	// StartupTaskPolicyDestroy completes the task as failed with a reason
+	// saying it was destroyed. The BBS then resolves and deletes it like any
+	// other completed task, once its completion callback has been handled.
+	StartupTaskPolicyDestroy StartupTaskPolicy = "destroy"
+)
+
+// ParseStartupTaskPolicy validates a configured policy. An empty policy keeps
+// the historical behaviour of failing the task.
+func ParseStartupTaskPolicy(policy string) (StartupTaskPolicy, error) {
+	switch StartupTaskPolicy(policy) {
+	case "":
+		return StartupTaskPolicyFail, nil
+	case StartupTaskPolicyFail, StartupTaskPolicyRetryComplete, StartupTaskPolicyDestroy:
+		return StartupTaskPolicy(policy), nil
+	default:
+		return "", fmt.Errorf("unknown startup task policy %q: must be one of %q, %q or %q",
+			policy, StartupTaskPolicyFail, StartupTaskPolicyRetryComplete, StartupTaskPolicyDestroy)
+	}
+}
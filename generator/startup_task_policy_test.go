@@ -0,0 +1,26 @@
+package generator_test
+
+import (
+	"code.cloudfoundry.org/rep/generator"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ParseStartupTaskPolicy", func() {
+	DescribeTable("accepts the known policies",
+		func(configured string, expected generator.StartupTaskPolicy) {
+			policy, err := generator.ParseStartupTaskPolicy(configured)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(policy).To(Equal(expected))
+		},
+		Entry("unset", "", generator.StartupTaskPolicyFail),
+		Entry("fail", "fail", generator.StartupTaskPolicyFail),
+		Entry("retry-complete", "retry-complete", generator.StartupTaskPolicyRetryComplete),
+		Entry("destroy", "destroy", generator.StartupTaskPolicyDestroy),
+	)
+
+	It("rejects unknown policies", func() {
+		_, err := generator.ParseStartupTaskPolicy("shrug")
+		Expect(err).To(MatchError(ContainSubstring(`unknown startup task policy "shrug"`)))
+	})
+})
@@ -0,0 +1,43 @@
+package generator
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/executor"
+)
+
+// StuckCreatingDetector tracks how long containers have been initializing
+// across bulk loops, so that those still being created after the maximum
+// creating duration can be cleaned up and rescheduled. A nil detector never
+// reports anything.
+type StuckCreatingDetector struct {
+	clock               clock.Clock
+	maxCreatingDuration time.Duration
+	creating            firstSeenTracker
+}
+
+func NewStuckCreatingDetector(clock clock.Clock, maxCreatingDuration time.Duration) *StuckCreatingDetector {
+	return &StuckCreatingDetector{
+		clock:               clock,
+		maxCreatingDuration: maxCreatingDuration,
+	}
+}
+
+// Stuck records the containers currently initializing and returns the guids
+// of those that have been initializing for at least the maximum creating
+// duration. A container that leaves the initializing state is forgotten.
+func (d *StuckCreatingDetector) Stuck(containers map[string]executor.Container) []string {
+	if d == nil {
+		return nil
+	}
+
+	creating := []string{}
+	for guid, container := range containers {
+		if container.State == executor.StateInitializing {
+			creating = append(creating, guid)
+		}
+	}
+
+	return d.creating.expired(d.clock.Now(), creating, d.maxCreatingDuration)
+}
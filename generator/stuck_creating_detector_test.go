@@ -0,0 +1,69 @@
+package generator_test
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/executor"
+	"code.cloudfoundry.org/rep/generator"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("StuckCreatingDetector", func() {
+	var (
+		fakeClock *fakeclock.FakeClock
+		detector  *generator.StuckCreatingDetector
+	)
+
+	containers := func(states map[string]executor.State) map[string]executor.Container {
+		containers := map[string]executor.Container{}
+		for guid, state := range states {
+			containers[guid] = executor.Container{Guid: guid, State: state}
+		}
+		return containers
+	}
+
+	BeforeEach(func() {
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		detector = generator.NewStuckCreatingDetector(fakeClock, time.Minute)
+	})
+
+	It("reports containers only once they have been initializing for the maximum duration", func() {
+		Expect(detector.Stuck(containers(map[string]executor.State{"guid-1": executor.StateInitializing}))).To(BeEmpty())
+
+		fakeClock.Increment(30 * time.Second)
+		Expect(detector.Stuck(containers(map[string]executor.State{
+			"guid-1": executor.StateInitializing,
+			"guid-2": executor.StateInitializing,
+		}))).To(BeEmpty())
+
+		fakeClock.Increment(30 * time.Second)
+		Expect(detector.Stuck(containers(map[string]executor.State{
+			"guid-1": executor.StateInitializing,
+			"guid-2": executor.StateInitializing,
+		}))).To(ConsistOf("guid-1"))
+	})
+
+	It("ignores containers that are not initializing", func() {
+		detector.Stuck(containers(map[string]executor.State{"guid-1": executor.StateReserved, "guid-2": executor.StateRunning}))
+		fakeClock.Increment(time.Hour)
+
+		Expect(detector.Stuck(containers(map[string]executor.State{"guid-1": executor.StateReserved, "guid-2": executor.StateRunning}))).To(BeEmpty())
+	})
+
+	It("forgets containers that finished initializing", func() {
+		detector.Stuck(containers(map[string]executor.State{"guid-1": executor.StateInitializing}))
+		fakeClock.Increment(time.Minute)
+
+		Expect(detector.Stuck(containers(map[string]executor.State{"guid-1": executor.StateCreated}))).To(BeEmpty())
+		Expect(detector.Stuck(containers(map[string]executor.State{"guid-1": executor.StateInitializing}))).To(BeEmpty())
+	})
+
+	Context("when the detector is nil", func() {
+		It("never reports anything", func() {
+			var nilDetector *generator.StuckCreatingDetector
+			Expect(nilDetector.Stuck(containers(map[string]executor.State{"guid-1": executor.StateInitializing}))).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/lager/v3"
+	"code.cloudfoundry.org/rep/auctioncellrep"
+)
+
+var allocationsCSVHeader = []string{"type", "guid", "rootfs", "memory_mb", "disk_mb", "tags", "result", "timestamp", "container_gone"}
+
+type AllocationHistory interface {
+	Decisions() []auctioncellrep.AllocationDecision
+}
+
+type allocationsCSVHandler struct {
+	history AllocationHistory
+}
+
+func newAllocationsCSVHandler(history AllocationHistory) *allocationsCSVHandler {
+	return &allocationsCSVHandler{history: history}
+}
+
+func (h *allocationsCSVHandler) ServeHTTP(w http.ResponseWriter, r *http.Request, logger lager.Logger) {
+	logger = logger.Session("allocations-csv")
+	if h.history == nil {
+		logger.Info("allocation-history-not-kept")
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	writer := csv.NewWriter(w)
+	// the csv writer keeps the first error, which is checked after flushing
+	writer.Write(allocationsCSVHeader)
+	for _, decision := range h.history.Decisions() {
+		writer.Write([]string{
+			decision.Kind,
+			decision.Guid,
+			decision.RootFS,
+			strconv.Itoa(int(decision.MemoryMB)),
+			strconv.Itoa(int(decision.DiskMB)),
+			strings.Join(decision.Tags, ";"),
+			decision.Result,
+			decision.Timestamp.UTC().Format(time.RFC3339Nano),
+			strconv.FormatBool(decision.ContainerGone),
+		})
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		logger.Debug("failed-to-write-allocations", lager.Data{"error": err.Error()})
+	}
+}
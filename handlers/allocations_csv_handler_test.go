@@ -0,0 +1,56 @@
+package handlers_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"net/http"
+	"time"
+
+	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/lager/v3/lagertest"
+	"code.cloudfoundry.org/rep"
+	"code.cloudfoundry.org/rep/auctioncellrep"
+	"code.cloudfoundry.org/rep/handlers"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AllocationsCSV", func() {
+	Context("when the allocation history is kept", func() {
+		BeforeEach(func() {
+			history := auctioncellrep.NewAllocationHistory(fakeclock.NewFakeClock(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)), 10)
+
+			resource := rep.NewResource(128, 256, 256)
+			acceptedLRP := rep.NewLRP("ig-1", models.NewActualLRPKey("pg-1", 0, "domain"), resource, rep.NewPlacementConstraint("preloaded:cflinuxfs4", []string{"a", "b"}, nil))
+			rejectedTask := rep.NewTask("tg-1", "domain", resource, rep.NewPlacementConstraint("docker:///busybox", nil, nil))
+			history.NotifyPerform(lagertest.NewTestLogger("test"), "trace-id",
+				rep.Work{LRPs: []rep.LRP{acceptedLRP}, Tasks: []rep.Task{rejectedTask}},
+				rep.Work{Tasks: []rep.Task{rejectedTask}},
+			)
+
+			StartServer(rep.RoutesLocalhostOnly, handlers.New(fakeLocalRep, fakeMetricCollector, fakeExecutorClient, fakeEvacuatable, fakeRequestMetrics, logger, false, handlers.Options{AllocationHistory: history}))
+		})
+
+		It("streams the recorded decisions as CSV", func() {
+			status, body := Request(rep.AllocationsCSVRoute, nil, nil)
+			Expect(status).To(Equal(http.StatusOK))
+
+			records, err := csv.NewReader(bytes.NewReader(body)).ReadAll()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(records).To(Equal([][]string{
+				{"type", "guid", "rootfs", "memory_mb", "disk_mb", "tags", "result", "timestamp", "container_gone"},
+				{"lrp", "ig-1", "preloaded:cflinuxfs4", "128", "256", "a;b", "accepted", "2024-01-02T03:04:05Z", "false"},
+				{"task", "tg-1", "docker:///busybox", "128", "256", "", "rejected", "2024-01-02T03:04:05Z", "false"},
+			}))
+		})
+	})
+
+	Context("when the allocation history is not kept", func() {
+		It("responds with 404", func() {
+			status, _ := Request(rep.AllocationsCSVRoute, nil, nil)
+			Expect(status).To(Equal(http.StatusNotFound))
+		})
+	})
+})
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+
+	"code.cloudfoundry.org/bbs"
+	"code.cloudfoundry.org/lager/v3"
+)
+
+// BBSClientInfo summarizes the settings of the rep's BBS client. It names
+// the certificate files but never includes their contents.
+type BBSClientInfo struct {
+	URL                    string `json:"url"`
+	RequestTimeout         string `json:"request_timeout"`
+	MaxIdleConnsPerHost    int    `json:"max_idle_conns_per_host"`
+	ClientSessionCacheSize int    `json:"client_session_cache_size"`
+	CAFile                 string `json:"ca_file,omitempty"`
+	CertFile               string `json:"cert_file,omitempty"`
+	KeyFile                string `json:"key_file,omitempty"`
+}
+
+// NewBBSClientInfo summarizes the configuration the BBS client was built
+// with, dropping any credentials from its URL.
+func NewBBSClientInfo(config bbs.ClientConfig) *BBSClientInfo {
+	address := config.URL
+	if parsed, err := url.Parse(address); err == nil && parsed.User != nil {
+		parsed.User = nil
+		address = parsed.String()
+	}
+
+	return &BBSClientInfo{
+		URL:                    address,
+		RequestTimeout:         config.RequestTimeout.String(),
+		MaxIdleConnsPerHost:    config.MaxIdleConnsPerHost,
+		ClientSessionCacheSize: config.ClientSessionCacheSize,
+		CAFile:                 config.CAFile,
+		CertFile:               config.CertFile,
+		KeyFile:                config.KeyFile,
+	}
+}
+
+type bbsClientInfoHandler struct {
+	info *BBSClientInfo
+}
+
+func newBBSClientInfoHandler(info *BBSClientInfo) *bbsClientInfoHandler {
+	return &bbsClientInfoHandler{info: info}
+}
+
+func (h *bbsClientInfoHandler) ServeHTTP(w http.ResponseWriter, r *http.Request, logger lager.Logger) {
+	if h.info == nil {
+		logger.Session("bbs-client-info").Info("bbs-client-info-not-reported")
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	// #nosec G104 - ignore errors when writing HTTP responses so we don't spam our logs during a DoS
+	json.NewEncoder(w).Encode(h.info)
+}
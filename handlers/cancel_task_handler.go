@@ -4,7 +4,6 @@ import (
 	"net/http"
 	"time"
 
-	"code.cloudfoundry.org/bbs/trace"
 	"code.cloudfoundry.org/executor"
 	"code.cloudfoundry.org/lager/v3"
 	"code.cloudfoundry.org/locket/metrics/helpers"
@@ -39,7 +38,7 @@ func (h *cancelTaskHandler) ServeHTTP(w http.ResponseWriter, r *http.Request, lo
 	go func() {
 		logger.Info("deleting-container")
 
-		err := h.executorClient.DeleteContainer(logger, trace.RequestIdFromRequest(r), taskGuid)
+		err := h.executorClient.DeleteContainer(logger, CorrelationIDFromContext(r.Context()), taskGuid)
 		switch err {
 		case nil:
 			logger.Info("succeeded-deleting-container")
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"code.cloudfoundry.org/executor"
+	"code.cloudfoundry.org/lager/v3"
+	"code.cloudfoundry.org/locket/metrics/helpers"
+	"code.cloudfoundry.org/rep"
+)
+
+type cancelTasksByDomainHandler struct {
+	executorClient executor.Client
+	metrics        helpers.RequestMetrics
+}
+
+func newCancelTasksByDomainHandler(executorClient executor.Client, requestMetrics helpers.RequestMetrics) *cancelTasksByDomainHandler {
+	return &cancelTasksByDomainHandler{
+		executorClient: executorClient,
+		metrics:        requestMetrics,
+	}
+}
+
+func (h *cancelTasksByDomainHandler) ServeHTTP(w http.ResponseWriter, r *http.Request, logger lager.Logger) {
+	var deferErr error
+
+	start := time.Now()
+	requestType := "CancelTasksByDomain"
+	startMetrics(h.metrics, requestType)
+	defer stopMetrics(h.metrics, requestType, start, &deferErr)
+
+	domain := r.FormValue(":domain")
+	logger = logger.Session("cancel-tasks-by-domain", lager.Data{"domain": domain}).WithTraceInfo(r)
+
+	// any domain may hold system tasks, so every cancellation has to be confirmed
+	if r.URL.Query().Get("confirm") != "true" {
+		logger.Info("refusing-to-cancel-domain-without-confirmation")
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	var containers []executor.Container
+	containers, deferErr = h.executorClient.ListContainers(logger)
+	if deferErr != nil {
+		logger.Error("failed-to-list-containers", deferErr)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	traceID := CorrelationIDFromContext(r.Context())
+	results := []rep.TaskCancellation{}
+	for _, task := range taskSummaries(containers) {
+		if task.Domain != domain {
+			continue
+		}
+
+		result := rep.TaskCancellation{TaskGuid: task.TaskGuid}
+		err := h.executorClient.DeleteContainer(logger, traceID, task.TaskGuid)
+		switch err {
+		case nil, executor.ErrContainerNotFound:
+			logger.Info("cancelled-task", lager.Data{"task-guid": task.TaskGuid})
+		default:
+			logger.Error("failed-to-cancel-task", err, lager.Data{"task-guid": task.TaskGuid})
+			result.Error = err.Error()
+		}
+		results = append(results, result)
+	}
+
+	logger.Info("cancelled-tasks", lager.Data{"num-tasks": len(results)})
+
+	// #nosec G104 - ignore errors when writing HTTP responses so we don't spam our logs during a DoS
+	json.NewEncoder(w).Encode(results)
+}
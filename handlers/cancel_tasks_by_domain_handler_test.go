@@ -0,0 +1,117 @@
+package handlers_test
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"code.cloudfoundry.org/executor"
+	"code.cloudfoundry.org/lager/v3"
+	"code.cloudfoundry.org/rep"
+	"code.cloudfoundry.org/rep/handlers"
+	"github.com/tedsuo/rata"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CancelTasksByDomain", func() {
+	cancelTasks := func(domain string, confirm bool) (int, []byte) {
+		request, err := requestGenerator.CreateRequest(rep.CancelTasksByDomainRoute, rata.Params{"domain": domain}, nil)
+		Expect(err).NotTo(HaveOccurred())
+		if confirm {
+			request.URL.RawQuery = "confirm=true"
+		}
+
+		response, err := client.Do(request)
+		Expect(err).NotTo(HaveOccurred())
+		defer response.Body.Close()
+
+		body, err := io.ReadAll(response.Body)
+		Expect(err).NotTo(HaveOccurred())
+		return response.StatusCode, body
+	}
+
+	BeforeEach(func() {
+		StartServer(rep.RoutesNetworkAccessible, handlers.New(fakeLocalRep, fakeMetricCollector, fakeExecutorClient, fakeEvacuatable, fakeRequestMetrics, logger, true, handlers.Options{}))
+
+		fakeExecutorClient.ListContainersReturns([]executor.Container{
+			{Guid: "task-2", Tags: executor.Tags{rep.LifecycleTag: rep.TaskLifecycle, rep.DomainTag: "cf-tasks"}},
+			{Guid: "lrp-1", Tags: executor.Tags{rep.LifecycleTag: rep.LRPLifecycle, rep.DomainTag: "cf-tasks"}},
+			{Guid: "task-1", Tags: executor.Tags{rep.LifecycleTag: rep.TaskLifecycle, rep.DomainTag: "cf-tasks"}},
+			{Guid: "task-3", Tags: executor.Tags{rep.LifecycleTag: rep.TaskLifecycle, rep.DomainTag: "other"}},
+		}, nil)
+	})
+
+	Context("when the domain has tasks", func() {
+		BeforeEach(func() {
+			fakeExecutorClient.DeleteContainerStub = func(_ lager.Logger, _ string, guid string) error {
+				switch guid {
+				case "task-1":
+					return errors.New("boom")
+				case "task-2":
+					return executor.ErrContainerNotFound
+				}
+				return nil
+			}
+		})
+
+		It("cancels only the tasks of that domain", func() {
+			status, _ := cancelTasks("cf-tasks", true)
+			Expect(status).To(Equal(http.StatusOK))
+
+			Expect(fakeExecutorClient.DeleteContainerCallCount()).To(Equal(2))
+			var cancelled []string
+			for i := 0; i < fakeExecutorClient.DeleteContainerCallCount(); i++ {
+				_, _, guid := fakeExecutorClient.DeleteContainerArgsForCall(i)
+				cancelled = append(cancelled, guid)
+			}
+			Expect(cancelled).To(ConsistOf("task-1", "task-2"))
+		})
+
+		It("reports the result for each task", func() {
+			_, body := cancelTasks("cf-tasks", true)
+			Expect(body).To(MatchJSON(`[
+				{"task_guid": "task-1", "error": "boom"},
+				{"task_guid": "task-2"}
+			]`))
+		})
+
+		It("emits the request metrics", func() {
+			cancelTasks("cf-tasks", true)
+
+			Expect(fakeRequestMetrics.IncrementRequestsStartedCounterCallCount()).To(Equal(1))
+			calledRequestType, _ := fakeRequestMetrics.IncrementRequestsStartedCounterArgsForCall(0)
+			Expect(calledRequestType).To(Equal("CancelTasksByDomain"))
+		})
+	})
+
+	Context("when the domain has no tasks", func() {
+		It("cancels nothing and returns an empty list", func() {
+			status, body := cancelTasks("empty", true)
+			Expect(status).To(Equal(http.StatusOK))
+			Expect(body).To(MatchJSON(`[]`))
+			Expect(fakeExecutorClient.DeleteContainerCallCount()).To(BeZero())
+		})
+	})
+
+	Context("when the cancellation is not confirmed", func() {
+		It("refuses to cancel the tasks", func() {
+			status, _ := cancelTasks("cf-tasks", false)
+			Expect(status).To(Equal(http.StatusBadRequest))
+			Expect(fakeExecutorClient.ListContainersCallCount()).To(BeZero())
+			Expect(fakeExecutorClient.DeleteContainerCallCount()).To(BeZero())
+		})
+	})
+
+	Context("when listing the containers fails", func() {
+		BeforeEach(func() {
+			fakeExecutorClient.ListContainersReturns(nil, errors.New("boom"))
+		})
+
+		It("responds with 500", func() {
+			status, _ := cancelTasks("cf-tasks", true)
+			Expect(status).To(Equal(http.StatusInternalServerError))
+		})
+	})
+})
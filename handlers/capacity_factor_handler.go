@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"code.cloudfoundry.org/lager/v3"
+)
+
+type CapacityFactorSetter interface {
+	Set(factor float64) error
+	Factor() float64
+}
+
+// StateInvalidator discards the cached cell state, so that the state served
+// to the auctioneer reflects a change right away.
+type StateInvalidator interface {
+	InvalidateState()
+}
+
+// CapacityFactorRequest sets the share, between 0.0 and 1.0, of the cell's
+// capacity it advertises and accepts work against.
+type CapacityFactorRequest struct {
+	Factor *float64 `json:"factor"`
+}
+
+type CapacityFactorResponse struct {
+	Factor float64 `json:"factor"`
+}
+
+type setCapacityFactorHandler struct {
+	capacityFactor   CapacityFactorSetter
+	stateInvalidator StateInvalidator
+	registrar        PresenceRegistrar
+}
+
+func newSetCapacityFactorHandler(capacityFactor CapacityFactorSetter, stateInvalidator StateInvalidator, registrar PresenceRegistrar) *setCapacityFactorHandler {
+	return &setCapacityFactorHandler{capacityFactor: capacityFactor, stateInvalidator: stateInvalidator, registrar: registrar}
+}
+
+func (h *setCapacityFactorHandler) ServeHTTP(w http.ResponseWriter, r *http.Request, logger lager.Logger) {
+	logger = logger.Session("set-capacity-factor")
+
+	if h.capacityFactor == nil {
+		logger.Info("capacity-factor-not-supported")
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	var request CapacityFactorRequest
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil || request.Factor == nil {
+		logger.Error("failed-to-decode-request", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	err = h.capacityFactor.Set(*request.Factor)
+	if err != nil {
+		logger.Error("invalid-capacity-factor", err, lager.Data{"factor": *request.Factor})
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	logger.Info("set", lager.Data{"factor": *request.Factor})
+
+	if h.stateInvalidator != nil {
+		h.stateInvalidator.InvalidateState()
+	}
+
+	if h.registrar != nil {
+		_, err = h.registrar.Reregister(logger)
+		if err != nil {
+			logger.Error("failed-to-reregister-presence", err)
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	// #nosec G104 - ignore errors when writing HTTP responses so we don't spam our logs during a DoS
+	json.NewEncoder(w).Encode(CapacityFactorResponse{Factor: h.capacityFactor.Factor()})
+}
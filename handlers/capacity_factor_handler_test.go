@@ -0,0 +1,121 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"strings"
+	"sync/atomic"
+
+	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/rep"
+	"code.cloudfoundry.org/rep/auctioncellrep"
+	"code.cloudfoundry.org/rep/handlers"
+	"code.cloudfoundry.org/rep/presence"
+	"github.com/tedsuo/ifrit"
+	ginkgomon "github.com/tedsuo/ifrit/ginkgomon_v2"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SetCapacityFactor", func() {
+	Context("when the capacity factor can be set", func() {
+		var (
+			capacityFactor   *auctioncellrep.CapacityFactor
+			stateInvalidator *countingStateInvalidator
+			process          ifrit.Process
+			registrar        *presence.Registrar
+		)
+
+		advertisedMemory := func() int32 {
+			status, body := Request(rep.PresencePayloadRoute, nil, nil)
+			Expect(status).To(Equal(http.StatusOK))
+
+			var cellPresence models.CellPresence
+			Expect(json.Unmarshal(body, &cellPresence)).To(Succeed())
+			return cellPresence.Capacity.MemoryMb
+		}
+
+		BeforeEach(func() {
+			capacityFactor = auctioncellrep.NewCapacityFactor()
+			stateInvalidator = &countingStateInvalidator{}
+			registrar = presence.NewRegistrar(logger, func() (ifrit.Runner, models.CellPresence, error) {
+				runner := ifrit.RunFunc(func(signals <-chan os.Signal, ready chan<- struct{}) error {
+					close(ready)
+					<-signals
+					return nil
+				})
+				memoryMB := int32(1024 * capacityFactor.Factor())
+				return runner, models.NewCellPresence(
+					"cell-id",
+					"https://cell-id.cell.service.cf.internal:1801",
+					"https://cell-id.cell.service.cf.internal:1801",
+					"the-zone",
+					models.NewCellCapacity(memoryMB, 2048, 10),
+					nil, nil, nil, nil, nil, nil,
+				), nil
+			})
+			process = ginkgomon.Invoke(registrar)
+
+			StartServer(rep.RoutesLocalhostOnly, handlers.New(fakeLocalRep, fakeMetricCollector, fakeExecutorClient, fakeEvacuatable, fakeRequestMetrics, logger, false, handlers.Options{PresenceRegistrar: registrar, CapacityFactor: capacityFactor, StateInvalidator: stateInvalidator}))
+		})
+
+		AfterEach(func() {
+			ginkgomon.Interrupt(process)
+		})
+
+		It("scales the advertised capacity and republishes the presence", func() {
+			Expect(advertisedMemory()).To(Equal(int32(1024)))
+
+			status, body := Request(rep.SetCapacityFactorRoute, nil, strings.NewReader(`{"factor": 0.5}`))
+			Expect(status).To(Equal(http.StatusOK))
+
+			var response handlers.CapacityFactorResponse
+			Expect(json.Unmarshal(body, &response)).To(Succeed())
+			Expect(response.Factor).To(Equal(0.5))
+			Expect(capacityFactor.Factor()).To(Equal(0.5))
+			Expect(advertisedMemory()).To(Equal(int32(512)))
+		})
+
+		It("invalidates the cached cell state", func() {
+			status, _ := Request(rep.SetCapacityFactorRoute, nil, strings.NewReader(`{"factor": 0.5}`))
+			Expect(status).To(Equal(http.StatusOK))
+			Expect(stateInvalidator.count.Load()).To(BeEquivalentTo(1))
+		})
+
+		It("restores the full capacity with a factor of 1.0", func() {
+			Request(rep.SetCapacityFactorRoute, nil, strings.NewReader(`{"factor": 0.5}`))
+			status, _ := Request(rep.SetCapacityFactorRoute, nil, strings.NewReader(`{"factor": 1.0}`))
+			Expect(status).To(Equal(http.StatusOK))
+			Expect(advertisedMemory()).To(Equal(int32(1024)))
+		})
+
+		It("rejects factors outside of 0.0 to 1.0", func() {
+			status, _ := Request(rep.SetCapacityFactorRoute, nil, strings.NewReader(`{"factor": 1.5}`))
+			Expect(status).To(Equal(http.StatusBadRequest))
+			Expect(capacityFactor.Factor()).To(Equal(1.0))
+			Expect(stateInvalidator.count.Load()).To(BeZero())
+		})
+
+		It("rejects requests without a factor", func() {
+			status, _ := Request(rep.SetCapacityFactorRoute, nil, strings.NewReader(`{}`))
+			Expect(status).To(Equal(http.StatusBadRequest))
+		})
+	})
+
+	Context("when the capacity factor cannot be set", func() {
+		It("responds with 404", func() {
+			status, _ := Request(rep.SetCapacityFactorRoute, nil, strings.NewReader(`{"factor": 0.5}`))
+			Expect(status).To(Equal(http.StatusNotFound))
+		})
+	})
+})
+
+type countingStateInvalidator struct {
+	count atomic.Int32
+}
+
+func (i *countingStateInvalidator) InvalidateState() {
+	i.count.Add(1)
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"code.cloudfoundry.org/lager/v3"
+	"code.cloudfoundry.org/rep/evacuation/evacuation_context"
+)
+
+// CellMode lists the modes the cell is in. Draining the cell is what starts
+// its evacuation, so a draining cell reports as evacuating. CapacityFactor is
+// left out when the cell's capacity cannot be scaled.
+type CellMode struct {
+	Evacuating      bool       `json:"evacuating"`
+	EvacuatingSince *time.Time `json:"evacuating_since,omitempty"`
+	CapacityFactor  *float64   `json:"capacity_factor,omitempty"`
+	ReadOnly        bool       `json:"read_only"`
+}
+
+type cellModeHandler struct {
+	evacuationReporter evacuation_context.EvacuationReporter
+	capacityFactor     CapacityFactorSetter
+	readOnlyMode       *ReadOnlyMode
+}
+
+func newCellModeHandler(evacuationReporter evacuation_context.EvacuationReporter, capacityFactor CapacityFactorSetter, readOnlyMode *ReadOnlyMode) *cellModeHandler {
+	return &cellModeHandler{evacuationReporter: evacuationReporter, capacityFactor: capacityFactor, readOnlyMode: readOnlyMode}
+}
+
+func (h *cellModeHandler) ServeHTTP(w http.ResponseWriter, r *http.Request, logger lager.Logger) {
+	if h.evacuationReporter == nil {
+		logger.Session("cell-mode").Info("cell-mode-not-reported")
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	mode := CellMode{
+		Evacuating: h.evacuationReporter.Evacuating(),
+		ReadOnly:   h.readOnlyMode.Enabled(),
+	}
+	if mode.Evacuating {
+		since := h.evacuationReporter.EvacuatingSince()
+		mode.EvacuatingSince = &since
+	}
+	if h.capacityFactor != nil {
+		factor := h.capacityFactor.Factor()
+		mode.CapacityFactor = &factor
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	// #nosec G104 - ignore errors when writing HTTP responses so we don't spam our logs during a DoS
+	json.NewEncoder(w).Encode(mode)
+}
@@ -0,0 +1,100 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"code.cloudfoundry.org/rep"
+	"code.cloudfoundry.org/rep/auctioncellrep"
+	"code.cloudfoundry.org/rep/evacuation/evacuation_context/fake_evacuation_context"
+	"code.cloudfoundry.org/rep/handlers"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CellMode", func() {
+	Context("when the cell mode is reported", func() {
+		var (
+			fakeEvacuationReporter *fake_evacuation_context.FakeEvacuationReporter
+			options                handlers.Options
+		)
+
+		BeforeEach(func() {
+			fakeEvacuationReporter = new(fake_evacuation_context.FakeEvacuationReporter)
+			options = handlers.Options{EvacuationReporter: fakeEvacuationReporter}
+		})
+
+		JustBeforeEach(func() {
+			StartServer(rep.RoutesLocalhostOnly, handlers.New(fakeLocalRep, fakeMetricCollector, fakeExecutorClient, fakeEvacuatable, fakeRequestMetrics, logger, false, options))
+		})
+
+		getCellMode := func() handlers.CellMode {
+			status, body := Request(rep.CellModeRoute, nil, nil)
+			Expect(status).To(Equal(http.StatusOK))
+
+			var mode handlers.CellMode
+			Expect(json.Unmarshal(body, &mode)).To(Succeed())
+			return mode
+		}
+
+		Context("when the cell is not evacuating", func() {
+			It("reports no mode set", func() {
+				mode := getCellMode()
+				Expect(mode.Evacuating).To(BeFalse())
+				Expect(mode.EvacuatingSince).To(BeNil())
+				Expect(mode.CapacityFactor).To(BeNil())
+				Expect(mode.ReadOnly).To(BeFalse())
+			})
+		})
+
+		Context("when the capacity factor is reduced", func() {
+			BeforeEach(func() {
+				capacityFactor := auctioncellrep.NewCapacityFactor()
+				Expect(capacityFactor.Set(0.5)).To(Succeed())
+				options.CapacityFactor = capacityFactor
+			})
+
+			It("reports the capacity factor", func() {
+				mode := getCellMode()
+				Expect(mode.CapacityFactor).To(HaveValue(Equal(0.5)))
+			})
+		})
+
+		Context("when the cell is in read-only mode", func() {
+			BeforeEach(func() {
+				options.ReadOnlyMode = handlers.NewReadOnlyMode(true)
+			})
+
+			It("reports the read-only mode", func() {
+				mode := getCellMode()
+				Expect(mode.ReadOnly).To(BeTrue())
+			})
+		})
+
+		Context("when the cell is evacuating", func() {
+			var evacuatingSince time.Time
+
+			BeforeEach(func() {
+				evacuatingSince = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+				fakeEvacuationReporter.EvacuatingReturns(true)
+				fakeEvacuationReporter.EvacuatingSinceReturns(evacuatingSince)
+			})
+
+			It("reports the evacuation and when it started", func() {
+				mode := getCellMode()
+				Expect(mode.Evacuating).To(BeTrue())
+				Expect(mode.EvacuatingSince).NotTo(BeNil())
+				Expect(mode.EvacuatingSince.Equal(evacuatingSince)).To(BeTrue())
+			})
+		})
+	})
+
+	Context("when the cell mode is not reported", func() {
+		It("responds with 404", func() {
+			status, _ := Request(rep.CellModeRoute, nil, nil)
+			Expect(status).To(Equal(http.StatusNotFound))
+		})
+	})
+})
@@ -1,6 +1,7 @@
 package handlers_test
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
@@ -32,15 +33,19 @@ var _ = Describe("ContainerMetrics", func() {
 				{
 					ProcessGUID:  "some-process-guid",
 					InstanceGUID: "some-instance-guid",
+					Reservation:  rep.ContainerReservation{MemoryMB: 256, DiskMB: 1024, MaxPids: 100},
 					CachedContainerMetrics: containermetrics.CachedContainerMetrics{
-						RxBytes: &one,
-						TxBytes: &one,
+						MemoryUsageBytes: 128 * 1024 * 1024,
+						DiskUsageBytes:   512 * 1024 * 1024,
+						RxBytes:          &one,
+						TxBytes:          &one,
 					},
 				},
 			},
 			Tasks: []rep.TaskMetric{
 				{
-					TaskGUID: "some-guid",
+					TaskGUID:    "some-guid",
+					Reservation: rep.ContainerReservation{MemoryMB: 64, DiskMB: 128, MaxPids: 10},
 				},
 			},
 		}
@@ -64,6 +69,7 @@ var _ = Describe("ContainerMetrics", func() {
 		Expect(lrps).To(ContainSubstring(`process_guid`))
 		Expect(lrps).To(ContainSubstring(`instance_guid`))
 		Expect(lrps).To(ContainSubstring(`index`))
+		Expect(lrps).To(ContainSubstring(`reservation`))
 		Expect(lrps).To(ContainSubstring(`metric_guid`))
 		Expect(lrps).To(ContainSubstring(`cpu_usage_fraction`))
 		Expect(lrps).To(ContainSubstring(`disk_usage_bytes`))
@@ -74,6 +80,7 @@ var _ = Describe("ContainerMetrics", func() {
 		Expect(lrps).To(ContainSubstring(`tx_bytes`))
 
 		Expect(tasks).To(ContainSubstring(`task_guid`))
+		Expect(tasks).To(ContainSubstring(`reservation`))
 		Expect(tasks).To(ContainSubstring(`metric_guid`))
 		Expect(tasks).To(ContainSubstring(`cpu_usage_fraction`))
 		Expect(tasks).To(ContainSubstring(`disk_usage_bytes`))
@@ -84,6 +91,55 @@ var _ = Describe("ContainerMetrics", func() {
 		Expect(tasks).ToNot(ContainSubstring(`tx_bytes`))
 	})
 
+	It("includes both the reservation and the actual usage of each container", func() {
+		status, body := Request(rep.ContainerMetricsRoute, nil, nil)
+		Expect(status).To(Equal(http.StatusOK))
+
+		var collection rep.ContainerMetricsCollection
+		Expect(json.Unmarshal(body, &collection)).To(Succeed())
+
+		Expect(collection.LRPs).To(HaveLen(1))
+		Expect(collection.LRPs[0].Reservation).To(Equal(rep.ContainerReservation{MemoryMB: 256, DiskMB: 1024, MaxPids: 100}))
+		Expect(collection.LRPs[0].MemoryUsageBytes).To(Equal(uint64(128 * 1024 * 1024)))
+		Expect(collection.LRPs[0].DiskUsageBytes).To(Equal(uint64(512 * 1024 * 1024)))
+
+		Expect(collection.Tasks).To(HaveLen(1))
+		Expect(collection.Tasks[0].Reservation).To(Equal(rep.ContainerReservation{MemoryMB: 64, DiskMB: 128, MaxPids: 10}))
+	})
+
+	It("reports zero filesystem usage for containers without usage alongside their disk reservation", func() {
+		status, body := Request(rep.ContainerMetricsRoute, nil, nil)
+		Expect(status).To(Equal(http.StatusOK))
+
+		var response struct {
+			Tasks []map[string]interface{} `json:"tasks"`
+		}
+		Expect(json.Unmarshal(body, &response)).To(Succeed())
+
+		Expect(response.Tasks).To(HaveLen(1))
+		Expect(response.Tasks[0]).To(HaveKeyWithValue("disk_usage_bytes", BeNumerically("==", 0)))
+		Expect(response.Tasks[0]).To(HaveKeyWithValue("reservation", HaveKeyWithValue("disk_mb", BeNumerically("==", 128))))
+	})
+
+	It("includes the network throughput of the containers", func() {
+		zero := uint64(0)
+		containerMetrics.Tasks[0].RxBytes = &zero
+		containerMetrics.Tasks[0].TxBytes = &zero
+
+		status, body := Request(rep.ContainerMetricsRoute, nil, nil)
+		Expect(status).To(Equal(http.StatusOK))
+
+		var collection rep.ContainerMetricsCollection
+		Expect(json.Unmarshal(body, &collection)).To(Succeed())
+
+		Expect(*collection.LRPs[0].RxBytes).To(Equal(uint64(1)))
+		Expect(*collection.LRPs[0].TxBytes).To(Equal(uint64(1)))
+		Expect(collection.Tasks[0].RxBytes).NotTo(BeNil())
+		Expect(*collection.Tasks[0].RxBytes).To(BeZero())
+		Expect(collection.Tasks[0].TxBytes).NotTo(BeNil())
+		Expect(*collection.Tasks[0].TxBytes).To(BeZero())
+	})
+
 	It("it returns whatever the container_metrics call returns", func() {
 		status, body := Request(rep.ContainerMetricsRoute, nil, nil)
 		Expect(status).To(Equal(http.StatusOK))
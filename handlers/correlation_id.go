@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"code.cloudfoundry.org/bbs/trace"
+	uuid "github.com/nu7hatch/gouuid"
+)
+
+type correlationIDKey struct{}
+
+// CorrelationIDFromContext returns the correlation ID of the request the
+// context belongs to, or an empty string if there is none.
+func CorrelationIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(correlationIDKey{}).(string)
+	return id
+}
+
+// withCorrelationID makes sure every request carries a request ID, generating
+// one when the caller did not send it. The ID is echoed back to the caller,
+// logged with the request and, through CorrelationIDFromContext, handed to the
+// executor as the trace ID of the work it starts, which in turn passes it on
+// to the BBS calls the resulting container events trigger.
+func withCorrelationID(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := trace.RequestIdFromRequest(r)
+		if id == "" {
+			generated, err := uuid.NewV4()
+			if err != nil {
+				handler(w, r)
+				return
+			}
+			id = generated.String()
+			r.Header.Set(trace.RequestIdHeader, id)
+		}
+
+		w.Header().Set(trace.RequestIdHeader, id)
+		handler(w, r.WithContext(context.WithValue(r.Context(), correlationIDKey{}, id)))
+	}
+}
@@ -0,0 +1,64 @@
+package handlers_test
+
+import (
+	"net/http"
+
+	"code.cloudfoundry.org/bbs/trace"
+	"code.cloudfoundry.org/rep"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Correlation IDs", func() {
+	var request *http.Request
+
+	BeforeEach(func() {
+		var err error
+		request, err = requestGenerator.CreateRequest(rep.PerformRoute, nil, JSONReaderFor(rep.Work{}))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	Context("when the request carries a request ID", func() {
+		const requestID = "eb89bcf8-3901-ff0f-a4b3-151312f5154b"
+
+		BeforeEach(func() {
+			request.Header.Set(trace.RequestIdHeader, requestID)
+		})
+
+		It("passes it on as the trace ID of the work and echoes it back", func() {
+			response, err := client.Do(request)
+			Expect(err).NotTo(HaveOccurred())
+			response.Body.Close()
+
+			Expect(response.Header.Get(trace.RequestIdHeader)).To(Equal(requestID))
+			Expect(fakeLocalRep.PerformCallCount()).To(Equal(1))
+			_, _, traceID, _ := fakeLocalRep.PerformArgsForCall(0)
+			Expect(traceID).To(Equal(requestID))
+		})
+	})
+
+	Context("when the request does not carry a request ID", func() {
+		It("generates one, passes it on as the trace ID of the work and echoes it back", func() {
+			response, err := client.Do(request)
+			Expect(err).NotTo(HaveOccurred())
+			response.Body.Close()
+
+			generated := response.Header.Get(trace.RequestIdHeader)
+			Expect(generated).NotTo(BeEmpty())
+			Expect(fakeLocalRep.PerformCallCount()).To(Equal(1))
+			_, _, traceID, _ := fakeLocalRep.PerformArgsForCall(0)
+			Expect(traceID).To(Equal(generated))
+		})
+
+		It("generates a distinct ID for every request", func() {
+			Request(rep.PerformRoute, nil, JSONReaderFor(rep.Work{}))
+			Request(rep.PerformRoute, nil, JSONReaderFor(rep.Work{}))
+
+			Expect(fakeLocalRep.PerformCallCount()).To(Equal(2))
+			_, _, first, _ := fakeLocalRep.PerformArgsForCall(0)
+			_, _, second, _ := fakeLocalRep.PerformArgsForCall(1)
+			Expect(first).NotTo(Equal(second))
+		})
+	})
+})
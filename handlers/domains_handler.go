@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"time"
+
+	"code.cloudfoundry.org/executor"
+	"code.cloudfoundry.org/lager/v3"
+	"code.cloudfoundry.org/locket/metrics/helpers"
+	"code.cloudfoundry.org/rep"
+)
+
+type domainsHandler struct {
+	client  executor.Client
+	metrics helpers.RequestMetrics
+}
+
+func newDomainsHandler(client executor.Client, metrics helpers.RequestMetrics) *domainsHandler {
+	return &domainsHandler{client: client, metrics: metrics}
+}
+
+func (h *domainsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request, logger lager.Logger) {
+	var deferErr error
+
+	start := time.Now()
+	requestType := "Domains"
+	startMetrics(h.metrics, requestType)
+	defer stopMetrics(h.metrics, requestType, start, &deferErr)
+
+	logger = logger.Session("domains-handler").WithTraceInfo(r)
+
+	var containers []executor.Container
+	containers, deferErr = h.client.ListContainers(logger)
+	if deferErr != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		logger.Error("failed-to-list-containers", deferErr)
+		return
+	}
+
+	// #nosec G104 - ignore errors when writing HTTP responses so we don't spam our logs during a DoS
+	json.NewEncoder(w).Encode(distinctDomains(containers))
+}
+
+func distinctDomains(containers []executor.Container) []string {
+	seen := map[string]struct{}{}
+	domains := []string{}
+	for _, container := range containers {
+		domain := container.Tags[rep.DomainTag]
+		if domain == "" {
+			continue
+		}
+		if _, ok := seen[domain]; ok {
+			continue
+		}
+		seen[domain] = struct{}{}
+		domains = append(domains, domain)
+	}
+
+	sort.Strings(domains)
+	return domains
+}
@@ -0,0 +1,59 @@
+package handlers_test
+
+import (
+	"errors"
+	"net/http"
+
+	"code.cloudfoundry.org/executor"
+	"code.cloudfoundry.org/rep"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Domains", func() {
+	Context("when listing the containers succeeds", func() {
+		BeforeEach(func() {
+			fakeExecutorClient.ListContainersReturns([]executor.Container{
+				{Guid: "container-1", Tags: executor.Tags{rep.DomainTag: "cf-apps"}},
+				{Guid: "container-2", Tags: executor.Tags{rep.DomainTag: "cf-tasks"}},
+				{Guid: "container-3", Tags: executor.Tags{rep.DomainTag: "cf-apps"}},
+				{Guid: "container-4", Tags: executor.Tags{rep.DomainTag: "other"}},
+				{Guid: "container-5"},
+			}, nil)
+		})
+
+		It("returns the distinct domains of the containers", func() {
+			status, body := Request(rep.DomainsRoute, nil, nil)
+			Expect(status).To(Equal(http.StatusOK))
+			Expect(body).To(MatchJSON(`["cf-apps", "cf-tasks", "other"]`))
+		})
+
+		It("emits the request metrics", func() {
+			Request(rep.DomainsRoute, nil, nil)
+
+			Expect(fakeRequestMetrics.IncrementRequestsStartedCounterCallCount()).To(Equal(1))
+			calledRequestType, _ := fakeRequestMetrics.IncrementRequestsStartedCounterArgsForCall(0)
+			Expect(calledRequestType).To(Equal("Domains"))
+		})
+	})
+
+	Context("when there are no containers", func() {
+		It("returns an empty list", func() {
+			status, body := Request(rep.DomainsRoute, nil, nil)
+			Expect(status).To(Equal(http.StatusOK))
+			Expect(body).To(MatchJSON(`[]`))
+		})
+	})
+
+	Context("when listing the containers fails", func() {
+		BeforeEach(func() {
+			fakeExecutorClient.ListContainersReturns(nil, errors.New("boom"))
+		})
+
+		It("responds with 500", func() {
+			status, _ := Request(rep.DomainsRoute, nil, nil)
+			Expect(status).To(Equal(http.StatusInternalServerError))
+		})
+	})
+})
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"code.cloudfoundry.org/executor"
+	"code.cloudfoundry.org/lager/v3"
+	"code.cloudfoundry.org/rep/evacuation/evacuation_context"
+)
+
+const (
+	IneligibleAlreadyEvacuating = "cell is already evacuating"
+	IneligibleNoContainers      = "cell has no containers to evacuate"
+	IneligiblePresenceMissing   = "cell presence is not published"
+)
+
+// EvacuationEligibility reports whether evacuating the cell would make
+// sense, and if not, every reason why not.
+type EvacuationEligibility struct {
+	Eligible bool     `json:"eligible"`
+	Reasons  []string `json:"reasons"`
+}
+
+type evacuationEligibilityHandler struct {
+	evacuationReporter evacuation_context.EvacuationReporter
+	executorClient     executor.Client
+	registrar          PresenceRegistrar
+}
+
+func newEvacuationEligibilityHandler(evacuationReporter evacuation_context.EvacuationReporter, executorClient executor.Client, registrar PresenceRegistrar) *evacuationEligibilityHandler {
+	return &evacuationEligibilityHandler{
+		evacuationReporter: evacuationReporter,
+		executorClient:     executorClient,
+		registrar:          registrar,
+	}
+}
+
+func (h *evacuationEligibilityHandler) ServeHTTP(w http.ResponseWriter, r *http.Request, logger lager.Logger) {
+	logger = logger.Session("evacuation-eligibility")
+
+	if h.evacuationReporter == nil {
+		logger.Info("evacuation-not-reported")
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	containers, err := h.executorClient.ListContainers(logger)
+	if err != nil {
+		logger.Error("failed-to-list-containers", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	reasons := []string{}
+	if h.evacuationReporter.Evacuating() {
+		reasons = append(reasons, IneligibleAlreadyEvacuating)
+	}
+	if len(containers) == 0 {
+		reasons = append(reasons, IneligibleNoContainers)
+	}
+	if !h.presencePublished() {
+		reasons = append(reasons, IneligiblePresenceMissing)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	// #nosec G104 - ignore errors when writing HTTP responses so we don't spam our logs during a DoS
+	json.NewEncoder(w).Encode(EvacuationEligibility{Eligible: len(reasons) == 0, Reasons: reasons})
+}
+
+func (h *evacuationEligibilityHandler) presencePublished() bool {
+	if h.registrar == nil {
+		return false
+	}
+	_, published := h.registrar.Presence()
+	return published
+}
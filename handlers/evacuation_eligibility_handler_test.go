@@ -0,0 +1,112 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/executor"
+	"code.cloudfoundry.org/lager/v3"
+	"code.cloudfoundry.org/rep"
+	"code.cloudfoundry.org/rep/evacuation/evacuation_context/fake_evacuation_context"
+	"code.cloudfoundry.org/rep/handlers"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type stubPresenceRegistrar struct {
+	published bool
+}
+
+func (r *stubPresenceRegistrar) Presence() (models.CellPresence, bool) {
+	return models.CellPresence{CellId: "cell-id"}, r.published
+}
+
+func (r *stubPresenceRegistrar) Reregister(lager.Logger) (models.CellPresence, error) {
+	return models.CellPresence{}, errors.New("not implemented")
+}
+
+func (r *stubPresenceRegistrar) Renew(lager.Logger) error {
+	return errors.New("not implemented")
+}
+
+var _ = Describe("EvacuationEligibility", func() {
+	Context("when the evacuation is reported", func() {
+		var (
+			fakeEvacuationReporter *fake_evacuation_context.FakeEvacuationReporter
+			registrar              *stubPresenceRegistrar
+		)
+
+		BeforeEach(func() {
+			fakeEvacuationReporter = new(fake_evacuation_context.FakeEvacuationReporter)
+			registrar = &stubPresenceRegistrar{published: true}
+			fakeExecutorClient.ListContainersReturns([]executor.Container{{Guid: "container-1"}}, nil)
+		})
+
+		JustBeforeEach(func() {
+			StartServer(rep.RoutesLocalhostOnly, handlers.New(fakeLocalRep, fakeMetricCollector, fakeExecutorClient, fakeEvacuatable, fakeRequestMetrics, logger, false, handlers.Options{PresenceRegistrar: registrar, EvacuationReporter: fakeEvacuationReporter}))
+		})
+
+		getEligibility := func() handlers.EvacuationEligibility {
+			status, body := Request(rep.EvacuationEligibilityRoute, nil, nil)
+			Expect(status).To(Equal(http.StatusOK))
+
+			var eligibility handlers.EvacuationEligibility
+			Expect(json.Unmarshal(body, &eligibility)).To(Succeed())
+			return eligibility
+		}
+
+		Context("when the cell is healthy, running containers and not evacuating", func() {
+			It("reports the cell as eligible", func() {
+				eligibility := getEligibility()
+				Expect(eligibility.Eligible).To(BeTrue())
+				Expect(eligibility.Reasons).To(BeEmpty())
+			})
+		})
+
+		Context("when the cell is already evacuating", func() {
+			BeforeEach(func() {
+				fakeEvacuationReporter.EvacuatingReturns(true)
+			})
+
+			It("reports the cell as ineligible", func() {
+				eligibility := getEligibility()
+				Expect(eligibility.Eligible).To(BeFalse())
+				Expect(eligibility.Reasons).To(ConsistOf(handlers.IneligibleAlreadyEvacuating))
+			})
+		})
+
+		Context("when the cell has no containers and no published presence", func() {
+			BeforeEach(func() {
+				fakeExecutorClient.ListContainersReturns(nil, nil)
+				registrar.published = false
+			})
+
+			It("reports every reason the cell is ineligible", func() {
+				eligibility := getEligibility()
+				Expect(eligibility.Eligible).To(BeFalse())
+				Expect(eligibility.Reasons).To(ConsistOf(handlers.IneligibleNoContainers, handlers.IneligiblePresenceMissing))
+			})
+		})
+
+		Context("when listing the containers fails", func() {
+			BeforeEach(func() {
+				fakeExecutorClient.ListContainersReturns(nil, errors.New("boom"))
+			})
+
+			It("responds with 500", func() {
+				status, _ := Request(rep.EvacuationEligibilityRoute, nil, nil)
+				Expect(status).To(Equal(http.StatusInternalServerError))
+			})
+		})
+	})
+
+	Context("when the evacuation is not reported", func() {
+		It("responds with 404", func() {
+			status, _ := Request(rep.EvacuationEligibilityRoute, nil, nil)
+			Expect(status).To(Equal(http.StatusNotFound))
+		})
+	})
+})
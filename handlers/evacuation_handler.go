@@ -24,9 +24,15 @@ func newEvacuationHandler(evacuatable evacuation_context.Evacuatable, requestMet
 }
 
 func (h *evacuationHandler) ServeHTTP(w http.ResponseWriter, r *http.Request, logger lager.Logger) {
-	h.evacuatable.Evacuate()
+	status := http.StatusAccepted
+	response := map[string]string{"ping_path": "/ping"}
+	if !h.evacuatable.Evacuate() {
+		logger.Session("evacuation").Info("already-evacuating")
+		status = http.StatusOK
+		response["already_evacuating"] = "true"
+	}
 
-	jsonBytes, err := json.Marshal(map[string]string{"ping_path": "/ping"})
+	jsonBytes, err := json.Marshal(response)
 	if err != nil {
 		//THIS SHOULD NEVER HAPPEN
 		w.WriteHeader(http.StatusInternalServerError)
@@ -35,7 +41,7 @@ func (h *evacuationHandler) ServeHTTP(w http.ResponseWriter, r *http.Request, lo
 
 	w.Header().Set("Content-Length", strconv.Itoa(len(jsonBytes)))
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusAccepted)
+	w.WriteHeader(status)
 	// #nosec G104 - ignore errors when writing HTTP responses so we don't spam our logs during a DoS
 	w.Write(jsonBytes)
 }
@@ -7,10 +7,15 @@ import (
 	"code.cloudfoundry.org/rep"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
 )
 
 var _ = Describe("EvacuationHandler", func() {
 	Context("when receiving a request", func() {
+		BeforeEach(func() {
+			fakeEvacuatable.EvacuateReturns(true)
+		})
+
 		It("starts evacuation", func() {
 			Request(rep.EvacuateRoute, nil, nil)
 			Expect(fakeEvacuatable.EvacuateCallCount()).To(Equal(1))
@@ -29,6 +34,33 @@ var _ = Describe("EvacuationHandler", func() {
 			Expect(err).NotTo(HaveOccurred())
 			Expect(responseValues).To(HaveKey("ping_path"))
 			Expect(responseValues["ping_path"]).To(Equal("/ping"))
+			Expect(responseValues).NotTo(HaveKey("already_evacuating"))
+		})
+	})
+
+	Context("when the cell is already evacuating", func() {
+		BeforeEach(func() {
+			fakeEvacuatable.EvacuateReturns(false)
+		})
+
+		It("responds with 200 OK", func() {
+			status, _ := Request(rep.EvacuateRoute, nil, nil)
+			Expect(status).To(Equal(http.StatusOK))
+		})
+
+		It("reports that the request was a no-op", func() {
+			_, body := Request(rep.EvacuateRoute, nil, nil)
+
+			var responseValues map[string]string
+			err := json.Unmarshal(body, &responseValues)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(responseValues["already_evacuating"]).To(Equal("true"))
+			Expect(responseValues["ping_path"]).To(Equal("/ping"))
+		})
+
+		It("logs it", func() {
+			Request(rep.EvacuateRoute, nil, nil)
+			Expect(logger).To(gbytes.Say("already-evacuating"))
 		})
 	})
 })
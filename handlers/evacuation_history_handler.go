@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"code.cloudfoundry.org/lager/v3"
+	"code.cloudfoundry.org/rep/evacuation"
+)
+
+type EvacuationHistory interface {
+	Evacuations() []evacuation.Summary
+}
+
+type evacuationHistoryHandler struct {
+	history EvacuationHistory
+}
+
+func newEvacuationHistoryHandler(history EvacuationHistory) *evacuationHistoryHandler {
+	return &evacuationHistoryHandler{history: history}
+}
+
+func (h *evacuationHistoryHandler) ServeHTTP(w http.ResponseWriter, r *http.Request, logger lager.Logger) {
+	if h.history == nil {
+		logger.Session("evacuation-history").Info("evacuation-history-not-kept")
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	// #nosec G104 - ignore errors when writing HTTP responses so we don't spam our logs during a DoS
+	json.NewEncoder(w).Encode(h.history.Evacuations())
+}
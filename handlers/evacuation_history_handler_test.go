@@ -0,0 +1,58 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"code.cloudfoundry.org/rep"
+	"code.cloudfoundry.org/rep/evacuation"
+	"code.cloudfoundry.org/rep/handlers"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("EvacuationHistory", func() {
+	Context("when the evacuation history is kept", func() {
+		var summary evacuation.Summary
+
+		BeforeEach(func() {
+			startedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+			summary = evacuation.Summary{
+				StartedAt:           startedAt,
+				FinishedAt:          startedAt.Add(time.Minute),
+				Outcome:             evacuation.OutcomeCompleted,
+				ContainersDrained:   3,
+				ContainersDestroyed: 1,
+			}
+
+			history := evacuation.NewHistory(5)
+			history.Record(summary)
+
+			StartServer(rep.RoutesLocalhostOnly, handlers.New(fakeLocalRep, fakeMetricCollector, fakeExecutorClient, fakeEvacuatable, fakeRequestMetrics, logger, false, handlers.Options{EvacuationHistory: history}))
+		})
+
+		It("returns the recorded evacuations", func() {
+			status, body := Request(rep.EvacuationHistoryRoute, nil, nil)
+			Expect(status).To(Equal(http.StatusOK))
+
+			var evacuations []evacuation.Summary
+			Expect(json.Unmarshal(body, &evacuations)).To(Succeed())
+			Expect(evacuations).To(HaveLen(1))
+			Expect(evacuations[0].StartedAt.Equal(summary.StartedAt)).To(BeTrue())
+			Expect(evacuations[0].FinishedAt.Equal(summary.FinishedAt)).To(BeTrue())
+			Expect(evacuations[0].Outcome).To(Equal(evacuation.OutcomeCompleted))
+			Expect(evacuations[0].ContainersDrained).To(Equal(3))
+			Expect(evacuations[0].ContainersDestroyed).To(Equal(1))
+			Expect(evacuations[0].ContainersRemaining).To(Equal(0))
+		})
+	})
+
+	Context("when the evacuation history is not kept", func() {
+		It("responds with 404", func() {
+			status, _ := Request(rep.EvacuationHistoryRoute, nil, nil)
+			Expect(status).To(Equal(http.StatusNotFound))
+		})
+	})
+})
@@ -0,0 +1,29 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"code.cloudfoundry.org/lager/v3"
+	"code.cloudfoundry.org/rep"
+)
+
+type extraRootFSHandler struct {
+	loads []rep.ExtraRootFSLoad
+}
+
+func newExtraRootFSHandler(loads []rep.ExtraRootFSLoad) *extraRootFSHandler {
+	return &extraRootFSHandler{loads: loads}
+}
+
+func (h *extraRootFSHandler) ServeHTTP(w http.ResponseWriter, r *http.Request, logger lager.Logger) {
+	if h.loads == nil {
+		logger.Session("extra-rootfs").Info("extra-rootfs-not-reported")
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	// #nosec G104 - ignore errors when writing HTTP responses so we don't spam our logs during a DoS
+	json.NewEncoder(w).Encode(h.loads)
+}
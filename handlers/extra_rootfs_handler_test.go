@@ -0,0 +1,42 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"code.cloudfoundry.org/rep"
+	"code.cloudfoundry.org/rep/handlers"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ExtraRootFS", func() {
+	Context("when the extra rootfs loads are reported", func() {
+		var loads []rep.ExtraRootFSLoad
+
+		BeforeEach(func() {
+			loads = []rep.ExtraRootFSLoad{
+				{Path: "/var/vcap/data/rootfses/a.tar", Name: "a", Loaded: true},
+				{Path: "/var/vcap/data/rootfses/b.tar", Name: "b", Reason: rep.ExtraRootFSMaxReachedReason},
+			}
+			StartServer(rep.RoutesLocalhostOnly, handlers.New(fakeLocalRep, fakeMetricCollector, fakeExecutorClient, fakeEvacuatable, fakeRequestMetrics, logger, false, handlers.Options{ExtraRootFSLoads: loads}))
+		})
+
+		It("lists every tarball with whether it was loaded", func() {
+			status, body := Request(rep.ExtraRootFSRoute, nil, nil)
+			Expect(status).To(Equal(http.StatusOK))
+
+			var reported []rep.ExtraRootFSLoad
+			Expect(json.Unmarshal(body, &reported)).To(Succeed())
+			Expect(reported).To(Equal(loads))
+		})
+	})
+
+	Context("when the extra rootfs loads are not reported", func() {
+		It("responds with 404", func() {
+			status, _ := Request(rep.ExtraRootFSRoute, nil, nil)
+			Expect(status).To(Equal(http.StatusNotFound))
+		})
+	})
+})
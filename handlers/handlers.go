@@ -12,6 +12,26 @@ import (
 	"github.com/tedsuo/rata"
 )
 
+// Options holds what the routes added on top of the auction routes depend
+// on. Routes whose dependency is left unset respond with 404.
+type Options struct {
+	MaxPlacementTagsPerRequest int
+	PresenceRegistrar          PresenceRegistrar
+	UptimeReporter             UptimeReporter
+	SupportedProviders         []string
+	EvacuationHistory          EvacuationHistory
+	EvacuationReporter         evacuation_context.EvacuationReporter
+	TLSInfo                    *TLSInfo
+	CapacityFactor             CapacityFactorSetter
+	StateInvalidator           StateInvalidator
+	DecisionNotifier           DecisionNotifier
+	LastCallers                *LastCallers
+	ReadOnlyMode               *ReadOnlyMode
+	AllocationHistory          AllocationHistory
+	BBSClientInfo              *BBSClientInfo
+	ExtraRootFSLoads           []rep.ExtraRootFSLoad
+}
+
 func New(
 	localCellClient auctioncellrep.AuctionCellClient,
 	localMetricCollector MetricCollector,
@@ -20,33 +40,78 @@ func New(
 	requestMetrics helpers.RequestMetrics,
 	logger lager.Logger,
 	secure bool,
+	options Options,
 ) rata.Handlers {
 
 	handlers := rata.Handlers{}
 	if secure {
 		stateHandler := newStateHandler(localCellClient, requestMetrics)
 		containerMetricsHandler := newContainerMetricsHandler(localMetricCollector, requestMetrics)
-		performHandler := newPerformHandler(localCellClient, requestMetrics)
+		lrpUsageHandler := newLRPUsageHandler(localMetricCollector, requestMetrics)
+		performHandler := newPerformHandler(localCellClient, requestMetrics, options.MaxPlacementTagsPerRequest, options.DecisionNotifier)
 		resetHandler := newResetHandler(localCellClient, requestMetrics)
 		updateLrpHandler := NewUpdateLRPInstanceHandler(executorClient, requestMetrics)
 		stopLrpHandler := NewStopLRPInstanceHandler(executorClient, requestMetrics)
 		cancelTaskHandler := newCancelTaskHandler(executorClient, requestMetrics)
+		cancelTasksByDomainHandler := newCancelTasksByDomainHandler(executorClient, requestMetrics)
+		domainsHandler := newDomainsHandler(executorClient, requestMetrics)
+		tasksHandler := newTasksHandler(executorClient, requestMetrics)
+		supportedProvidersHandler := newSupportedProvidersHandler(options.SupportedProviders, requestMetrics)
 
-		handlers[rep.StateRoute] = logWrap(stateHandler.ServeHTTP, logger)
+		handlers[rep.StateRoute] = logWrap(recordCaller(options.LastCallers, rep.StateRoute, stateHandler.ServeHTTP), logger)
 		handlers[rep.ContainerMetricsRoute] = logWrap(containerMetricsHandler.ServeHTTP, logger)
-		handlers[rep.PerformRoute] = logWrap(performHandler.ServeHTTP, logger)
-		handlers[rep.SimResetRoute] = logWrap(resetHandler.ServeHTTP, logger)
+		handlers[rep.LRPUsageRoute] = logWrap(lrpUsageHandler.ServeHTTP, logger)
+		handlers[rep.PerformRoute] = logWrap(rejectWhenReadOnly(options.ReadOnlyMode, recordCaller(options.LastCallers, rep.PerformRoute, performHandler.ServeHTTP)), logger)
+		handlers[rep.SimResetRoute] = logWrap(rejectWhenReadOnly(options.ReadOnlyMode, resetHandler.ServeHTTP), logger)
 
-		handlers[rep.StopLRPInstanceRoute] = logWrap(stopLrpHandler.ServeHTTP, logger)
-		handlers[rep.UpdateLRPInstanceRoute] = logWrap(updateLrpHandler.ServeHTTP, logger)
-		handlers[rep.UpdateLRPInstanceRoute_r0] = logWrap(updateLrpHandler.ServeHTTP, logger)
-		handlers[rep.CancelTaskRoute] = logWrap(cancelTaskHandler.ServeHTTP, logger)
+		handlers[rep.StopLRPInstanceRoute] = logWrap(rejectWhenReadOnly(options.ReadOnlyMode, stopLrpHandler.ServeHTTP), logger)
+		handlers[rep.UpdateLRPInstanceRoute] = logWrap(rejectWhenReadOnly(options.ReadOnlyMode, updateLrpHandler.ServeHTTP), logger)
+		handlers[rep.UpdateLRPInstanceRoute_r0] = logWrap(rejectWhenReadOnly(options.ReadOnlyMode, updateLrpHandler.ServeHTTP), logger)
+		handlers[rep.CancelTaskRoute] = logWrap(rejectWhenReadOnly(options.ReadOnlyMode, cancelTaskHandler.ServeHTTP), logger)
+		handlers[rep.CancelTasksByDomainRoute] = logWrap(rejectWhenReadOnly(options.ReadOnlyMode, cancelTasksByDomainHandler.ServeHTTP), logger)
+		handlers[rep.DomainsRoute] = logWrap(domainsHandler.ServeHTTP, logger)
+		handlers[rep.TasksRoute] = logWrap(tasksHandler.ServeHTTP, logger)
+		handlers[rep.SupportedProvidersRoute] = logWrap(supportedProvidersHandler.ServeHTTP, logger)
 	} else {
 		pingHandler := newPingHandler(requestMetrics)
 		evacuationHandler := newEvacuationHandler(evacuatable, requestMetrics)
+		resourceAccountingHandler := newResourceAccountingHandler(localCellClient)
+		presencePayloadHandler := newPresencePayloadHandler(options.PresenceRegistrar)
+		reregisterPresenceHandler := newReregisterPresenceHandler(options.PresenceRegistrar)
+		renewPresenceHandler := newRenewPresenceHandler(options.PresenceRegistrar)
+		uptimeHandler := newUptimeHandler(options.UptimeReporter)
+		evacuationHistoryHandler := newEvacuationHistoryHandler(options.EvacuationHistory)
+		cellModeHandler := newCellModeHandler(options.EvacuationReporter, options.CapacityFactor, options.ReadOnlyMode)
+		evacuationEligibilityHandler := newEvacuationEligibilityHandler(options.EvacuationReporter, executorClient, options.PresenceRegistrar)
+		runtimeHandler := newRuntimeHandler()
+		tlsInfoHandler := newTLSInfoHandler(options.TLSInfo)
+		setCapacityFactorHandler := newSetCapacityFactorHandler(options.CapacityFactor, options.StateInvalidator, options.PresenceRegistrar)
+		lastCallerHandler := newLastCallerHandler(options.LastCallers)
+		setReadOnlyModeHandler := newSetReadOnlyModeHandler(options.ReadOnlyMode)
+		allocationsCSVHandler := newAllocationsCSVHandler(options.AllocationHistory)
+		validatePlacementHandler := newValidatePlacementHandler(localCellClient)
+		bbsClientInfoHandler := newBBSClientInfoHandler(options.BBSClientInfo)
+		extraRootFSHandler := newExtraRootFSHandler(options.ExtraRootFSLoads)
 
 		handlers[rep.PingRoute] = logWrap(pingHandler.ServeHTTP, logger)
 		handlers[rep.EvacuateRoute] = logWrap(evacuationHandler.ServeHTTP, logger)
+		handlers[rep.ResourceAccountingRoute] = logWrap(resourceAccountingHandler.ServeHTTP, logger)
+		handlers[rep.PresencePayloadRoute] = logWrap(presencePayloadHandler.ServeHTTP, logger)
+		handlers[rep.ReregisterPresenceRoute] = logWrap(reregisterPresenceHandler.ServeHTTP, logger)
+		handlers[rep.RenewPresenceRoute] = logWrap(renewPresenceHandler.ServeHTTP, logger)
+		handlers[rep.UptimeRoute] = logWrap(uptimeHandler.ServeHTTP, logger)
+		handlers[rep.EvacuationHistoryRoute] = logWrap(evacuationHistoryHandler.ServeHTTP, logger)
+		handlers[rep.CellModeRoute] = logWrap(cellModeHandler.ServeHTTP, logger)
+		handlers[rep.EvacuationEligibilityRoute] = logWrap(evacuationEligibilityHandler.ServeHTTP, logger)
+		handlers[rep.RuntimeRoute] = logWrap(runtimeHandler.ServeHTTP, logger)
+		handlers[rep.TLSInfoRoute] = logWrap(tlsInfoHandler.ServeHTTP, logger)
+		handlers[rep.SetCapacityFactorRoute] = logWrap(setCapacityFactorHandler.ServeHTTP, logger)
+		handlers[rep.LastCallerRoute] = logWrap(lastCallerHandler.ServeHTTP, logger)
+		handlers[rep.SetReadOnlyModeRoute] = logWrap(setReadOnlyModeHandler.ServeHTTP, logger)
+		handlers[rep.AllocationsCSVRoute] = logWrap(allocationsCSVHandler.ServeHTTP, logger)
+		handlers[rep.ValidatePlacementRoute] = logWrap(validatePlacementHandler.ServeHTTP, logger)
+		handlers[rep.BBSClientInfoRoute] = logWrap(bbsClientInfoHandler.ServeHTTP, logger)
+		handlers[rep.ExtraRootFSRoute] = logWrap(extraRootFSHandler.ServeHTTP, logger)
 	}
 
 	return handlers
@@ -64,8 +129,8 @@ func NewLegacy(
 	requestMetrics helpers.RequestMetrics,
 	logger lager.Logger,
 ) rata.Handlers {
-	insecureHandlers := New(localCellClient, localMetricCollector, executorClient, evacuatable, requestMetrics, logger, false)
-	secureHandlers := New(localCellClient, localMetricCollector, executorClient, evacuatable, requestMetrics, logger, true)
+	insecureHandlers := New(localCellClient, localMetricCollector, executorClient, evacuatable, requestMetrics, logger, false, Options{})
+	secureHandlers := New(localCellClient, localMetricCollector, executorClient, evacuatable, requestMetrics, logger, true, Options{})
 	for name, handler := range secureHandlers {
 		insecureHandlers[name] = handler
 	}
@@ -73,15 +138,16 @@ func NewLegacy(
 }
 
 func logWrap(loggable func(http.ResponseWriter, *http.Request, lager.Logger), logger lager.Logger) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
+	return withCorrelationID(func(w http.ResponseWriter, r *http.Request) {
 		requestLog := logger.Session("request", lager.Data{
-			"method":  r.Method,
-			"request": r.URL.String(),
+			"method":         r.Method,
+			"request":        r.URL.String(),
+			"correlation-id": CorrelationIDFromContext(r.Context()),
 		})
 
 		defer requestLog.Debug("done")
 		requestLog.Debug("serving")
 
 		loggable(w, r, requestLog)
-	}
+	})
 }
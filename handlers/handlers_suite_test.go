@@ -49,14 +49,24 @@ var _ = BeforeEach(func() {
 	fakeEvacuatable = new(fake_evacuation_context.FakeEvacuatable)
 	fakeRequestMetrics = new(helpersfakes.FakeRequestMetrics)
 
-	handler, err := rata.NewRouter(rep.Routes, handlers.NewLegacy(fakeLocalRep, fakeMetricCollector, fakeExecutorClient, fakeEvacuatable, fakeRequestMetrics, logger))
-	Expect(err).NotTo(HaveOccurred())
-
-	server = httptest.NewServer(handler)
-	requestGenerator = rata.NewRequestGenerator(server.URL, rep.Routes)
+	StartServer(rep.Routes, handlers.NewLegacy(fakeLocalRep, fakeMetricCollector, fakeExecutorClient, fakeEvacuatable, fakeRequestMetrics, logger))
 	client = new(http.Client)
 })
 
+// StartServer replaces the server under test with one serving the given
+// handlers, for tests that need handlers configured differently.
+func StartServer(routes rata.Routes, h rata.Handlers) {
+	if server != nil {
+		server.Close()
+	}
+
+	handler, err := rata.NewRouter(routes, h)
+	ExpectWithOffset(1, err).NotTo(HaveOccurred())
+
+	server = httptest.NewServer(handler)
+	requestGenerator = rata.NewRequestGenerator(server.URL, routes)
+}
+
 var _ = AfterEach(func() {
 	server.Close()
 })
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/lager/v3"
+)
+
+// Caller identifies a client by the common name of the certificate it
+// presented, along with when it made the request.
+type Caller struct {
+	CommonName string    `json:"common_name"`
+	At         time.Time `json:"at"`
+}
+
+// LastCallers remembers the most recent caller of each tracked route. A nil
+// LastCallers records nothing.
+type LastCallers struct {
+	clock clock.Clock
+
+	lock    sync.Mutex
+	callers map[string]Caller
+}
+
+func NewLastCallers(clock clock.Clock) *LastCallers {
+	return &LastCallers{clock: clock, callers: map[string]Caller{}}
+}
+
+func (l *LastCallers) Record(route string, r *http.Request) {
+	if l == nil {
+		return
+	}
+
+	caller := Caller{At: l.clock.Now()}
+	if r.TLS != nil && len(r.TLS.PeerCertificates) > 0 {
+		caller.CommonName = r.TLS.PeerCertificates[0].Subject.CommonName
+	}
+
+	l.lock.Lock()
+	l.callers[route] = caller
+	l.lock.Unlock()
+}
+
+// Callers returns the last caller of each route called so far.
+func (l *LastCallers) Callers() map[string]Caller {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	callers := make(map[string]Caller, len(l.callers))
+	for route, caller := range l.callers {
+		callers[route] = caller
+	}
+	return callers
+}
+
+func recordCaller(lastCallers *LastCallers, route string, loggable func(http.ResponseWriter, *http.Request, lager.Logger)) func(http.ResponseWriter, *http.Request, lager.Logger) {
+	return func(w http.ResponseWriter, r *http.Request, logger lager.Logger) {
+		lastCallers.Record(route, r)
+		loggable(w, r, logger)
+	}
+}
+
+type lastCallerHandler struct {
+	lastCallers *LastCallers
+}
+
+func newLastCallerHandler(lastCallers *LastCallers) *lastCallerHandler {
+	return &lastCallerHandler{lastCallers: lastCallers}
+}
+
+func (h *lastCallerHandler) ServeHTTP(w http.ResponseWriter, r *http.Request, logger lager.Logger) {
+	if h.lastCallers == nil {
+		logger.Session("last-caller").Info("callers-not-tracked")
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	// #nosec G104 - ignore errors when writing HTTP responses so we don't spam our logs during a DoS
+	json.NewEncoder(w).Encode(h.lastCallers.Callers())
+}
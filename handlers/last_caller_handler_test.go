@@ -0,0 +1,104 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/rep"
+	"code.cloudfoundry.org/rep/handlers"
+	"code.cloudfoundry.org/tlsconfig"
+	"github.com/tedsuo/rata"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("LastCaller", func() {
+	Context("when callers are tracked", func() {
+		var (
+			fakeClock   *fakeclock.FakeClock
+			lastCallers *handlers.LastCallers
+			tlsServer   *httptest.Server
+			tlsClient   *http.Client
+		)
+
+		BeforeEach(func() {
+			fakeClock = fakeclock.NewFakeClock(time.Unix(1700000000, 0).UTC())
+			lastCallers = handlers.NewLastCallers(fakeClock)
+
+			certsPath := filepath.Join("..", "cmd", "rep", "fixtures", "blue-certs")
+			serverTLSConfig, err := tlsconfig.Build(
+				tlsconfig.WithInternalServiceDefaults(),
+				tlsconfig.WithIdentityFromFile(filepath.Join(certsPath, "server.crt"), filepath.Join(certsPath, "server.key")),
+			).Server(tlsconfig.WithClientAuthenticationFromFile(filepath.Join(certsPath, "server-ca.crt")))
+			Expect(err).NotTo(HaveOccurred())
+
+			clientTLSConfig, err := tlsconfig.Build(
+				tlsconfig.WithInternalServiceDefaults(),
+				tlsconfig.WithIdentityFromFile(filepath.Join(certsPath, "client.crt"), filepath.Join(certsPath, "client.key")),
+			).Client(tlsconfig.WithAuthorityFromFile(filepath.Join(certsPath, "server-ca.crt")))
+			Expect(err).NotTo(HaveOccurred())
+
+			secureHandlers := handlers.New(fakeLocalRep, fakeMetricCollector, fakeExecutorClient, fakeEvacuatable, fakeRequestMetrics, logger, true, handlers.Options{LastCallers: lastCallers})
+			router, err := rata.NewRouter(rep.RoutesNetworkAccessible, secureHandlers)
+			Expect(err).NotTo(HaveOccurred())
+
+			tlsServer = httptest.NewUnstartedServer(router)
+			tlsServer.TLS = serverTLSConfig
+			tlsServer.StartTLS()
+			tlsClient = &http.Client{Transport: &http.Transport{TLSClientConfig: clientTLSConfig}}
+
+			StartServer(rep.RoutesLocalhostOnly, handlers.New(fakeLocalRep, fakeMetricCollector, fakeExecutorClient, fakeEvacuatable, fakeRequestMetrics, logger, false, handlers.Options{LastCallers: lastCallers}))
+		})
+
+		AfterEach(func() {
+			tlsServer.Close()
+		})
+
+		callers := func() map[string]handlers.Caller {
+			status, body := Request(rep.LastCallerRoute, nil, nil)
+			Expect(status).To(Equal(http.StatusOK))
+
+			var callers map[string]handlers.Caller
+			Expect(json.Unmarshal(body, &callers)).To(Succeed())
+			return callers
+		}
+
+		It("reports nothing before any call", func() {
+			Expect(callers()).To(BeEmpty())
+		})
+
+		It("records the common name of the last client to call each route", func() {
+			response, err := tlsClient.Get(tlsServer.URL + "/state")
+			Expect(err).NotTo(HaveOccurred())
+			response.Body.Close()
+
+			fakeClock.Increment(time.Minute)
+			response, err = tlsClient.Post(tlsServer.URL+"/work", "application/json", JSONReaderFor(rep.Work{}))
+			Expect(err).NotTo(HaveOccurred())
+			response.Body.Close()
+
+			reported := callers()
+			Expect(reported).To(HaveLen(2))
+			Expect(reported[rep.StateRoute].CommonName).To(Equal("client"))
+			Expect(reported[rep.StateRoute].At).To(BeTemporally("==", time.Unix(1700000000, 0)))
+			Expect(reported[rep.PerformRoute].CommonName).To(Equal("client"))
+			Expect(reported[rep.PerformRoute].At).To(BeTemporally("==", time.Unix(1700000060, 0)))
+		})
+	})
+
+	Context("when callers are not tracked", func() {
+		BeforeEach(func() {
+			StartServer(rep.RoutesLocalhostOnly, handlers.New(fakeLocalRep, fakeMetricCollector, fakeExecutorClient, fakeEvacuatable, fakeRequestMetrics, logger, false, handlers.Options{}))
+		})
+
+		It("responds with 404", func() {
+			status, _ := Request(rep.LastCallerRoute, nil, nil)
+			Expect(status).To(Equal(http.StatusNotFound))
+		})
+	})
+})
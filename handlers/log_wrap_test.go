@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"net/http"
 
+	"code.cloudfoundry.org/bbs/trace"
 	"code.cloudfoundry.org/rep"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -18,4 +19,16 @@ var _ = Describe("LogWrap", func() {
 		Expect(logger.Buffer()).To(gbytes.Say("serving"))
 		Expect(logger.Buffer()).To(gbytes.Say("done"))
 	})
+
+	It("logs the correlation ID of the request", func() {
+		request, err := requestGenerator.CreateRequest(rep.PingRoute, nil, bytes.NewBufferString(""))
+		Expect(err).NotTo(HaveOccurred())
+		request.Header.Set(trace.RequestIdHeader, "some-correlation-id")
+
+		response, err := client.Do(request)
+		Expect(err).NotTo(HaveOccurred())
+		response.Body.Close()
+
+		Expect(logger.Buffer()).To(gbytes.Say(`"correlation-id":"some-correlation-id"`))
+	})
 })
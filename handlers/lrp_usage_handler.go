@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"code.cloudfoundry.org/lager/v3"
+	"code.cloudfoundry.org/locket/metrics/helpers"
+	"code.cloudfoundry.org/rep"
+)
+
+type lrpUsageHandler struct {
+	rep     MetricCollector
+	metrics helpers.RequestMetrics
+}
+
+func newLRPUsageHandler(rep MetricCollector, metrics helpers.RequestMetrics) *lrpUsageHandler {
+	return &lrpUsageHandler{rep: rep, metrics: metrics}
+}
+
+func (h *lrpUsageHandler) ServeHTTP(w http.ResponseWriter, r *http.Request, logger lager.Logger) {
+	var deferErr error
+
+	start := time.Now()
+	requestType := "LRPUsage"
+	startMetrics(h.metrics, requestType)
+	defer stopMetrics(h.metrics, requestType, start, &deferErr)
+
+	processGuid := r.FormValue(":process_guid")
+	logger = logger.Session("lrp-usage-handler", lager.Data{"process-guid": processGuid}).WithTraceInfo(r)
+
+	if processGuid == "" {
+		deferErr = errors.New("process_guid missing from request")
+		logger.Error("missing-process-guid", deferErr)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	var metricsCollection *rep.ContainerMetricsCollection
+	metricsCollection, deferErr = h.rep.Metrics(logger)
+	if deferErr != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		logger.Error("failed-to-fetch-container-metrics", deferErr)
+		return
+	}
+
+	// #nosec G104 - ignore errors when writing HTTP responses so we don't spam our logs during a DoS
+	json.NewEncoder(w).Encode(lrpUsage(processGuid, metricsCollection.LRPs))
+}
+
+func lrpUsage(processGuid string, lrpMetrics []rep.LRPMetric) rep.LRPUsage {
+	usage := rep.LRPUsage{ProcessGUID: processGuid}
+	for _, metric := range lrpMetrics {
+		if metric.ProcessGUID != processGuid {
+			continue
+		}
+		usage.Instances++
+		usage.Reservation.MemoryMB += metric.Reservation.MemoryMB
+		usage.Reservation.DiskMB += metric.Reservation.DiskMB
+		usage.Reservation.MaxPids += metric.Reservation.MaxPids
+		usage.CPUUsageFraction += metric.CPUUsageFraction
+		usage.MemoryUsageBytes += metric.MemoryUsageBytes
+		usage.MemoryQuotaBytes += metric.MemoryQuotaBytes
+		usage.DiskUsageBytes += metric.DiskUsageBytes
+		usage.DiskQuotaBytes += metric.DiskQuotaBytes
+	}
+	return usage
+}
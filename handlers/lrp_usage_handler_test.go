@@ -0,0 +1,110 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"code.cloudfoundry.org/executor/containermetrics"
+	"code.cloudfoundry.org/rep"
+	"github.com/tedsuo/rata"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("LRPUsage", func() {
+	Context("when fetching the container metrics succeeds", func() {
+		BeforeEach(func() {
+			fakeMetricCollector.MetricsReturns(&rep.ContainerMetricsCollection{
+				CellID: "some-cell-id",
+				LRPs: []rep.LRPMetric{
+					{
+						ProcessGUID:  "some-process-guid",
+						InstanceGUID: "instance-1",
+						Index:        0,
+						Reservation:  rep.ContainerReservation{MemoryMB: 256, DiskMB: 1024, MaxPids: 100},
+						CachedContainerMetrics: containermetrics.CachedContainerMetrics{
+							CPUUsageFraction: 0.25,
+							MemoryUsageBytes: 100,
+							MemoryQuotaBytes: 1000,
+							DiskUsageBytes:   200,
+							DiskQuotaBytes:   2000,
+						},
+					},
+					{
+						ProcessGUID:  "some-process-guid",
+						InstanceGUID: "instance-2",
+						Index:        1,
+						Reservation:  rep.ContainerReservation{MemoryMB: 256, DiskMB: 1024, MaxPids: 100},
+						CachedContainerMetrics: containermetrics.CachedContainerMetrics{
+							CPUUsageFraction: 0.5,
+							MemoryUsageBytes: 300,
+							MemoryQuotaBytes: 1000,
+							DiskUsageBytes:   400,
+							DiskQuotaBytes:   2000,
+						},
+					},
+					{
+						ProcessGUID:  "other-process-guid",
+						InstanceGUID: "instance-3",
+						Reservation:  rep.ContainerReservation{MemoryMB: 512, DiskMB: 512, MaxPids: 10},
+						CachedContainerMetrics: containermetrics.CachedContainerMetrics{
+							MemoryUsageBytes: 5000,
+							DiskUsageBytes:   5000,
+						},
+					},
+				},
+				Tasks: []rep.TaskMetric{
+					{TaskGUID: "some-process-guid"},
+				},
+			}, nil)
+		})
+
+		It("sums the usage across the instances of the LRP", func() {
+			status, body := Request(rep.LRPUsageRoute, rata.Params{"process_guid": "some-process-guid"}, nil)
+			Expect(status).To(Equal(http.StatusOK))
+
+			var usage rep.LRPUsage
+			Expect(json.Unmarshal(body, &usage)).To(Succeed())
+			Expect(usage).To(Equal(rep.LRPUsage{
+				ProcessGUID:      "some-process-guid",
+				Instances:        2,
+				Reservation:      rep.ContainerReservation{MemoryMB: 512, DiskMB: 2048, MaxPids: 200},
+				CPUUsageFraction: 0.75,
+				MemoryUsageBytes: 400,
+				MemoryQuotaBytes: 2000,
+				DiskUsageBytes:   600,
+				DiskQuotaBytes:   4000,
+			}))
+		})
+
+		It("reports no usage for an LRP without instances on the cell", func() {
+			status, body := Request(rep.LRPUsageRoute, rata.Params{"process_guid": "unknown-process-guid"}, nil)
+			Expect(status).To(Equal(http.StatusOK))
+
+			var usage rep.LRPUsage
+			Expect(json.Unmarshal(body, &usage)).To(Succeed())
+			Expect(usage).To(Equal(rep.LRPUsage{ProcessGUID: "unknown-process-guid"}))
+		})
+
+		It("emits the request metrics", func() {
+			Request(rep.LRPUsageRoute, rata.Params{"process_guid": "some-process-guid"}, nil)
+
+			Expect(fakeRequestMetrics.IncrementRequestsStartedCounterCallCount()).To(Equal(1))
+			calledRequestType, _ := fakeRequestMetrics.IncrementRequestsStartedCounterArgsForCall(0)
+			Expect(calledRequestType).To(Equal("LRPUsage"))
+		})
+	})
+
+	Context("when fetching the container metrics fails", func() {
+		BeforeEach(func() {
+			fakeMetricCollector.MetricsReturns(nil, errors.New("boom"))
+		})
+
+		It("responds with 500", func() {
+			status, _ := Request(rep.LRPUsageRoute, rata.Params{"process_guid": "some-process-guid"}, nil)
+			Expect(status).To(Equal(http.StatusInternalServerError))
+		})
+	})
+})
@@ -1,24 +1,44 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"time"
 
-	"code.cloudfoundry.org/bbs/trace"
 	"code.cloudfoundry.org/lager/v3"
 	"code.cloudfoundry.org/locket/metrics/helpers"
 	"code.cloudfoundry.org/rep"
 	"code.cloudfoundry.org/rep/auctioncellrep"
 )
 
+var ErrTooManyPlacementTags = errors.New("work contains too many placement tags")
+
+// DecisionNotifier is told which work the cell accepted and rejected on
+// every successful Perform. It must not block.
+type DecisionNotifier interface {
+	NotifyPerform(logger lager.Logger, traceID string, work, failedWork rep.Work)
+}
+
+// DecisionNotifiers notifies each of its members in turn.
+type DecisionNotifiers []DecisionNotifier
+
+func (n DecisionNotifiers) NotifyPerform(logger lager.Logger, traceID string, work, failedWork rep.Work) {
+	for _, notifier := range n {
+		notifier.NotifyPerform(logger, traceID, work, failedWork)
+	}
+}
+
 type perform struct {
-	rep     auctioncellrep.AuctionCellClient
-	metrics helpers.RequestMetrics
+	rep                        auctioncellrep.AuctionCellClient
+	metrics                    helpers.RequestMetrics
+	maxPlacementTagsPerRequest int
+	decisionNotifier           DecisionNotifier
 }
 
-func newPerformHandler(rep auctioncellrep.AuctionCellClient, metrics helpers.RequestMetrics) *perform {
-	return &perform{rep: rep, metrics: metrics}
+func newPerformHandler(rep auctioncellrep.AuctionCellClient, metrics helpers.RequestMetrics, maxPlacementTagsPerRequest int, decisionNotifier DecisionNotifier) *perform {
+	return &perform{rep: rep, metrics: metrics, maxPlacementTagsPerRequest: maxPlacementTagsPerRequest, decisionNotifier: decisionNotifier}
 }
 
 func (h *perform) ServeHTTP(w http.ResponseWriter, r *http.Request, logger lager.Logger) {
@@ -38,14 +58,55 @@ func (h *perform) ServeHTTP(w http.ResponseWriter, r *http.Request, logger lager
 		return
 	}
 
+	if h.maxPlacementTagsPerRequest > 0 {
+		placementTags := countPlacementTags(work)
+		if placementTags > h.maxPlacementTagsPerRequest {
+			deferErr = ErrTooManyPlacementTags
+			w.WriteHeader(http.StatusBadRequest)
+			logger.Error("too-many-placement-tags", deferErr, lager.Data{
+				"placement-tags":     placementTags,
+				"max-placement-tags": h.maxPlacementTagsPerRequest,
+			})
+			return
+		}
+	}
+
+	ctx := r.Context()
+	if deadline := r.Header.Get(rep.PerformDeadlineHeader); deadline != "" {
+		timeout, err := time.ParseDuration(deadline)
+		if err != nil {
+			logger.Info("ignoring-invalid-deadline", lager.Data{"deadline": deadline, "error": err.Error()})
+		} else {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+	}
+
+	traceID := CorrelationIDFromContext(r.Context())
 	var failedWork rep.Work
-	failedWork, deferErr = h.rep.Perform(logger, trace.RequestIdFromRequest(r), work)
+	failedWork, deferErr = h.rep.Perform(ctx, logger, traceID, work)
 	if deferErr != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		logger.Error("failed-to-perform-work", deferErr)
 		return
 	}
 
+	if h.decisionNotifier != nil {
+		h.decisionNotifier.NotifyPerform(logger, traceID, work, failedWork)
+	}
+
 	// #nosec G104 - ignore errors when writing HTTP responses so we don't spam our logs during a DoS
 	json.NewEncoder(w).Encode(failedWork)
 }
+
+func countPlacementTags(work rep.Work) int {
+	count := 0
+	for _, lrp := range work.LRPs {
+		count += len(lrp.PlacementTags)
+	}
+	for _, task := range work.Tasks {
+		count += len(task.PlacementTags)
+	}
+	return count
+}
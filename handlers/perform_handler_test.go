@@ -2,14 +2,17 @@ package handlers_test
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
 	"strings"
 	"time"
 
+	"code.cloudfoundry.org/bbs/models"
 	"code.cloudfoundry.org/lager/v3"
 	"code.cloudfoundry.org/rep"
+	"code.cloudfoundry.org/rep/handlers"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -54,7 +57,7 @@ var _ = Describe("Perform", func() {
 
 		Context("and no perform error", func() {
 			BeforeEach(func() {
-				fakeLocalRep.PerformStub = func(logger lager.Logger, traceID string, work rep.Work) (rep.Work, error) {
+				fakeLocalRep.PerformStub = func(ctx context.Context, logger lager.Logger, traceID string, work rep.Work) (rep.Work, error) {
 					time.Sleep(requestLatency)
 					return failedWork, nil
 				}
@@ -66,7 +69,7 @@ var _ = Describe("Perform", func() {
 				Expect(body).To(MatchJSON(JSONFor(failedWork)))
 
 				Expect(fakeLocalRep.PerformCallCount()).To(Equal(1))
-				_, traceID, actualWork := fakeLocalRep.PerformArgsForCall(0)
+				_, _, traceID, actualWork := fakeLocalRep.PerformArgsForCall(0)
 				Expect(traceID).To(Equal(requestIdHeader))
 				Expect(actualWork).To(Equal(requestedWork))
 
@@ -104,6 +107,71 @@ var _ = Describe("Perform", func() {
 			})
 		})
 
+		Context("and a deadline header", func() {
+			var (
+				deadline           string
+				performDeadline    time.Time
+				performHasDeadline bool
+			)
+
+			BeforeEach(func() {
+				performHasDeadline = false
+				fakeLocalRep.PerformStub = func(ctx context.Context, logger lager.Logger, traceID string, work rep.Work) (rep.Work, error) {
+					performDeadline, performHasDeadline = ctx.Deadline()
+					return failedWork, nil
+				}
+			})
+
+			performWithDeadline := func() int {
+				request, err := requestGenerator.CreateRequest(rep.PerformRoute, nil, JSONReaderFor(requestedWork))
+				Expect(err).NotTo(HaveOccurred())
+				request.Header.Set(rep.PerformDeadlineHeader, deadline)
+
+				response, err := client.Do(request)
+				Expect(err).NotTo(HaveOccurred())
+				response.Body.Close()
+				return response.StatusCode
+			}
+
+			Context("when the deadline is loose", func() {
+				BeforeEach(func() {
+					deadline = "1m"
+				})
+
+				It("performs the work with a context expiring at the deadline", func() {
+					sent := time.Now()
+					Expect(performWithDeadline()).To(Equal(http.StatusOK))
+					Expect(performHasDeadline).To(BeTrue())
+					Expect(performDeadline).To(BeTemporally("~", sent.Add(time.Minute), 5*time.Second))
+				})
+			})
+
+			Context("when the deadline is tight", func() {
+				BeforeEach(func() {
+					deadline = "1ms"
+				})
+
+				It("performs the work with a context expiring at the deadline", func() {
+					sent := time.Now()
+					Expect(performWithDeadline()).To(Equal(http.StatusOK))
+					Expect(performHasDeadline).To(BeTrue())
+					Expect(performDeadline).To(BeTemporally("~", sent, 5*time.Second))
+				})
+			})
+
+			Context("when the deadline is not a duration", func() {
+				BeforeEach(func() {
+					deadline = "soon"
+				})
+
+				It("ignores it", func() {
+					Expect(performWithDeadline()).To(Equal(http.StatusOK))
+					Expect(performHasDeadline).To(BeFalse())
+					Expect(logger).To(gbytes.Say("ignoring-invalid-deadline"))
+				})
+			})
+		})
+
 		Context("and a perform error", func() {
 			BeforeEach(func() {
 				fakeLocalRep.PerformReturns(failedWork, errors.New("kaboom"))
@@ -117,7 +185,7 @@ var _ = Describe("Perform", func() {
 				Expect(body).To(BeEmpty())
 
 				Expect(fakeLocalRep.PerformCallCount()).To(Equal(1))
-				_, traceID, actualWork := fakeLocalRep.PerformArgsForCall(0)
+				_, _, traceID, actualWork := fakeLocalRep.PerformArgsForCall(0)
 				Expect(traceID).To(Equal(requestIdHeader))
 				Expect(actualWork).To(Equal(requestedWork))
 
@@ -172,4 +240,116 @@ var _ = Describe("Perform", func() {
 			Expect(calledRequestType).To(Equal("Perform"))
 		})
 	})
+
+	Context("when a limit on placement tags per request is configured", func() {
+		var work rep.Work
+
+		BeforeEach(func() {
+			StartServer(rep.RoutesNetworkAccessible, handlers.New(fakeLocalRep, fakeMetricCollector, fakeExecutorClient, fakeEvacuatable, fakeRequestMetrics, logger, true, handlers.Options{MaxPlacementTagsPerRequest: 3}))
+
+			resource := rep.NewResource(128, 256, 256)
+			work = rep.Work{
+				LRPs: []rep.LRP{
+					rep.NewLRP("ig-1", models.NewActualLRPKey("pg-1", 0, "domain"), resource, rep.NewPlacementConstraint("some-rootfs", []string{"a", "b"}, nil)),
+				},
+				Tasks: []rep.Task{
+					rep.NewTask("tg-1", "domain", resource, rep.NewPlacementConstraint("some-rootfs", []string{"c"}, nil)),
+				},
+			}
+		})
+
+		Context("and the request is within the limit", func() {
+			It("performs the work", func() {
+				status, _ := Request(rep.PerformRoute, nil, JSONReaderFor(work))
+				Expect(status).To(Equal(http.StatusOK))
+				Expect(fakeLocalRep.PerformCallCount()).To(Equal(1))
+			})
+		})
+
+		Context("and the request exceeds the limit", func() {
+			BeforeEach(func() {
+				work.Tasks[0].PlacementTags = append(work.Tasks[0].PlacementTags, "d")
+			})
+
+			It("rejects the request with a 400", func() {
+				status, body := Request(rep.PerformRoute, nil, JSONReaderFor(work))
+				Expect(status).To(Equal(http.StatusBadRequest))
+				Expect(body).To(BeEmpty())
+				Expect(fakeLocalRep.PerformCallCount()).To(Equal(0))
+				Eventually(logger).Should(gbytes.Say("too-many-placement-tags"))
+			})
+
+			It("emits the failed request metric", func() {
+				Request(rep.PerformRoute, nil, JSONReaderFor(work))
+
+				Expect(fakeRequestMetrics.IncrementRequestsFailedCounterCallCount()).To(Equal(1))
+				calledRequestType, _ := fakeRequestMetrics.IncrementRequestsFailedCounterArgsForCall(0)
+				Expect(calledRequestType).To(Equal("Perform"))
+			})
+		})
+	})
+
+	Context("when a decision notifier is configured", func() {
+		var (
+			notifier         *recordingDecisionNotifier
+			work, failedWork rep.Work
+			performErr       error
+		)
+
+		BeforeEach(func() {
+			notifier = &recordingDecisionNotifier{}
+			StartServer(rep.RoutesNetworkAccessible, handlers.New(fakeLocalRep, fakeMetricCollector, fakeExecutorClient, fakeEvacuatable, fakeRequestMetrics, logger, true, handlers.Options{DecisionNotifier: notifier}))
+
+			resource := rep.NewResource(128, 256, 256)
+			work = rep.Work{
+				LRPs: []rep.LRP{
+					rep.NewLRP("ig-1", models.NewActualLRPKey("pg-1", 0, "domain"), resource, rep.NewPlacementConstraint("some-rootfs", nil, nil)),
+				},
+				Tasks: []rep.Task{
+					rep.NewTask("tg-1", "domain", resource, rep.NewPlacementConstraint("some-rootfs", nil, nil)),
+				},
+			}
+			failedWork = rep.Work{Tasks: work.Tasks}
+			performErr = nil
+		})
+
+		JustBeforeEach(func() {
+			fakeLocalRep.PerformReturns(failedWork, performErr)
+		})
+
+		It("notifies it of the work and the failed work", func() {
+			status, _ := RequestTracing(rep.PerformRoute, nil, JSONReaderFor(work), "eb89bcf8-3901-ff0f-a4b3-151312f5154b")
+			Expect(status).To(Equal(http.StatusOK))
+
+			Expect(notifier.calls).To(HaveLen(1))
+			Expect(notifier.calls[0].traceID).To(Equal("eb89bcf8-3901-ff0f-a4b3-151312f5154b"))
+			Expect(notifier.calls[0].work).To(Equal(work))
+			Expect(notifier.calls[0].failedWork).To(Equal(failedWork))
+		})
+
+		Context("when performing the work fails", func() {
+			BeforeEach(func() {
+				performErr = errors.New("boom")
+			})
+
+			It("does not notify it", func() {
+				status, _ := Request(rep.PerformRoute, nil, JSONReaderFor(work))
+				Expect(status).To(Equal(http.StatusInternalServerError))
+				Expect(notifier.calls).To(BeEmpty())
+			})
+		})
+	})
 })
+
+type decisionNotification struct {
+	traceID          string
+	work, failedWork rep.Work
+}
+
+type recordingDecisionNotifier struct {
+	calls []decisionNotification
+}
+
+func (n *recordingDecisionNotifier) NotifyPerform(logger lager.Logger, traceID string, work, failedWork rep.Work) {
+	n.calls = append(n.calls, decisionNotification{traceID: traceID, work: work, failedWork: failedWork})
+}
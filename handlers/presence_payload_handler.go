@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/lager/v3"
+)
+
+type PresenceRegistrar interface {
+	Presence() (models.CellPresence, bool)
+	Reregister(logger lager.Logger) (models.CellPresence, error)
+	Renew(logger lager.Logger) error
+}
+
+type presencePayloadHandler struct {
+	registrar PresenceRegistrar
+}
+
+func newPresencePayloadHandler(registrar PresenceRegistrar) *presencePayloadHandler {
+	return &presencePayloadHandler{registrar: registrar}
+}
+
+func (h *presencePayloadHandler) ServeHTTP(w http.ResponseWriter, r *http.Request, logger lager.Logger) {
+	logger = logger.Session("presence-payload")
+
+	if h.registrar == nil {
+		logger.Info("no-presence-published")
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	cellPresence, published := h.registrar.Presence()
+	if !published {
+		logger.Info("no-presence-published")
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	// #nosec G104 - ignore errors when writing HTTP responses so we don't spam our logs during a DoS
+	json.NewEncoder(w).Encode(cellPresence)
+}
+
+type reregisterPresenceHandler struct {
+	registrar PresenceRegistrar
+}
+
+func newReregisterPresenceHandler(registrar PresenceRegistrar) *reregisterPresenceHandler {
+	return &reregisterPresenceHandler{registrar: registrar}
+}
+
+func (h *reregisterPresenceHandler) ServeHTTP(w http.ResponseWriter, r *http.Request, logger lager.Logger) {
+	logger = logger.Session("reregister-presence")
+
+	if h.registrar == nil {
+		logger.Info("no-presence-published")
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	cellPresence, err := h.registrar.Reregister(logger)
+	if err != nil {
+		logger.Error("failed-to-reregister-presence", err)
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	// #nosec G104 - ignore errors when writing HTTP responses so we don't spam our logs during a DoS
+	json.NewEncoder(w).Encode(cellPresence)
+}
+
+type renewPresenceHandler struct {
+	registrar PresenceRegistrar
+}
+
+func newRenewPresenceHandler(registrar PresenceRegistrar) *renewPresenceHandler {
+	return &renewPresenceHandler{registrar: registrar}
+}
+
+func (h *renewPresenceHandler) ServeHTTP(w http.ResponseWriter, r *http.Request, logger lager.Logger) {
+	logger = logger.Session("renew-presence")
+
+	if h.registrar == nil {
+		logger.Info("no-presence-published")
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	err := h.registrar.Renew(logger)
+	if err != nil {
+		logger.Error("failed-to-renew-presence", err)
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,180 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+
+	"code.cloudfoundry.org/bbs/models"
+	locketmodels "code.cloudfoundry.org/locket/models"
+	"code.cloudfoundry.org/locket/models/modelsfakes"
+	"code.cloudfoundry.org/rep"
+	"code.cloudfoundry.org/rep/handlers"
+	"code.cloudfoundry.org/rep/presence"
+	"github.com/tedsuo/ifrit"
+	ginkgomon "github.com/tedsuo/ifrit/ginkgomon_v2"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PresencePayload", func() {
+	Context("when the cell published its presence", func() {
+		var (
+			builds    int
+			process   ifrit.Process
+			registrar *presence.Registrar
+		)
+
+		cellPresenceFor := func(build int) models.CellPresence {
+			return models.NewCellPresence(
+				"cell-id",
+				"https://cell-id.cell.service.cf.internal:1801",
+				"https://cell-id.cell.service.cf.internal:1801",
+				"the-zone",
+				models.NewCellCapacity(int32(1024*build), 2048, 10),
+				[]string{"docker"},
+				[]string{"cflinuxfs4:/path/to/cflinuxfs4?somehash"},
+				nil,
+				[]string{"tag"},
+				nil,
+				map[string]string{"rack": "r1"},
+			)
+		}
+
+		BeforeEach(func() {
+			builds = 0
+			registrar = presence.NewRegistrar(logger, func() (ifrit.Runner, models.CellPresence, error) {
+				builds++
+				runner := ifrit.RunFunc(func(signals <-chan os.Signal, ready chan<- struct{}) error {
+					close(ready)
+					<-signals
+					return nil
+				})
+				return runner, cellPresenceFor(builds), nil
+			})
+			process = ginkgomon.Invoke(registrar)
+
+			StartServer(rep.RoutesLocalhostOnly, handlers.New(fakeLocalRep, fakeMetricCollector, fakeExecutorClient, fakeEvacuatable, fakeRequestMetrics, logger, false, handlers.Options{PresenceRegistrar: registrar}))
+		})
+
+		AfterEach(func() {
+			ginkgomon.Interrupt(process)
+		})
+
+		It("returns the published presence", func() {
+			status, body := Request(rep.PresencePayloadRoute, nil, nil)
+			Expect(status).To(Equal(http.StatusOK))
+
+			expected, err := json.Marshal(cellPresenceFor(1))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(body).To(MatchJSON(expected))
+		})
+
+		Context("and it is reregistered", func() {
+			It("publishes a rebuilt presence", func() {
+				status, body := Request(rep.ReregisterPresenceRoute, nil, nil)
+				Expect(status).To(Equal(http.StatusOK))
+
+				expected, err := json.Marshal(cellPresenceFor(2))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(body).To(MatchJSON(expected))
+
+				status, body = Request(rep.PresencePayloadRoute, nil, nil)
+				Expect(status).To(Equal(http.StatusOK))
+				Expect(body).To(MatchJSON(expected))
+			})
+		})
+	})
+
+	Context("when no presence was published", func() {
+		It("responds with 404", func() {
+			status, _ := Request(rep.PresencePayloadRoute, nil, nil)
+			Expect(status).To(Equal(http.StatusNotFound))
+		})
+
+		It("cannot reregister it", func() {
+			status, _ := Request(rep.ReregisterPresenceRoute, nil, nil)
+			Expect(status).To(Equal(http.StatusNotFound))
+		})
+	})
+})
+
+var _ = Describe("RenewPresence", func() {
+	Context("when the cell published its presence", func() {
+		var (
+			fakeLocketClient *modelsfakes.FakeLocketClient
+			resource         *locketmodels.Resource
+			renewable        bool
+			process          ifrit.Process
+		)
+
+		BeforeEach(func() {
+			fakeLocketClient = new(modelsfakes.FakeLocketClient)
+			resource = &locketmodels.Resource{Key: "cell-id", Owner: "owner", Value: "presence", TypeCode: locketmodels.PRESENCE}
+			renewable = true
+		})
+
+		JustBeforeEach(func() {
+			registrar := presence.NewRegistrar(logger, func() (ifrit.Runner, models.CellPresence, error) {
+				var runner ifrit.Runner = ifrit.RunFunc(func(signals <-chan os.Signal, ready chan<- struct{}) error {
+					close(ready)
+					<-signals
+					return nil
+				})
+				if renewable {
+					runner = presence.NewRenewingRunner(runner, presence.NewHandoffLocketClient(fakeLocketClient), resource, 15)
+				}
+				return runner, models.CellPresence{CellId: "cell-id"}, nil
+			})
+			process = ginkgomon.Invoke(registrar)
+
+			StartServer(rep.RoutesLocalhostOnly, handlers.New(fakeLocalRep, fakeMetricCollector, fakeExecutorClient, fakeEvacuatable, fakeRequestMetrics, logger, false, handlers.Options{PresenceRegistrar: registrar}))
+		})
+
+		AfterEach(func() {
+			ginkgomon.Interrupt(process)
+		})
+
+		It("renews the presence lock immediately", func() {
+			status, _ := Request(rep.RenewPresenceRoute, nil, nil)
+			Expect(status).To(Equal(http.StatusNoContent))
+
+			Expect(fakeLocketClient.LockCallCount()).To(Equal(1))
+			_, request, _ := fakeLocketClient.LockArgsForCall(0)
+			Expect(request.Resource).To(Equal(resource))
+			Expect(request.TtlInSeconds).To(BeEquivalentTo(15))
+		})
+
+		Context("when renewing the lock fails", func() {
+			BeforeEach(func() {
+				fakeLocketClient.LockReturns(nil, errors.New("lock is held by another owner"))
+			})
+
+			It("responds with 503", func() {
+				status, _ := Request(rep.RenewPresenceRoute, nil, nil)
+				Expect(status).To(Equal(http.StatusServiceUnavailable))
+			})
+		})
+
+		Context("when the presence runner cannot renew its lock", func() {
+			BeforeEach(func() {
+				renewable = false
+			})
+
+			It("responds with 503", func() {
+				status, _ := Request(rep.RenewPresenceRoute, nil, nil)
+				Expect(status).To(Equal(http.StatusServiceUnavailable))
+				Expect(fakeLocketClient.LockCallCount()).To(BeZero())
+			})
+		})
+	})
+
+	Context("when no presence was published", func() {
+		It("responds with 404", func() {
+			status, _ := Request(rep.RenewPresenceRoute, nil, nil)
+			Expect(status).To(Equal(http.StatusNotFound))
+		})
+	})
+})
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+
+	"code.cloudfoundry.org/lager/v3"
+)
+
+// ReadOnlyMode freezes a cell for forensics: while enabled, the routes that
+// change the cell's containers are refused with 503 and only reads are
+// served. A nil ReadOnlyMode is never enabled.
+type ReadOnlyMode struct {
+	enabled atomic.Bool
+}
+
+func NewReadOnlyMode(enabled bool) *ReadOnlyMode {
+	mode := &ReadOnlyMode{}
+	mode.enabled.Store(enabled)
+	return mode
+}
+
+func (m *ReadOnlyMode) Enabled() bool {
+	return m != nil && m.enabled.Load()
+}
+
+func (m *ReadOnlyMode) Set(enabled bool) {
+	m.enabled.Store(enabled)
+}
+
+func rejectWhenReadOnly(mode *ReadOnlyMode, loggable func(http.ResponseWriter, *http.Request, lager.Logger)) func(http.ResponseWriter, *http.Request, lager.Logger) {
+	return func(w http.ResponseWriter, r *http.Request, logger lager.Logger) {
+		if mode.Enabled() {
+			logger.Info("rejected-in-read-only-mode")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		loggable(w, r, logger)
+	}
+}
+
+type ReadOnlyModeRequest struct {
+	Enabled *bool `json:"enabled"`
+}
+
+type ReadOnlyModeResponse struct {
+	Enabled bool `json:"enabled"`
+}
+
+type setReadOnlyModeHandler struct {
+	mode *ReadOnlyMode
+}
+
+func newSetReadOnlyModeHandler(mode *ReadOnlyMode) *setReadOnlyModeHandler {
+	return &setReadOnlyModeHandler{mode: mode}
+}
+
+func (h *setReadOnlyModeHandler) ServeHTTP(w http.ResponseWriter, r *http.Request, logger lager.Logger) {
+	logger = logger.Session("set-read-only-mode")
+
+	if h.mode == nil {
+		logger.Info("read-only-mode-not-supported")
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	var request ReadOnlyModeRequest
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil || request.Enabled == nil {
+		logger.Error("failed-to-decode-request", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	h.mode.Set(*request.Enabled)
+	logger.Info("set", lager.Data{"enabled": *request.Enabled})
+
+	w.Header().Set("Content-Type", "application/json")
+	// #nosec G104 - ignore errors when writing HTTP responses so we don't spam our logs during a DoS
+	json.NewEncoder(w).Encode(ReadOnlyModeResponse{Enabled: h.mode.Enabled()})
+}
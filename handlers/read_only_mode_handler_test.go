@@ -0,0 +1,116 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"code.cloudfoundry.org/rep"
+	"code.cloudfoundry.org/rep/handlers"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/tedsuo/rata"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ReadOnlyMode", func() {
+	Describe("the network accessible routes", func() {
+		var readOnlyMode *handlers.ReadOnlyMode
+
+		BeforeEach(func() {
+			readOnlyMode = handlers.NewReadOnlyMode(true)
+			StartServer(rep.RoutesNetworkAccessible, handlers.New(fakeLocalRep, fakeMetricCollector, fakeExecutorClient, fakeEvacuatable, fakeRequestMetrics, logger, true, handlers.Options{ReadOnlyMode: readOnlyMode}))
+		})
+
+		Context("when the cell is read-only", func() {
+			It("refuses the routes that mutate the cell", func() {
+				status, _ := Request(rep.PerformRoute, nil, JSONReaderFor(rep.Work{}))
+				Expect(status).To(Equal(http.StatusServiceUnavailable))
+				Expect(fakeLocalRep.PerformCallCount()).To(BeZero())
+
+				status, _ = Request(rep.SimResetRoute, nil, nil)
+				Expect(status).To(Equal(http.StatusServiceUnavailable))
+				Expect(fakeLocalRep.ResetCallCount()).To(BeZero())
+
+				status, _ = Request(rep.StopLRPInstanceRoute, rata.Params{"process_guid": "pg", "instance_guid": "ig"}, nil)
+				Expect(status).To(Equal(http.StatusServiceUnavailable))
+				Expect(fakeExecutorClient.StopContainerCallCount()).To(BeZero())
+
+				status, _ = Request(rep.CancelTaskRoute, rata.Params{"task_guid": "tg"}, nil)
+				Expect(status).To(Equal(http.StatusServiceUnavailable))
+				Expect(fakeExecutorClient.DeleteContainerCallCount()).To(BeZero())
+
+				status, _ = Request(rep.CancelTasksByDomainRoute, rata.Params{"domain": "some-domain"}, nil)
+				Expect(status).To(Equal(http.StatusServiceUnavailable))
+
+				Expect(logger).To(gbytes.Say("rejected-in-read-only-mode"))
+			})
+
+			It("still serves reads", func() {
+				status, _ := Request(rep.StateRoute, nil, nil)
+				Expect(status).To(Equal(http.StatusOK))
+				Expect(fakeLocalRep.StateCallCount()).To(Equal(1))
+
+				status, _ = Request(rep.ContainerMetricsRoute, nil, nil)
+				Expect(status).To(Equal(http.StatusOK))
+
+				status, _ = Request(rep.TasksRoute, nil, nil)
+				Expect(status).To(Equal(http.StatusOK))
+			})
+		})
+
+		Context("when the cell is no longer read-only", func() {
+			BeforeEach(func() {
+				readOnlyMode.Set(false)
+			})
+
+			It("serves the mutating routes again", func() {
+				status, _ := Request(rep.PerformRoute, nil, JSONReaderFor(rep.Work{}))
+				Expect(status).To(Equal(http.StatusOK))
+				Expect(fakeLocalRep.PerformCallCount()).To(Equal(1))
+			})
+		})
+	})
+
+	Describe("SetReadOnlyMode", func() {
+		Context("when read-only mode is supported", func() {
+			var readOnlyMode *handlers.ReadOnlyMode
+
+			BeforeEach(func() {
+				readOnlyMode = handlers.NewReadOnlyMode(false)
+				StartServer(rep.RoutesLocalhostOnly, handlers.New(fakeLocalRep, fakeMetricCollector, fakeExecutorClient, fakeEvacuatable, fakeRequestMetrics, logger, false, handlers.Options{ReadOnlyMode: readOnlyMode}))
+			})
+
+			It("toggles read-only mode", func() {
+				status, body := Request(rep.SetReadOnlyModeRoute, nil, strings.NewReader(`{"enabled":true}`))
+				Expect(status).To(Equal(http.StatusOK))
+				var response handlers.ReadOnlyModeResponse
+				Expect(json.Unmarshal(body, &response)).To(Succeed())
+				Expect(response.Enabled).To(BeTrue())
+				Expect(readOnlyMode.Enabled()).To(BeTrue())
+
+				status, _ = Request(rep.SetReadOnlyModeRoute, nil, strings.NewReader(`{"enabled":false}`))
+				Expect(status).To(Equal(http.StatusOK))
+				Expect(readOnlyMode.Enabled()).To(BeFalse())
+			})
+
+			It("rejects a request without the flag", func() {
+				status, _ := Request(rep.SetReadOnlyModeRoute, nil, strings.NewReader(`{}`))
+				Expect(status).To(Equal(http.StatusBadRequest))
+				Expect(readOnlyMode.Enabled()).To(BeFalse())
+			})
+		})
+
+		Context("when read-only mode is not supported", func() {
+			BeforeEach(func() {
+				StartServer(rep.RoutesLocalhostOnly, handlers.New(fakeLocalRep, fakeMetricCollector, fakeExecutorClient, fakeEvacuatable, fakeRequestMetrics, logger, false, handlers.Options{}))
+			})
+
+			It("responds with 404", func() {
+				status, _ := Request(rep.SetReadOnlyModeRoute, nil, strings.NewReader(`{"enabled":true}`))
+				Expect(status).To(Equal(http.StatusNotFound))
+			})
+		})
+	})
+})
@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"code.cloudfoundry.org/lager/v3"
+	"code.cloudfoundry.org/rep/auctioncellrep"
+)
+
+type resourceAccountingHandler struct {
+	rep auctioncellrep.AuctionCellClient
+}
+
+func newResourceAccountingHandler(rep auctioncellrep.AuctionCellClient) *resourceAccountingHandler {
+	return &resourceAccountingHandler{rep: rep}
+}
+
+func (h *resourceAccountingHandler) ServeHTTP(w http.ResponseWriter, r *http.Request, logger lager.Logger) {
+	logger = logger.Session("resource-accounting")
+
+	accounting, err := h.rep.ResourceAccounting(logger)
+	if err != nil {
+		logger.Error("failed-to-fetch-resource-accounting", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	// #nosec G104 - ignore errors when writing HTTP responses so we don't spam our logs during a DoS
+	json.NewEncoder(w).Encode(accounting)
+}
@@ -0,0 +1,121 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"code.cloudfoundry.org/clock"
+	mfakes "code.cloudfoundry.org/diego-logging-client/testhelpers"
+	"code.cloudfoundry.org/executor"
+	"code.cloudfoundry.org/rep"
+	"code.cloudfoundry.org/rep/auctioncellrep"
+	"code.cloudfoundry.org/rep/auctioncellrep/auctioncellrepfakes"
+	"code.cloudfoundry.org/rep/evacuation/evacuation_context/fake_evacuation_context"
+	"code.cloudfoundry.org/rep/handlers"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ResourceAccounting", func() {
+	Context("when computing the accounting succeeds", func() {
+		BeforeEach(func() {
+			cellRep := auctioncellrep.New(
+				"cell-id",
+				0,
+				"https://cell-id.cell.service.cf.internal:1801",
+				rep.StackPathMap{"cflinuxfs4": "/path/to/cflinuxfs4"},
+				new(auctioncellrepfakes.FakeContainerMetricsProvider),
+				[]string{"docker"},
+				"the-zone",
+				fakeExecutorClient,
+				new(fake_evacuation_context.FakeEvacuationReporter),
+				nil,
+				nil,
+				32,
+				true,
+				new(auctioncellrepfakes.FakeBatchContainerAllocator),
+				clock.NewClock(),
+				new(mfakes.FakeIngressClient),
+				auctioncellrep.Options{},
+			)
+			StartServer(rep.RoutesLocalhostOnly, handlers.New(cellRep, fakeMetricCollector, fakeExecutorClient, fakeEvacuatable, fakeRequestMetrics, logger, false, handlers.Options{}))
+
+			fakeExecutorClient.ListContainersReturns([]executor.Container{
+				{
+					Guid:     "lrp-container",
+					Resource: executor.Resource{MemoryMB: 160, DiskMB: 100, RootFSPath: "/path/to/cflinuxfs4"},
+					Tags:     executor.Tags{rep.LifecycleTag: rep.LRPLifecycle},
+				},
+				{
+					Guid:     "task-container",
+					Resource: executor.Resource{MemoryMB: 64, DiskMB: 200, RootFSPath: "/path/to/cflinuxfs4"},
+					Tags:     executor.Tags{rep.LifecycleTag: rep.TaskLifecycle},
+				},
+			}, nil)
+			fakeExecutorClient.TotalResourcesReturns(executor.ExecutorResources{MemoryMB: 1024, DiskMB: 2048, Containers: 10}, nil)
+			fakeExecutorClient.RemainingResourcesReturns(executor.ExecutorResources{MemoryMB: 700, DiskMB: 1748, Containers: 8}, nil)
+		})
+
+		It("breaks the total down into reserved, allocated and remaining resources", func() {
+			status, body := Request(rep.ResourceAccountingRoute, nil, nil)
+			Expect(status).To(Equal(http.StatusOK))
+
+			var accounting rep.ResourceAccounting
+			Expect(json.Unmarshal(body, &accounting)).To(Succeed())
+
+			Expect(accounting.Total).To(Equal(rep.Resources{MemoryMB: 1024, DiskMB: 2048, Containers: 10}))
+			Expect(accounting.ProxyReserved).To(Equal(rep.Resources{MemoryMB: 32}))
+			Expect(accounting.Allocated).To(Equal(rep.Resources{MemoryMB: 192, DiskMB: 300, Containers: 2}))
+			Expect(accounting.Remaining).To(Equal(rep.Resources{MemoryMB: 700, DiskMB: 1748, Containers: 8}))
+			Expect(accounting.SystemReserved).To(Equal(rep.Resources{MemoryMB: 100}))
+			Expect(accounting.Segments).To(BeEmpty())
+		})
+
+		It("keeps the arithmetic consistent", func() {
+			_, body := Request(rep.ResourceAccountingRoute, nil, nil)
+
+			var accounting rep.ResourceAccounting
+			Expect(json.Unmarshal(body, &accounting)).To(Succeed())
+
+			parts := []rep.Resources{accounting.SystemReserved, accounting.ProxyReserved, accounting.Allocated, accounting.Remaining}
+			var sum rep.Resources
+			for _, r := range parts {
+				sum.MemoryMB += r.MemoryMB
+				sum.DiskMB += r.DiskMB
+				sum.Containers += r.Containers
+			}
+			Expect(sum).To(Equal(accounting.Total))
+		})
+
+		Context("when the executor reports negative remaining resources", func() {
+			BeforeEach(func() {
+				fakeExecutorClient.TotalResourcesReturns(executor.ExecutorResources{MemoryMB: 200, DiskMB: 2048, Containers: 10}, nil)
+				fakeExecutorClient.RemainingResourcesReturns(executor.ExecutorResources{MemoryMB: -24, DiskMB: 1748, Containers: 8}, nil)
+			})
+
+			It("reports the drift instead of folding it into the system reservation", func() {
+				_, body := Request(rep.ResourceAccountingRoute, nil, nil)
+
+				var accounting rep.ResourceAccounting
+				Expect(json.Unmarshal(body, &accounting)).To(Succeed())
+
+				Expect(accounting.Remaining).To(Equal(rep.Resources{DiskMB: 1748, Containers: 8}))
+				Expect(accounting.Drift).To(Equal(rep.Resources{MemoryMB: 24}))
+				Expect(accounting.SystemReserved).To(Equal(rep.Resources{}))
+			})
+		})
+	})
+
+	Context("when computing the accounting fails", func() {
+		BeforeEach(func() {
+			fakeLocalRep.ResourceAccountingReturns(rep.ResourceAccounting{}, errors.New("boom"))
+		})
+
+		It("responds with 500", func() {
+			status, _ := Request(rep.ResourceAccountingRoute, nil, nil)
+			Expect(status).To(Equal(http.StatusInternalServerError))
+		})
+	})
+})
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"runtime"
+	"time"
+
+	"code.cloudfoundry.org/lager/v3"
+	"code.cloudfoundry.org/rep/fdusage"
+)
+
+// RuntimeStats is a snapshot of the rep process's goroutines, memory,
+// garbage collection and file descriptors. The file descriptor counts are
+// left out on platforms that cannot report them.
+type RuntimeStats struct {
+	NumGoroutine   int           `json:"num_goroutine"`
+	HeapAllocBytes uint64        `json:"heap_alloc_bytes"`
+	HeapInuseBytes uint64        `json:"heap_inuse_bytes"`
+	HeapObjects    uint64        `json:"heap_objects"`
+	SysBytes       uint64        `json:"sys_bytes"`
+	NumGC          uint32        `json:"num_gc"`
+	LastGC         *time.Time    `json:"last_gc,omitempty"`
+	LastGCPause    time.Duration `json:"last_gc_pause_ns"`
+	TotalGCPause   time.Duration `json:"total_gc_pause_ns"`
+	GCCPUFraction  float64       `json:"gc_cpu_fraction"`
+	OpenFDs        int           `json:"open_fds,omitempty"`
+	FDLimit        uint64        `json:"fd_limit,omitempty"`
+}
+
+type runtimeHandler struct{}
+
+func newRuntimeHandler() *runtimeHandler {
+	return &runtimeHandler{}
+}
+
+func (h *runtimeHandler) ServeHTTP(w http.ResponseWriter, r *http.Request, logger lager.Logger) {
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+
+	stats := RuntimeStats{
+		NumGoroutine:   runtime.NumGoroutine(),
+		HeapAllocBytes: memStats.HeapAlloc,
+		HeapInuseBytes: memStats.HeapInuse,
+		HeapObjects:    memStats.HeapObjects,
+		SysBytes:       memStats.Sys,
+		NumGC:          memStats.NumGC,
+		TotalGCPause:   time.Duration(memStats.PauseTotalNs),
+		GCCPUFraction:  memStats.GCCPUFraction,
+	}
+	if memStats.NumGC > 0 {
+		lastGC := time.Unix(0, int64(memStats.LastGC))
+		stats.LastGC = &lastGC
+		stats.LastGCPause = time.Duration(memStats.PauseNs[(memStats.NumGC+255)%256])
+	}
+
+	usage, err := fdusage.Read()
+	if err != nil {
+		logger.Session("runtime").Debug("failed-to-read-fd-usage", lager.Data{"error": err.Error()})
+	} else {
+		stats.OpenFDs = usage.Open
+		stats.FDLimit = usage.Limit
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	// #nosec G104 - ignore errors when writing HTTP responses so we don't spam our logs during a DoS
+	json.NewEncoder(w).Encode(stats)
+}
@@ -0,0 +1,33 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"runtime"
+
+	"code.cloudfoundry.org/rep"
+	"code.cloudfoundry.org/rep/handlers"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Runtime", func() {
+	It("reports the goroutine count and memory stats", func() {
+		runtime.GC()
+
+		status, body := Request(rep.RuntimeRoute, nil, nil)
+		Expect(status).To(Equal(http.StatusOK))
+
+		var stats handlers.RuntimeStats
+		Expect(json.Unmarshal(body, &stats)).To(Succeed())
+		Expect(stats.NumGoroutine).To(BeNumerically(">", 0))
+		Expect(stats.HeapAllocBytes).To(BeNumerically(">", 0))
+		Expect(stats.SysBytes).To(BeNumerically(">=", stats.HeapInuseBytes))
+		Expect(stats.NumGC).To(BeNumerically(">", 0))
+		Expect(stats.LastGC).NotTo(BeNil())
+		Expect(stats.TotalGCPause).To(BeNumerically(">=", stats.LastGCPause))
+		Expect(stats.OpenFDs).To(BeNumerically(">", 0))
+		Expect(stats.FDLimit).To(BeNumerically(">=", uint64(stats.OpenFDs)))
+	})
+})
@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"code.cloudfoundry.org/lager/v3"
+	"github.com/tedsuo/rata"
+)
+
+// WithSlowRequestLogging wraps every handler so that requests taking longer
+// than threshold are logged. A zero threshold leaves the handlers untouched.
+// lager has no warn level, so slow requests are logged at info.
+func WithSlowRequestLogging(handlers rata.Handlers, logger lager.Logger, threshold time.Duration) rata.Handlers {
+	if threshold <= 0 {
+		return handlers
+	}
+
+	logger = logger.Session("slow-request-logger", lager.Data{"threshold": threshold.String()})
+	wrapped := rata.Handlers{}
+	for route, handler := range handlers {
+		wrapped[route] = slowRequestWrap(route, handler, logger, threshold)
+	}
+	return wrapped
+}
+
+func slowRequestWrap(route string, handler http.Handler, logger lager.Logger, threshold time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		handler.ServeHTTP(w, r)
+		duration := time.Since(start)
+
+		if duration <= threshold {
+			return
+		}
+
+		logger.Info("slow-request", lager.Data{
+			"route":     route,
+			"method":    r.Method,
+			"request":   r.URL.String(),
+			"duration":  duration.String(),
+			"client-cn": clientCommonName(r),
+		})
+	}
+}
+
+func clientCommonName(r *http.Request) string {
+	if r.TLS == nil || len(r.TLS.PeerCertificates) == 0 {
+		return ""
+	}
+	return r.TLS.PeerCertificates[0].Subject.CommonName
+}
@@ -0,0 +1,87 @@
+package handlers_test
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"code.cloudfoundry.org/lager/v3/lagertest"
+	"code.cloudfoundry.org/rep/handlers"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+	"github.com/tedsuo/rata"
+)
+
+var _ = Describe("WithSlowRequestLogging", func() {
+	var (
+		slowLogger *lagertest.TestLogger
+		threshold  time.Duration
+		delay      time.Duration
+		wrapped    rata.Handlers
+		request    *http.Request
+	)
+
+	BeforeEach(func() {
+		slowLogger = lagertest.NewTestLogger("test")
+		threshold = 50 * time.Millisecond
+		delay = 0
+
+		request = httptest.NewRequest("GET", "/state", nil)
+		request.TLS = &tls.ConnectionState{
+			PeerCertificates: []*x509.Certificate{
+				{Subject: pkix.Name{CommonName: "auctioneer"}},
+			},
+		}
+	})
+
+	JustBeforeEach(func() {
+		inner := rata.Handlers{
+			"STATE": http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				time.Sleep(delay)
+				w.WriteHeader(http.StatusOK)
+			}),
+		}
+		wrapped = handlers.WithSlowRequestLogging(inner, slowLogger, threshold)
+
+		recorder := httptest.NewRecorder()
+		wrapped["STATE"].ServeHTTP(recorder, request)
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+	})
+
+	Context("when the request is faster than the threshold", func() {
+		It("does not log it", func() {
+			Expect(slowLogger.Buffer()).NotTo(gbytes.Say("slow-request"))
+		})
+	})
+
+	Context("when the request is slower than the threshold", func() {
+		BeforeEach(func() {
+			delay = 100 * time.Millisecond
+		})
+
+		It("logs the route, duration and client CN", func() {
+			Expect(slowLogger.Buffer()).To(gbytes.Say("slow-request"))
+			Expect(slowLogger.LogMessages()).To(ContainElement("test.slow-request-logger.slow-request"))
+
+			logs := slowLogger.Logs()
+			Expect(logs).To(HaveLen(1))
+			Expect(logs[0].Data["route"]).To(Equal("STATE"))
+			Expect(logs[0].Data["client-cn"]).To(Equal("auctioneer"))
+			Expect(logs[0].Data).To(HaveKey("duration"))
+		})
+
+		Context("when the threshold is zero", func() {
+			BeforeEach(func() {
+				threshold = 0
+			})
+
+			It("does not log anything", func() {
+				Expect(slowLogger.Logs()).To(BeEmpty())
+			})
+		})
+	})
+})
@@ -5,7 +5,6 @@ import (
 	"net/http"
 	"time"
 
-	"code.cloudfoundry.org/bbs/trace"
 	"code.cloudfoundry.org/executor"
 	"code.cloudfoundry.org/lager/v3"
 	"code.cloudfoundry.org/locket/metrics/helpers"
@@ -56,7 +55,7 @@ func (h *StopLRPInstanceHandler) ServeHTTP(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	traceID := trace.RequestIdFromRequest(r)
+	traceID := CorrelationIDFromContext(r.Context())
 	deferErr = h.client.StopContainer(logger, traceID, rep.LRPContainerGuid(processGuid, instanceGuid))
 	if deferErr != nil {
 		w.WriteHeader(http.StatusInternalServerError)
@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"code.cloudfoundry.org/lager/v3"
+	"code.cloudfoundry.org/locket/metrics/helpers"
+)
+
+type supportedProvidersHandler struct {
+	providers []string
+	metrics   helpers.RequestMetrics
+}
+
+func newSupportedProvidersHandler(providers []string, metrics helpers.RequestMetrics) *supportedProvidersHandler {
+	if providers == nil {
+		providers = []string{}
+	}
+	return &supportedProvidersHandler{providers: providers, metrics: metrics}
+}
+
+func (h *supportedProvidersHandler) ServeHTTP(w http.ResponseWriter, r *http.Request, logger lager.Logger) {
+	var deferErr error
+
+	start := time.Now()
+	requestType := "SupportedProviders"
+	startMetrics(h.metrics, requestType)
+	defer stopMetrics(h.metrics, requestType, start, &deferErr)
+
+	// #nosec G104 - ignore errors when writing HTTP responses so we don't spam our logs during a DoS
+	json.NewEncoder(w).Encode(h.providers)
+}
@@ -0,0 +1,41 @@
+package handlers_test
+
+import (
+	"net/http"
+
+	"code.cloudfoundry.org/rep"
+	"code.cloudfoundry.org/rep/handlers"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SupportedProviders", func() {
+	Context("when the cell supports providers", func() {
+		BeforeEach(func() {
+			StartServer(rep.RoutesNetworkAccessible, handlers.New(fakeLocalRep, fakeMetricCollector, fakeExecutorClient, fakeEvacuatable, fakeRequestMetrics, logger, true, handlers.Options{SupportedProviders: []string{"docker", "buildpack"}}))
+		})
+
+		It("returns the configured providers", func() {
+			status, body := Request(rep.SupportedProvidersRoute, nil, nil)
+			Expect(status).To(Equal(http.StatusOK))
+			Expect(body).To(MatchJSON(`["docker", "buildpack"]`))
+		})
+
+		It("emits the request metrics", func() {
+			Request(rep.SupportedProvidersRoute, nil, nil)
+
+			Expect(fakeRequestMetrics.IncrementRequestsStartedCounterCallCount()).To(Equal(1))
+			calledRequestType, _ := fakeRequestMetrics.IncrementRequestsStartedCounterArgsForCall(0)
+			Expect(calledRequestType).To(Equal("SupportedProviders"))
+		})
+	})
+
+	Context("when no providers are configured", func() {
+		It("returns an empty list", func() {
+			status, body := Request(rep.SupportedProvidersRoute, nil, nil)
+			Expect(status).To(Equal(http.StatusOK))
+			Expect(body).To(MatchJSON(`[]`))
+		})
+	})
+})
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"time"
+
+	"code.cloudfoundry.org/executor"
+	"code.cloudfoundry.org/lager/v3"
+	"code.cloudfoundry.org/locket/metrics/helpers"
+	"code.cloudfoundry.org/rep"
+)
+
+type tasksHandler struct {
+	client  executor.Client
+	metrics helpers.RequestMetrics
+}
+
+func newTasksHandler(client executor.Client, metrics helpers.RequestMetrics) *tasksHandler {
+	return &tasksHandler{client: client, metrics: metrics}
+}
+
+func (h *tasksHandler) ServeHTTP(w http.ResponseWriter, r *http.Request, logger lager.Logger) {
+	var deferErr error
+
+	start := time.Now()
+	requestType := "Tasks"
+	startMetrics(h.metrics, requestType)
+	defer stopMetrics(h.metrics, requestType, start, &deferErr)
+
+	logger = logger.Session("tasks-handler").WithTraceInfo(r)
+
+	var containers []executor.Container
+	containers, deferErr = h.client.ListContainers(logger)
+	if deferErr != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		logger.Error("failed-to-list-containers", deferErr)
+		return
+	}
+
+	// #nosec G104 - ignore errors when writing HTTP responses so we don't spam our logs during a DoS
+	json.NewEncoder(w).Encode(taskSummaries(containers))
+}
+
+func taskSummaries(containers []executor.Container) []rep.TaskSummary {
+	tasks := []rep.TaskSummary{}
+	for _, container := range containers {
+		if container.Tags[rep.LifecycleTag] != rep.TaskLifecycle {
+			continue
+		}
+		tasks = append(tasks, rep.TaskSummary{
+			TaskGuid:  container.Guid,
+			Domain:    container.Tags[rep.DomainTag],
+			State:     container.State,
+			AuctionID: container.Tags[rep.AuctionIDTag],
+		})
+	}
+
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].TaskGuid < tasks[j].TaskGuid })
+	return tasks
+}
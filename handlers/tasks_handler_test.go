@@ -0,0 +1,73 @@
+package handlers_test
+
+import (
+	"errors"
+	"net/http"
+
+	"code.cloudfoundry.org/executor"
+	"code.cloudfoundry.org/rep"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Tasks", func() {
+	Context("when listing the containers succeeds", func() {
+		BeforeEach(func() {
+			fakeExecutorClient.ListContainersReturns([]executor.Container{
+				{
+					Guid:  "task-2",
+					State: executor.StateCreated,
+					Tags:  executor.Tags{rep.LifecycleTag: rep.TaskLifecycle, rep.DomainTag: "cf-tasks"},
+				},
+				{
+					Guid:  "lrp-1",
+					State: executor.StateRunning,
+					Tags:  executor.Tags{rep.LifecycleTag: rep.LRPLifecycle, rep.DomainTag: "cf-apps"},
+				},
+				{
+					Guid:  "task-1",
+					State: executor.StateRunning,
+					Tags:  executor.Tags{rep.LifecycleTag: rep.TaskLifecycle, rep.DomainTag: "cf-tasks", rep.AuctionIDTag: "auction-1"},
+				},
+				{Guid: "untagged", State: executor.StateRunning},
+			}, nil)
+		})
+
+		It("returns only the task containers", func() {
+			status, body := Request(rep.TasksRoute, nil, nil)
+			Expect(status).To(Equal(http.StatusOK))
+			Expect(body).To(MatchJSON(`[
+				{"task_guid": "task-1", "domain": "cf-tasks", "state": "running", "auction_id": "auction-1"},
+				{"task_guid": "task-2", "domain": "cf-tasks", "state": "created"}
+			]`))
+		})
+
+		It("emits the request metrics", func() {
+			Request(rep.TasksRoute, nil, nil)
+
+			Expect(fakeRequestMetrics.IncrementRequestsStartedCounterCallCount()).To(Equal(1))
+			calledRequestType, _ := fakeRequestMetrics.IncrementRequestsStartedCounterArgsForCall(0)
+			Expect(calledRequestType).To(Equal("Tasks"))
+		})
+	})
+
+	Context("when there are no tasks", func() {
+		It("returns an empty list", func() {
+			status, body := Request(rep.TasksRoute, nil, nil)
+			Expect(status).To(Equal(http.StatusOK))
+			Expect(body).To(MatchJSON(`[]`))
+		})
+	})
+
+	Context("when listing the containers fails", func() {
+		BeforeEach(func() {
+			fakeExecutorClient.ListContainersReturns(nil, errors.New("boom"))
+		})
+
+		It("responds with 500", func() {
+			status, _ := Request(rep.TasksRoute, nil, nil)
+			Expect(status).To(Equal(http.StatusInternalServerError))
+		})
+	})
+})
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"os"
+	"time"
+
+	"code.cloudfoundry.org/lager/v3"
+)
+
+// TLSInfo summarizes the TLS configuration of a rep server. It never
+// includes private keys.
+type TLSInfo struct {
+	Certificate  *CertificateInfo  `json:"certificate,omitempty"`
+	CAs          []CertificateInfo `json:"cas"`
+	MinVersion   string            `json:"min_version,omitempty"`
+	MaxVersion   string            `json:"max_version,omitempty"`
+	CipherSuites []string          `json:"cipher_suites"`
+	ClientAuth   string            `json:"client_auth"`
+}
+
+type CertificateInfo struct {
+	Subject   string    `json:"subject"`
+	Issuer    string    `json:"issuer"`
+	DNSNames  []string  `json:"dns_names,omitempty"`
+	NotBefore time.Time `json:"not_before"`
+	NotAfter  time.Time `json:"not_after"`
+}
+
+// NewTLSInfo summarizes tlsConfig, along with the CAs in caCertFile used to
+// authenticate clients.
+func NewTLSInfo(tlsConfig *tls.Config, caCertFile string) (*TLSInfo, error) {
+	info := &TLSInfo{
+		CAs:          []CertificateInfo{},
+		CipherSuites: []string{},
+		ClientAuth:   tlsConfig.ClientAuth.String(),
+	}
+	if tlsConfig.MinVersion != 0 {
+		info.MinVersion = tls.VersionName(tlsConfig.MinVersion)
+	}
+	if tlsConfig.MaxVersion != 0 {
+		info.MaxVersion = tls.VersionName(tlsConfig.MaxVersion)
+	}
+	for _, suite := range tlsConfig.CipherSuites {
+		info.CipherSuites = append(info.CipherSuites, tls.CipherSuiteName(suite))
+	}
+
+	if len(tlsConfig.Certificates) > 0 && len(tlsConfig.Certificates[0].Certificate) > 0 {
+		cert, err := x509.ParseCertificate(tlsConfig.Certificates[0].Certificate[0])
+		if err != nil {
+			return nil, err
+		}
+		certInfo := newCertificateInfo(cert)
+		info.Certificate = &certInfo
+	}
+
+	if caCertFile != "" {
+		caBytes, err := os.ReadFile(caCertFile)
+		if err != nil {
+			return nil, err
+		}
+		for {
+			var block *pem.Block
+			block, caBytes = pem.Decode(caBytes)
+			if block == nil {
+				break
+			}
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return nil, err
+			}
+			info.CAs = append(info.CAs, newCertificateInfo(cert))
+		}
+		if len(info.CAs) == 0 {
+			return nil, errors.New("no certificates found in ca cert file")
+		}
+	}
+
+	return info, nil
+}
+
+func newCertificateInfo(cert *x509.Certificate) CertificateInfo {
+	return CertificateInfo{
+		Subject:   cert.Subject.String(),
+		Issuer:    cert.Issuer.String(),
+		DNSNames:  cert.DNSNames,
+		NotBefore: cert.NotBefore,
+		NotAfter:  cert.NotAfter,
+	}
+}
+
+type tlsInfoHandler struct {
+	info *TLSInfo
+}
+
+func newTLSInfoHandler(info *TLSInfo) *tlsInfoHandler {
+	return &tlsInfoHandler{info: info}
+}
+
+func (h *tlsInfoHandler) ServeHTTP(w http.ResponseWriter, r *http.Request, logger lager.Logger) {
+	if h.info == nil {
+		logger.Session("tls-info").Info("tls-info-not-reported")
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	// #nosec G104 - ignore errors when writing HTTP responses so we don't spam our logs during a DoS
+	json.NewEncoder(w).Encode(h.info)
+}
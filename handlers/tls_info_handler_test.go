@@ -0,0 +1,86 @@
+package handlers_test
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"code.cloudfoundry.org/rep"
+	"code.cloudfoundry.org/rep/handlers"
+	"code.cloudfoundry.org/tlsconfig"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TLSInfo", func() {
+	Context("when the tls configuration is reported", func() {
+		var (
+			certsPath string
+			tlsConfig *tls.Config
+		)
+
+		parseCert := func(path string) *x509.Certificate {
+			certBytes, err := os.ReadFile(path)
+			Expect(err).NotTo(HaveOccurred())
+			block, _ := pem.Decode(certBytes)
+			Expect(block).NotTo(BeNil())
+			cert, err := x509.ParseCertificate(block.Bytes)
+			Expect(err).NotTo(HaveOccurred())
+			return cert
+		}
+
+		BeforeEach(func() {
+			certsPath = filepath.Join("..", "cmd", "rep", "fixtures", "blue-certs")
+
+			var err error
+			tlsConfig, err = tlsconfig.Build(
+				tlsconfig.WithInternalServiceDefaults(),
+				tlsconfig.WithIdentityFromFile(filepath.Join(certsPath, "server.crt"), filepath.Join(certsPath, "server.key")),
+			).Server(tlsconfig.WithClientAuthenticationFromFile(filepath.Join(certsPath, "server-ca.crt")))
+			Expect(err).NotTo(HaveOccurred())
+
+			tlsInfo, err := handlers.NewTLSInfo(tlsConfig, filepath.Join(certsPath, "server-ca.crt"))
+			Expect(err).NotTo(HaveOccurred())
+			StartServer(rep.RoutesLocalhostOnly, handlers.New(fakeLocalRep, fakeMetricCollector, fakeExecutorClient, fakeEvacuatable, fakeRequestMetrics, logger, false, handlers.Options{TLSInfo: tlsInfo}))
+		})
+
+		It("summarizes the certificates and protocol settings", func() {
+			status, body := Request(rep.TLSInfoRoute, nil, nil)
+			Expect(status).To(Equal(http.StatusOK))
+
+			var info handlers.TLSInfo
+			Expect(json.Unmarshal(body, &info)).To(Succeed())
+
+			serverCert := parseCert(filepath.Join(certsPath, "server.crt"))
+			Expect(info.Certificate).NotTo(BeNil())
+			Expect(info.Certificate.Subject).To(Equal(serverCert.Subject.String()))
+			Expect(info.Certificate.Issuer).To(Equal(serverCert.Issuer.String()))
+			Expect(info.Certificate.NotAfter.Equal(serverCert.NotAfter)).To(BeTrue())
+
+			caCert := parseCert(filepath.Join(certsPath, "server-ca.crt"))
+			Expect(info.CAs).To(HaveLen(1))
+			Expect(info.CAs[0].Subject).To(Equal(caCert.Subject.String()))
+
+			Expect(info.MinVersion).To(Equal(tls.VersionName(tlsConfig.MinVersion)))
+			Expect(info.CipherSuites).To(HaveLen(len(tlsConfig.CipherSuites)))
+			Expect(info.ClientAuth).To(Equal(tls.RequireAndVerifyClientCert.String()))
+		})
+
+		It("never includes private keys", func() {
+			_, body := Request(rep.TLSInfoRoute, nil, nil)
+			Expect(string(body)).NotTo(ContainSubstring("PRIVATE KEY"))
+		})
+	})
+
+	Context("when the tls configuration is not reported", func() {
+		It("responds with 404", func() {
+			status, _ := Request(rep.TLSInfoRoute, nil, nil)
+			Expect(status).To(Equal(http.StatusNotFound))
+		})
+	})
+})
@@ -0,0 +1,33 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"code.cloudfoundry.org/lager/v3"
+	"code.cloudfoundry.org/rep/uptime"
+)
+
+type UptimeReporter interface {
+	Status() uptime.Status
+}
+
+type uptimeHandler struct {
+	reporter UptimeReporter
+}
+
+func newUptimeHandler(reporter UptimeReporter) *uptimeHandler {
+	return &uptimeHandler{reporter: reporter}
+}
+
+func (h *uptimeHandler) ServeHTTP(w http.ResponseWriter, r *http.Request, logger lager.Logger) {
+	if h.reporter == nil {
+		logger.Session("uptime").Info("uptime-not-tracked")
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	// #nosec G104 - ignore errors when writing HTTP responses so we don't spam our logs during a DoS
+	json.NewEncoder(w).Encode(h.reporter.Status())
+}
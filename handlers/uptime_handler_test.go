@@ -0,0 +1,55 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/rep"
+	"code.cloudfoundry.org/rep/handlers"
+	"code.cloudfoundry.org/rep/uptime"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Uptime", func() {
+	Context("when uptime is tracked", func() {
+		var fakeClock *fakeclock.FakeClock
+
+		BeforeEach(func() {
+			fakeClock = fakeclock.NewFakeClock(time.Now())
+			restartCountFile := filepath.Join(GinkgoT().TempDir(), "restart-count")
+
+			// simulate a previous run of the rep
+			_, err := uptime.NewTracker(logger, fakeClock, restartCountFile)
+			Expect(err).NotTo(HaveOccurred())
+
+			tracker, err := uptime.NewTracker(logger, fakeClock, restartCountFile)
+			Expect(err).NotTo(HaveOccurred())
+
+			StartServer(rep.RoutesLocalhostOnly, handlers.New(fakeLocalRep, fakeMetricCollector, fakeExecutorClient, fakeEvacuatable, fakeRequestMetrics, logger, false, handlers.Options{UptimeReporter: tracker}))
+		})
+
+		It("reports the uptime and the restart count", func() {
+			fakeClock.Increment(time.Minute)
+
+			status, body := Request(rep.UptimeRoute, nil, nil)
+			Expect(status).To(Equal(http.StatusOK))
+
+			var reported uptime.Status
+			Expect(json.Unmarshal(body, &reported)).To(Succeed())
+			Expect(reported.UptimeSeconds).To(Equal(60.0))
+			Expect(reported.RestartCount).To(Equal(1))
+		})
+	})
+
+	Context("when uptime is not tracked", func() {
+		It("responds with 404", func() {
+			status, _ := Request(rep.UptimeRoute, nil, nil)
+			Expect(status).To(Equal(http.StatusNotFound))
+		})
+	})
+})
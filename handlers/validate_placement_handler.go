@@ -0,0 +1,41 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"code.cloudfoundry.org/lager/v3"
+	"code.cloudfoundry.org/rep"
+	"code.cloudfoundry.org/rep/auctioncellrep"
+)
+
+type validatePlacementHandler struct {
+	rep auctioncellrep.AuctionCellClient
+}
+
+func newValidatePlacementHandler(rep auctioncellrep.AuctionCellClient) *validatePlacementHandler {
+	return &validatePlacementHandler{rep: rep}
+}
+
+func (h *validatePlacementHandler) ServeHTTP(w http.ResponseWriter, r *http.Request, logger lager.Logger) {
+	logger = logger.Session("validate-placement")
+
+	var work rep.Work
+	err := json.NewDecoder(r.Body).Decode(&work)
+	if err != nil {
+		logger.Error("failed-to-unmarshal", err)
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	validation, err := h.rep.ValidatePlacement(r.Context(), logger, work)
+	if err != nil {
+		logger.Error("failed-to-validate-placement", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	// #nosec G104 - ignore errors when writing HTTP responses so we don't spam our logs during a DoS
+	json.NewEncoder(w).Encode(validation)
+}
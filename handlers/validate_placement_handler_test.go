@@ -0,0 +1,76 @@
+package handlers_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/rep"
+	"code.cloudfoundry.org/rep/handlers"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ValidatePlacement", func() {
+	var work rep.Work
+
+	BeforeEach(func() {
+		work = rep.Work{
+			LRPs: []rep.LRP{rep.NewLRP("ig-1", models.NewActualLRPKey("pg-1", 0, "domain"), rep.NewResource(128, 256, 10), rep.NewPlacementConstraint("preloaded:linux", nil, nil))},
+		}
+		StartServer(rep.RoutesLocalhostOnly, handlers.New(fakeLocalRep, fakeMetricCollector, fakeExecutorClient, fakeEvacuatable, fakeRequestMetrics, logger, false, handlers.Options{}))
+	})
+
+	Context("when the validation succeeds", func() {
+		var validation rep.PlacementValidation
+
+		BeforeEach(func() {
+			validation = rep.PlacementValidation{
+				LRPs:      []rep.PlacementFit{{Guid: "ig-1", Fits: true}},
+				Tasks:     []rep.PlacementFit{},
+				Remaining: rep.Resources{MemoryMB: 896, DiskMB: 768, Containers: 2},
+			}
+			fakeLocalRep.ValidatePlacementReturns(validation, nil)
+		})
+
+		It("returns the computed fit", func() {
+			status, body := Request(rep.ValidatePlacementRoute, nil, JSONReaderFor(work))
+			Expect(status).To(Equal(http.StatusOK))
+
+			var returned rep.PlacementValidation
+			Expect(json.Unmarshal(body, &returned)).To(Succeed())
+			Expect(returned).To(Equal(validation))
+
+			Expect(fakeLocalRep.ValidatePlacementCallCount()).To(Equal(1))
+			_, _, validatedWork := fakeLocalRep.ValidatePlacementArgsForCall(0)
+			Expect(validatedWork).To(Equal(work))
+		})
+
+		It("does not perform the work", func() {
+			Request(rep.ValidatePlacementRoute, nil, JSONReaderFor(work))
+			Expect(fakeLocalRep.PerformCallCount()).To(BeZero())
+		})
+	})
+
+	Context("when the validation fails", func() {
+		BeforeEach(func() {
+			fakeLocalRep.ValidatePlacementReturns(rep.PlacementValidation{}, errors.New("boom"))
+		})
+
+		It("responds with 500", func() {
+			status, _ := Request(rep.ValidatePlacementRoute, nil, JSONReaderFor(work))
+			Expect(status).To(Equal(http.StatusInternalServerError))
+		})
+	})
+
+	Context("when the body is not valid JSON", func() {
+		It("responds with 400", func() {
+			status, _ := Request(rep.ValidatePlacementRoute, nil, strings.NewReader("{"))
+			Expect(status).To(Equal(http.StatusBadRequest))
+			Expect(fakeLocalRep.ValidatePlacementCallCount()).To(BeZero())
+		})
+	})
+})
@@ -0,0 +1,72 @@
+package harmonizer
+
+import (
+	"sync"
+
+	"code.cloudfoundry.org/operationq"
+)
+
+// boundedQueue is a sliding queue of size one, like
+// operationq.NewSlidingQueue(1), which also bounds how many operations
+// execute at once. The sliding queue already executes the operations of
+// different containers in parallel, but without any bound.
+type boundedQueue struct {
+	slots chan struct{}
+
+	lock    sync.Mutex
+	pending map[string]operationq.Operation
+	running map[string]struct{}
+}
+
+// NewBoundedQueue returns a queue executing the operations of a container one
+// at a time, and at most concurrency operations at once. Only the latest
+// operation pushed for a container waits to execute: an operation waiting for
+// a free slot is replaced by a newer one for the same container. A
+// non-positive concurrency returns an unbounded sliding queue.
+func NewBoundedQueue(concurrency int) operationq.Queue {
+	if concurrency <= 0 {
+		return operationq.NewSlidingQueue(1)
+	}
+
+	return &boundedQueue{
+		slots:   make(chan struct{}, concurrency),
+		pending: map[string]operationq.Operation{},
+		running: map[string]struct{}{},
+	}
+}
+
+func (q *boundedQueue) Push(op operationq.Operation) {
+	key := op.Key()
+
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	q.pending[key] = op
+	if _, running := q.running[key]; !running {
+		q.running[key] = struct{}{}
+		go q.run(key)
+	}
+}
+
+// run executes the operations of a container until none are pending. A slot
+// is taken before the pending operation is picked, so the operation executed
+// is the latest one pushed by the time a slot is free.
+func (q *boundedQueue) run(key string) {
+	for {
+		q.slots <- struct{}{}
+
+		q.lock.Lock()
+		op, found := q.pending[key]
+		if !found {
+			delete(q.running, key)
+			q.lock.Unlock()
+			<-q.slots
+			return
+		}
+		delete(q.pending, key)
+		q.lock.Unlock()
+
+		op.Execute()
+		<-q.slots
+	}
+}
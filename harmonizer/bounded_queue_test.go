@@ -0,0 +1,125 @@
+package harmonizer_test
+
+import (
+	"sync/atomic"
+
+	"code.cloudfoundry.org/operationq"
+	"code.cloudfoundry.org/operationq/fake_operationq"
+	"code.cloudfoundry.org/rep/harmonizer"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("BoundedQueue", func() {
+	var (
+		running     int32
+		maxRunning  int32
+		release     chan struct{}
+		concurrency int
+
+		queue operationq.Queue
+	)
+
+	newOperation := func(key string, executed chan<- string) *fake_operationq.FakeOperation {
+		op := new(fake_operationq.FakeOperation)
+		op.KeyReturns(key)
+		op.ExecuteStub = func() {
+			current := atomic.AddInt32(&running, 1)
+			for {
+				highest := atomic.LoadInt32(&maxRunning)
+				if current <= highest || atomic.CompareAndSwapInt32(&maxRunning, highest, current) {
+					break
+				}
+			}
+			<-release
+			atomic.AddInt32(&running, -1)
+			executed <- key
+		}
+		return op
+	}
+
+	BeforeEach(func() {
+		running = 0
+		maxRunning = 0
+		release = make(chan struct{})
+		concurrency = 2
+	})
+
+	JustBeforeEach(func() {
+		queue = harmonizer.NewBoundedQueue(concurrency)
+	})
+
+	It("executes operations of different containers in parallel up to the concurrency", func() {
+		executed := make(chan string, 3)
+		queue.Push(newOperation("container-1", executed))
+		queue.Push(newOperation("container-2", executed))
+		queue.Push(newOperation("container-3", executed))
+
+		Eventually(func() int32 { return atomic.LoadInt32(&running) }).Should(Equal(int32(2)))
+		Consistently(func() int32 { return atomic.LoadInt32(&running) }).Should(Equal(int32(2)))
+
+		close(release)
+		Eventually(executed).Should(Receive())
+		Eventually(executed).Should(Receive())
+		Eventually(executed).Should(Receive())
+		Expect(atomic.LoadInt32(&maxRunning)).To(Equal(int32(2)))
+	})
+
+	It("executes the operations of a container one at a time", func() {
+		executed := make(chan string, 2)
+		queue.Push(newOperation("container-1", executed))
+		Eventually(func() int32 { return atomic.LoadInt32(&running) }).Should(Equal(int32(1)))
+
+		queue.Push(newOperation("container-1", executed))
+		Consistently(func() int32 { return atomic.LoadInt32(&running) }).Should(Equal(int32(1)))
+
+		close(release)
+		Eventually(executed).Should(Receive())
+		Eventually(executed).Should(Receive())
+		Expect(atomic.LoadInt32(&maxRunning)).To(Equal(int32(1)))
+	})
+
+	Context("when an operation is waiting for a free slot", func() {
+		BeforeEach(func() {
+			concurrency = 1
+		})
+
+		It("replaces it with a newer operation for the same container", func() {
+			executed := make(chan string, 3)
+			queue.Push(newOperation("container-1", executed))
+			Eventually(func() int32 { return atomic.LoadInt32(&running) }).Should(Equal(int32(1)))
+
+			stale := newOperation("container-2", executed)
+			latest := newOperation("container-2", executed)
+			queue.Push(stale)
+			queue.Push(latest)
+
+			close(release)
+			Eventually(executed).Should(Receive(Equal("container-1")))
+			Eventually(executed).Should(Receive(Equal("container-2")))
+			Consistently(executed).ShouldNot(Receive())
+			Expect(stale.ExecuteCallCount()).To(BeZero())
+			Expect(latest.ExecuteCallCount()).To(Equal(1))
+		})
+	})
+
+	Context("when the concurrency is not positive", func() {
+		BeforeEach(func() {
+			concurrency = 0
+		})
+
+		It("does not bound the operations", func() {
+			executed := make(chan string, 3)
+			queue.Push(newOperation("container-1", executed))
+			queue.Push(newOperation("container-2", executed))
+			queue.Push(newOperation("container-3", executed))
+
+			Eventually(func() int32 { return atomic.LoadInt32(&running) }).Should(Equal(int32(3)))
+
+			close(release)
+			Eventually(executed).Should(Receive())
+			Eventually(executed).Should(Receive())
+			Eventually(executed).Should(Receive())
+		})
+	})
+})
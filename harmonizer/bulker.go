@@ -2,6 +2,7 @@ package harmonizer
 
 import (
 	"os"
+	"sort"
 	"time"
 
 	"code.cloudfoundry.org/clock"
@@ -12,7 +13,10 @@ import (
 	"code.cloudfoundry.org/rep/generator"
 )
 
-const repBulkSyncDuration = "RepBulkSyncDuration"
+const (
+	repBulkSyncDuration         = "RepBulkSyncDuration"
+	repBulkSyncRetriesExhausted = "RepBulkSyncRetriesExhausted"
+)
 
 type Bulker struct {
 	logger lager.Logger
@@ -24,6 +28,13 @@ type Bulker struct {
 	generator              generator.Generator
 	queue                  operationq.Queue
 	metronClient           loggingclient.IngressClient
+	maxRetries             int
+	retryInterval          time.Duration
+	maxOperationsPerLoop   int
+
+	// lastQueuedGuid is the guid of the last operation queued by a capped
+	// loop; the next loop resumes after it.
+	lastQueuedGuid string
 }
 
 func NewBulker(
@@ -35,6 +46,9 @@ func NewBulker(
 	generator generator.Generator,
 	queue operationq.Queue,
 	metronClient loggingclient.IngressClient,
+	maxRetries int,
+	retryInterval time.Duration,
+	maxOperationsPerLoop int,
 ) *Bulker {
 	return &Bulker{
 		logger: logger,
@@ -46,6 +60,9 @@ func NewBulker(
 		generator:              generator,
 		queue:                  queue,
 		metronClient:           metronClient,
+		maxRetries:             maxRetries,
+		retryInterval:          retryInterval,
+		maxOperationsPerLoop:   maxOperationsPerLoop,
 	}
 }
 
@@ -81,12 +98,17 @@ func (b *Bulker) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
 			return nil
 		}
 
-		b.sync(logger)
+		if signal := b.sync(logger, signals); signal != nil {
+			logger.Info("received-signal", lager.Data{"signal": signal.String()})
+			return nil
+		}
 		timer.Reset(interval)
 	}
 }
 
-func (b *Bulker) sync(logger lager.Logger) {
+// sync queues the operations generated for this loop. It returns the signal
+// received while waiting to retry, if any, in which case nothing is queued.
+func (b *Bulker) sync(logger lager.Logger, signals <-chan os.Signal) os.Signal {
 	logger = logger.Session("sync")
 
 	logger.Info("starting")
@@ -94,7 +116,10 @@ func (b *Bulker) sync(logger lager.Logger) {
 
 	startTime := b.clock.Now()
 
-	ops, batchError := b.generator.BatchOperations(logger)
+	ops, signal, batchError := b.batchOperations(logger, signals)
+	if signal != nil {
+		return signal
+	}
 
 	endTime := b.clock.Now()
 
@@ -105,10 +130,82 @@ func (b *Bulker) sync(logger lager.Logger) {
 
 	if batchError != nil {
 		logger.Error("failed-to-generate-operations", batchError)
-		return
+		return nil
 	}
 
-	for _, operation := range ops {
-		b.queue.Push(operation)
+	if b.maxOperationsPerLoop <= 0 || len(ops) <= b.maxOperationsPerLoop {
+		for _, operation := range ops {
+			b.queue.Push(operation)
+		}
+		return nil
+	}
+
+	guids := b.nextGuids(ops)
+	logger.Info("capped-operations", lager.Data{
+		"operations":              len(ops),
+		"max-operations-per-loop": b.maxOperationsPerLoop,
+	})
+	for _, guid := range guids {
+		b.queue.Push(ops[guid])
+	}
+	return nil
+}
+
+// nextGuids picks maxOperationsPerLoop of the operations in guid order,
+// starting after the last guid queued by the previous capped loop and
+// wrapping around, so that operations which keep being generated cannot
+// starve the rest.
+func (b *Bulker) nextGuids(ops map[string]operationq.Operation) []string {
+	guids := make([]string, 0, len(ops))
+	for guid := range ops {
+		guids = append(guids, guid)
 	}
+	sort.Strings(guids)
+
+	start := sort.SearchStrings(guids, b.lastQueuedGuid)
+	if start < len(guids) && guids[start] == b.lastQueuedGuid {
+		start++
+	}
+
+	next := make([]string, 0, b.maxOperationsPerLoop)
+	for i := 0; i < b.maxOperationsPerLoop; i++ {
+		next = append(next, guids[(start+i)%len(guids)])
+	}
+	b.lastQueuedGuid = next[len(next)-1]
+	return next
+}
+
+// batchOperations retries generating the operations up to maxRetries times,
+// so that a transient failure listing the executor's containers does not
+// skip a whole reconciliation pass. A signal received while waiting to retry
+// stops the retries and is returned instead.
+func (b *Bulker) batchOperations(logger lager.Logger, signals <-chan os.Signal) (map[string]operationq.Operation, os.Signal, error) {
+	ops, err := b.generator.BatchOperations(logger)
+	for attempt := 1; err != nil && attempt <= b.maxRetries; attempt++ {
+		logger.Info("retrying-batch-operations", lager.Data{
+			"attempt":     attempt,
+			"max-retries": b.maxRetries,
+			"error":       err.Error(),
+		})
+		if b.retryInterval > 0 {
+			timer := b.clock.NewTimer(b.retryInterval)
+			select {
+			case <-timer.C():
+			case signal := <-signals:
+				timer.Stop()
+				return nil, signal, nil
+			}
+		}
+		ops, err = b.generator.BatchOperations(logger)
+	}
+
+	if err != nil && b.maxRetries > 0 {
+		logger.Error("exhausted-batch-operations-retries", err, lager.Data{"max-retries": b.maxRetries})
+		sendErr := b.metronClient.IncrementCounter(repBulkSyncRetriesExhausted)
+		if sendErr != nil {
+			logger.Error("failed-to-send-rep-bulk-sync-retries-exhausted-metric", sendErr)
+		}
+	}
+
+	return ops, nil, err
 }
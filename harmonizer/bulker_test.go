@@ -31,6 +31,9 @@ var _ = Describe("Bulker", func() {
 		evacuatable            evacuation_context.Evacuatable
 		evacuationNotifier     evacuation_context.EvacuationNotifier
 		fakeMetronClient       *mfakes.FakeIngressClient
+		maxRetries             int
+		retryInterval          time.Duration
+		maxOperationsPerLoop   int
 
 		bulker  *harmonizer.Bulker
 		process ifrit.Process
@@ -44,9 +47,15 @@ var _ = Describe("Bulker", func() {
 		fakeGenerator = new(fake_generator.FakeGenerator)
 		fakeQueue = new(fake_operationq.FakeQueue)
 		fakeMetronClient = new(mfakes.FakeIngressClient)
+		maxRetries = 0
+		retryInterval = 0
+		maxOperationsPerLoop = 0
 
 		evacuatable, _, evacuationNotifier = evacuation_context.New()
 
+	})
+
+	JustBeforeEach(func() {
 		bulker = harmonizer.NewBulker(
 			logger,
 			pollInterval,
@@ -56,10 +65,11 @@ var _ = Describe("Bulker", func() {
 			fakeGenerator,
 			fakeQueue,
 			fakeMetronClient,
+			maxRetries,
+			retryInterval,
+			maxOperationsPerLoop,
 		)
-	})
 
-	JustBeforeEach(func() {
 		process = ifrit.Invoke(bulker)
 		Eventually(fakeClock.WatcherCount).Should(Equal(1))
 	})
@@ -166,4 +176,129 @@ var _ = Describe("Bulker", func() {
 			})
 		})
 	})
+
+	Context("when retries are configured", func() {
+		disaster := errors.New("executor unavailable")
+
+		BeforeEach(func() {
+			maxRetries = 2
+		})
+
+		JustBeforeEach(func() {
+			fakeClock.WaitForWatcherAndIncrement(pollInterval)
+		})
+
+		Context("and generating the batch operations fails transiently", func() {
+			var operation *fake_operationq.FakeOperation
+
+			BeforeEach(func() {
+				operation = new(fake_operationq.FakeOperation)
+				fakeGenerator.BatchOperationsReturnsOnCall(0, nil, disaster)
+				fakeGenerator.BatchOperationsReturnsOnCall(1, map[string]operationq.Operation{"guid1": operation}, nil)
+			})
+
+			It("retries and pushes the operations onto the queue", func() {
+				Eventually(fakeQueue.PushCallCount).Should(Equal(1))
+				Expect(fakeQueue.PushArgsForCall(0)).To(Equal(operation))
+				Expect(fakeGenerator.BatchOperationsCallCount()).To(Equal(2))
+			})
+
+			It("logs the retry", func() {
+				Eventually(logger).Should(gbytes.Say("retrying-batch-operations"))
+				Eventually(logger).Should(gbytes.Say("executor unavailable"))
+			})
+
+			It("does not emit the retries exhausted metric", func() {
+				Eventually(fakeQueue.PushCallCount).Should(Equal(1))
+				Expect(fakeMetronClient.IncrementCounterCallCount()).To(BeZero())
+			})
+		})
+
+		Context("and generating the batch operations keeps failing", func() {
+			BeforeEach(func() {
+				fakeGenerator.BatchOperationsReturns(nil, disaster)
+			})
+
+			It("gives up after the configured number of retries", func() {
+				Eventually(logger).Should(gbytes.Say("exhausted-batch-operations-retries"))
+				Expect(fakeGenerator.BatchOperationsCallCount()).To(Equal(3))
+				Consistently(fakeQueue.PushCallCount).Should(BeZero())
+			})
+
+			It("emits the retries exhausted metric", func() {
+				Eventually(fakeMetronClient.IncrementCounterCallCount).Should(Equal(1))
+				Expect(fakeMetronClient.IncrementCounterArgsForCall(0)).To(Equal("RepBulkSyncRetriesExhausted"))
+			})
+
+			Context("and a signal arrives while waiting to retry", func() {
+				BeforeEach(func() {
+					retryInterval = time.Minute
+				})
+
+				It("stops retrying and exits", func() {
+					Eventually(fakeGenerator.BatchOperationsCallCount).Should(Equal(1))
+					Eventually(fakeClock.WatcherCount).Should(Equal(1))
+
+					process.Signal(os.Interrupt)
+					Eventually(process.Wait()).Should(Receive(BeNil()))
+					Expect(fakeGenerator.BatchOperationsCallCount()).To(Equal(1))
+					Expect(fakeQueue.PushCallCount()).To(BeZero())
+					Expect(logger).To(gbytes.Say("received-signal"))
+				})
+			})
+		})
+	})
+
+	Context("when the operations per loop are capped", func() {
+		var operations map[string]*fake_operationq.FakeOperation
+
+		BeforeEach(func() {
+			maxOperationsPerLoop = 2
+			operations = map[string]*fake_operationq.FakeOperation{}
+			for _, guid := range []string{"guid1", "guid2", "guid3"} {
+				operations[guid] = new(fake_operationq.FakeOperation)
+			}
+
+			fakeGenerator.BatchOperationsStub = func(lager.Logger) (map[string]operationq.Operation, error) {
+				ops := map[string]operationq.Operation{}
+				for guid, operation := range operations {
+					ops[guid] = operation
+				}
+				return ops, nil
+			}
+		})
+
+		JustBeforeEach(func() {
+			fakeClock.WaitForWatcherAndIncrement(pollInterval)
+		})
+
+		It("pushes at most the cap onto the queue", func() {
+			Eventually(fakeQueue.PushCallCount).Should(Equal(2))
+			Consistently(fakeQueue.PushCallCount).Should(Equal(2))
+			Expect(fakeQueue.PushArgsForCall(0)).To(Equal(operations["guid1"]))
+			Expect(fakeQueue.PushArgsForCall(1)).To(Equal(operations["guid2"]))
+			Expect(logger).To(gbytes.Say("capped-operations"))
+		})
+
+		It("picks up the remaining operations on the next loop", func() {
+			Eventually(fakeQueue.PushCallCount).Should(Equal(2))
+
+			fakeClock.WaitForWatcherAndIncrement(pollInterval)
+			Eventually(fakeQueue.PushCallCount).Should(Equal(4))
+			Expect(fakeQueue.PushArgsForCall(2)).To(Equal(operations["guid3"]))
+			Expect(fakeQueue.PushArgsForCall(3)).To(Equal(operations["guid1"]))
+		})
+
+		Context("when there are no more operations than the cap", func() {
+			BeforeEach(func() {
+				delete(operations, "guid3")
+			})
+
+			It("pushes all of them onto the queue", func() {
+				Eventually(fakeQueue.PushCallCount).Should(Equal(2))
+				Consistently(fakeQueue.PushCallCount).Should(Equal(2))
+				Expect(logger).NotTo(gbytes.Say("capped-operations"))
+			})
+		})
+	})
 })
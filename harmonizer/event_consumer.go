@@ -2,27 +2,39 @@ package harmonizer
 
 import (
 	"os"
+	"time"
 
+	"code.cloudfoundry.org/clock"
 	"code.cloudfoundry.org/lager/v3"
 	"code.cloudfoundry.org/operationq"
 	"code.cloudfoundry.org/rep/generator"
 )
 
 type EventConsumer struct {
-	logger    lager.Logger
-	generator generator.Generator
-	queue     operationq.Queue
+	logger      lager.Logger
+	generator   generator.Generator
+	queue       operationq.Queue
+	clock       clock.Clock
+	batchWindow time.Duration
 }
 
+// NewEventConsumer returns a runner that pushes the operations generated from
+// executor events onto the queue. When batchWindow is positive, operations
+// for the same container received within the window are coalesced and only
+// the latest one is pushed once the window closes.
 func NewEventConsumer(
 	logger lager.Logger,
 	generator generator.Generator,
 	queue operationq.Queue,
+	clock clock.Clock,
+	batchWindow time.Duration,
 ) *EventConsumer {
 	return &EventConsumer{
-		logger:    logger,
-		generator: generator,
-		queue:     queue,
+		logger:      logger,
+		generator:   generator,
+		queue:       queue,
+		clock:       clock,
+		batchWindow: batchWindow,
 	}
 }
 
@@ -40,19 +52,82 @@ func (consumer *EventConsumer) Run(signals <-chan os.Signal, ready chan<- struct
 	close(ready)
 	logger.Info("started")
 
+	batch := newOperationBatch()
+	var batchTimer clock.Timer
+	var batchClosed <-chan time.Time
+
 	for {
 		select {
 		case op, ok := <-stream:
 			if !ok {
 				logger.Info("event-stream-closed")
+				consumer.flush(logger, batch)
 				return nil
 			}
 
-			consumer.queue.Push(op)
+			if consumer.batchWindow <= 0 {
+				consumer.queue.Push(op)
+				continue
+			}
+
+			if batch.add(op) {
+				logger.Debug("coalesced-operation", lager.Data{"key": op.Key()})
+			}
+
+			if batchTimer == nil {
+				batchTimer = consumer.clock.NewTimer(consumer.batchWindow)
+				batchClosed = batchTimer.C()
+			}
+
+		case <-batchClosed:
+			consumer.flush(logger, batch)
+			batch = newOperationBatch()
+			batchTimer = nil
+			batchClosed = nil
 
 		case signal := <-signals:
 			logger.Info("received-signal", lager.Data{"signal": signal.String()})
+			if batchTimer != nil {
+				batchTimer.Stop()
+			}
 			return nil
 		}
 	}
 }
+
+func (consumer *EventConsumer) flush(logger lager.Logger, batch *operationBatch) {
+	if len(batch.keys) == 0 {
+		return
+	}
+
+	logger.Debug("flushing-batch", lager.Data{"operations": len(batch.keys), "coalesced": batch.coalesced})
+	for _, key := range batch.keys {
+		consumer.queue.Push(batch.operations[key])
+	}
+}
+
+// operationBatch keeps the latest operation per key, in the order the keys
+// were first seen.
+type operationBatch struct {
+	keys       []string
+	operations map[string]operationq.Operation
+	coalesced  int
+}
+
+func newOperationBatch() *operationBatch {
+	return &operationBatch{operations: map[string]operationq.Operation{}}
+}
+
+// add records op and reports whether it replaced a pending operation for the
+// same key.
+func (b *operationBatch) add(op operationq.Operation) bool {
+	key := op.Key()
+	_, replaced := b.operations[key]
+	if replaced {
+		b.coalesced++
+	} else {
+		b.keys = append(b.keys, key)
+	}
+	b.operations[key] = op
+	return replaced
+}
@@ -3,7 +3,9 @@ package harmonizer_test
 import (
 	"errors"
 	"os"
+	"time"
 
+	"code.cloudfoundry.org/clock/fakeclock"
 	"code.cloudfoundry.org/lager/v3/lagertest"
 	"code.cloudfoundry.org/operationq"
 	"code.cloudfoundry.org/operationq/fake_operationq"
@@ -19,6 +21,8 @@ var _ = Describe("EventConsumer", func() {
 		logger        *lagertest.TestLogger
 		fakeGenerator *fake_generator.FakeGenerator
 		fakeQueue     *fake_operationq.FakeQueue
+		fakeClock     *fakeclock.FakeClock
+		batchWindow   time.Duration
 
 		consumer *harmonizer.EventConsumer
 		process  ifrit.Process
@@ -28,11 +32,12 @@ var _ = Describe("EventConsumer", func() {
 		logger = lagertest.NewTestLogger("test")
 		fakeGenerator = new(fake_generator.FakeGenerator)
 		fakeQueue = new(fake_operationq.FakeQueue)
-
-		consumer = harmonizer.NewEventConsumer(logger, fakeGenerator, fakeQueue)
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		batchWindow = 0
 	})
 
 	JustBeforeEach(func() {
+		consumer = harmonizer.NewEventConsumer(logger, fakeGenerator, fakeQueue, fakeClock, batchWindow)
 		process = ifrit.Invoke(consumer)
 	})
 
@@ -75,6 +80,73 @@ var _ = Describe("EventConsumer", func() {
 				Eventually(process.Wait()).Should(Receive(BeNil()))
 			})
 		})
+
+		Context("when a batch window is configured", func() {
+			newOperation := func(key string) *fake_operationq.FakeOperation {
+				op := new(fake_operationq.FakeOperation)
+				op.KeyReturns(key)
+				return op
+			}
+
+			pushedOperations := func() []operationq.Operation {
+				ops := []operationq.Operation{}
+				for i := 0; i < fakeQueue.PushCallCount(); i++ {
+					ops = append(ops, fakeQueue.PushArgsForCall(i))
+				}
+				return ops
+			}
+
+			BeforeEach(func() {
+				batchWindow = time.Second
+			})
+
+			It("pushes the latest operation per container once the window closes", func() {
+				firstA := newOperation("container-a")
+				b := newOperation("container-b")
+				secondA := newOperation("container-a")
+				thirdA := newOperation("container-a")
+
+				receivedOperations <- firstA
+				receivedOperations <- b
+				receivedOperations <- secondA
+				receivedOperations <- thirdA
+
+				Consistently(fakeQueue.PushCallCount).Should(BeZero())
+
+				fakeClock.WaitForWatcherAndIncrement(batchWindow)
+
+				Eventually(pushedOperations).Should(HaveLen(2))
+				ops := pushedOperations()
+				Expect(ops[0]).To(BeIdenticalTo(thirdA))
+				Expect(ops[1]).To(BeIdenticalTo(b))
+			})
+
+			It("starts a new batch for operations received after the window closes", func() {
+				first := newOperation("container-a")
+				second := newOperation("container-a")
+
+				receivedOperations <- first
+				fakeClock.WaitForWatcherAndIncrement(batchWindow)
+				Eventually(fakeQueue.PushCallCount).Should(Equal(1))
+
+				receivedOperations <- second
+				Consistently(fakeQueue.PushCallCount).Should(Equal(1))
+
+				fakeClock.WaitForWatcherAndIncrement(batchWindow)
+				Eventually(fakeQueue.PushCallCount).Should(Equal(2))
+				Expect(fakeQueue.PushArgsForCall(1)).To(BeIdenticalTo(second))
+			})
+
+			It("pushes the pending operations when the stream terminates", func() {
+				op := newOperation("container-a")
+				receivedOperations <- op
+				close(receivedOperations)
+
+				Eventually(process.Wait()).Should(Receive(BeNil()))
+				Expect(fakeQueue.PushCallCount()).To(Equal(1))
+				Expect(fakeQueue.PushArgsForCall(0)).To(BeIdenticalTo(op))
+			})
+		})
 	})
 
 	Context("when subscribing to events fails", func() {
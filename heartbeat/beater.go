@@ -0,0 +1,50 @@
+package heartbeat
+
+import (
+	"code.cloudfoundry.org/executor"
+	"code.cloudfoundry.org/lager/v3"
+)
+
+// Beater logs a heartbeat line with the cell's running container count and
+// remaining capacity, for log-based liveness monitoring.
+type Beater struct {
+	logger         lager.Logger
+	executorClient executor.Client
+	cellID         string
+}
+
+func NewBeater(logger lager.Logger, executorClient executor.Client, cellID string) *Beater {
+	return &Beater{
+		logger:         logger.Session("heartbeat"),
+		executorClient: executorClient,
+		cellID:         cellID,
+	}
+}
+
+func (b *Beater) Beat() {
+	data := lager.Data{"cell-id": b.cellID}
+
+	containers, err := b.executorClient.ListContainers(b.logger)
+	if err != nil {
+		b.logger.Error("failed-to-list-containers", err)
+	} else {
+		running := 0
+		for _, container := range containers {
+			if container.State == executor.StateRunning {
+				running++
+			}
+		}
+		data["running-containers"] = running
+	}
+
+	remaining, err := b.executorClient.RemainingResources(b.logger)
+	if err != nil {
+		b.logger.Error("failed-to-get-remaining-resources", err)
+	} else {
+		data["remaining-memory-mb"] = remaining.MemoryMB
+		data["remaining-disk-mb"] = remaining.DiskMB
+		data["remaining-containers"] = remaining.Containers
+	}
+
+	b.logger.Info("beat", data)
+}
@@ -0,0 +1,72 @@
+package heartbeat_test
+
+import (
+	"errors"
+
+	"code.cloudfoundry.org/executor"
+	fakeexecutor "code.cloudfoundry.org/executor/fakes"
+	"code.cloudfoundry.org/lager/v3"
+	"code.cloudfoundry.org/lager/v3/lagertest"
+	"code.cloudfoundry.org/rep/heartbeat"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Beater", func() {
+	var (
+		logger         *lagertest.TestLogger
+		executorClient *fakeexecutor.FakeClient
+		beater         *heartbeat.Beater
+	)
+
+	beats := func() []lager.LogFormat {
+		var logs []lager.LogFormat
+		for _, log := range logger.Logs() {
+			if log.Message == "test.heartbeat.beat" {
+				logs = append(logs, log)
+			}
+		}
+		return logs
+	}
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("test")
+		executorClient = new(fakeexecutor.FakeClient)
+
+		executorClient.ListContainersReturns([]executor.Container{
+			{Guid: "running-1", State: executor.StateRunning},
+			{Guid: "running-2", State: executor.StateRunning},
+			{Guid: "reserved", State: executor.StateReserved},
+		}, nil)
+		executorClient.RemainingResourcesReturns(executor.ExecutorResources{MemoryMB: 512, DiskMB: 1024, Containers: 7}, nil)
+	})
+
+	JustBeforeEach(func() {
+		beater = heartbeat.NewBeater(logger, executorClient, "cell-id")
+	})
+
+	It("logs the cell id, running container count and remaining capacity", func() {
+		beater.Beat()
+		Expect(beats()).To(HaveLen(1))
+
+		data := beats()[0].Data
+		Expect(data).To(HaveKeyWithValue("cell-id", "cell-id"))
+		Expect(data).To(HaveKeyWithValue("running-containers", BeNumerically("==", 2)))
+		Expect(data).To(HaveKeyWithValue("remaining-memory-mb", BeNumerically("==", 512)))
+		Expect(data).To(HaveKeyWithValue("remaining-disk-mb", BeNumerically("==", 1024)))
+		Expect(data).To(HaveKeyWithValue("remaining-containers", BeNumerically("==", 7)))
+	})
+
+	Context("when the executor cannot be queried", func() {
+		BeforeEach(func() {
+			executorClient.ListContainersReturns(nil, errors.New("boom"))
+		})
+
+		It("still beats with what it knows", func() {
+			beater.Beat()
+			Expect(beats()).To(HaveLen(1))
+			Expect(beats()[0].Data).NotTo(HaveKey("running-containers"))
+			Expect(beats()[0].Data).To(HaveKey("remaining-memory-mb"))
+		})
+	})
+})
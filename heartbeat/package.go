@@ -0,0 +1 @@
+package heartbeat // import "code.cloudfoundry.org/rep/heartbeat"
@@ -0,0 +1 @@
+package periodic // import "code.cloudfoundry.org/rep/periodic"
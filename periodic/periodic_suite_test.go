@@ -0,0 +1,12 @@
+package periodic_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"testing"
+)
+
+func TestPeriodic(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Periodic Suite")
+}
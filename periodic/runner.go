@@ -0,0 +1,41 @@
+package periodic
+
+import (
+	"os"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+)
+
+// Runner is an ifrit.Runner calling report once every interval until it is
+// signalled. It is ready as soon as it starts, and the first report comes
+// one interval later.
+type Runner struct {
+	clock    clock.Clock
+	interval time.Duration
+	report   func()
+}
+
+func NewRunner(clock clock.Clock, interval time.Duration, report func()) *Runner {
+	return &Runner{
+		clock:    clock,
+		interval: interval,
+		report:   report,
+	}
+}
+
+func (r *Runner) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	close(ready)
+
+	ticker := r.clock.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-signals:
+			return nil
+		case <-ticker.C():
+			r.report()
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package periodic_test
+
+import (
+	"os"
+	"sync/atomic"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/rep/periodic"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/tedsuo/ifrit"
+)
+
+var _ = Describe("Runner", func() {
+	const interval = 30 * time.Second
+
+	var (
+		fakeClock *fakeclock.FakeClock
+		reports   int32
+		process   ifrit.Process
+	)
+
+	BeforeEach(func() {
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		atomic.StoreInt32(&reports, 0)
+		process = ifrit.Invoke(periodic.NewRunner(fakeClock, interval, func() {
+			atomic.AddInt32(&reports, 1)
+		}))
+	})
+
+	AfterEach(func() {
+		process.Signal(os.Interrupt)
+		Eventually(process.Wait()).Should(Receive())
+	})
+
+	reportCount := func() int32 {
+		return atomic.LoadInt32(&reports)
+	}
+
+	It("does not report before the interval elapses", func() {
+		fakeClock.WaitForWatcherAndIncrement(interval - time.Second)
+		Consistently(reportCount).Should(BeZero())
+	})
+
+	It("reports once per interval", func() {
+		fakeClock.WaitForWatcherAndIncrement(interval)
+		Eventually(reportCount).Should(BeEquivalentTo(1))
+
+		fakeClock.WaitForWatcherAndIncrement(interval)
+		Eventually(reportCount).Should(BeEquivalentTo(2))
+	})
+
+	It("exits cleanly when signalled", func() {
+		process.Signal(os.Interrupt)
+		Eventually(process.Wait()).Should(Receive(BeNil()))
+		Expect(fakeClock.WatcherCount()).To(BeZero())
+	})
+})
@@ -0,0 +1,97 @@
+package presence
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	loggingclient "code.cloudfoundry.org/diego-logging-client"
+	"code.cloudfoundry.org/lager/v3"
+	"code.cloudfoundry.org/locket/models"
+	"google.golang.org/grpc"
+)
+
+const (
+	presenceAcquiredEvent = "CellPresenceAcquired"
+	presenceLostEvent     = "CellPresenceLost"
+)
+
+// auditingLocketClient wraps a locket client and records an audit entry every
+// time the cell presence lock transitions between held and not held.
+type auditingLocketClient struct {
+	models.LocketClient
+
+	logger       lager.Logger
+	clock        clock.Clock
+	metronClient loggingclient.IngressClient
+	cellID       string
+	owner        string
+
+	lock sync.Mutex
+	held bool
+}
+
+// NewAuditingLocketClient returns a locket client that logs (and, when
+// metronClient is non-nil, emits a counter event) whenever the presence for
+// cellID is acquired or lost.
+func NewAuditingLocketClient(
+	logger lager.Logger,
+	clock clock.Clock,
+	metronClient loggingclient.IngressClient,
+	client models.LocketClient,
+	cellID string,
+	owner string,
+) models.LocketClient {
+	return &auditingLocketClient{
+		LocketClient: client,
+		logger:       logger.Session("presence-audit"),
+		clock:        clock,
+		metronClient: metronClient,
+		cellID:       cellID,
+		owner:        owner,
+	}
+}
+
+func (c *auditingLocketClient) Lock(ctx context.Context, in *models.LockRequest, opts ...grpc.CallOption) (*models.LockResponse, error) {
+	resp, err := c.LocketClient.Lock(ctx, in, opts...)
+	if err != nil {
+		c.transition(false, presenceLostEvent, "presence-lost", lager.Data{"error": err.Error()})
+	} else {
+		c.transition(true, presenceAcquiredEvent, "presence-acquired", lager.Data{})
+	}
+	return resp, err
+}
+
+func (c *auditingLocketClient) Release(ctx context.Context, in *models.ReleaseRequest, opts ...grpc.CallOption) (*models.ReleaseResponse, error) {
+	resp, err := c.LocketClient.Release(ctx, in, opts...)
+	if err == nil {
+		c.transition(false, presenceLostEvent, "presence-released", lager.Data{})
+	}
+	return resp, err
+}
+
+func (c *auditingLocketClient) transition(held bool, event, message string, data lager.Data) {
+	c.lock.Lock()
+	changed := c.held != held
+	c.held = held
+	c.lock.Unlock()
+
+	if !changed {
+		return
+	}
+
+	data["cell-id"] = c.cellID
+	data["owner"] = c.owner
+	data["timestamp"] = c.clock.Now().UTC().Format(time.RFC3339Nano)
+	c.logger.Info(message, data)
+
+	if c.metronClient == nil {
+		return
+	}
+
+	err := c.metronClient.IncrementCounter(event)
+	if err != nil {
+		c.logger.Debug("failed-to-emit-audit-event", lager.Data{"event": event, "error": err.Error()})
+	}
+}
@@ -0,0 +1,134 @@
+package presence_test
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	mfakes "code.cloudfoundry.org/diego-logging-client/testhelpers"
+	"code.cloudfoundry.org/lager/v3/lagertest"
+	"code.cloudfoundry.org/locket/models"
+	"code.cloudfoundry.org/locket/models/modelsfakes"
+	"code.cloudfoundry.org/rep/presence"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AuditingLocketClient", func() {
+	var (
+		logger           *lagertest.TestLogger
+		fakeClock        *fakeclock.FakeClock
+		fakeLocketClient *modelsfakes.FakeLocketClient
+		fakeMetronClient *mfakes.FakeIngressClient
+		client           models.LocketClient
+		lockRequest      *models.LockRequest
+	)
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("test")
+		fakeClock = fakeclock.NewFakeClock(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+		fakeLocketClient = new(modelsfakes.FakeLocketClient)
+		fakeMetronClient = new(mfakes.FakeIngressClient)
+		lockRequest = &models.LockRequest{Resource: &models.Resource{Key: "cell-id", Owner: "owner-guid"}}
+
+		client = presence.NewAuditingLocketClient(logger, fakeClock, fakeMetronClient, fakeLocketClient, "cell-id", "owner-guid")
+	})
+
+	It("delegates lock requests to the wrapped client", func() {
+		_, err := client.Lock(context.Background(), lockRequest)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(fakeLocketClient.LockCallCount()).To(Equal(1))
+		_, req, _ := fakeLocketClient.LockArgsForCall(0)
+		Expect(req).To(Equal(lockRequest))
+	})
+
+	Context("when the presence is acquired", func() {
+		BeforeEach(func() {
+			_, err := client.Lock(context.Background(), lockRequest)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("logs an audit entry with the cell id, owner and timestamp", func() {
+			Expect(logger.LogMessages()).To(Equal([]string{"test.presence-audit.presence-acquired"}))
+			data := logger.Logs()[0].Data
+			Expect(data["cell-id"]).To(Equal("cell-id"))
+			Expect(data["owner"]).To(Equal("owner-guid"))
+			Expect(data["timestamp"]).To(Equal("2026-01-02T03:04:05Z"))
+		})
+
+		It("emits an acquired event", func() {
+			Expect(fakeMetronClient.IncrementCounterCallCount()).To(Equal(1))
+			Expect(fakeMetronClient.IncrementCounterArgsForCall(0)).To(Equal("CellPresenceAcquired"))
+		})
+
+		It("does not audit subsequent successful renewals", func() {
+			_, err := client.Lock(context.Background(), lockRequest)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(logger.LogMessages()).To(HaveLen(1))
+			Expect(fakeMetronClient.IncrementCounterCallCount()).To(Equal(1))
+		})
+
+		Context("and then lost", func() {
+			BeforeEach(func() {
+				fakeLocketClient.LockReturns(nil, errors.New("lock-collision"))
+				_, err := client.Lock(context.Background(), lockRequest)
+				Expect(err).To(HaveOccurred())
+			})
+
+			It("logs an audit entry for the loss", func() {
+				Expect(logger.LogMessages()).To(Equal([]string{
+					"test.presence-audit.presence-acquired",
+					"test.presence-audit.presence-lost",
+				}))
+				data := logger.Logs()[1].Data
+				Expect(data["cell-id"]).To(Equal("cell-id"))
+				Expect(data["owner"]).To(Equal("owner-guid"))
+				Expect(data["error"]).To(Equal("lock-collision"))
+			})
+
+			It("emits a lost event", func() {
+				Expect(fakeMetronClient.IncrementCounterCallCount()).To(Equal(2))
+				Expect(fakeMetronClient.IncrementCounterArgsForCall(1)).To(Equal("CellPresenceLost"))
+			})
+		})
+
+		Context("and then released", func() {
+			BeforeEach(func() {
+				_, err := client.Release(context.Background(), &models.ReleaseRequest{Resource: lockRequest.Resource})
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("logs an audit entry for the release", func() {
+				Expect(logger.LogMessages()).To(ContainElement("test.presence-audit.presence-released"))
+			})
+		})
+	})
+
+	Context("when the presence was never acquired and locking fails", func() {
+		BeforeEach(func() {
+			fakeLocketClient.LockReturns(nil, errors.New("boom"))
+			_, err := client.Lock(context.Background(), lockRequest)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("does not audit anything", func() {
+			Expect(logger.LogMessages()).To(BeEmpty())
+			Expect(fakeMetronClient.IncrementCounterCallCount()).To(Equal(0))
+		})
+	})
+
+	Context("when no metron client is provided", func() {
+		BeforeEach(func() {
+			client = presence.NewAuditingLocketClient(logger, fakeClock, nil, fakeLocketClient, "cell-id", "owner-guid")
+		})
+
+		It("still logs the audit entry", func() {
+			_, err := client.Lock(context.Background(), lockRequest)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(logger.LogMessages()).To(ContainElement("test.presence-audit.presence-acquired"))
+		})
+	})
+})
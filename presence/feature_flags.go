@@ -0,0 +1,50 @@
+package presence
+
+import (
+	"strconv"
+	"strings"
+)
+
+// FeatureFlagAnnotationPrefix prefixes the cell annotations that carry the
+// cell's feature flags. The cell presence has no dedicated field for them, so
+// they travel alongside the operator supplied annotations.
+const FeatureFlagAnnotationPrefix = "feature-flag/"
+
+// AnnotateFeatureFlags returns a copy of annotations with one entry per
+// feature flag added. A flag wins over an operator annotation with the same
+// key.
+func AnnotateFeatureFlags(annotations map[string]string, flags map[string]bool) map[string]string {
+	if len(flags) == 0 {
+		return annotations
+	}
+
+	annotated := make(map[string]string, len(annotations)+len(flags))
+	for key, value := range annotations {
+		annotated[key] = value
+	}
+	for flag, enabled := range flags {
+		annotated[FeatureFlagAnnotationPrefix+flag] = strconv.FormatBool(enabled)
+	}
+
+	return annotated
+}
+
+// FeatureFlagsFromAnnotations extracts the feature flags advertised in a
+// cell's annotations. Annotations whose value is not a boolean are ignored.
+func FeatureFlagsFromAnnotations(annotations map[string]string) map[string]bool {
+	flags := map[string]bool{}
+	for key, value := range annotations {
+		flag := strings.TrimPrefix(key, FeatureFlagAnnotationPrefix)
+		if flag == key || flag == "" {
+			continue
+		}
+
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			continue
+		}
+		flags[flag] = enabled
+	}
+
+	return flags
+}
@@ -0,0 +1,64 @@
+package presence_test
+
+import (
+	"encoding/json"
+
+	"code.cloudfoundry.org/rep/presence"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FeatureFlags", func() {
+	var (
+		annotations map[string]string
+		flags       map[string]bool
+	)
+
+	BeforeEach(func() {
+		annotations = map[string]string{"rack": "r1"}
+		flags = map[string]bool{"fast-start": true, "new-allocator": false}
+	})
+
+	It("adds the flags to the annotations without dropping the existing ones", func() {
+		annotated := presence.AnnotateFeatureFlags(annotations, flags)
+		Expect(annotated).To(Equal(map[string]string{
+			"rack":                       "r1",
+			"feature-flag/fast-start":    "true",
+			"feature-flag/new-allocator": "false",
+		}))
+		Expect(annotations).To(Equal(map[string]string{"rack": "r1"}))
+	})
+
+	It("round-trips the flags through an encoded presence payload", func() {
+		payload, err := json.Marshal(map[string]interface{}{
+			"cell_id":     "cell-id",
+			"annotations": presence.AnnotateFeatureFlags(annotations, flags),
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		value, err := presence.EncodePayload(payload, true, 1)
+		Expect(err).NotTo(HaveOccurred())
+
+		decoded, err := presence.DecodePayload(value)
+		Expect(err).NotTo(HaveOccurred())
+
+		var cellPresence struct {
+			Annotations map[string]string `json:"annotations"`
+		}
+		Expect(json.Unmarshal(decoded, &cellPresence)).To(Succeed())
+		Expect(presence.FeatureFlagsFromAnnotations(cellPresence.Annotations)).To(Equal(flags))
+	})
+
+	It("leaves the annotations untouched when there are no flags", func() {
+		Expect(presence.AnnotateFeatureFlags(annotations, nil)).To(Equal(annotations))
+	})
+
+	It("ignores annotations that are not boolean feature flags", func() {
+		Expect(presence.FeatureFlagsFromAnnotations(map[string]string{
+			"rack":                 "r1",
+			"feature-flag/":        "true",
+			"feature-flag/broken":  "maybe",
+			"feature-flag/enabled": "true",
+		})).To(Equal(map[string]bool{"enabled": true}))
+	})
+})
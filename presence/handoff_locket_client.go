@@ -0,0 +1,41 @@
+package presence
+
+import (
+	"context"
+	"sync/atomic"
+
+	"code.cloudfoundry.org/locket/models"
+	"google.golang.org/grpc"
+)
+
+// HandoffLocketClient passes lock calls through to a locket client until it
+// is retired. A retired client drops Lock and Release calls, so a presence
+// runner that was superseded by one with the same owner can be stopped
+// without overwriting or releasing the presence that replaced it.
+type HandoffLocketClient struct {
+	models.LocketClient
+
+	retired atomic.Bool
+}
+
+func NewHandoffLocketClient(client models.LocketClient) *HandoffLocketClient {
+	return &HandoffLocketClient{LocketClient: client}
+}
+
+func (c *HandoffLocketClient) Retire() {
+	c.retired.Store(true)
+}
+
+func (c *HandoffLocketClient) Lock(ctx context.Context, in *models.LockRequest, opts ...grpc.CallOption) (*models.LockResponse, error) {
+	if c.retired.Load() {
+		return &models.LockResponse{}, nil
+	}
+	return c.LocketClient.Lock(ctx, in, opts...)
+}
+
+func (c *HandoffLocketClient) Release(ctx context.Context, in *models.ReleaseRequest, opts ...grpc.CallOption) (*models.ReleaseResponse, error) {
+	if c.retired.Load() {
+		return &models.ReleaseResponse{}, nil
+	}
+	return c.LocketClient.Release(ctx, in, opts...)
+}
@@ -0,0 +1 @@
+package presence // import "code.cloudfoundry.org/rep/presence"
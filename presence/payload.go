@@ -0,0 +1,65 @@
+package presence
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"io"
+	"strings"
+)
+
+// CompressedPayloadPrefix marks a presence value whose payload was gzipped
+// and base64 encoded. Consumers seeing it must call DecodePayload before
+// unmarshalling the presence.
+const CompressedPayloadPrefix = "gzip+base64:"
+
+// DefaultCompressionThreshold is the payload size, in bytes, above which a
+// presence payload is compressed when no threshold is configured.
+const DefaultCompressionThreshold = 4096
+
+// EncodePayload returns the value to store in locket for payload. The payload
+// is only compressed when compress is set and it is larger than threshold;
+// smaller payloads are stored as-is so existing consumers keep working.
+func EncodePayload(payload []byte, compress bool, threshold int) (string, error) {
+	if threshold <= 0 {
+		threshold = DefaultCompressionThreshold
+	}
+
+	if !compress || len(payload) <= threshold {
+		return string(payload), nil
+	}
+
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	_, err := writer.Write(payload)
+	if err != nil {
+		return "", err
+	}
+
+	err = writer.Close()
+	if err != nil {
+		return "", err
+	}
+
+	return CompressedPayloadPrefix + base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// DecodePayload reverses EncodePayload, returning the raw presence payload.
+func DecodePayload(value string) ([]byte, error) {
+	if !strings.HasPrefix(value, CompressedPayloadPrefix) {
+		return []byte(value), nil
+	}
+
+	compressed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, CompressedPayloadPrefix))
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	return io.ReadAll(reader)
+}
@@ -0,0 +1,82 @@
+package presence
+
+import (
+	"encoding/json"
+	"strings"
+
+	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/lager/v3"
+)
+
+// PayloadSizeLimit configures the check on the size of the presence value
+// stored in locket. A zero WarningThreshold disables the check.
+type PayloadSizeLimit struct {
+	WarningThreshold int
+	Trim             bool
+}
+
+// EncodePresence marshals and encodes the cell presence for locket. When the
+// encoded value exceeds the warning threshold a warning is logged and, if
+// trimming is enabled, the operator-supplied annotations are dropped. The
+// annotations the rep itself advertises are kept since auctioneers rely on
+// them. The presence that was encoded is returned alongside the value.
+func EncodePresence(
+	logger lager.Logger,
+	cellPresence models.CellPresence,
+	compress bool,
+	compressionThreshold int,
+	limit PayloadSizeLimit,
+) (string, models.CellPresence, error) {
+	value, err := encodePresence(cellPresence, compress, compressionThreshold)
+	if err != nil {
+		return "", cellPresence, err
+	}
+
+	if limit.WarningThreshold <= 0 || len(value) <= limit.WarningThreshold {
+		return value, cellPresence, nil
+	}
+
+	logger.Info("presence-payload-exceeds-threshold", lager.Data{
+		"size":      len(value),
+		"threshold": limit.WarningThreshold,
+		"trim":      limit.Trim,
+	})
+	if !limit.Trim {
+		return value, cellPresence, nil
+	}
+
+	trimmed := cellPresence
+	trimmed.Annotations = repAnnotations(cellPresence.Annotations)
+	value, err = encodePresence(trimmed, compress, compressionThreshold)
+	if err != nil {
+		return "", cellPresence, err
+	}
+
+	logger.Info("trimmed-presence-payload", lager.Data{
+		"size":                 len(value),
+		"dropped-annotations":  len(cellPresence.Annotations) - len(trimmed.Annotations),
+		"still-over-threshold": len(value) > limit.WarningThreshold,
+	})
+	return value, trimmed, nil
+}
+
+func encodePresence(cellPresence models.CellPresence, compress bool, compressionThreshold int) (string, error) {
+	payload, err := json.Marshal(cellPresence)
+	if err != nil {
+		return "", err
+	}
+	return EncodePayload(payload, compress, compressionThreshold)
+}
+
+func repAnnotations(annotations map[string]string) map[string]string {
+	kept := map[string]string{}
+	for key, value := range annotations {
+		if strings.HasPrefix(key, FeatureFlagAnnotationPrefix) ||
+			key == PrivilegedContainersAnnotation ||
+			key == RegistryMirrorAnnotation ||
+			key == SecurityProfilesAnnotation {
+			kept[key] = value
+		}
+	}
+	return kept
+}
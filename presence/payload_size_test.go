@@ -0,0 +1,109 @@
+package presence_test
+
+import (
+	"encoding/json"
+	"strings"
+
+	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/lager/v3/lagertest"
+	"code.cloudfoundry.org/rep/presence"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("EncodePresence", func() {
+	var (
+		logger       *lagertest.TestLogger
+		cellPresence models.CellPresence
+		limit        presence.PayloadSizeLimit
+	)
+
+	decode := func(value string) models.CellPresence {
+		payload, err := presence.DecodePayload(value)
+		Expect(err).NotTo(HaveOccurred())
+
+		var decoded models.CellPresence
+		Expect(json.Unmarshal(payload, &decoded)).To(Succeed())
+		return decoded
+	}
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("test")
+		annotations := presence.AnnotateFeatureFlags(map[string]string{
+			"rack":        "r1",
+			"description": strings.Repeat("a", 8192),
+		}, map[string]bool{"some-flag": true})
+		annotations = presence.AnnotatePrivilegedContainers(annotations, false)
+		annotations = presence.AnnotateSecurityProfiles(annotations, []string{"seccomp/runtime-default"})
+		cellPresence = models.NewCellPresence("cell-id", "1.2.3.4", "https://cell-id.cell.service.cf.internal:1801",
+			"z1", models.NewCellCapacity(128, 1024, 10), nil, nil, nil, nil, nil, annotations)
+		limit = presence.PayloadSizeLimit{}
+	})
+
+	It("encodes the presence", func() {
+		value, encoded, err := presence.EncodePresence(logger, cellPresence, false, 0, limit)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(encoded).To(Equal(cellPresence))
+		Expect(decode(value)).To(Equal(cellPresence))
+		Expect(logger).NotTo(gbytes.Say("presence-payload-exceeds-threshold"))
+	})
+
+	Context("when the payload is under the warning threshold", func() {
+		BeforeEach(func() {
+			limit = presence.PayloadSizeLimit{WarningThreshold: 64 * 1024, Trim: true}
+		})
+
+		It("leaves the presence untouched", func() {
+			value, encoded, err := presence.EncodePresence(logger, cellPresence, false, 0, limit)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(encoded).To(Equal(cellPresence))
+			Expect(decode(value)).To(Equal(cellPresence))
+			Expect(logger).NotTo(gbytes.Say("presence-payload-exceeds-threshold"))
+		})
+	})
+
+	Context("when the payload exceeds the warning threshold", func() {
+		BeforeEach(func() {
+			limit = presence.PayloadSizeLimit{WarningThreshold: 4096}
+		})
+
+		It("warns but keeps the full presence", func() {
+			value, encoded, err := presence.EncodePresence(logger, cellPresence, false, 0, limit)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(encoded).To(Equal(cellPresence))
+			Expect(decode(value)).To(Equal(cellPresence))
+			Expect(logger).To(gbytes.Say("presence-payload-exceeds-threshold"))
+			Expect(logger).NotTo(gbytes.Say("trimmed-presence-payload"))
+		})
+
+		It("checks the size after compression", func() {
+			value, encoded, err := presence.EncodePresence(logger, cellPresence, true, 1024, limit)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(value).To(HavePrefix(presence.CompressedPayloadPrefix))
+			Expect(encoded).To(Equal(cellPresence))
+			Expect(logger).NotTo(gbytes.Say("presence-payload-exceeds-threshold"))
+		})
+
+		Context("and trimming is enabled", func() {
+			BeforeEach(func() {
+				limit.Trim = true
+			})
+
+			It("drops the operator annotations and keeps the ones the rep advertises", func() {
+				value, encoded, err := presence.EncodePresence(logger, cellPresence, false, 0, limit)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(len(value)).To(BeNumerically("<=", limit.WarningThreshold))
+				Expect(encoded.Annotations).To(Equal(map[string]string{
+					presence.FeatureFlagAnnotationPrefix + "some-flag": "true",
+					presence.PrivilegedContainersAnnotation:            "false",
+					presence.SecurityProfilesAnnotation:                "seccomp/runtime-default",
+				}))
+				Expect(decode(value)).To(Equal(encoded))
+				Expect(encoded.CellId).To(Equal(cellPresence.CellId))
+				Expect(logger).To(gbytes.Say("presence-payload-exceeds-threshold"))
+				Expect(logger).To(gbytes.Say("trimmed-presence-payload.*\"dropped-annotations\":2"))
+			})
+		})
+	})
+})
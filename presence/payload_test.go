@@ -0,0 +1,65 @@
+package presence_test
+
+import (
+	"strings"
+
+	"code.cloudfoundry.org/rep/presence"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Payload", func() {
+	var largePayload []byte
+
+	BeforeEach(func() {
+		largePayload = []byte(`{"cell_id":"cell-id","metadata":"` + strings.Repeat("a", 8192) + `"}`)
+	})
+
+	Context("when compression is enabled", func() {
+		It("compresses payloads above the threshold and round-trips them", func() {
+			value, err := presence.EncodePayload(largePayload, true, 1024)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(value).To(HavePrefix(presence.CompressedPayloadPrefix))
+			Expect(len(value)).To(BeNumerically("<", len(largePayload)))
+
+			decoded, err := presence.DecodePayload(value)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(decoded).To(Equal(largePayload))
+		})
+
+		It("leaves payloads at or below the threshold uncompressed", func() {
+			payload := []byte(`{"cell_id":"cell-id"}`)
+			value, err := presence.EncodePayload(payload, true, len(payload))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(value).To(Equal(string(payload)))
+		})
+
+		It("uses the default threshold when none is configured", func() {
+			payload := []byte(strings.Repeat("a", presence.DefaultCompressionThreshold))
+			value, err := presence.EncodePayload(payload, true, 0)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(value).To(Equal(string(payload)))
+		})
+	})
+
+	Context("when compression is disabled", func() {
+		It("never compresses the payload", func() {
+			value, err := presence.EncodePayload(largePayload, false, 1024)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(value).To(Equal(string(largePayload)))
+		})
+	})
+
+	Describe("DecodePayload", func() {
+		It("returns uncompressed values unchanged", func() {
+			decoded, err := presence.DecodePayload(`{"cell_id":"cell-id"}`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(decoded).To(MatchJSON(`{"cell_id":"cell-id"}`))
+		})
+
+		It("errors on corrupt compressed values", func() {
+			_, err := presence.DecodePayload(presence.CompressedPayloadPrefix + "not-base64!")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,30 @@
+package presence
+
+import "strconv"
+
+// PrivilegedContainersAnnotation is the cell annotation advertising whether
+// the cell runs work requiring privileged containers.
+const PrivilegedContainersAnnotation = "privileged-containers"
+
+// AnnotatePrivilegedContainers returns a copy of annotations advertising
+// whether privileged containers are allowed on the cell.
+func AnnotatePrivilegedContainers(annotations map[string]string, allowed bool) map[string]string {
+	annotated := make(map[string]string, len(annotations)+1)
+	for key, value := range annotations {
+		annotated[key] = value
+	}
+	annotated[PrivilegedContainersAnnotation] = strconv.FormatBool(allowed)
+
+	return annotated
+}
+
+// PrivilegedContainersAllowed reports whether a cell's annotations allow
+// privileged containers. Cells that do not advertise it predate the setting
+// and always allowed them.
+func PrivilegedContainersAllowed(annotations map[string]string) bool {
+	allowed, err := strconv.ParseBool(annotations[PrivilegedContainersAnnotation])
+	if err != nil {
+		return true
+	}
+	return allowed
+}
@@ -0,0 +1,35 @@
+package presence_test
+
+import (
+	"code.cloudfoundry.org/rep/presence"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PrivilegedContainers", func() {
+	var annotations map[string]string
+
+	BeforeEach(func() {
+		annotations = map[string]string{"rack": "r1"}
+	})
+
+	It("advertises a cell allowing privileged containers", func() {
+		annotated := presence.AnnotatePrivilegedContainers(annotations, true)
+		Expect(annotated).To(Equal(map[string]string{
+			"rack":                  "r1",
+			"privileged-containers": "true",
+		}))
+		Expect(annotations).To(Equal(map[string]string{"rack": "r1"}))
+		Expect(presence.PrivilegedContainersAllowed(annotated)).To(BeTrue())
+	})
+
+	It("advertises a cell refusing privileged containers", func() {
+		annotated := presence.AnnotatePrivilegedContainers(annotations, false)
+		Expect(annotated).To(HaveKeyWithValue("privileged-containers", "false"))
+		Expect(presence.PrivilegedContainersAllowed(annotated)).To(BeFalse())
+	})
+
+	It("treats cells that do not advertise it as allowing privileged containers", func() {
+		Expect(presence.PrivilegedContainersAllowed(annotations)).To(BeTrue())
+	})
+})
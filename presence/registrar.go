@@ -0,0 +1,225 @@
+package presence
+
+import (
+	"errors"
+	"os"
+	"sync"
+
+	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/lager/v3"
+	"github.com/tedsuo/ifrit"
+)
+
+var (
+	ErrRegistrarNotRunning = errors.New("presence registrar is not running")
+	ErrRenewalNotSupported = errors.New("presence runner does not support renewal")
+	ErrPresenceExited      = errors.New("presence runner exited before it was ready")
+)
+
+// BuildFunc constructs the cell presence and the runner maintaining it in
+// locket.
+type BuildFunc func() (ifrit.Runner, models.CellPresence, error)
+
+// Retirer is implemented by presence runners that can be stopped without
+// releasing their lock, because a rebuilt presence with the same owner has
+// taken it over.
+type Retirer interface {
+	Retire()
+}
+
+// Registrar is an ifrit.Runner maintaining the cell presence. Unlike a bare
+// presence runner it can be asked to rebuild the presence and publish it
+// again without restarting the rep.
+type Registrar struct {
+	logger     lager.Logger
+	build      BuildFunc
+	reregister chan chan reregistration
+	stopped    chan struct{}
+
+	lock      sync.RWMutex
+	presence  models.CellPresence
+	runner    ifrit.Runner
+	published bool
+}
+
+type reregistration struct {
+	presence models.CellPresence
+	err      error
+}
+
+type publication struct {
+	runner   ifrit.Runner
+	presence models.CellPresence
+	process  ifrit.Process
+	exited   <-chan error
+}
+
+func NewRegistrar(logger lager.Logger, build BuildFunc) *Registrar {
+	return &Registrar{
+		logger:     logger.Session("presence-registrar"),
+		build:      build,
+		reregister: make(chan chan reregistration),
+		stopped:    make(chan struct{}),
+	}
+}
+
+func (r *Registrar) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	defer close(r.stopped)
+
+	current, signal, err := r.start(signals, false)
+	if err != nil || signal != nil {
+		return err
+	}
+	r.set(current)
+	close(ready)
+
+	for {
+		select {
+		case signal := <-signals:
+			current.process.Signal(signal)
+			return <-current.exited
+
+		case err := <-current.exited:
+			return err
+
+		case reply := <-r.reregister:
+			r.logger.Info("reregistering")
+			next, signal, err := r.start(signals, true)
+			if signal != nil {
+				current.process.Signal(signal)
+				return <-current.exited
+			}
+			if err != nil {
+				r.logger.Error("failed-to-reregister", err)
+				reply <- reregistration{err: err}
+				continue
+			}
+
+			// The rebuilt presence holds the lock now, so the current one
+			// is stopped without releasing it.
+			r.set(next)
+			current.retire()
+			current.process.Signal(os.Interrupt)
+			select {
+			case <-current.exited:
+			case signal := <-signals:
+				next.process.Signal(signal)
+				<-current.exited
+				return <-next.exited
+			}
+
+			current = next
+			reply <- reregistration{presence: next.presence}
+			r.logger.Info("reregistered")
+		}
+	}
+}
+
+// Presence returns the presence most recently published, and false when
+// nothing was published yet.
+func (r *Registrar) Presence() (models.CellPresence, bool) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	return r.presence, r.published
+}
+
+// Reregister rebuilds the presence and publishes it, and only then stops
+// the one published before. The previous presence stays published when the
+// rebuild fails. It returns the newly published presence.
+func (r *Registrar) Reregister(logger lager.Logger) (models.CellPresence, error) {
+	if _, published := r.Presence(); !published {
+		return models.CellPresence{}, ErrRegistrarNotRunning
+	}
+
+	reply := make(chan reregistration, 1)
+	select {
+	case r.reregister <- reply:
+	case <-r.stopped:
+		return models.CellPresence{}, ErrRegistrarNotRunning
+	}
+
+	var result reregistration
+	select {
+	case result = <-reply:
+	case <-r.stopped:
+		return models.CellPresence{}, ErrRegistrarNotRunning
+	}
+	if result.err != nil {
+		return models.CellPresence{}, result.err
+	}
+
+	logger.Info("reregistered-presence", lager.Data{"cell-id": result.presence.CellId})
+	return result.presence, nil
+}
+
+// Renew renews the lock on the published presence immediately, without
+// rebuilding it.
+func (r *Registrar) Renew(logger lager.Logger) error {
+	r.lock.RLock()
+	runner, published := r.runner, r.published
+	r.lock.RUnlock()
+
+	if !published {
+		return ErrRegistrarNotRunning
+	}
+
+	renewer, ok := runner.(Renewer)
+	if !ok {
+		return ErrRenewalNotSupported
+	}
+	return renewer.Renew(logger)
+}
+
+// start builds a presence and runs it until it is ready. When confirm is
+// set, the lock is renewed right away so a rebuilt presence is known to be
+// published before the previous one is stopped. It returns the signal
+// received while starting, if any.
+func (r *Registrar) start(signals <-chan os.Signal, confirm bool) (*publication, os.Signal, error) {
+	runner, cellPresence, err := r.build()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	process := ifrit.Background(runner)
+	p := &publication{runner: runner, presence: cellPresence, process: process, exited: process.Wait()}
+
+	select {
+	case <-process.Ready():
+	case err := <-p.exited:
+		if err == nil {
+			err = ErrPresenceExited
+		}
+		return nil, nil, err
+	case signal := <-signals:
+		p.retire()
+		process.Signal(signal)
+		<-p.exited
+		return nil, signal, nil
+	}
+
+	if renewer, ok := runner.(Renewer); confirm && ok {
+		err = renewer.Renew(r.logger)
+		if err != nil {
+			p.retire()
+			process.Signal(os.Interrupt)
+			<-p.exited
+			return nil, nil, err
+		}
+	}
+
+	return p, nil, nil
+}
+
+func (r *Registrar) set(p *publication) {
+	r.lock.Lock()
+	r.presence = p.presence
+	r.runner = p.runner
+	r.published = true
+	r.lock.Unlock()
+}
+
+func (p *publication) retire() {
+	if retirer, ok := p.runner.(Retirer); ok {
+		retirer.Retire()
+	}
+}
@@ -0,0 +1,197 @@
+package presence_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync/atomic"
+
+	"code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/lager/v3/lagertest"
+	locketmodels "code.cloudfoundry.org/locket/models"
+	"code.cloudfoundry.org/locket/models/modelsfakes"
+	"code.cloudfoundry.org/rep/presence"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/tedsuo/ifrit"
+	ginkgomon "github.com/tedsuo/ifrit/ginkgomon_v2"
+)
+
+var _ = Describe("Registrar", func() {
+	var (
+		logger    *lagertest.TestLogger
+		builds    int32
+		started   chan int32
+		signaled  chan int32
+		holdExit  chan struct{}
+		exitErr   error
+		buildErr  error
+		registrar *presence.Registrar
+		process   ifrit.Process
+	)
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("test")
+		builds = 0
+		started = make(chan int32, 10)
+		signaled = make(chan int32, 10)
+		holdExit = nil
+		exitErr = nil
+		buildErr = nil
+
+		registrar = presence.NewRegistrar(logger, func() (ifrit.Runner, models.CellPresence, error) {
+			if buildErr != nil && atomic.LoadInt32(&builds) > 0 {
+				return nil, models.CellPresence{}, buildErr
+			}
+
+			build := atomic.AddInt32(&builds, 1)
+			hold := holdExit
+			runner := ifrit.RunFunc(func(signals <-chan os.Signal, ready chan<- struct{}) error {
+				started <- build
+				close(ready)
+				<-signals
+				signaled <- build
+				if hold != nil && build == 1 {
+					<-hold
+				}
+				return exitErr
+			})
+			return runner, models.CellPresence{CellId: "cell-id", Zone: string(rune('a' + build - 1))}, nil
+		})
+	})
+
+	Context("before it runs", func() {
+		It("has no presence", func() {
+			_, published := registrar.Presence()
+			Expect(published).To(BeFalse())
+		})
+
+		It("cannot reregister", func() {
+			_, err := registrar.Reregister(logger)
+			Expect(err).To(Equal(presence.ErrRegistrarNotRunning))
+		})
+	})
+
+	Context("when the first build fails", func() {
+		BeforeEach(func() {
+			registrar = presence.NewRegistrar(logger, func() (ifrit.Runner, models.CellPresence, error) {
+				return nil, models.CellPresence{}, errors.New("boom")
+			})
+		})
+
+		It("exits with its error", func() {
+			process = ifrit.Background(registrar)
+			Eventually(process.Wait()).Should(Receive(MatchError("boom")))
+		})
+	})
+
+	Context("when running", func() {
+		JustBeforeEach(func() {
+			process = ginkgomon.Invoke(registrar)
+			Expect(started).To(Receive(Equal(int32(1))))
+		})
+
+		AfterEach(func() {
+			ginkgomon.Interrupt(process)
+		})
+
+		It("publishes the built presence", func() {
+			cellPresence, published := registrar.Presence()
+			Expect(published).To(BeTrue())
+			Expect(cellPresence.Zone).To(Equal("a"))
+		})
+
+		It("publishes a rebuilt presence before stopping the previous one", func() {
+			cellPresence, err := registrar.Reregister(logger)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cellPresence.Zone).To(Equal("b"))
+			Expect(started).To(Receive(Equal(int32(2))))
+			Expect(signaled).To(Receive(Equal(int32(1))))
+
+			current, _ := registrar.Presence()
+			Expect(current).To(Equal(cellPresence))
+			Consistently(process.Wait()).ShouldNot(Receive())
+		})
+
+		Context("when rebuilding fails", func() {
+			BeforeEach(func() {
+				buildErr = errors.New("executor unavailable")
+			})
+
+			It("keeps the previous presence", func() {
+				_, err := registrar.Reregister(logger)
+				Expect(err).To(MatchError("executor unavailable"))
+				Expect(signaled).NotTo(Receive())
+
+				current, _ := registrar.Presence()
+				Expect(current.Zone).To(Equal("a"))
+				Consistently(process.Wait()).ShouldNot(Receive())
+			})
+		})
+
+		Context("when signaled while the previous presence is stopping", func() {
+			BeforeEach(func() {
+				holdExit = make(chan struct{})
+			})
+
+			It("stops the rebuilt presence too", func() {
+				go registrar.Reregister(logger)
+				Eventually(signaled).Should(Receive(Equal(int32(1))))
+
+				process.Signal(os.Interrupt)
+				Eventually(signaled).Should(Receive(Equal(int32(2))))
+
+				close(holdExit)
+				Eventually(process.Wait()).Should(Receive(BeNil()))
+			})
+		})
+
+		It("releases the presence when signaled", func() {
+			process.Signal(os.Interrupt)
+			Eventually(process.Wait()).Should(Receive(BeNil()))
+			Expect(signaled).To(Receive(Equal(int32(1))))
+
+			_, err := registrar.Reregister(logger)
+			Expect(err).To(Equal(presence.ErrRegistrarNotRunning))
+		})
+	})
+
+	Context("when the presence runner fails", func() {
+		BeforeEach(func() {
+			exitErr = errors.New("boom")
+			process = ginkgomon.Invoke(registrar)
+		})
+
+		It("exits with its error", func() {
+			process.Signal(os.Interrupt)
+			Eventually(process.Wait()).Should(Receive(MatchError("boom")))
+		})
+	})
+})
+
+var _ = Describe("HandoffLocketClient", func() {
+	var (
+		fakeLocketClient *modelsfakes.FakeLocketClient
+		client           *presence.HandoffLocketClient
+	)
+
+	BeforeEach(func() {
+		fakeLocketClient = new(modelsfakes.FakeLocketClient)
+		client = presence.NewHandoffLocketClient(fakeLocketClient)
+	})
+
+	It("passes lock calls through until it is retired", func() {
+		_, err := client.Lock(context.Background(), &locketmodels.LockRequest{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fakeLocketClient.LockCallCount()).To(Equal(1))
+
+		client.Retire()
+
+		_, err = client.Lock(context.Background(), &locketmodels.LockRequest{})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = client.Release(context.Background(), &locketmodels.ReleaseRequest{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fakeLocketClient.LockCallCount()).To(Equal(1))
+		Expect(fakeLocketClient.ReleaseCallCount()).To(BeZero())
+	})
+})
@@ -0,0 +1,32 @@
+package presence
+
+// RegistryMirrorAnnotation is the cell annotation advertising the address of
+// a registry mirror running on the cell. Schedulers can prefer such cells for
+// images that are not cached elsewhere yet.
+const RegistryMirrorAnnotation = "local-registry-mirror"
+
+// AnnotateRegistryMirror returns a copy of annotations advertising mirror. An
+// empty mirror leaves the annotations untouched.
+func AnnotateRegistryMirror(annotations map[string]string, mirror string) map[string]string {
+	if mirror == "" {
+		return annotations
+	}
+
+	annotated := make(map[string]string, len(annotations)+1)
+	for key, value := range annotations {
+		annotated[key] = value
+	}
+	annotated[RegistryMirrorAnnotation] = mirror
+
+	return annotated
+}
+
+// RegistryMirrorFromAnnotations returns the registry mirror advertised in a
+// cell's annotations, if any.
+func RegistryMirrorFromAnnotations(annotations map[string]string) (string, bool) {
+	mirror, ok := annotations[RegistryMirrorAnnotation]
+	if !ok || mirror == "" {
+		return "", false
+	}
+	return mirror, true
+}
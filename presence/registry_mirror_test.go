@@ -0,0 +1,36 @@
+package presence_test
+
+import (
+	"code.cloudfoundry.org/rep/presence"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RegistryMirror", func() {
+	var annotations map[string]string
+
+	BeforeEach(func() {
+		annotations = map[string]string{"rack": "r1"}
+	})
+
+	It("advertises the mirror without dropping the existing annotations", func() {
+		annotated := presence.AnnotateRegistryMirror(annotations, "127.0.0.1:5000")
+		Expect(annotated).To(Equal(map[string]string{
+			"rack":                  "r1",
+			"local-registry-mirror": "127.0.0.1:5000",
+		}))
+		Expect(annotations).To(Equal(map[string]string{"rack": "r1"}))
+
+		mirror, ok := presence.RegistryMirrorFromAnnotations(annotated)
+		Expect(ok).To(BeTrue())
+		Expect(mirror).To(Equal("127.0.0.1:5000"))
+	})
+
+	It("does not advertise anything when there is no mirror", func() {
+		annotated := presence.AnnotateRegistryMirror(annotations, "")
+		Expect(annotated).To(Equal(annotations))
+
+		_, ok := presence.RegistryMirrorFromAnnotations(annotated)
+		Expect(ok).To(BeFalse())
+	})
+})
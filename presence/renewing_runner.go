@@ -0,0 +1,53 @@
+package presence
+
+import (
+	"context"
+
+	"code.cloudfoundry.org/lager/v3"
+	"code.cloudfoundry.org/locket/models"
+	"github.com/tedsuo/ifrit"
+)
+
+// Renewer renews the presence lock right away instead of waiting for the
+// next renewal interval.
+type Renewer interface {
+	Renew(logger lager.Logger) error
+}
+
+// RenewingRunner is a presence runner that can also be asked to renew the
+// lock it maintains immediately. The runner must lock through locketClient,
+// so that retiring it also silences the runner.
+type RenewingRunner struct {
+	ifrit.Runner
+
+	locketClient *HandoffLocketClient
+	resource     *models.Resource
+	ttlInSeconds int64
+}
+
+func NewRenewingRunner(runner ifrit.Runner, locketClient *HandoffLocketClient, resource *models.Resource, ttlInSeconds int64) *RenewingRunner {
+	return &RenewingRunner{
+		Runner:       runner,
+		locketClient: locketClient,
+		resource:     resource,
+		ttlInSeconds: ttlInSeconds,
+	}
+}
+
+func (r *RenewingRunner) Renew(logger lager.Logger) error {
+	_, err := r.locketClient.Lock(context.Background(), &models.LockRequest{
+		Resource:     r.resource,
+		TtlInSeconds: r.ttlInSeconds,
+	})
+	if err != nil {
+		logger.Error("failed-to-renew-presence", err, lager.Data{"key": r.resource.Key})
+		return err
+	}
+
+	logger.Info("renewed-presence", lager.Data{"key": r.resource.Key})
+	return nil
+}
+
+func (r *RenewingRunner) Retire() {
+	r.locketClient.Retire()
+}
@@ -0,0 +1,30 @@
+package presence
+
+import (
+	"sort"
+	"strings"
+)
+
+// SecurityProfilesAnnotation is the cell annotation advertising the
+// seccomp and AppArmor profiles the cell can apply to containers, as a
+// comma-separated list.
+const SecurityProfilesAnnotation = "security-profiles"
+
+// AnnotateSecurityProfiles returns a copy of annotations advertising
+// profiles. No profiles leaves the annotations untouched.
+func AnnotateSecurityProfiles(annotations map[string]string, profiles []string) map[string]string {
+	if len(profiles) == 0 {
+		return annotations
+	}
+
+	sorted := append([]string(nil), profiles...)
+	sort.Strings(sorted)
+
+	annotated := make(map[string]string, len(annotations)+1)
+	for key, value := range annotations {
+		annotated[key] = value
+	}
+	annotated[SecurityProfilesAnnotation] = strings.Join(sorted, ",")
+
+	return annotated
+}
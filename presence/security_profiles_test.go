@@ -0,0 +1,30 @@
+package presence_test
+
+import (
+	"code.cloudfoundry.org/rep/presence"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SecurityProfiles", func() {
+	var annotations map[string]string
+
+	BeforeEach(func() {
+		annotations = map[string]string{"rack": "r1"}
+	})
+
+	It("advertises the profiles without dropping the existing annotations", func() {
+		annotated := presence.AnnotateSecurityProfiles(annotations, []string{"seccomp/runtime-default", "apparmor/garden-default"})
+		Expect(annotated).To(Equal(map[string]string{
+			"rack":              "r1",
+			"security-profiles": "apparmor/garden-default,seccomp/runtime-default",
+		}))
+		Expect(annotations).To(Equal(map[string]string{"rack": "r1"}))
+	})
+
+	It("does not advertise anything when there are no profiles", func() {
+		annotated := presence.AnnotateSecurityProfiles(annotations, nil)
+		Expect(annotated).To(Equal(annotations))
+		Expect(annotated).NotTo(HaveKey(presence.SecurityProfilesAnnotation))
+	})
+})
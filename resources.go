@@ -10,6 +10,7 @@ import (
 	"strings"
 
 	bbsmodels "code.cloudfoundry.org/bbs/models"
+	"code.cloudfoundry.org/executor"
 	"code.cloudfoundry.org/executor/containermetrics"
 	"code.cloudfoundry.org/routing-info/internalroutes"
 )
@@ -122,15 +123,95 @@ type ContainerMetricsCollection struct {
 	Tasks  []TaskMetric `json:"tasks"`
 }
 
+// ContainerReservation is the amount of resources reserved for a container
+// when it was allocated, as opposed to what it is actually using.
+type ContainerReservation struct {
+	MemoryMB int `json:"memory_mb"`
+	DiskMB   int `json:"disk_mb"`
+	MaxPids  int `json:"max_pids"`
+}
+
+// ResourceAccounting breaks a cell's total capacity down into what the
+// system holds back, what is reserved for container proxies, what is
+// allocated to containers and what remains. Remaining is never negative;
+// Drift is how far it was raised to get there. Total is always the sum of
+// the other four, less Drift. Segments breaks down the capacity of each
+// isolation segment the cell has a limit for.
+type ResourceAccounting struct {
+	Total          Resources                  `json:"total"`
+	SystemReserved Resources                  `json:"system_reserved"`
+	ProxyReserved  Resources                  `json:"proxy_reserved"`
+	Allocated      Resources                  `json:"allocated"`
+	Remaining      Resources                  `json:"remaining"`
+	Drift          Resources                  `json:"drift"`
+	Segments       map[string]SegmentCapacity `json:"segments,omitempty"`
+}
+
+// SegmentCapacity is the capacity of the cell given to the isolation
+// segment of one placement tag, what its containers are allocated and what
+// remains of it.
+type SegmentCapacity struct {
+	Total     Resources `json:"total"`
+	Allocated Resources `json:"allocated"`
+	Remaining Resources `json:"remaining"`
+}
+
+// TaskSummary describes a task container on the cell.
+type TaskSummary struct {
+	TaskGuid  string         `json:"task_guid"`
+	Domain    string         `json:"domain"`
+	State     executor.State `json:"state"`
+	AuctionID string         `json:"auction_id,omitempty"`
+}
+
+// TaskCancellation is the outcome of cancelling one task of a domain. Error
+// is empty when the task was cancelled.
+type TaskCancellation struct {
+	TaskGuid string `json:"task_guid"`
+	Error    string `json:"error,omitempty"`
+}
+
+// LRPUsage is the resource usage of all the instances of an LRP on the cell,
+// summed across its containers.
+type LRPUsage struct {
+	ProcessGUID      string               `json:"process_guid"`
+	Instances        int                  `json:"instances"`
+	Reservation      ContainerReservation `json:"reservation"`
+	CPUUsageFraction float64              `json:"cpu_usage_fraction"`
+	MemoryUsageBytes uint64               `json:"memory_usage_bytes"`
+	MemoryQuotaBytes uint64               `json:"memory_quota_bytes"`
+	DiskUsageBytes   uint64               `json:"disk_usage_bytes"`
+	DiskQuotaBytes   uint64               `json:"disk_quota_bytes"`
+}
+
+// PlacementFit is whether a single LRP instance or task of a proposed
+// Perform would be placed on the cell, and why not when it would not.
+type PlacementFit struct {
+	Guid   string `json:"guid"`
+	Fits   bool   `json:"fits"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// PlacementValidation is the computed fit of every item of a proposed
+// Perform, and the capacity that would remain once the fitting items were
+// placed.
+type PlacementValidation struct {
+	LRPs      []PlacementFit `json:"lrps"`
+	Tasks     []PlacementFit `json:"tasks"`
+	Remaining Resources      `json:"remaining"`
+}
+
 type LRPMetric struct {
-	InstanceGUID string `json:"instance_guid"`
-	ProcessGUID  string `json:"process_guid"`
-	Index        int32  `json:"index"`
+	InstanceGUID string               `json:"instance_guid"`
+	ProcessGUID  string               `json:"process_guid"`
+	Index        int32                `json:"index"`
+	Reservation  ContainerReservation `json:"reservation"`
 	containermetrics.CachedContainerMetrics
 }
 
 type TaskMetric struct {
-	TaskGUID string `json:"task_guid"`
+	TaskGUID    string               `json:"task_guid"`
+	Reservation ContainerReservation `json:"reservation"`
 	containermetrics.CachedContainerMetrics
 }
 
@@ -3,19 +3,41 @@ package rep
 import "github.com/tedsuo/rata"
 
 const (
-	StateRoute            = "STATE"
-	ContainerMetricsRoute = "ContainerMetrics"
-	PerformRoute          = "PERFORM"
+	StateRoute              = "STATE"
+	ContainerMetricsRoute   = "ContainerMetrics"
+	DomainsRoute            = "Domains"
+	TasksRoute              = "Tasks"
+	SupportedProvidersRoute = "SupportedProviders"
+	PerformRoute            = "PERFORM"
+	LRPUsageRoute           = "LRPUsage"
 
 	UpdateLRPInstanceRoute    = "UpdateLRPInstance"
 	UpdateLRPInstanceRoute_r0 = "UpdateLRPInstance_r0"
 	StopLRPInstanceRoute      = "StopLRPInstance"
 	CancelTaskRoute           = "CancelTask"
+	CancelTasksByDomainRoute  = "CancelTasksByDomain"
 
 	SimResetRoute = "RESET"
 
-	PingRoute     = "Ping"
-	EvacuateRoute = "Evacuate"
+	PingRoute                  = "Ping"
+	EvacuateRoute              = "Evacuate"
+	ResourceAccountingRoute    = "ResourceAccounting"
+	PresencePayloadRoute       = "PresencePayload"
+	ReregisterPresenceRoute    = "ReregisterPresence"
+	UptimeRoute                = "Uptime"
+	EvacuationHistoryRoute     = "EvacuationHistory"
+	CellModeRoute              = "CellMode"
+	EvacuationEligibilityRoute = "EvacuationEligibility"
+	RuntimeRoute               = "Runtime"
+	TLSInfoRoute               = "TLSInfo"
+	SetCapacityFactorRoute     = "SetCapacityFactor"
+	LastCallerRoute            = "LastCaller"
+	SetReadOnlyModeRoute       = "SetReadOnlyMode"
+	AllocationsCSVRoute        = "AllocationsCSV"
+	ValidatePlacementRoute     = "ValidatePlacement"
+	RenewPresenceRoute         = "RenewPresence"
+	BBSClientInfoRoute         = "BBSClientInfo"
+	ExtraRootFSRoute           = "ExtraRootFS"
 )
 
 func NewRoutes(networkAccessible bool) rata.Routes {
@@ -25,12 +47,17 @@ func NewRoutes(networkAccessible bool) rata.Routes {
 		routes = append(routes,
 			rata.Route{Path: "/state", Method: "GET", Name: StateRoute},
 			rata.Route{Path: "/container_metrics", Method: "GET", Name: ContainerMetricsRoute},
+			rata.Route{Path: "/domains", Method: "GET", Name: DomainsRoute},
+			rata.Route{Path: "/tasks", Method: "GET", Name: TasksRoute},
+			rata.Route{Path: "/supported_providers", Method: "GET", Name: SupportedProvidersRoute},
 			rata.Route{Path: "/work", Method: "POST", Name: PerformRoute},
 
 			rata.Route{Path: "/v2/lrps/:process_guid/instances/:instance_guid", Method: "PUT", Name: UpdateLRPInstanceRoute},
 			rata.Route{Path: "/v1/lrps/:process_guid/instances/:instance_guid", Method: "PUT", Name: UpdateLRPInstanceRoute_r0},
 			rata.Route{Path: "/v1/lrps/:process_guid/instances/:instance_guid/stop", Method: "POST", Name: StopLRPInstanceRoute},
+			rata.Route{Path: "/v1/lrps/:process_guid/usage", Method: "GET", Name: LRPUsageRoute},
 			rata.Route{Path: "/v1/tasks/:task_guid/cancel", Method: "POST", Name: CancelTaskRoute},
+			rata.Route{Path: "/v1/domains/:domain/tasks/cancel", Method: "POST", Name: CancelTasksByDomainRoute},
 
 			rata.Route{Path: "/sim/reset", Method: "POST", Name: SimResetRoute},
 		)
@@ -38,6 +65,23 @@ func NewRoutes(networkAccessible bool) rata.Routes {
 		routes = append(routes,
 			rata.Route{Path: "/ping", Method: "GET", Name: PingRoute},
 			rata.Route{Path: "/evacuate", Method: "POST", Name: EvacuateRoute},
+			rata.Route{Path: "/resource_accounting", Method: "GET", Name: ResourceAccountingRoute},
+			rata.Route{Path: "/presence_payload", Method: "GET", Name: PresencePayloadRoute},
+			rata.Route{Path: "/presence/reregister", Method: "POST", Name: ReregisterPresenceRoute},
+			rata.Route{Path: "/presence/renew", Method: "POST", Name: RenewPresenceRoute},
+			rata.Route{Path: "/uptime", Method: "GET", Name: UptimeRoute},
+			rata.Route{Path: "/evacuation_history", Method: "GET", Name: EvacuationHistoryRoute},
+			rata.Route{Path: "/cell_mode", Method: "GET", Name: CellModeRoute},
+			rata.Route{Path: "/evacuation_eligibility", Method: "GET", Name: EvacuationEligibilityRoute},
+			rata.Route{Path: "/runtime", Method: "GET", Name: RuntimeRoute},
+			rata.Route{Path: "/tls_info", Method: "GET", Name: TLSInfoRoute},
+			rata.Route{Path: "/capacity_factor", Method: "POST", Name: SetCapacityFactorRoute},
+			rata.Route{Path: "/last_caller", Method: "GET", Name: LastCallerRoute},
+			rata.Route{Path: "/read_only", Method: "POST", Name: SetReadOnlyModeRoute},
+			rata.Route{Path: "/allocations.csv", Method: "GET", Name: AllocationsCSVRoute},
+			rata.Route{Path: "/validate_placement", Method: "POST", Name: ValidatePlacementRoute},
+			rata.Route{Path: "/bbs_client_info", Method: "GET", Name: BBSClientInfoRoute},
+			rata.Route{Path: "/extra_rootfs", Method: "GET", Name: ExtraRootFSRoute},
 		)
 	}
 	return routes
@@ -0,0 +1 @@
+package sessiontickets // import "code.cloudfoundry.org/rep/sessiontickets"
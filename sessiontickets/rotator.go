@@ -0,0 +1,92 @@
+package sessiontickets
+
+import (
+	"crypto/rand"
+	"crypto/tls"
+	"os"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/lager/v3"
+)
+
+// Rotator is an ifrit.Runner that periodically replaces the session ticket
+// key of a server's tls.Config with a freshly generated one. The previous key
+// is kept for decryption only, so sessions issued just before a rotation can
+// still be resumed until the next one.
+type Rotator struct {
+	logger    lager.Logger
+	clock     clock.Clock
+	tlsConfig *tls.Config
+	interval  time.Duration
+
+	keysLock sync.Mutex
+	keys     [][32]byte
+}
+
+// NewRotator constructs a Rotator for tlsConfig, which must be the config the
+// server actually serves with rather than a copy of it.
+func NewRotator(logger lager.Logger, clk clock.Clock, tlsConfig *tls.Config, interval time.Duration) *Rotator {
+	return &Rotator{
+		logger:    logger.Session("session-ticket-rotator"),
+		clock:     clk,
+		tlsConfig: tlsConfig,
+		interval:  interval,
+	}
+}
+
+// Run implements ifrit.Runner. The first key is installed before the runner
+// becomes ready, so no ticket is ever issued with the static default key.
+func (r *Rotator) Run(signals <-chan os.Signal, ready chan<- struct{}) error {
+	err := r.rotate()
+	if err != nil {
+		r.logger.Error("failed-to-generate-key", err)
+		return err
+	}
+	close(ready)
+
+	ticker := r.clock.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-signals:
+			return nil
+		case <-ticker.C():
+			err := r.rotate()
+			if err != nil {
+				r.logger.Error("failed-to-rotate-key", err)
+				continue
+			}
+			r.logger.Info("rotated")
+		}
+	}
+}
+
+// Keys returns the keys currently in use, newest first.
+func (r *Rotator) Keys() [][32]byte {
+	r.keysLock.Lock()
+	defer r.keysLock.Unlock()
+	return append([][32]byte(nil), r.keys...)
+}
+
+func (r *Rotator) rotate() error {
+	var key [32]byte
+	_, err := rand.Read(key[:])
+	if err != nil {
+		return err
+	}
+
+	r.keysLock.Lock()
+	defer r.keysLock.Unlock()
+
+	keys := [][32]byte{key}
+	if len(r.keys) > 0 {
+		keys = append(keys, r.keys[0])
+	}
+	r.keys = keys
+	r.tlsConfig.SetSessionTicketKeys(keys)
+
+	return nil
+}
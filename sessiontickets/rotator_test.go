@@ -0,0 +1,143 @@
+package sessiontickets_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"net/http"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/lager/v3/lagertest"
+	"code.cloudfoundry.org/rep/sessiontickets"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/tedsuo/ifrit"
+	ginkgomon "github.com/tedsuo/ifrit/ginkgomon_v2"
+)
+
+var _ = Describe("Rotator", func() {
+	const interval = time.Hour
+
+	var (
+		logger    *lagertest.TestLogger
+		fakeClock *fakeclock.FakeClock
+		tlsConfig *tls.Config
+		rotator   *sessiontickets.Rotator
+		process   ifrit.Process
+	)
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("test")
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		tlsConfig = &tls.Config{Certificates: []tls.Certificate{selfSignedCertificate()}}
+
+		rotator = sessiontickets.NewRotator(logger, fakeClock, tlsConfig, interval)
+		process = ginkgomon.Invoke(rotator)
+	})
+
+	AfterEach(func() {
+		ginkgomon.Interrupt(process)
+	})
+
+	rotate := func() {
+		before := rotator.Keys()
+		fakeClock.WaitForWatcherAndIncrement(interval)
+		Eventually(rotator.Keys).ShouldNot(Equal(before))
+	}
+
+	It("installs a key before becoming ready", func() {
+		Expect(rotator.Keys()).To(HaveLen(1))
+	})
+
+	It("rotates in a fresh key and retains the previous one", func() {
+		first := rotator.Keys()[0]
+
+		rotate()
+		keys := rotator.Keys()
+		Expect(keys).To(HaveLen(2))
+		Expect(keys[0]).NotTo(Equal(first))
+		Expect(keys[1]).To(Equal(first))
+
+		rotate()
+		Expect(rotator.Keys()).To(HaveLen(2))
+		Expect(rotator.Keys()).NotTo(ContainElement(first))
+	})
+
+	Describe("session resumption", func() {
+		var (
+			listener net.Listener
+			client   *http.Client
+			url      string
+		)
+
+		BeforeEach(func() {
+			var err error
+			listener, err = net.Listen("tcp", "127.0.0.1:0")
+			Expect(err).NotTo(HaveOccurred())
+			url = "https://" + listener.Addr().String()
+
+			server := &http.Server{
+				Handler:           http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}),
+				ReadHeaderTimeout: 5 * time.Second,
+			}
+			go server.Serve(tls.NewListener(listener, tlsConfig))
+
+			client = &http.Client{Transport: &http.Transport{
+				DisableKeepAlives: true,
+				TLSClientConfig: &tls.Config{
+					InsecureSkipVerify: true, // #nosec G402 - self-signed test certificate
+					ClientSessionCache: tls.NewLRUClientSessionCache(1),
+				},
+			}}
+		})
+
+		AfterEach(func() {
+			listener.Close()
+		})
+
+		resumed := func() bool {
+			resp, err := client.Get(url)
+			Expect(err).NotTo(HaveOccurred())
+			resp.Body.Close()
+			return resp.TLS.DidResume
+		}
+
+		It("resumes sessions issued before the last rotation", func() {
+			Expect(resumed()).To(BeFalse())
+
+			rotate()
+			Expect(resumed()).To(BeTrue())
+		})
+
+		It("stops resuming sessions once their key is no longer retained", func() {
+			Expect(resumed()).To(BeFalse())
+
+			rotate()
+			rotate()
+			Expect(resumed()).To(BeFalse())
+		})
+	})
+})
+
+func selfSignedCertificate() tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	Expect(err).NotTo(HaveOccurred())
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	Expect(err).NotTo(HaveOccurred())
+
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
@@ -0,0 +1,12 @@
+package sessiontickets_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"testing"
+)
+
+func TestSessionTickets(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Session Tickets Suite")
+}
@@ -0,0 +1,42 @@
+package shutdown
+
+import (
+	"errors"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/executor"
+	"code.cloudfoundry.org/lager/v3"
+)
+
+var ErrCleanupTimedOut = errors.New("executor cleanup did not finish before the timeout")
+
+// CleanupExecutor cleans up the executor client, giving up after timeout so
+// a hung executor cannot block the rep from exiting. The abandoned cleanup
+// keeps running until the process exits. A non-positive timeout waits for
+// the cleanup to finish. It reports whether the cleanup finished.
+func CleanupExecutor(logger lager.Logger, clk clock.Clock, executorClient executor.Client, timeout time.Duration) bool {
+	logger = logger.Session("executor-cleanup")
+
+	if timeout <= 0 {
+		executorClient.Cleanup(logger)
+		return true
+	}
+
+	done := make(chan struct{})
+	go func() {
+		executorClient.Cleanup(logger)
+		close(done)
+	}()
+
+	timer := clk.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case <-done:
+		return true
+	case <-timer.C():
+		logger.Error("incomplete", ErrCleanupTimedOut, lager.Data{"timeout": timeout.String()})
+		return false
+	}
+}
@@ -0,0 +1,78 @@
+package shutdown_test
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/executor/fakes"
+	"code.cloudfoundry.org/lager/v3"
+	"code.cloudfoundry.org/lager/v3/lagertest"
+	"code.cloudfoundry.org/rep/shutdown"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("CleanupExecutor", func() {
+	const timeout = 10 * time.Second
+
+	var (
+		logger         *lagertest.TestLogger
+		fakeClock      *fakeclock.FakeClock
+		executorClient *fakes.FakeClient
+		release        chan struct{}
+	)
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("test")
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		executorClient = new(fakes.FakeClient)
+		release = make(chan struct{})
+	})
+
+	AfterEach(func() {
+		close(release)
+	})
+
+	Context("when the cleanup finishes in time", func() {
+		It("reports that it finished", func() {
+			Expect(shutdown.CleanupExecutor(logger, fakeClock, executorClient, timeout)).To(BeTrue())
+			Expect(executorClient.CleanupCallCount()).To(Equal(1))
+		})
+	})
+
+	Context("when the executor hangs", func() {
+		BeforeEach(func() {
+			executorClient.CleanupStub = func(lager.Logger) {
+				<-release
+			}
+		})
+
+		It("gives up after the timeout", func() {
+			finished := make(chan bool)
+			go func() {
+				finished <- shutdown.CleanupExecutor(logger, fakeClock, executorClient, timeout)
+			}()
+
+			fakeClock.WaitForWatcherAndIncrement(timeout - time.Second)
+			Consistently(finished).ShouldNot(Receive())
+
+			fakeClock.Increment(time.Second)
+			Eventually(finished).Should(Receive(BeFalse()))
+			Expect(logger).To(gbytes.Say("executor-cleanup.incomplete"))
+		})
+
+		Context("and there is no timeout", func() {
+			It("waits for the cleanup", func() {
+				finished := make(chan bool)
+				go func() {
+					finished <- shutdown.CleanupExecutor(logger, fakeClock, executorClient, 0)
+				}()
+
+				Consistently(finished).ShouldNot(Receive())
+				release <- struct{}{}
+				Eventually(finished).Should(Receive(BeTrue()))
+			})
+		})
+	})
+})
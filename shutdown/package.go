@@ -0,0 +1 @@
+package shutdown // import "code.cloudfoundry.org/rep/shutdown"
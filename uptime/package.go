@@ -0,0 +1 @@
+package uptime // import "code.cloudfoundry.org/rep/uptime"
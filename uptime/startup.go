@@ -0,0 +1,23 @@
+package uptime
+
+import (
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	loggingclient "code.cloudfoundry.org/diego-logging-client"
+	"code.cloudfoundry.org/lager/v3"
+)
+
+const startupDurationMetric = "RepStartupDuration"
+
+// ReportStartupComplete logs and emits how long the rep took from its
+// process starting to all of its members being ready.
+func ReportStartupComplete(logger lager.Logger, clk clock.Clock, metronClient loggingclient.IngressClient, processStartedAt time.Time) {
+	duration := clk.Since(processStartedAt)
+	logger.Info("startup-complete", lager.Data{"duration": duration.String()})
+
+	err := metronClient.SendDuration(startupDurationMetric, duration)
+	if err != nil {
+		logger.Error("failed-to-send-startup-duration-metric", err)
+	}
+}
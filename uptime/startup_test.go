@@ -0,0 +1,48 @@
+package uptime_test
+
+import (
+	"errors"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	mfakes "code.cloudfoundry.org/diego-logging-client/testhelpers"
+	"code.cloudfoundry.org/lager/v3/lagertest"
+	"code.cloudfoundry.org/rep/uptime"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gbytes"
+)
+
+var _ = Describe("ReportStartupComplete", func() {
+	var (
+		logger           *lagertest.TestLogger
+		fakeClock        *fakeclock.FakeClock
+		fakeMetronClient *mfakes.FakeIngressClient
+		processStartedAt time.Time
+	)
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("test")
+		processStartedAt = time.Now()
+		fakeClock = fakeclock.NewFakeClock(processStartedAt)
+		fakeMetronClient = new(mfakes.FakeIngressClient)
+	})
+
+	It("emits the time from the process starting to now once", func() {
+		fakeClock.Increment(3 * time.Second)
+		uptime.ReportStartupComplete(logger, fakeClock, fakeMetronClient, processStartedAt)
+
+		Expect(fakeMetronClient.SendDurationCallCount()).To(Equal(1))
+		name, value, _ := fakeMetronClient.SendDurationArgsForCall(0)
+		Expect(name).To(Equal("RepStartupDuration"))
+		Expect(value).To(Equal(3 * time.Second))
+		Expect(logger).To(gbytes.Say("startup-complete"))
+	})
+
+	It("logs when emitting the metric fails", func() {
+		fakeMetronClient.SendDurationReturns(errors.New("boom"))
+		uptime.ReportStartupComplete(logger, fakeClock, fakeMetronClient, processStartedAt)
+
+		Expect(logger).To(gbytes.Say("failed-to-send-startup-duration-metric"))
+	})
+})
@@ -0,0 +1,97 @@
+package uptime
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"code.cloudfoundry.org/lager/v3"
+)
+
+// Status is how long the rep has been up and how many times it restarted.
+type Status struct {
+	StartedAt     time.Time `json:"started_at"`
+	UptimeSeconds float64   `json:"uptime_seconds"`
+	RestartCount  int       `json:"restart_count"`
+}
+
+// Tracker reports the rep's uptime and restart count. The restart count is
+// persisted to a file so it survives the process; without a file it is
+// always 0.
+type Tracker struct {
+	clock        clock.Clock
+	startedAt    time.Time
+	restartCount int
+}
+
+// NewTracker records a start of the rep. When restartCountFile already
+// exists this start is counted as a restart.
+func NewTracker(logger lager.Logger, clk clock.Clock, restartCountFile string) (*Tracker, error) {
+	tracker := &Tracker{
+		clock:     clk,
+		startedAt: clk.Now(),
+	}
+
+	if restartCountFile == "" {
+		return tracker, nil
+	}
+
+	restartCount, err := recordStart(restartCountFile)
+	if err != nil {
+		return nil, err
+	}
+	tracker.restartCount = restartCount
+
+	logger.Info("recorded-start", lager.Data{"restart-count": restartCount})
+	return tracker, nil
+}
+
+func (t *Tracker) Status() Status {
+	return Status{
+		StartedAt:     t.startedAt,
+		UptimeSeconds: t.clock.Since(t.startedAt).Seconds(),
+		RestartCount:  t.restartCount,
+	}
+}
+
+func recordStart(path string) (int, error) {
+	restartCount := 0
+
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		previous, err := strconv.Atoi(strings.TrimSpace(string(data)))
+		if err != nil {
+			return 0, fmt.Errorf("invalid restart count in %s: %w", path, err)
+		}
+		restartCount = previous + 1
+	case !os.IsNotExist(err):
+		return 0, err
+	}
+
+	// write to a temporary file first so a crash never leaves a truncated count
+	tmpFile, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(tmpFile.Name())
+
+	_, err = tmpFile.WriteString(strconv.Itoa(restartCount))
+	if closeErr := tmpFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	err = os.Rename(tmpFile.Name(), path)
+	if err != nil {
+		return 0, err
+	}
+
+	return restartCount, nil
+}
@@ -0,0 +1,73 @@
+package uptime_test
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	"code.cloudfoundry.org/lager/v3/lagertest"
+	"code.cloudfoundry.org/rep/uptime"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Tracker", func() {
+	var (
+		logger           *lagertest.TestLogger
+		fakeClock        *fakeclock.FakeClock
+		restartCountFile string
+	)
+
+	BeforeEach(func() {
+		logger = lagertest.NewTestLogger("test")
+		fakeClock = fakeclock.NewFakeClock(time.Now())
+		restartCountFile = filepath.Join(GinkgoT().TempDir(), "restart-count")
+	})
+
+	It("reports an increasing uptime", func() {
+		tracker, err := uptime.NewTracker(logger, fakeClock, restartCountFile)
+		Expect(err).NotTo(HaveOccurred())
+
+		startedAt := fakeClock.Now()
+		Expect(tracker.Status().StartedAt).To(Equal(startedAt))
+		Expect(tracker.Status().UptimeSeconds).To(BeZero())
+
+		fakeClock.Increment(90 * time.Second)
+		Expect(tracker.Status().UptimeSeconds).To(Equal(90.0))
+		Expect(tracker.Status().StartedAt).To(Equal(startedAt))
+	})
+
+	It("counts every start after the first as a restart", func() {
+		for expected := 0; expected < 3; expected++ {
+			tracker, err := uptime.NewTracker(logger, fakeClock, restartCountFile)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tracker.Status().RestartCount).To(Equal(expected))
+		}
+
+		data, err := os.ReadFile(restartCountFile)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(data)).To(Equal("2"))
+	})
+
+	Context("when the restart count file is corrupt", func() {
+		BeforeEach(func() {
+			Expect(os.WriteFile(restartCountFile, []byte("garbage"), 0644)).To(Succeed())
+		})
+
+		It("returns an error", func() {
+			_, err := uptime.NewTracker(logger, fakeClock, restartCountFile)
+			Expect(err).To(MatchError(ContainSubstring("invalid restart count")))
+		})
+	})
+
+	Context("when no restart count file is configured", func() {
+		It("always reports no restarts", func() {
+			for i := 0; i < 2; i++ {
+				tracker, err := uptime.NewTracker(logger, fakeClock, "")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(tracker.Status().RestartCount).To(BeZero())
+			}
+		})
+	})
+})
@@ -0,0 +1,12 @@
+package uptime_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"testing"
+)
+
+func TestUptime(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Uptime Suite")
+}